@@ -1,7 +1,7 @@
 // Commande simple-bot : binaire unique regroupant toutes les sous-commandes du projet
-// (bot, web, admin, backtest, patternscan, order, rsi, volatility, test). Le code commun
-// n'est ainsi compilé et déployé qu'une seule fois. Chaque sous-commande vit dans un
-// package internal/cli/<nom>cli exposant Main(args []string).
+// (bot, web, admin, strategy, backtest, patternscan, order, reconcile, rsi, volatility, test).
+// Le code commun n'est ainsi compilé et déployé qu'une seule fois. Chaque sous-commande
+// vit dans un package internal/cli/<nom>cli exposant Main(args []string).
 //
 // Usage : simple-bot [--root DIR] <commande> [options de la commande...]
 //
@@ -25,7 +25,9 @@ import (
 	"bot/internal/cli/botcli"
 	"bot/internal/cli/ordercli"
 	"bot/internal/cli/patternscancli"
+	"bot/internal/cli/reconcilecli"
 	"bot/internal/cli/rsicli"
+	"bot/internal/cli/strategycli"
 	"bot/internal/cli/testcli"
 	"bot/internal/cli/volatilitycli"
 	"bot/internal/cli/webcli"
@@ -37,9 +39,11 @@ var commands = map[string]func(args []string){
 	"bot":         botcli.Main,
 	"web":         webcli.Main,
 	"admin":       admincli.Main,
+	"strategy":    strategycli.Main,
 	"backtest":    backtestcli.Main,
 	"patternscan": patternscancli.Main,
 	"order":       ordercli.Main,
+	"reconcile":   reconcilecli.Main,
 	"rsi":         rsicli.Main,
 	"volatility":  volatilitycli.Main,
 	"test":        testcli.Main,
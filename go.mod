@@ -11,6 +11,8 @@ require (
 	github.com/joho/godotenv v1.5.1 // direct
 	github.com/mattn/go-sqlite3 v1.14.48 // direct
 	github.com/robfig/cron/v3 v3.0.1 // direct
+	golang.org/x/crypto v0.54.0 // direct
+	gopkg.in/yaml.v3 v3.0.1 // direct
 )
 
 require github.com/anthropics/anthropic-sdk-go v1.57.0
@@ -77,11 +79,9 @@ require (
 	go.mongodb.org/mongo-driver/v2 v2.8.0 // indirect
 	go.yaml.in/yaml/v4 v4.0.0-rc.6 // indirect
 	golang.org/x/arch v0.29.0 // indirect
-	golang.org/x/crypto v0.54.0 // indirect
 	golang.org/x/net v0.57.0 // indirect
 	golang.org/x/sync v0.22.0 // indirect
 	golang.org/x/sys v0.47.0 // indirect
 	golang.org/x/text v0.40.0 // indirect
 	google.golang.org/protobuf v1.36.11 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
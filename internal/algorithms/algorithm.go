@@ -1,7 +1,10 @@
 package algorithms
 
 import (
+	"time"
+
 	"bot/internal/core/database"
+	"bot/internal/decimal"
 )
 
 // MarketPrecision represents market precision information
@@ -10,6 +13,15 @@ type MarketPrecision struct {
 	PriceDecimals  int
 	Amount         float64
 	AmountDecimals int
+
+	// Limites de l'exchange (0 = pas de limite), cf. FitBuySignal et CheckSell :
+	// quantité de base, montant en devise de cotation (notional) et bande de prix.
+	MinAmount float64
+	MaxAmount float64
+	MinCost   float64
+	MaxCost   float64
+	MinPrice  float64
+	MaxPrice  float64
 }
 
 // IndicatorCalculator fournit les indicateurs techniques nécessaires aux
@@ -23,6 +35,7 @@ type IndicatorCalculator interface {
 	CalculateVolatility(pair, timeframe string, period int) (float64, error)
 	CalculateRecentHigh(pair, timeframe string, periods int) (float64, error)
 	CalculateMACD(pair, timeframe string, fastPeriod, slowPeriod, signalPeriod int) (macd, signal, histogram float64, err error)
+	CalculateBollingerBands(pair, timeframe string, period int, k float64) (upper, middle, lower float64, err error)
 }
 
 // TradingContext provides all necessary data for trading decisions
@@ -32,6 +45,7 @@ type TradingContext struct {
 	CurrentPrice float64
 	Calculator   IndicatorCalculator
 	Precision    MarketPrecision // Ajout des précisions du marché
+	Now          time.Time       // instant de la décision (zéro = horloge système, instant simulé en backtest)
 }
 
 // Balance represents asset balance
@@ -50,6 +64,7 @@ type SellSignal struct {
 	ShouldSell bool
 	LimitPrice float64
 	Reason     string
+	Protective bool // sortie de protection (stop-loss, âge maximal, prix de revient)
 }
 
 // ForceBuyer est une capacité OPTIONNELLE : un algorithme qui l'implémente peut
@@ -61,6 +76,17 @@ type ForceBuyer interface {
 	ForceBuySignal(ctx TradingContext, strategy database.Strategy) (BuySignal, error)
 }
 
+// LadderBuyer est une capacité OPTIONNELLE : un algorithme qui l'implémente pose
+// PLUSIEURS achats en un seul tick (ex. grille : un ordre limite par niveau libre).
+// active contient les cycles actifs de la stratégie (achat en attente ou position
+// non encore vendue), pour que l'algorithme ne repose pas un ordre sur un niveau
+// déjà occupé. Les signaux sont triés par priorité : l'appelant peut tronquer la
+// liste (plafond de cycles concurrents, solde) sans perdre les plus pertinents.
+// Quand il est implémenté, il remplace ShouldBuy dans l'exécution et le backtest.
+type LadderBuyer interface {
+	LadderBuySignals(ctx TradingContext, strategy database.Strategy, active []database.Cycle) ([]BuySignal, error)
+}
+
 // Algorithm defines the interface for trading algorithms
 type Algorithm interface {
 	// Algorithm identification
@@ -92,6 +118,8 @@ func NewAlgorithmRegistry() *AlgorithmRegistry {
 	// Register built-in algorithms
 	registry.Register(&RSI_DCA{})
 	registry.Register(&MACD_Cross{})
+	registry.Register(&Bollinger_DCA{})
+	registry.Register(&Grid{})
 
 	return registry
 }
@@ -121,15 +149,30 @@ func (ar *AlgorithmRegistry) GetAll() map[string]Algorithm {
 	return ar.algorithms
 }
 
-// RoundPrice rounds a price according to market precision
+// RoundPrice rounds a price DOWN to the market tick (exact decimal arithmetic)
 func RoundPrice(price float64, precision MarketPrecision) float64 {
-	factor := 1 / precision.Price
-	return float64(int64(price*factor)) / factor
+	return roundStep(price, precision.Price, decimal.Down)
+}
+
+// RoundPriceUp arrondit un prix au tick SUPÉRIEUR (limite de vente qui ne doit pas
+// passer sous un prix de revient).
+func RoundPriceUp(price float64, precision MarketPrecision) float64 {
+	return roundStep(price, precision.Price, decimal.Ceil)
 }
 
-// RoundAmountUp rounds an amount UP according to market precision
-// This ensures that the order value meets minimum requirements
+// RoundAmount rounds an amount UP to the market step, so that the order value covers
+// the quote amount it was computed from. An amount already on the step is unchanged.
 func RoundAmount(amount float64, precision MarketPrecision) float64 {
-	factor := 1 / precision.Amount
-	return float64(int64(amount*factor)+1) / factor
+	return roundStep(amount, precision.Amount, decimal.Up)
+}
+
+// Cost retourne le montant en devise de cotation (notional) d'un ordre, sans erreur
+// d'arrondi flottant.
+func Cost(amount, price float64) float64 {
+	return decimal.FromFloat(amount).Mul(decimal.FromFloat(price)).Float64()
+}
+
+// roundStep arrondit v à un multiple de step (0 = pas inconnu, v inchangé).
+func roundStep(v, step float64, mode decimal.RoundingMode) float64 {
+	return decimal.FromFloat(v).RoundStep(decimal.FromFloat(step), mode).Float64()
 }
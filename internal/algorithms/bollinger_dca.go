@@ -0,0 +1,197 @@
+package algorithms
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"fmt"
+)
+
+// Bollinger_DCA implémente un retour à la moyenne sur les bandes de Bollinger :
+// achat quand le prix passe sous la bande basse (filtre RSI optionnel), cible sur
+// la bande médiane, jamais en dessous de l'objectif de profit.
+type Bollinger_DCA struct{}
+
+// Name returns the algorithm name
+func (a *Bollinger_DCA) Name() string {
+	return "bollinger_dca"
+}
+
+// Description returns the algorithm description
+func (a *Bollinger_DCA) Description() string {
+	return "Bollinger Bands mean reversion: buys below the lower band, targets the middle band"
+}
+
+// RequiredIndicators returns the indicators needed by this algorithm
+func (a *Bollinger_DCA) RequiredIndicators() []string {
+	return []string{"BollingerBands", "RSI"}
+}
+
+// ValidateConfig validates the strategy configuration for this algorithm
+func (a *Bollinger_DCA) ValidateConfig(strategy database.Strategy) error {
+	if strategy.BBPeriod <= 1 {
+		return fmt.Errorf("bb_period must be greater than 1, got %d", strategy.BBPeriod)
+	}
+	if strategy.BBMultiplier <= 0 {
+		return fmt.Errorf("bb_multiplier must be positive, got %.2f", strategy.BBMultiplier)
+	}
+	if strategy.BBTimeframe == "" {
+		return fmt.Errorf("bb_timeframe is required for Bollinger_DCA algorithm")
+	}
+	if strategy.ProfitTarget <= 0 {
+		return fmt.Errorf("profit_target must be positive, got %.2f", strategy.ProfitTarget)
+	}
+
+	// Filtre RSI optionnel : actif dès qu'un seuil est renseigné
+	if strategy.RSIThreshold != nil {
+		if *strategy.RSIThreshold < 0 || *strategy.RSIThreshold > 100 {
+			return fmt.Errorf("rsi_threshold must be between 0 and 100, got %.2f", *strategy.RSIThreshold)
+		}
+		if strategy.RSIPeriod == nil || *strategy.RSIPeriod <= 0 {
+			return fmt.Errorf("rsi_period must be positive when rsi_threshold is set")
+		}
+	}
+
+	if err := validateExitRules(strategy); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ShouldBuy achète quand le prix courant est sous la bande basse et, si le filtre
+// RSI est configuré, que le RSI est sous son seuil.
+func (a *Bollinger_DCA) ShouldBuy(ctx TradingContext, strategy database.Strategy) (BuySignal, error) {
+	logger.Debugf("[%s] Bollinger_DCA.ShouldBuy: checking Bollinger Bands for %s", ctx.ExchangeName, ctx.Pair)
+
+	upper, middle, lower, err := ctx.Calculator.CalculateBollingerBands(ctx.Pair, strategy.BBTimeframe, strategy.BBPeriod, strategy.BBMultiplier)
+	if err != nil {
+		return BuySignal{}, fmt.Errorf("failed to calculate Bollinger Bands: %w", err)
+	}
+
+	logger.Infof("[%s] Bollinger_DCA.ShouldBuy: price = %.4f, bands = %.4f / %.4f / %.4f",
+		ctx.ExchangeName, ctx.CurrentPrice, lower, middle, upper)
+
+	if ctx.CurrentPrice >= lower {
+		return BuySignal{
+			ShouldBuy: false,
+			Reason:    fmt.Sprintf("Price %.4f >= lower band %.4f", ctx.CurrentPrice, lower),
+		}, nil
+	}
+
+	reason := fmt.Sprintf("Price %.4f < lower band %.4f (BB%d x%.1f %s)",
+		ctx.CurrentPrice, lower, strategy.BBPeriod, strategy.BBMultiplier, strategy.BBTimeframe)
+
+	// Filtre RSI (optionnel) : confirme la survente
+	if strategy.RSIThreshold != nil && strategy.RSIPeriod != nil {
+		rsiTimeframe := strategy.RSITimeframe
+		if rsiTimeframe == "" {
+			rsiTimeframe = strategy.BBTimeframe
+		}
+		rsi, err := ctx.Calculator.CalculateRSI(ctx.Pair, rsiTimeframe, *strategy.RSIPeriod)
+		if err != nil {
+			return BuySignal{}, fmt.Errorf("failed to calculate RSI: %w", err)
+		}
+		logger.Infof("[%s] Bollinger_DCA.ShouldBuy: RSI = %.2f, threshold = %.2f", ctx.ExchangeName, rsi, *strategy.RSIThreshold)
+		if rsi > *strategy.RSIThreshold {
+			return BuySignal{
+				ShouldBuy: false,
+				Reason:    fmt.Sprintf("%s but RSI %.2f > threshold %.2f", reason, rsi, *strategy.RSIThreshold),
+			}, nil
+		}
+		reason = fmt.Sprintf("%s, RSI %.2f < %.2f", reason, rsi, *strategy.RSIThreshold)
+	}
+
+	return a.buildBuySignal(ctx, strategy, middle, reason), nil
+}
+
+// ForceBuySignal construit un signal d'achat en COURT-CIRCUITANT la condition de bande
+// basse et le filtre RSI (achat manuel). Les bandes restent calculées : la bande
+// médiane fixe la cible, comme pour un achat automatique.
+func (a *Bollinger_DCA) ForceBuySignal(ctx TradingContext, strategy database.Strategy) (BuySignal, error) {
+	_, middle, lower, err := ctx.Calculator.CalculateBollingerBands(ctx.Pair, strategy.BBTimeframe, strategy.BBPeriod, strategy.BBMultiplier)
+	if err != nil {
+		return BuySignal{}, fmt.Errorf("failed to calculate Bollinger Bands: %w", err)
+	}
+
+	logger.Infof("[%s] Bollinger_DCA.ForceBuySignal: achat manuel forcé (prix %.4f, bande basse %.4f, filtres ignorés)",
+		ctx.ExchangeName, ctx.CurrentPrice, lower)
+	return a.buildBuySignal(ctx, strategy, middle, "Manual buy (Bollinger filters bypassed)"), nil
+}
+
+// buildBuySignal calcule le signal d'achat au prix courant. La cible est la bande
+// médiane, relevée à l'objectif de profit quand la bande est trop proche du prix
+// d'achat (elle ne couvrirait pas les frais).
+func (a *Bollinger_DCA) buildBuySignal(ctx TradingContext, strategy database.Strategy, middle float64, reason string) BuySignal {
+	limitPrice := RoundPrice(ctx.CurrentPrice, ctx.Precision)
+
+	targetPrice := middle
+	minTarget := limitPrice * (1.0 + strategy.ProfitTarget/100.0)
+	if targetPrice < minTarget {
+		targetPrice = minTarget
+	}
+	targetPrice = RoundPrice(targetPrice, ctx.Precision)
+
+	baseAmount := strategy.QuoteAmount / limitPrice
+	baseAmount = RoundAmount(baseAmount, ctx.Precision)
+
+	logger.Infof("[%s] Bollinger_DCA.ShouldBuy: BUY signal - LimitPrice=%.4f, MiddleBand=%.4f, TargetPrice=%.4f",
+		ctx.ExchangeName, limitPrice, middle, targetPrice)
+
+	return BuySignal{
+		ShouldBuy:   true,
+		Amount:      baseAmount,
+		LimitPrice:  limitPrice,
+		TargetPrice: targetPrice,
+		Reason:      fmt.Sprintf("%s, target %.4f (middle band %.4f)", reason, targetPrice, middle),
+	}
+}
+
+// ShouldSell determines if we should sell a position
+func (a *Bollinger_DCA) ShouldSell(ctx TradingContext, cycle database.Cycle, strategy database.Strategy) (SellSignal, error) {
+	logger.Debugf("[%s] Bollinger_DCA.ShouldSell: checking cycle %d", ctx.ExchangeName, cycle.ID)
+
+	// Sorties de protection (stop-loss, âge maximal, prix de revient)
+	if signal, ok := checkExitRules(ctx, cycle, strategy); ok {
+		return signal, nil
+	}
+
+	// Cible atteinte : le trailing stop décide de la sortie
+	if ctx.CurrentPrice >= cycle.TargetPrice {
+		trailingStopThreshold := 1.0 - (strategy.TrailingStopDelta / 100.0)
+
+		if ctx.CurrentPrice < (cycle.MaxPrice * trailingStopThreshold) {
+			priceOffset := ctx.CurrentPrice * (strategy.SellOffset / 100.0)
+			limitPrice := RoundPrice(ctx.CurrentPrice+priceOffset, ctx.Precision)
+
+			logger.Infof("[%s] Bollinger_DCA.ShouldSell: SELL signal - Trailing Stop triggered for position in cycle %d", ctx.ExchangeName, cycle.ID)
+
+			return SellSignal{
+				ShouldSell: true,
+				LimitPrice: limitPrice,
+				Reason: fmt.Sprintf("Trailing Stop: %.4f < Max %.4f * %.4f%% = %.4f",
+					ctx.CurrentPrice, cycle.MaxPrice, (1.0-trailingStopThreshold)*100.0, cycle.MaxPrice*trailingStopThreshold),
+			}, nil
+		}
+	}
+
+	return SellSignal{
+		ShouldSell: false,
+		Reason: fmt.Sprintf("Holding position - current %.4f, target %.4f, max %.4f",
+			ctx.CurrentPrice, cycle.TargetPrice, cycle.MaxPrice),
+	}, nil
+}
+
+// GetParameterHints returns hints for configuring this algorithm
+func (a *Bollinger_DCA) GetParameterHints() map[string]string {
+	return map[string]string{
+		"bb_period":           "Période de la moyenne mobile des bandes (20 standard)",
+		"bb_multiplier":       "Nombre d'écarts-types entre la médiane et les bandes (2.0 standard)",
+		"bb_timeframe":        "Timeframe des bandes (1h, 4h typiques)",
+		"rsi_threshold":       "Filtre RSI optionnel : n'achète que si RSI < seuil (vide = sans filtre)",
+		"rsi_period":          "Période RSI du filtre (14 standard)",
+		"rsi_timeframe":       "Timeframe RSI du filtre (défaut : timeframe des bandes)",
+		"profit_target":       "Profit minimum en % : la cible est la bande médiane, relevée à ce minimum",
+		"trailing_stop_delta": "Trailing stop percentage",
+		"sell_offset":         "Price offset above market for sell orders",
+	}
+}
@@ -0,0 +1,103 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+
+	"bot/internal/core/database"
+)
+
+func bollingerStrategy() database.Strategy {
+	s := baseStrategy(0)
+	s.AlgorithmName = "bollinger_dca"
+	s.RSIThreshold = nil
+	s.BBPeriod = 20
+	s.BBMultiplier = 2
+	s.BBTimeframe = "1h"
+	s.ProfitTarget = 1.0
+	return s
+}
+
+// Achat sous la bande basse uniquement, filtre RSI optionnel, cible sur la bande
+// médiane relevée à l'objectif de profit quand elle est trop proche.
+func TestBollingerDCA_ShouldBuy(t *testing.T) {
+	algo := &Bollinger_DCA{}
+	precision := MarketPrecision{Price: 0.01, Amount: 0.0001}
+	bands := [3]float64{110, 104, 98}
+
+	cases := []struct {
+		name       string
+		price      float64
+		rsi        float64
+		threshold  *float64
+		middle     float64
+		wantBuy    bool
+		wantTarget float64
+	}{
+		{"au-dessus de la bande basse", 99, 20, nil, 104, false, 0},
+		{"sous la bande basse : cible médiane", 97, 50, nil, 104, true, 104},
+		{"médiane trop proche : objectif de profit", 97, 50, nil, 97.5, true, 97.97},
+		{"filtre RSI bloquant", 97, 45, fp(40), 104, false, 0},
+		{"filtre RSI passant", 97, 35, fp(40), 104, true, 104},
+	}
+
+	for _, tc := range cases {
+		strategy := bollingerStrategy()
+		strategy.RSIThreshold = tc.threshold
+		if err := algo.ValidateConfig(strategy); err != nil {
+			t.Fatalf("%s : config invalide : %v", tc.name, err)
+		}
+		b := bands
+		b[1] = tc.middle
+		ctx := TradingContext{
+			ExchangeName: "test", Pair: "BTC/USDC", CurrentPrice: tc.price, Precision: precision,
+			Calculator: stubCalc{rsi: tc.rsi, bands: b},
+		}
+
+		sig, err := algo.ShouldBuy(ctx, strategy)
+		if err != nil {
+			t.Fatalf("%s : %v", tc.name, err)
+		}
+		if sig.ShouldBuy != tc.wantBuy {
+			t.Errorf("%s : achat=%v, attendu %v (%s)", tc.name, sig.ShouldBuy, tc.wantBuy, sig.Reason)
+			continue
+		}
+		if tc.wantBuy && math.Abs(sig.TargetPrice-tc.wantTarget) > 1e-9 {
+			t.Errorf("%s : cible %.4f, attendu %.4f", tc.name, sig.TargetPrice, tc.wantTarget)
+		}
+	}
+}
+
+// L'achat manuel ignore la bande basse mais garde la cible sur la médiane.
+func TestBollingerDCA_ForceBuy(t *testing.T) {
+	algo := &Bollinger_DCA{}
+	ctx := TradingContext{
+		ExchangeName: "test", Pair: "BTC/USDC", CurrentPrice: 100,
+		Precision:  MarketPrecision{Price: 0.01, Amount: 0.0001},
+		Calculator: stubCalc{bands: [3]float64{110, 104, 98}},
+	}
+	var _ ForceBuyer = algo
+
+	sig, err := algo.ForceBuySignal(ctx, bollingerStrategy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.ShouldBuy || sig.LimitPrice != 100 || sig.TargetPrice != 104 {
+		t.Errorf("achat forcé = %+v, attendu achat @ 100, cible 104", sig)
+	}
+}
+
+func TestBollingerDCA_ValidateConfig(t *testing.T) {
+	algo := &Bollinger_DCA{}
+	strategy := bollingerStrategy()
+	strategy.BBMultiplier = 0
+	if err := algo.ValidateConfig(strategy); err == nil {
+		t.Error("multiplicateur nul accepté")
+	}
+	strategy = bollingerStrategy()
+	strategy.RSIThreshold = fp(30)
+	strategy.RSIPeriod = nil
+	if err := algo.ValidateConfig(strategy); err == nil {
+		t.Error("filtre RSI sans période accepté")
+	}
+}
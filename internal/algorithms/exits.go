@@ -0,0 +1,142 @@
+package algorithms
+
+import (
+	"fmt"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/logger"
+)
+
+// marketExitDiscount (%) : une sortie « au marché » est posée en ordre limite sous le
+// prix courant, pour être exécutée immédiatement (le bot ne place que des ordres limites).
+const marketExitDiscount = 0.5
+
+// validateExitRules vérifie les sorties de protection, communes à tous les algorithmes.
+func validateExitRules(strategy database.Strategy) error {
+	if strategy.StopLossPercent < 0 || strategy.StopLossPercent >= 100 {
+		return fmt.Errorf("stop_loss_percent must be between 0 and 100, got %.2f", strategy.StopLossPercent)
+	}
+	if strategy.MaxCycleAgeDays < 0 {
+		return fmt.Errorf("max_cycle_age_days cannot be negative, got %d", strategy.MaxCycleAgeDays)
+	}
+	if strategy.BreakEvenAfterDays < 0 {
+		return fmt.Errorf("break_even_after_days cannot be negative, got %d", strategy.BreakEvenAfterDays)
+	}
+	switch strategy.MaxCycleAgeExit {
+	case "", database.CycleAgeExitMarket, database.CycleAgeExitBreakEven:
+	default:
+		return fmt.Errorf("max_cycle_age_exit must be %q or %q, got %q",
+			database.CycleAgeExitMarket, database.CycleAgeExitBreakEven, strategy.MaxCycleAgeExit)
+	}
+	return nil
+}
+
+// breakEvenPrice retourne le prix de vente qui couvre le prix d'achat et les frais
+// des deux côtés. Les frais de vente sont supposés au même taux que ceux de l'achat.
+func breakEvenPrice(cycle database.Cycle) float64 {
+	buyPrice := cycle.BuyOrder.ExecutedPrice()
+	cost := buyPrice * cycle.BuyOrder.ExecutedAmount()
+	if cost <= 0 {
+		return buyPrice
+	}
+	feeRate := cycle.BuyOrder.Fees / cost
+	if feeRate >= 1 {
+		return buyPrice
+	}
+	return buyPrice * (1 + feeRate) / (1 - feeRate)
+}
+
+// checkExitRules applique les sorties de protection de la stratégie, dans l'ordre :
+//   - stop-loss : le prix est tombé de StopLossPercent sous le prix d'achat -> vente au marché ;
+//   - âge maximal : le cycle a plus de MaxCycleAgeDays jours -> vente au marché, ou
+//     ordre limite au prix de revient selon MaxCycleAgeExit ;
+//   - prix de revient : après BreakEvenAfterDays jours, on vend dès que le prix couvre
+//     l'achat et les frais, sans attendre l'objectif.
+//
+// Le second retour indique si une règle s'applique ; sinon l'algorithme poursuit
+// avec sa logique habituelle (objectif + trailing stop).
+func checkExitRules(ctx TradingContext, cycle database.Cycle, strategy database.Strategy) (SellSignal, bool) {
+	buyPrice := cycle.BuyOrder.ExecutedPrice()
+	if buyPrice <= 0 {
+		return SellSignal{}, false
+	}
+
+	if strategy.StopLossPercent > 0 {
+		stopPrice := buyPrice * (1 - strategy.StopLossPercent/100.0)
+		if ctx.CurrentPrice <= stopPrice {
+			logger.Infof("[%s] SELL signal - Stop-loss triggered for cycle %d", ctx.ExchangeName, cycle.ID)
+			return SellSignal{
+				ShouldSell: true,
+				Protective: true,
+				LimitPrice: marketExitPrice(ctx),
+				Reason: fmt.Sprintf("Stop-loss: %.4f <= buy %.4f - %.2f%% = %.4f",
+					ctx.CurrentPrice, buyPrice, strategy.StopLossPercent, stopPrice),
+			}, true
+		}
+	}
+
+	ageDays := ctx.now().Sub(cycle.CreatedAt).Hours() / 24
+	breakEven := breakEvenPrice(cycle)
+
+	if strategy.MaxCycleAgeDays > 0 && ageDays >= float64(strategy.MaxCycleAgeDays) {
+		logger.Infof("[%s] SELL signal - Cycle %d older than %d days", ctx.ExchangeName, cycle.ID, strategy.MaxCycleAgeDays)
+		if strategy.MaxCycleAgeExit == database.CycleAgeExitBreakEven {
+			return SellSignal{
+				ShouldSell: true,
+				Protective: true,
+				LimitPrice: breakEvenExitPrice(ctx, strategy, breakEven),
+				Reason: fmt.Sprintf("Max cycle age: %.1f days >= %d, selling at break-even %.4f",
+					ageDays, strategy.MaxCycleAgeDays, breakEven),
+			}, true
+		}
+		return SellSignal{
+			ShouldSell: true,
+			Protective: true,
+			LimitPrice: marketExitPrice(ctx),
+			Reason: fmt.Sprintf("Max cycle age: %.1f days >= %d, selling at market %.4f",
+				ageDays, strategy.MaxCycleAgeDays, ctx.CurrentPrice),
+		}, true
+	}
+
+	// Au-dessus de l'objectif, le trailing stop habituel garde la main.
+	if strategy.BreakEvenAfterDays > 0 && ageDays >= float64(strategy.BreakEvenAfterDays) &&
+		ctx.CurrentPrice >= breakEven && ctx.CurrentPrice < cycle.TargetPrice {
+		logger.Infof("[%s] SELL signal - Break-even reached for cycle %d", ctx.ExchangeName, cycle.ID)
+		return SellSignal{
+			ShouldSell: true,
+			Protective: true,
+			LimitPrice: breakEvenExitPrice(ctx, strategy, breakEven),
+			Reason: fmt.Sprintf("Break-even after %d days: %.4f >= %.4f (age %.1f days)",
+				strategy.BreakEvenAfterDays, ctx.CurrentPrice, breakEven, ageDays),
+		}, true
+	}
+
+	return SellSignal{}, false
+}
+
+// marketExitPrice retourne une limite de vente immédiatement exécutable.
+func marketExitPrice(ctx TradingContext) float64 {
+	return RoundPrice(ctx.CurrentPrice*(1-marketExitDiscount/100.0), ctx.Precision)
+}
+
+// breakEvenExitPrice retourne la limite d'une sortie au prix de revient : le prix
+// courant majoré de l'offset de vente s'il est au-dessus, sinon le prix de revient
+// (l'ordre attend alors que le marché remonte).
+func breakEvenExitPrice(ctx TradingContext, strategy database.Strategy, breakEven float64) float64 {
+	limitPrice := ctx.CurrentPrice * (1 + strategy.SellOffset/100.0)
+	if limitPrice > breakEven {
+		return RoundPrice(limitPrice, ctx.Precision)
+	}
+	// Arrondi au tick supérieur : RoundPrice tronque, ce qui vendrait à perte.
+	return RoundPriceUp(breakEven, ctx.Precision)
+}
+
+// now retourne l'instant de la décision : l'horloge système en exécution réelle,
+// l'instant simulé en backtest.
+func (ctx TradingContext) now() time.Time {
+	if ctx.Now.IsZero() {
+		return time.Now()
+	}
+	return ctx.Now
+}
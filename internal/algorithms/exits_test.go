@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"bot/internal/core/database"
+)
+
+// exitCycle construit un cycle acheté 1 @ 100 (frais 0,1 %), ouvert depuis ageDays
+// jours, avec une cible à 102.
+func exitCycle(now time.Time, ageDays int) database.Cycle {
+	return database.Cycle{
+		ID:          1,
+		TargetPrice: 102,
+		MaxPrice:    100,
+		CreatedAt:   now.Add(-time.Duration(ageDays) * 24 * time.Hour),
+		BuyOrder:    database.Order{Side: database.Buy, Status: database.Filled, Amount: 1, Price: 100, Fees: 0.1},
+	}
+}
+
+func TestBreakEvenPrice(t *testing.T) {
+	got := breakEvenPrice(exitCycle(time.Now(), 0))
+	want := 100 * 1.001 / 0.999
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("breakEvenPrice = %.6f, attendu %.6f", got, want)
+	}
+}
+
+// Chaque règle de sortie, pour les deux algorithmes : stop-loss, âge maximal (marché
+// ou prix de revient), prix de revient après N jours, et absence de règle.
+func TestShouldSell_ExitRules(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	precision := MarketPrecision{Price: 0.01, Amount: 0.0001}
+
+	cases := []struct {
+		name      string
+		price     float64
+		ageDays   int
+		configure func(s *database.Strategy)
+		wantSell  bool
+		wantProt  bool
+		wantLimit float64
+	}{
+		{"stop-loss déclenché", 89, 1,
+			func(s *database.Strategy) { s.StopLossPercent = 10 }, true, true, 88.55},
+		{"stop-loss non atteint", 91, 1,
+			func(s *database.Strategy) { s.StopLossPercent = 10 }, false, false, 0},
+		{"âge maximal, sortie au marché", 95, 31,
+			func(s *database.Strategy) { s.MaxCycleAgeDays = 30 }, true, true, 94.52},
+		{"âge maximal, sortie au prix de revient", 95, 31,
+			func(s *database.Strategy) {
+				s.MaxCycleAgeDays = 30
+				s.MaxCycleAgeExit = database.CycleAgeExitBreakEven
+			}, true, true, 100.21},
+		{"âge maximal non atteint", 95, 29,
+			func(s *database.Strategy) { s.MaxCycleAgeDays = 30 }, false, false, 0},
+		{"prix de revient après N jours, couvert", 100.5, 8,
+			func(s *database.Strategy) { s.BreakEvenAfterDays = 7 }, true, true, 100.6},
+		{"prix de revient après N jours, pas encore couvert", 100.1, 8,
+			func(s *database.Strategy) { s.BreakEvenAfterDays = 7 }, false, false, 0},
+		{"prix de revient, trop tôt", 100.5, 6,
+			func(s *database.Strategy) { s.BreakEvenAfterDays = 7 }, false, false, 0},
+		{"aucune règle : on garde sous la cible", 60, 400,
+			func(s *database.Strategy) {}, false, false, 0},
+	}
+
+	algos := []Algorithm{&RSI_DCA{}, &MACD_Cross{}}
+	for _, algo := range algos {
+		for _, tc := range cases {
+			strategy := baseStrategy(0)
+			tc.configure(&strategy)
+			if err := validateExitRules(strategy); err != nil {
+				t.Fatalf("%s : config invalide : %v", tc.name, err)
+			}
+			ctx := TradingContext{ExchangeName: "test", Pair: "BTC/USDC", CurrentPrice: tc.price, Precision: precision, Now: now}
+
+			sig, err := algo.ShouldSell(ctx, exitCycle(now, tc.ageDays), strategy)
+			if err != nil {
+				t.Fatalf("%s/%s : %v", algo.Name(), tc.name, err)
+			}
+			if sig.ShouldSell != tc.wantSell || sig.Protective != tc.wantProt {
+				t.Errorf("%s/%s : sell=%v protective=%v, attendu %v/%v (%s)",
+					algo.Name(), tc.name, sig.ShouldSell, sig.Protective, tc.wantSell, tc.wantProt, sig.Reason)
+				continue
+			}
+			if !tc.wantSell {
+				continue
+			}
+			if math.Abs(sig.LimitPrice-tc.wantLimit) > 1e-9 {
+				t.Errorf("%s/%s : limite %.4f, attendu %.4f", algo.Name(), tc.name, sig.LimitPrice, tc.wantLimit)
+			}
+		}
+	}
+}
+
+func TestValidateExitRules(t *testing.T) {
+	strategy := baseStrategy(0)
+	strategy.StopLossPercent = 100
+	if err := validateExitRules(strategy); err == nil {
+		t.Error("stop-loss à 100 % accepté")
+	}
+	strategy = baseStrategy(0)
+	strategy.MaxCycleAgeExit = "limit"
+	if err := validateExitRules(strategy); err == nil {
+		t.Error("mode de sortie inconnu accepté")
+	}
+}
@@ -0,0 +1,162 @@
+package algorithms
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"fmt"
+	"math"
+)
+
+// Grid implémente une grille d'ordres limites : GridLevels niveaux de prix répartis
+// régulièrement entre GridLowerPrice et GridUpperPrice. Un achat de QuoteAmount reste
+// posé sur chaque niveau libre sous le prix courant ; dès qu'il est rempli, la vente
+// est posée au niveau immédiatement supérieur. Le niveau se libère quand la vente est
+// exécutée et l'achat y est reposé au tick suivant.
+type Grid struct{}
+
+// Name returns the algorithm name
+func (a *Grid) Name() string {
+	return "grid"
+}
+
+// Description returns the algorithm description
+func (a *Grid) Description() string {
+	return "Grid trading: a resting buy on every empty level below the price, a sell one level above each filled buy"
+}
+
+// RequiredIndicators returns the indicators needed by this algorithm
+func (a *Grid) RequiredIndicators() []string {
+	return []string{}
+}
+
+// ValidateConfig validates the strategy configuration for this algorithm
+func (a *Grid) ValidateConfig(strategy database.Strategy) error {
+	if strategy.GridLowerPrice <= 0 {
+		return fmt.Errorf("grid_lower_price must be positive, got %.4f", strategy.GridLowerPrice)
+	}
+	if strategy.GridUpperPrice <= strategy.GridLowerPrice {
+		return fmt.Errorf("grid_upper_price (%.4f) must be greater than grid_lower_price (%.4f)",
+			strategy.GridUpperPrice, strategy.GridLowerPrice)
+	}
+	if strategy.GridLevels < 2 {
+		return fmt.Errorf("grid_levels must be at least 2, got %d", strategy.GridLevels)
+	}
+	if strategy.QuoteAmount <= 0 {
+		return fmt.Errorf("quote_amount must be positive, got %.2f", strategy.QuoteAmount)
+	}
+
+	// La vente est posée dès le remplissage de l'achat : les sorties de protection,
+	// évaluées sur les positions sans vente, ne s'appliqueraient jamais.
+	if strategy.StopLossPercent != 0 || strategy.MaxCycleAgeDays != 0 || strategy.BreakEvenAfterDays != 0 {
+		return fmt.Errorf("exit rules (stop-loss, max cycle age, break-even) are not supported by the grid algorithm")
+	}
+
+	return nil
+}
+
+// gridLevels retourne les prix des niveaux de la grille, du plus bas au plus haut.
+func gridLevels(strategy database.Strategy) []float64 {
+	step := (strategy.GridUpperPrice - strategy.GridLowerPrice) / float64(strategy.GridLevels-1)
+	levels := make([]float64, strategy.GridLevels)
+	for i := range levels {
+		levels[i] = strategy.GridLowerPrice + float64(i)*step
+	}
+	return levels
+}
+
+// gridLevelIndex retourne le niveau le plus proche d'un prix d'achat (les prix posés
+// sont arrondis à la précision du marché et ne tombent pas exactement sur le niveau).
+func gridLevelIndex(strategy database.Strategy, price float64) int {
+	step := (strategy.GridUpperPrice - strategy.GridLowerPrice) / float64(strategy.GridLevels-1)
+	return int(math.Round((price - strategy.GridLowerPrice) / step))
+}
+
+// LadderBuySignals retourne un achat pour chaque niveau libre sous le prix courant,
+// du plus proche au plus éloigné. Le niveau le plus haut ne reçoit jamais d'achat :
+// il n'a pas de niveau supérieur où revendre.
+func (a *Grid) LadderBuySignals(ctx TradingContext, strategy database.Strategy, active []database.Cycle) ([]BuySignal, error) {
+	levels := gridLevels(strategy)
+
+	occupied := make(map[int]bool, len(active))
+	for _, cycle := range active {
+		occupied[gridLevelIndex(strategy, cycle.BuyOrder.Price)] = true
+	}
+
+	var signals []BuySignal
+	for i := len(levels) - 2; i >= 0; i-- {
+		if occupied[i] {
+			continue
+		}
+		limitPrice := RoundPrice(levels[i], ctx.Precision)
+		if limitPrice >= ctx.CurrentPrice {
+			continue
+		}
+		targetPrice := RoundPrice(levels[i+1], ctx.Precision)
+		baseAmount := RoundAmount(strategy.QuoteAmount/limitPrice, ctx.Precision)
+
+		signals = append(signals, BuySignal{
+			ShouldBuy:   true,
+			Amount:      baseAmount,
+			LimitPrice:  limitPrice,
+			TargetPrice: targetPrice,
+			Reason:      fmt.Sprintf("Grid level %d/%d at %.4f, sell at %.4f", i+1, len(levels), limitPrice, targetPrice),
+		})
+	}
+
+	logger.Infof("[%s] Grid.LadderBuySignals: price = %.4f, %d active level(s), %d buy order(s) to place",
+		ctx.ExchangeName, ctx.CurrentPrice, len(occupied), len(signals))
+
+	return signals, nil
+}
+
+// ShouldBuy retourne l'achat du niveau libre le plus proche sous le prix, sans
+// connaître les niveaux occupés. L'exécution et le backtest passent par
+// LadderBuySignals, qui pose toute l'échelle en un tick.
+func (a *Grid) ShouldBuy(ctx TradingContext, strategy database.Strategy) (BuySignal, error) {
+	signals, err := a.LadderBuySignals(ctx, strategy, nil)
+	if err != nil {
+		return BuySignal{}, err
+	}
+	if len(signals) == 0 {
+		return BuySignal{
+			ShouldBuy: false,
+			Reason:    fmt.Sprintf("No grid level below price %.4f", ctx.CurrentPrice),
+		}, nil
+	}
+	return signals[0], nil
+}
+
+// ShouldSell pose la vente au niveau supérieur (cible du cycle) dès que l'achat est
+// rempli : l'ordre reste au carnet jusqu'à ce que le prix remonte d'un niveau.
+func (a *Grid) ShouldSell(ctx TradingContext, cycle database.Cycle, strategy database.Strategy) (SellSignal, error) {
+	logger.Debugf("[%s] Grid.ShouldSell: checking cycle %d", ctx.ExchangeName, cycle.ID)
+
+	if cycle.BuyOrder.Status != database.Filled {
+		return SellSignal{
+			ShouldSell: false,
+			Reason:     fmt.Sprintf("Buy order of cycle %d not filled yet", cycle.ID),
+		}, nil
+	}
+
+	limitPrice := RoundPrice(cycle.TargetPrice, ctx.Precision)
+
+	logger.Infof("[%s] Grid.ShouldSell: SELL signal - resting sell one level above for cycle %d at %.4f",
+		ctx.ExchangeName, cycle.ID, limitPrice)
+
+	return SellSignal{
+		ShouldSell: true,
+		LimitPrice: limitPrice,
+		Reason: fmt.Sprintf("Grid: bought %.4f, resting sell at next level %.4f",
+			cycle.BuyOrder.ExecutedPrice(), limitPrice),
+	}, nil
+}
+
+// GetParameterHints returns hints for configuring this algorithm
+func (a *Grid) GetParameterHints() map[string]string {
+	return map[string]string{
+		"grid_lower_price": "Prix du niveau le plus bas de la grille",
+		"grid_upper_price": "Prix du niveau le plus haut (aucun achat n'y est posé : il sert de dernière vente)",
+		"grid_levels":      "Nombre de niveaux, bornes comprises (au moins 2)",
+		"quote_amount":     "Montant investi par niveau (quote)",
+	}
+}
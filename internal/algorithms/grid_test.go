@@ -0,0 +1,93 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+
+	"bot/internal/core/database"
+)
+
+// gridStrategy : 5 niveaux de 90 à 110 (pas de 5), 20 par niveau.
+func gridStrategy() database.Strategy {
+	s := baseStrategy(0)
+	s.AlgorithmName = "grid"
+	s.GridLowerPrice = 90
+	s.GridUpperPrice = 110
+	s.GridLevels = 5
+	s.QuoteAmount = 20
+	return s
+}
+
+// Un achat par niveau libre sous le prix, du plus proche au plus éloigné, avec la
+// vente prévue au niveau supérieur ; les niveaux occupés sont sautés.
+func TestGrid_LadderBuySignals(t *testing.T) {
+	algo := &Grid{}
+	strategy := gridStrategy()
+	if err := algo.ValidateConfig(strategy); err != nil {
+		t.Fatalf("config invalide : %v", err)
+	}
+	ctx := TradingContext{
+		ExchangeName: "test", Pair: "BTC/USDC", CurrentPrice: 102,
+		Precision: MarketPrecision{Price: 0.01, Amount: 0.0001},
+	}
+
+	// Niveau 95 occupé par un achat en attente (prix arrondi, légèrement décalé).
+	active := []database.Cycle{{ID: 1, TargetPrice: 100, BuyOrder: database.Order{Price: 94.99, Status: database.Pending}}}
+
+	signals, err := algo.LadderBuySignals(ctx, strategy, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][2]float64{{100, 105}, {90, 95}}
+	if len(signals) != len(want) {
+		t.Fatalf("%d signaux, attendu %d : %+v", len(signals), len(want), signals)
+	}
+	for i, w := range want {
+		if signals[i].LimitPrice != w[0] || signals[i].TargetPrice != w[1] {
+			t.Errorf("signal %d : achat %.2f / vente %.2f, attendu %.2f / %.2f",
+				i, signals[i].LimitPrice, signals[i].TargetPrice, w[0], w[1])
+		}
+		if math.Abs(signals[i].Amount*signals[i].LimitPrice-20) > 0.05 {
+			t.Errorf("signal %d : notional %.4f, attendu 20", i, signals[i].Amount*signals[i].LimitPrice)
+		}
+	}
+
+	// Au-dessus de la borne haute : jamais d'achat sur le dernier niveau.
+	ctx.CurrentPrice = 120
+	signals, _ = algo.LadderBuySignals(ctx, strategy, nil)
+	if len(signals) != 4 || signals[0].LimitPrice != 105 {
+		t.Errorf("au-dessus de la grille : %d signaux (premier %.2f), attendu 4 (premier 105)", len(signals), signals[0].LimitPrice)
+	}
+}
+
+// La vente est posée à la cible dès que l'achat est rempli.
+func TestGrid_ShouldSell(t *testing.T) {
+	algo := &Grid{}
+	ctx := TradingContext{ExchangeName: "test", Pair: "BTC/USDC", CurrentPrice: 96, Precision: MarketPrecision{Price: 0.01, Amount: 0.0001}}
+	cycle := database.Cycle{ID: 1, TargetPrice: 100, BuyOrder: database.Order{Price: 95, Amount: 0.2, Status: database.Filled}}
+
+	sig, err := algo.ShouldSell(ctx, cycle, gridStrategy())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sig.ShouldSell || sig.LimitPrice != 100 {
+		t.Errorf("vente=%v à %.2f, attendu true à 100 (%s)", sig.ShouldSell, sig.LimitPrice, sig.Reason)
+	}
+}
+
+func TestGrid_ValidateConfig(t *testing.T) {
+	algo := &Grid{}
+	cases := map[string]func(s *database.Strategy){
+		"borne basse nulle":      func(s *database.Strategy) { s.GridLowerPrice = 0 },
+		"bornes inversées":       func(s *database.Strategy) { s.GridUpperPrice = 80 },
+		"un seul niveau":         func(s *database.Strategy) { s.GridLevels = 1 },
+		"stop-loss non supporté": func(s *database.Strategy) { s.StopLossPercent = 5 },
+	}
+	for name, configure := range cases {
+		strategy := gridStrategy()
+		configure(&strategy)
+		if err := algo.ValidateConfig(strategy); err == nil {
+			t.Errorf("%s : config acceptée", name)
+		}
+	}
+}
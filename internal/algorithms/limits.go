@@ -0,0 +1,166 @@
+package algorithms
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/decimal"
+	"fmt"
+)
+
+// minLimitTolerance : un achat sous le minimum de l'exchange (quantité ou montant) est
+// relevé au minimum tant que le surcoût reste sous ce seuil (écart d'arrondi) ; au-delà
+// l'achat est refusé plutôt que d'engager plus que prévu.
+const minLimitTolerance = 0.10
+
+// FitBuySignal ajuste un signal d'achat aux limites du marché : la quantité est réduite
+// sous les plafonds (quantité, montant) et relevée aux minimums si l'écart reste sous
+// minLimitTolerance. Retourne une erreur expliquant le refus si l'ordre ne peut pas
+// être posé (prix hors bande, achat trop petit, limites incompatibles).
+func FitBuySignal(signal BuySignal, p MarketPrecision) (BuySignal, error) {
+	price := signal.LimitPrice
+	if err := checkPriceBand(price, p); err != nil {
+		return signal, err
+	}
+
+	amount := signal.Amount
+	if p.MaxAmount > 0 && amount > p.MaxAmount {
+		amount = floorAmount(p.MaxAmount, p)
+	}
+	if p.MaxCost > 0 && Cost(amount, price) > p.MaxCost {
+		amount = floorAmount(p.MaxCost/price, p)
+	}
+
+	need := amount
+	if p.MinAmount > 0 && need < p.MinAmount {
+		need = ceilAmount(p.MinAmount, p)
+	}
+	if p.MinCost > 0 && Cost(need, price) < p.MinCost {
+		need = ceilAmount(p.MinCost/price, p)
+	}
+	if need > amount {
+		if need > signal.Amount*(1+minLimitTolerance) {
+			return signal, fmt.Errorf("achat de %g (%.2f en quote) sous le minimum de l'exchange (%s)",
+				signal.Amount, signal.Amount*price, minimums(p))
+		}
+		if (p.MaxAmount > 0 && need > p.MaxAmount) || (p.MaxCost > 0 && Cost(need, price) > p.MaxCost) {
+			return signal, fmt.Errorf("limites de l'exchange incompatibles au prix %g (%s)", price, minimums(p))
+		}
+		amount = need
+	}
+	if amount <= 0 {
+		return signal, fmt.Errorf("quantité nulle après application des plafonds de l'exchange")
+	}
+
+	signal.Amount = amount
+	return signal, nil
+}
+
+// CheckSell vérifie qu'une vente de amount au prix limite price respecte les limites du
+// marché. Une vente n'est jamais ajustée : la quantité d'un cycle doit partir en entier.
+func CheckSell(amount, price float64, p MarketPrecision) error {
+	if err := checkPriceBand(price, p); err != nil {
+		return err
+	}
+	if p.MinAmount > 0 && amount < p.MinAmount {
+		return fmt.Errorf("quantité %g sous le minimum de l'exchange (%g)", amount, p.MinAmount)
+	}
+	if p.MaxAmount > 0 && amount > p.MaxAmount {
+		return fmt.Errorf("quantité %g au-dessus du maximum de l'exchange (%g)", amount, p.MaxAmount)
+	}
+	if cost := Cost(amount, price); p.MinCost > 0 && cost < p.MinCost {
+		return fmt.Errorf("montant %.2f sous le minimum de l'exchange (%g)", cost, p.MinCost)
+	}
+	if cost := Cost(amount, price); p.MaxCost > 0 && cost > p.MaxCost {
+		return fmt.Errorf("montant %.2f au-dessus du maximum de l'exchange (%g)", cost, p.MaxCost)
+	}
+	return nil
+}
+
+// CheckQuoteAmount vérifie qu'un montant par ordre (Strategy.QuoteAmount) peut être
+// tradé sur le marché : montant minimum et maximum, et, si price est connu (> 0),
+// quantité minimum à ce prix. Sert à refuser à l'enregistrement une stratégie dont
+// aucun achat ne pourrait être posé.
+func CheckQuoteAmount(quoteAmount, price float64, p MarketPrecision) error {
+	if p.MinCost > 0 && quoteAmount < p.MinCost {
+		return fmt.Errorf("le montant par ordre (%.2f) est inférieur au minimum de l'exchange (%g)", quoteAmount, p.MinCost)
+	}
+	if p.MaxCost > 0 && quoteAmount > p.MaxCost {
+		return fmt.Errorf("le montant par ordre (%.2f) dépasse le maximum de l'exchange (%g)", quoteAmount, p.MaxCost)
+	}
+	if price > 0 && p.MinAmount > 0 && quoteAmount/price < p.MinAmount {
+		return fmt.Errorf("le montant par ordre (%.2f) achète %g au prix actuel (%g), sous la quantité minimum de l'exchange (%g, soit %.2f)",
+			quoteAmount, quoteAmount/price, price, p.MinAmount, p.MinAmount*price)
+	}
+	return nil
+}
+
+// CheckStrategyAmounts vérifie qu'une stratégie peut poser ses achats sur le marché : montant
+// par ordre et, en taille dynamique, taille minimale (cf. CheckQuoteAmount).
+func CheckStrategyAmounts(s database.Strategy, price float64, p MarketPrecision) error {
+	if err := CheckQuoteAmount(s.QuoteAmount, price, p); err != nil {
+		return err
+	}
+	if s.DynamicSizingEnabled && s.DynamicSizingMin != nil {
+		if err := CheckQuoteAmount(*s.DynamicSizingMin, price, p); err != nil {
+			return fmt.Errorf("taille dynamique mini : %w", err)
+		}
+	}
+	return nil
+}
+
+// MarketLimitsSource fournit les limites de marché relevées par le bot et le dernier prix
+// connu d'une paire (implémenté par *database.DB).
+type MarketLimitsSource interface {
+	GetMarketLimits(pair string) (*database.MarketLimits, error)
+	GetLastPrice(pair string) (float64, error)
+}
+
+// CheckStrategyMarket refuse à l'enregistrement (formulaire web, import de fichier) une
+// stratégie dont les achats ne pourraient jamais être posés sur l'exchange (cf.
+// CheckStrategyAmounts). Sans limites relevées par le bot pour sa paire, rien n'est vérifié.
+func CheckStrategyMarket(src MarketLimitsSource, s database.Strategy) error {
+	l, err := src.GetMarketLimits(s.Pair)
+	if err != nil || l == nil {
+		return err
+	}
+	price, err := src.GetLastPrice(s.Pair)
+	if err != nil {
+		return err
+	}
+	return CheckStrategyAmounts(s, price, limitsPrecision(*l))
+}
+
+// limitsPrecision construit un MarketPrecision à partir des limites de marché relevées
+// en base. Les pas de prix et de quantité n'y figurent pas (0).
+func limitsPrecision(l database.MarketLimits) MarketPrecision {
+	return MarketPrecision{
+		MinAmount: l.MinAmount, MaxAmount: l.MaxAmount,
+		MinCost: l.MinCost, MaxCost: l.MaxCost,
+		MinPrice: l.MinPrice, MaxPrice: l.MaxPrice,
+	}
+}
+
+// checkPriceBand vérifie que price est dans la bande de prix acceptée par l'exchange.
+func checkPriceBand(price float64, p MarketPrecision) error {
+	if p.MinPrice > 0 && price < p.MinPrice {
+		return fmt.Errorf("prix %g sous le prix minimum de l'exchange (%g)", price, p.MinPrice)
+	}
+	if p.MaxPrice > 0 && price > p.MaxPrice {
+		return fmt.Errorf("prix %g au-dessus du prix maximum de l'exchange (%g)", price, p.MaxPrice)
+	}
+	return nil
+}
+
+// minimums décrit les minimums du marché pour les messages de refus.
+func minimums(p MarketPrecision) string {
+	return fmt.Sprintf("quantité min %g, montant min %g", p.MinAmount, p.MinCost)
+}
+
+// floorAmount arrondit une quantité au pas inférieur du marché.
+func floorAmount(amount float64, p MarketPrecision) float64 {
+	return roundStep(amount, p.Amount, decimal.Down)
+}
+
+// ceilAmount arrondit une quantité au pas supérieur du marché.
+func ceilAmount(amount float64, p MarketPrecision) float64 {
+	return roundStep(amount, p.Amount, decimal.Up)
+}
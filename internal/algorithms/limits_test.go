@@ -0,0 +1,108 @@
+package algorithms
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFitBuySignal(t *testing.T) {
+	p := MarketPrecision{Price: 0.01, Amount: 0.001, AmountDecimals: 3, MinAmount: 0.01, MinCost: 5, MaxAmount: 2, MaxCost: 1000, MinPrice: 1, MaxPrice: 10000}
+
+	cases := []struct {
+		name    string
+		amount  float64
+		price   float64
+		want    float64
+		refused string
+	}{
+		{"dans les limites", 0.5, 100, 0.5, ""},
+		{"relevé au montant minimum", 0.048, 100, 0.05, ""},
+		{"relevé à la quantité minimum", 0.0095, 500, 0.01, ""},
+		{"trop petit pour être relevé", 0.03, 100, 0, "sous le minimum"},
+		{"plafonné à la quantité max", 3, 100, 2, ""},
+		{"plafonné au montant max", 1.5, 900, 1.111, ""},
+		{"prix sous la bande", 10, 0.5, 0, "prix minimum"},
+		{"prix au-dessus de la bande", 0.001, 20000, 0, "prix maximum"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FitBuySignal(BuySignal{ShouldBuy: true, Amount: tc.amount, LimitPrice: tc.price}, p)
+			if tc.refused != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.refused) {
+					t.Fatalf("erreur = %v, attendu un refus « %s »", err, tc.refused)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("refus inattendu : %v", err)
+			}
+			if math.Abs(got.Amount-tc.want) > 1e-12 {
+				t.Errorf("quantité = %v, attendu %v", got.Amount, tc.want)
+			}
+		})
+	}
+
+	// Sans limites (backtest, marché inconnu) : signal inchangé
+	got, err := FitBuySignal(BuySignal{Amount: 0.00001, LimitPrice: 3}, MarketPrecision{Price: 0.01, Amount: 0.000001})
+	if err != nil || got.Amount != 0.00001 {
+		t.Errorf("sans limites : %v, %v", got.Amount, err)
+	}
+}
+
+func TestCheckSell(t *testing.T) {
+	p := MarketPrecision{MinAmount: 0.01, MinCost: 5, MaxAmount: 2}
+	if err := CheckSell(0.1, 100, p); err != nil {
+		t.Errorf("vente valide refusée : %v", err)
+	}
+	if err := CheckSell(0.04, 100, p); err == nil {
+		t.Error("vente sous le montant minimum acceptée")
+	}
+	if err := CheckSell(3, 100, p); err == nil {
+		t.Error("vente au-dessus de la quantité max acceptée")
+	}
+}
+
+func TestCheckQuoteAmount(t *testing.T) {
+	p := MarketPrecision{MinAmount: 0.001, MinCost: 5, MaxCost: 1000}
+	if err := CheckQuoteAmount(20, 10000, p); err != nil {
+		t.Errorf("montant valide refusé : %v", err)
+	}
+	if err := CheckQuoteAmount(4, 0, p); err == nil {
+		t.Error("montant sous le minimum accepté")
+	}
+	if err := CheckQuoteAmount(2000, 0, p); err == nil {
+		t.Error("montant au-dessus du maximum accepté")
+	}
+	// 8 USDC à 10000 = 0.0008 < 0.001
+	if err := CheckQuoteAmount(8, 10000, p); err == nil {
+		t.Error("quantité sous le minimum au prix courant acceptée")
+	}
+	// Prix inconnu : seule la borne en quote est vérifiée
+	if err := CheckQuoteAmount(8, 0, p); err != nil {
+		t.Errorf("prix inconnu : %v", err)
+	}
+}
+
+func TestRoundPriceAndAmount(t *testing.T) {
+	cases := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		// 0.29 / 0.01 vaut 28.999999999999996 en flottant : l'ancien arrondi perdait un tick
+		{"prix déjà sur le tick", RoundPrice(0.29, MarketPrecision{Price: 0.01}), 0.29},
+		{"prix tronqué au tick", RoundPrice(101.239, MarketPrecision{Price: 0.01}), 101.23},
+		{"prix relevé au tick", RoundPriceUp(101.231, MarketPrecision{Price: 0.01}), 101.24},
+		{"actif à très petit prix", RoundPrice(0.00000123456789, MarketPrecision{Price: 1e-10}), 0.0000012345},
+		{"quantité déjà sur le pas", RoundAmount(0.3, MarketPrecision{Amount: 0.1}), 0.3},
+		{"quantité relevée au pas", RoundAmount(0.30001, MarketPrecision{Amount: 0.1}), 0.4},
+		{"pas inconnu", RoundAmount(0.123456, MarketPrecision{}), 0.123456},
+		{"notional exact", Cost(0.1, 0.3), 0.03},
+	}
+	for _, tc := range cases {
+		if tc.got != tc.want {
+			t.Errorf("%s : %v, attendu %v", tc.name, tc.got, tc.want)
+		}
+	}
+}
@@ -43,6 +43,10 @@ func (a *MACD_Cross) ValidateConfig(strategy database.Strategy) error {
 		return fmt.Errorf("profit_target must be positive, got %.2f", strategy.ProfitTarget)
 	}
 
+	if err := validateExitRules(strategy); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -98,6 +102,11 @@ func (a *MACD_Cross) ShouldBuy(ctx TradingContext, strategy database.Strategy) (
 func (a *MACD_Cross) ShouldSell(ctx TradingContext, cycle database.Cycle, strategy database.Strategy) (SellSignal, error) {
 	logger.Debugf("MACD_Cross.ShouldSell: checking cycle %d", cycle.ID)
 
+	// Sorties de protection (stop-loss, âge maximal, prix de revient)
+	if signal, ok := checkExitRules(ctx, cycle, strategy); ok {
+		return signal, nil
+	}
+
 	// Simple sell logic: sell when target price is reached
 	if ctx.CurrentPrice >= cycle.TargetPrice {
 		// Apply trailing stop logic
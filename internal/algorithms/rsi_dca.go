@@ -74,6 +74,10 @@ func (a *RSI_DCA) ValidateConfig(strategy database.Strategy) error {
 		}
 	}
 
+	if err := validateExitRules(strategy); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -294,6 +298,11 @@ func (a *RSI_DCA) calculateDynamicQuoteAmount(ctx TradingContext, strategy datab
 func (a *RSI_DCA) ShouldSell(ctx TradingContext, cycle database.Cycle, strategy database.Strategy) (SellSignal, error) {
 	logger.Debugf("[%s] RSI_DCA.ShouldSell: checking cycle %d", ctx.ExchangeName, cycle.ID)
 
+	// Sorties de protection (stop-loss, âge maximal, prix de revient)
+	if signal, ok := checkExitRules(ctx, cycle, strategy); ok {
+		return signal, nil
+	}
+
 	// Check if current price has reached the target price
 	if ctx.CurrentPrice >= cycle.TargetPrice {
 		// Apply trailing stop logic (same as original bot.go)
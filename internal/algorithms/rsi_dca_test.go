@@ -23,6 +23,7 @@ type stubCalc struct {
 	volShort  float64 // volatilité courante (période = VolatilityPeriod)
 	volRef    float64 // volatilité de référence (période = VolatilityPeriod * mult)
 	refPeriod int
+	bands     [3]float64 // bandes de Bollinger : haute, médiane, basse
 }
 
 func (s stubCalc) CalculateRSI(pair, tf string, period int) (float64, error) { return s.rsi, nil }
@@ -37,6 +38,9 @@ func (s stubCalc) CalculateRecentHigh(pair, tf string, periods int) (float64, er
 func (s stubCalc) CalculateMACD(pair, tf string, fast, slow, signal int) (float64, float64, float64, error) {
 	return 0, 0, 0, nil
 }
+func (s stubCalc) CalculateBollingerBands(pair, tf string, period int, k float64) (float64, float64, float64, error) {
+	return s.bands[0], s.bands[1], s.bands[2], nil
+}
 
 func fp(v float64) *float64 { return &v }
 func ip(v int) *int         { return &v }
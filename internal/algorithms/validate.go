@@ -0,0 +1,88 @@
+package algorithms
+
+import (
+	"bot/internal/core/database"
+	"fmt"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ValidateStrategy valide une stratégie complète avant enregistrement (formulaire web,
+// import de fichier). Couvre d'abord les champs communs, le filtre de tendance EMA
+// (non couvert par les algos), puis délègue la validation spécifique à l'algorithme
+// (RSI/MACD + taille dynamique) à son ValidateConfig pour garantir la parité avec le runtime.
+func (ar *AlgorithmRegistry) ValidateStrategy(s database.Strategy) error {
+	// Champs de base communs à toutes les stratégies
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("le nom de la stratégie est requis")
+	}
+	// Déclenchement des achats : cron (heure fixe) XOR intervalle périodique.
+	// Exactement un des deux doit être renseigné.
+	hasCron := strings.TrimSpace(s.CronExpression) != ""
+	hasInterval := s.BuyIntervalSeconds > 0
+	if hasCron && hasInterval {
+		return fmt.Errorf("choisissez soit une expression cron, soit un intervalle périodique, pas les deux")
+	}
+	if !hasCron && !hasInterval {
+		return fmt.Errorf("une expression cron ou un intervalle d'achat périodique est requis")
+	}
+	if hasCron {
+		// Parité avec le runtime : le scheduler utilise robfig/cron pour planifier.
+		// Un cron invalide passait la validation puis échouait silencieusement à se planifier.
+		if _, err := cron.ParseStandard(strings.TrimSpace(s.CronExpression)); err != nil {
+			return fmt.Errorf("expression cron invalide : %w", err)
+		}
+	}
+	if hasInterval && s.BuyIntervalSeconds < 3600 {
+		return fmt.Errorf("l'intervalle d'achat périodique doit être d'au moins 1 heure (reçu %d s)", s.BuyIntervalSeconds)
+	}
+	if s.QuoteAmount <= 0 {
+		return fmt.Errorf("le montant par ordre doit être positif (reçu %.2f)", s.QuoteAmount)
+	}
+	if s.MaxConcurrentCycles < 0 {
+		return fmt.Errorf("le nombre de cycles simultanés ne peut pas être négatif (0 = illimité, reçu %d)", s.MaxConcurrentCycles)
+	}
+	if s.MaxBuyOrderAgeHours < 0 {
+		return fmt.Errorf("l'âge maximal d'un ordre d'achat ne peut pas être négatif (0 = désactivé, reçu %d)", s.MaxBuyOrderAgeHours)
+	}
+	if s.MaxCapital < 0 {
+		return fmt.Errorf("le capital max ne peut pas être négatif (0 = illimité, reçu %.2f)", s.MaxCapital)
+	}
+	if s.MaxCapital > 0 && !s.DynamicSizingEnabled && s.MaxCapital < s.QuoteAmount {
+		return fmt.Errorf("le capital max (%.2f) doit couvrir au moins un ordre (%.2f)", s.MaxCapital, s.QuoteAmount)
+	}
+	if s.ProfitTarget <= 0 {
+		return fmt.Errorf("l'objectif de profit doit être positif (reçu %.2f)", s.ProfitTarget)
+	}
+	if s.TrailingStopDelta < 0 {
+		return fmt.Errorf("le trailing stop ne peut pas être négatif (reçu %.2f)", s.TrailingStopDelta)
+	}
+	if s.SellOffset < 0 {
+		return fmt.Errorf("l'offset de vente ne peut pas être négatif (reçu %.2f)", s.SellOffset)
+	}
+	if s.VolatilityPeriod != nil && *s.VolatilityPeriod <= 0 {
+		return fmt.Errorf("la période de volatilité doit être positive (reçu %d)", *s.VolatilityPeriod)
+	}
+
+	// Filtre de tendance EMA (si activé) — non couvert par les ValidateConfig des algos
+	if s.TrendFilterEnabled {
+		if s.TrendFilterFastPeriod == nil || s.TrendFilterSlowPeriod == nil {
+			return fmt.Errorf("les périodes EMA rapide et lente sont requises quand le filtre de tendance est activé")
+		}
+		if *s.TrendFilterFastPeriod <= 0 || *s.TrendFilterSlowPeriod <= 0 {
+			return fmt.Errorf("les périodes EMA du filtre de tendance doivent être positives")
+		}
+		if *s.TrendFilterFastPeriod >= *s.TrendFilterSlowPeriod {
+			return fmt.Errorf("l'EMA rapide (%d) doit être inférieure à l'EMA lente (%d)",
+				*s.TrendFilterFastPeriod, *s.TrendFilterSlowPeriod)
+		}
+	}
+
+	// Validation spécifique à l'algorithme (réutilise les règles du runtime)
+	algo, ok := ar.Get(s.AlgorithmName)
+	if !ok {
+		return fmt.Errorf("algorithme inconnu : %q", s.AlgorithmName)
+	}
+	return algo.ValidateConfig(s)
+}
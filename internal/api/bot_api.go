@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"bot/internal/logger"
+	"bot/internal/metrics"
 )
 
 type BotAPI struct {
@@ -20,10 +21,15 @@ type BotInterface interface {
 	ExchangeName() string
 	ReloadStrategies() error
 	CollectCandles(pair, timeframe string, limit int) (int, int, error) // fetched, saved, error
-	// FetchBalances returns balances (asset → free/used/total amounts), base currency, quote currency, current price, error
-	FetchBalances() (map[string]BalanceAmounts, string, string, float64, error)
+	// FetchBalances returns balances (asset → free/used/total amounts), quote currency, current prices in quote (asset → price), error
+	FetchBalances() (map[string]BalanceAmounts, string, map[string]float64, error)
 	// ForceBuy déclenche un achat manuel immédiat et retourne un résumé de l'ordre posé.
 	ForceBuy() (string, error)
+	// KillSwitch suspend tous les achats (arrêt d'urgence) et annule les achats en attente ;
+	// retourne le nombre d'ordres annulés. ResetBreaker réarme le disjoncteur. user
+	// identifie l'opérateur dans l'historique des déclenchements.
+	KillSwitch(user string) (int, error)
+	ResetBreaker(user string) error
 }
 
 // BalanceAmounts détaille un solde d'actif : disponible, bloqué en ordres ouverts, et total.
@@ -69,8 +75,12 @@ func (api *BotAPI) Start() {
 	http.HandleFunc("/reload", api.handleReload)
 	http.HandleFunc("/collect/candles", api.handleCollectCandles)
 	http.HandleFunc("/health", api.handleHealth)
+	// Lecture seule et sans jeton, comme /health : scrapé par Prometheus.
+	http.HandleFunc("/metrics", metrics.Default.Handler())
 	http.HandleFunc("/balance", api.handleBalance)
 	http.HandleFunc("/buy", api.handleBuy)
+	http.HandleFunc("/killswitch", api.handleKillSwitch)
+	http.HandleFunc("/breaker/reset", api.handleBreakerReset)
 
 	port := os.Getenv("BOT_API_PORT")
 	if port == "" {
@@ -154,6 +164,86 @@ func (api *BotAPI) handleBuy(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": msg})
 }
 
+// BreakerRequest identifie l'opérateur d'un arrêt d'urgence ou d'un réarmement (corps
+// facultatif).
+type BreakerRequest struct {
+	User string `json:"user"`
+}
+
+// decodeBreakerRequest lit le corps facultatif des routes du disjoncteur.
+func decodeBreakerRequest(r *http.Request) BreakerRequest {
+	var req BreakerRequest
+	_ = json.NewDecoder(r.Body).Decode(&req)
+	return req
+}
+
+// handleKillSwitch déclenche l'arrêt d'urgence : achats suspendus jusqu'au réarmement
+// et annulation de tous les ordres d'achat en attente.
+func (api *BotAPI) handleKillSwitch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if !api.isValidToken(authHeader) {
+		logger.Warnf("[%s] Invalid token for kill switch request from %s", api.exchangeName, r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	req := decodeBreakerRequest(r)
+	logger.Infof("[%s] Kill switch request authenticated from %s", api.exchangeName, r.RemoteAddr)
+	cancelled, err := api.bot.KillSwitch(req.User)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		logger.Errorf("[%s] Kill switch failed: %v", api.exchangeName, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "kill switch failed", "message": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":    "success",
+		"message":   "buying halted",
+		"cancelled": cancelled,
+	})
+}
+
+// handleBreakerReset réarme le disjoncteur : les achats reprennent.
+func (api *BotAPI) handleBreakerReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	authHeader := r.Header.Get("Authorization")
+	if !api.isValidToken(authHeader) {
+		logger.Warnf("[%s] Invalid token for breaker reset request from %s", api.exchangeName, r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	req := decodeBreakerRequest(r)
+	logger.Infof("[%s] Breaker reset request authenticated from %s", api.exchangeName, r.RemoteAddr)
+	err := api.bot.ResetBreaker(req.User)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		logger.Errorf("[%s] Breaker reset failed: %v", api.exchangeName, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "reset failed", "message": err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]string{"status": "success", "message": "buying resumed"})
+}
+
 func (api *BotAPI) handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"status": "healthy"})
@@ -254,7 +344,7 @@ func (api *BotAPI) handleBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	balances, baseAsset, quoteAsset, currentPrice, err := api.bot.FetchBalances()
+	balances, quoteAsset, prices, err := api.bot.FetchBalances()
 	w.Header().Set("Content-Type", "application/json")
 	if err != nil {
 		logger.Errorf("[%s] Failed to fetch balances: %v", api.exchangeName, err)
@@ -267,8 +357,8 @@ func (api *BotAPI) handleBalance(w http.ResponseWriter, r *http.Request) {
 	totalValue := 0.0
 	for asset, amounts := range balances {
 		entry := BalanceEntry{Asset: asset, Free: amounts.Free, Used: amounts.Used, Total: amounts.Total}
-		if asset == baseAsset && currentPrice > 0 {
-			entry.Value = amounts.Total * currentPrice
+		if price := prices[asset]; price > 0 {
+			entry.Value = amounts.Total * price
 		} else if asset == quoteAsset {
 			entry.Value = amounts.Total
 		}
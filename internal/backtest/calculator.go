@@ -105,6 +105,14 @@ func (c *Calculator) CalculateMACD(pair, timeframe string, fastPeriod, slowPerio
 	return market.MACDValue(closes, fastPeriod, slowPeriod, signalPeriod)
 }
 
+func (c *Calculator) CalculateBollingerBands(pair, timeframe string, period int, k float64) (float64, float64, float64, error) {
+	closes, err := c.closesAsOf(timeframe, market.BollingerWindow(period))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return market.BollingerValue(closes, period, k)
+}
+
 // CalculateRecentHigh renvoie le plus-haut sur les `periods` dernières bougies
 // clôturées à c.nowMs (utilisé par la taille dynamique).
 func (c *Calculator) CalculateRecentHigh(pair, timeframe string, periods int) (float64, error) {
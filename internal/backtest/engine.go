@@ -9,7 +9,9 @@ import (
 
 	"bot/internal/algorithms"
 	"bot/internal/core/database"
+	"bot/internal/decimal"
 	"bot/internal/logger"
+	"bot/internal/risk"
 
 	"github.com/robfig/cron/v3"
 )
@@ -23,6 +25,14 @@ type Config struct {
 	Precision      algorithms.MarketPrecision // précision marché pour l'arrondi des ordres
 	StartMs, EndMs int64                      // bornes temporelles (0 = toute la plage dispo)
 	BuyTTLBars     int                        // annule un achat non rempli après N bougies (0 = jamais)
+	KeepEquity     bool                       // conserve la courbe d'équité dans Result.Equity (sinon seules les métriques restent)
+
+	// Limites de risque, appliquées comme par le bot avec Strategy.MaxCapital. La
+	// stratégie étant seule, son capital engagé est aussi celui de l'instance.
+	Limits risk.Limits
+	// QuoteBalance : solde quote de départ, pour la réserve minimale (0 = inconnu,
+	// réserve non vérifiée). Évolue avec les achats, ventes et frais simulés.
+	QuoteBalance float64
 }
 
 // Result agrège les métriques d'un backtest.
@@ -33,6 +43,8 @@ type Result struct {
 	BuysPlaced           int
 	BuysFilled           int
 	BuysCancelled        int
+	BuysSkipped          int                // tentatives d'achat refusées par une limite de risque (réessayées au tick suivant)
+	SkippedByLimit       map[risk.Limit]int // détail des refus par limite
 	CyclesClosed         int
 	CyclesOpenEnd        int
 	BuysPerDay           float64
@@ -46,9 +58,23 @@ type Result struct {
 	RealizedReturnPct    float64 // RealizedPnL / InvestedClosed * 100
 	Fees                 float64
 	WinRate              float64 // % de cycles bouclés à PnL > 0
+	ProtectiveExits      int     // ventes déclenchées par une sortie de protection (stop-loss, âge, prix de revient)
 	UnrealizedPnL        float64 // P&L latent des cycles ouverts au dernier prix
 	OpenNotional         float64 // notional investi encore détenu (cycles non vendus)
 	FinalPrice           float64
+
+	// Courbe d'équité (une valeur par bougie du chemin de prix, conservée si
+	// Config.KeepEquity) et métriques de risque dérivées, rapportées à PeakCapital
+	// (cf. computeRiskMetrics).
+	Equity               []EquityPoint
+	MaxDrawdown          float64 // plus forte baisse d'équité depuis un plus-haut (quote)
+	MaxDrawdownPct       float64 // MaxDrawdown / (PeakCapital + équité au plus-haut) * 100
+	MaxUnderwaterDays    float64 // plus longue période passée sous un plus-haut d'équité
+	UnderwaterPct        float64 // % des bougies sous le plus-haut d'équité
+	SharpeRatio          float64 // annualisé, rendements journaliers, taux sans risque nul
+	SortinoRatio         float64 // idem, volatilité à la baisse uniquement
+	ExposurePct          float64 // % des bougies avec au moins une position détenue
+	CapitalEfficiencyPct float64 // P&L total (réalisé + latent) / PeakCapital * 100
 }
 
 // simCycle est l'état interne d'un cycle pendant la simulation.
@@ -58,6 +84,7 @@ type simCycle struct {
 	amount     float64
 	target     float64
 	placedBar  int
+	placedMs   int64
 	buyFilled  bool
 	buyPrice   float64
 	buyFillMs  int64
@@ -122,7 +149,14 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 		firstMs   int64 = -1
 		lastClose float64
 		lastMs    int64
+		// Dépense du jour UTC en cours, pour risk.Limits.MaxDailySpend
+		spendDay   int64 = -1
+		dailySpend decimal.Decimal
+		// Cumuls en décimal exact, recopiés dans res à chaque bougie
+		realized, fees, invested decimal.Decimal
+		feeRate                  = decimal.FromFloat(cfg.FeeRate)
 	)
+	const dayMs = int64(24 * time.Hour / time.Millisecond)
 
 	for i := range price {
 		c := price[i]
@@ -140,6 +174,7 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 		lastClose = c.ClosePrice
 		calc.SetNow(closeMs)
 		ctx.CurrentPrice = c.ClosePrice
+		ctx.Now = time.UnixMilli(closeMs)
 
 		// 1) Remplissage des ordres posés AUX TICKS PRÉCÉDENTS.
 		kept := open[:0]
@@ -151,9 +186,12 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 					cy.buyFillMs = closeMs
 					cy.maxPrice = c.ClosePrice
 					res.BuysFilled++
-					res.Fees += cy.buyPrice * cy.amount * cfg.FeeRate
+					fees = fees.Add(notional(cy.buyPrice, cy.amount).Mul(feeRate))
 				} else if cfg.BuyTTLBars > 0 && i-cy.placedBar >= cfg.BuyTTLBars {
 					res.BuysCancelled++
+					if cy.placedMs/dayMs == spendDay {
+						dailySpend = dailySpend.Sub(notional(cy.buyLimit, cy.amount)) // un achat annulé ne compte pas dans la dépense
+					}
 					continue // ordre annulé, cycle abandonné
 				} else {
 					kept = append(kept, cy)
@@ -162,12 +200,13 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 			} else if cy.sellPlaced {
 				if c.HighPrice >= cy.sellLimit {
 					cy.closed = true
-					gross := (cy.sellLimit - cy.buyPrice) * cy.amount
-					res.Fees += cy.sellLimit * cy.amount * cfg.FeeRate
-					pnl := gross - cy.buyPrice*cy.amount*cfg.FeeRate - cy.sellLimit*cy.amount*cfg.FeeRate
-					res.RealizedPnL += pnl
-					res.InvestedClosed += cy.buyPrice * cy.amount
-					if pnl > 0 {
+					bought, sold := notional(cy.buyPrice, cy.amount), notional(cy.sellLimit, cy.amount)
+					sellFee := sold.Mul(feeRate)
+					fees = fees.Add(sellFee)
+					pnl := sold.Sub(bought).Sub(bought.Mul(feeRate)).Sub(sellFee)
+					realized = realized.Add(pnl)
+					invested = invested.Add(bought)
+					if pnl.Sign() > 0 {
 						wins++
 					}
 					durations = append(durations, float64(closeMs-cy.buyFillMs)/86400000.0)
@@ -178,6 +217,7 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 			kept = append(kept, cy)
 		}
 		open = kept
+		res.RealizedPnL, res.Fees, res.InvestedClosed = realized.Float64(), fees.Float64(), invested.Float64()
 
 		// 2) Mise à jour du plus-haut + 3) évaluation des ventes (cycles à achat rempli).
 		for _, cy := range open {
@@ -187,15 +227,27 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 			if c.HighPrice > cy.maxPrice {
 				cy.maxPrice = c.HighPrice
 			}
-			dbCycle := database.Cycle{ID: cy.id, TargetPrice: cy.target, MaxPrice: cy.maxPrice}
+			dbCycle := database.Cycle{
+				ID: cy.id, TargetPrice: cy.target, MaxPrice: cy.maxPrice,
+				CreatedAt: time.UnixMilli(cy.placedMs),
+				BuyOrder: database.Order{
+					Side: database.Buy, Status: database.Filled,
+					Amount: cy.amount, Price: cy.buyPrice,
+					Fees: notional(cy.buyPrice, cy.amount).Mul(feeRate).Float64(),
+				},
+			}
 			sig, err := algo.ShouldSell(ctx, dbCycle, cfg.Strategy)
 			if err == nil && sig.ShouldSell {
 				cy.sellPlaced = true
 				cy.sellLimit = sig.LimitPrice
+				if sig.Protective {
+					res.ProtectiveExits++
+				}
 			}
 		}
 
-		// 4) Déclencheur d'achat.
+		// 4) Déclencheur d'achat. Un algorithme à ordres multiples (grille) pose
+		// plusieurs achats dans le même tick, dans la limite des cycles concurrents.
 		if trigger.fires(closeMs) {
 			active := 0
 			for _, cy := range open {
@@ -203,10 +255,41 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 					active++
 				}
 			}
-			withinCap := cfg.Strategy.MaxConcurrentCycles <= 0 || active < cfg.Strategy.MaxConcurrentCycles
-			if withinCap {
-				sig, err := algo.ShouldBuy(ctx, cfg.Strategy)
-				if err == nil && sig.ShouldBuy {
+			room := cfg.Strategy.MaxConcurrentCycles - active
+			if cfg.Strategy.MaxConcurrentCycles <= 0 || room > 0 {
+				var signals []algorithms.BuySignal
+				if ladder, ok := algo.(algorithms.LadderBuyer); ok {
+					sigs, err := ladder.LadderBuySignals(ctx, cfg.Strategy, activeCycles(open))
+					if err == nil {
+						signals = sigs
+					}
+				} else {
+					sig, err := algo.ShouldBuy(ctx, cfg.Strategy)
+					if err == nil && sig.ShouldBuy {
+						signals = []algorithms.BuySignal{sig}
+					}
+				}
+				if cfg.Strategy.MaxConcurrentCycles > 0 && len(signals) > room {
+					signals = signals[:room]
+				}
+				if day := closeMs / dayMs; day != spendDay {
+					spendDay, dailySpend = day, decimal.Zero
+				}
+				exposure := simExposure(open, res.RealizedPnL, dailySpend.Float64(), cfg)
+				placed := 0
+				for _, sig := range signals {
+					cost := algorithms.Cost(sig.Amount, sig.LimitPrice)
+					if breach := cfg.Limits.Check(cfg.Strategy.MaxCapital, exposure, cost); breach != nil {
+						// Signaux triés par priorité : on s'arrête au premier refus, comme le bot
+						res.BuysSkipped++
+						if res.SkippedByLimit == nil {
+							res.SkippedByLimit = make(map[risk.Limit]int)
+						}
+						res.SkippedByLimit[breach.Limit]++
+						break
+					}
+					exposure.Commit(cost)
+					dailySpend = dailySpend.Add(notional(sig.LimitPrice, sig.Amount))
 					nextID++
 					open = append(open, &simCycle{
 						id:        nextID,
@@ -214,22 +297,35 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 						amount:    sig.Amount,
 						target:    sig.TargetPrice,
 						placedBar: i,
+						placedMs:  closeMs,
 					})
 					res.BuysPlaced++
+					placed++
+				}
+				if placed > 0 {
 					trigger.consume(closeMs)
 				}
 			}
 		}
 
-		// Suivi du capital (notional des cycles à achat rempli, non bouclés).
+		// Suivi du capital (notional des cycles à achat rempli, non bouclés) et de l'équité.
 		capital := 0.0
+		unrealized := 0.0
 		liveOpen := 0
 		for _, cy := range open {
 			if cy.buyFilled && !cy.closed {
 				capital += cy.buyPrice * cy.amount
+				unrealized += (c.ClosePrice - cy.buyPrice) * cy.amount
 				liveOpen++
 			}
 		}
+		res.Equity = append(res.Equity, EquityPoint{
+			TimeMs:     closeMs,
+			Realized:   res.RealizedPnL,
+			Unrealized: unrealized,
+			Equity:     res.RealizedPnL + unrealized,
+			Invested:   capital,
+		})
 		if capital > res.PeakCapital {
 			res.PeakCapital = capital
 		}
@@ -273,9 +369,58 @@ func Run(cfg Config, candlesByTF map[string][]database.Candle) (Result, error) {
 	if math.IsNaN(res.RealizedReturnPct) {
 		res.RealizedReturnPct = 0
 	}
+	computeRiskMetrics(&res)
+	if !cfg.KeepEquity {
+		res.Equity = nil
+	}
 	return res, nil
 }
 
+// simExposure calcule l'exposition de la simulation pour les limites de risque : le
+// capital engagé (notional des cycles ouverts, achats en attente compris) et, si le
+// solde de départ est connu, le solde quote libre restant.
+func simExposure(open []*simCycle, realizedPnL, dailySpend float64, cfg Config) risk.Exposure {
+	e := risk.Exposure{DailySpend: dailySpend, FreeQuote: -1}
+	fees := 0.0
+	for _, cy := range open {
+		if cy.closed {
+			continue
+		}
+		e.StrategyCapital += cy.buyLimit * cy.amount
+		if cy.buyFilled {
+			fees += cy.buyPrice * cy.amount * cfg.FeeRate
+		}
+	}
+	e.TotalCapital = e.StrategyCapital
+	if cfg.QuoteBalance > 0 {
+		e.FreeQuote = math.Max(cfg.QuoteBalance+realizedPnL-e.StrategyCapital-fees, 0)
+	}
+	return e
+}
+
+// notional retourne price * amount en décimal exact.
+func notional(price, amount float64) decimal.Decimal {
+	return decimal.FromFloat(price).Mul(decimal.FromFloat(amount))
+}
+
+// activeCycles convertit les cycles simulés non bouclés en cycles de base, tels que
+// les verrait l'algorithme en exécution réelle (achat en attente ou rempli).
+func activeCycles(open []*simCycle) []database.Cycle {
+	cycles := make([]database.Cycle, 0, len(open))
+	for _, cy := range open {
+		if cy.closed {
+			continue
+		}
+		buy := database.Order{Side: database.Buy, Status: database.Pending, Amount: cy.amount, Price: cy.buyLimit}
+		if cy.buyFilled {
+			buy.Status = database.Filled
+			buy.Price = cy.buyPrice
+		}
+		cycles = append(cycles, database.Cycle{ID: cy.id, TargetPrice: cy.target, MaxPrice: cy.maxPrice, BuyOrder: buy})
+	}
+	return cycles
+}
+
 // buyTrigger décide à quels instants une tentative d'achat est autorisée.
 type buyTrigger struct {
 	intervalMs int64
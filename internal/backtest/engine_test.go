@@ -6,6 +6,7 @@ import (
 
 	"bot/internal/algorithms"
 	"bot/internal/core/database"
+	"bot/internal/risk"
 )
 
 const tf15 = int64(15 * 60 * 1000) // durée d'une bougie 15m en ms
@@ -100,3 +101,158 @@ func TestEngineProducesCycles(t *testing.T) {
 		t.Errorf("win rate = %.0f%%, attendu 100%% (vente uniquement à la cible)", res.WinRate)
 	}
 }
+
+// TestEngineStopLoss : après un krach sans rebond, le stop-loss solde les cycles
+// à perte au lieu de les laisser ouverts jusqu'à la fin de la période.
+func TestEngineStopLoss(t *testing.T) {
+	// Oscillation autour de 100, puis chute linéaire jusqu'à 70.
+	candles := makeCandles(200, func(i int) float64 {
+		if i < 40 {
+			return 100 + 2*math.Sin(float64(i)/3.0)
+		}
+		return math.Max(70, 100-float64(i-40)*0.5)
+	})
+
+	threshold := 100.0
+	period := 14
+	volPeriod := 7
+	adj := 0.0
+	strategy := database.Strategy{
+		Name: "test", AlgorithmName: "rsi_dca", Enabled: true,
+		RSIThreshold: &threshold, RSIPeriod: &period, RSITimeframe: "15m",
+		ProfitTarget: 1.0, TrailingStopDelta: 0.1, SellOffset: 0.1,
+		VolatilityPeriod: &volPeriod, VolatilityAdjustment: &adj, VolatilityTimeframe: "15m",
+		QuoteAmount: 20, BuyIntervalSeconds: 3600, MaxConcurrentCycles: 1,
+	}
+	cfg := Config{
+		Pair: "BTC/USDC", PriceTimeframe: "15m", FeeRate: 0.001,
+		Precision: algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001},
+	}
+	series := map[string][]database.Candle{"15m": candles}
+
+	cfg.Strategy = strategy
+	without, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if without.CyclesOpenEnd == 0 || without.ProtectiveExits != 0 {
+		t.Fatalf("sans stop-loss : %d cycles ouverts / %d sorties de protection, attendu > 0 / 0",
+			without.CyclesOpenEnd, without.ProtectiveExits)
+	}
+
+	strategy.StopLossPercent = 5
+	cfg.Strategy = strategy
+	with, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if with.ProtectiveExits == 0 || with.CyclesClosed == 0 {
+		t.Fatalf("avec stop-loss : %d sorties de protection / %d cycles bouclés, attendu > 0",
+			with.ProtectiveExits, with.CyclesClosed)
+	}
+	if with.RealizedPnL >= 0 {
+		t.Errorf("P&L réalisé = %.2f, attendu une perte (ventes sous le prix d'achat)", with.RealizedPnL)
+	}
+	if with.UnrealizedPnL <= without.UnrealizedPnL {
+		t.Errorf("latent avec stop-loss (%.2f) devrait être meilleur que sans (%.2f)", with.UnrealizedPnL, without.UnrealizedPnL)
+	}
+}
+
+// TestEngineGrid : la grille pose toute l'échelle d'achats en un tick, puis boucle
+// des cycles d'un niveau sur un prix qui oscille dans la plage.
+func TestEngineGrid(t *testing.T) {
+	candles := makeCandles(200, func(i int) float64 { return 100 + 6*math.Sin(float64(i)/5.0) })
+	strategy := database.Strategy{
+		Name: "test", AlgorithmName: "grid", Enabled: true,
+		GridLowerPrice: 90, GridUpperPrice: 110, GridLevels: 11, // pas de 2
+		QuoteAmount: 20, BuyIntervalSeconds: 900,
+	}
+	cfg := Config{
+		Pair: "BTC/USDC", Strategy: strategy, PriceTimeframe: "15m",
+		FeeRate:   0.001,
+		Precision: algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001},
+		EndMs:     tf15, // premier tick seulement
+	}
+	series := map[string][]database.Candle{"15m": candles}
+
+	first, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Premier tick : clôture à 100 -> un achat sur chaque niveau de 90 à 98.
+	if first.BuysPlaced != 5 {
+		t.Fatalf("premier tick : %d achats posés, attendu 5 (un par niveau sous le prix)", first.BuysPlaced)
+	}
+
+	cfg.EndMs = 0
+	res, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.CyclesClosed == 0 {
+		t.Fatal("aucun cycle bouclé")
+	}
+	if res.PeakOpenCycles > 10 {
+		t.Errorf("pic de %d cycles ouverts, attendu au plus 10 (un par niveau achetable)", res.PeakOpenCycles)
+	}
+	if res.WinRate != 100 {
+		t.Errorf("win rate = %.0f%%, attendu 100%% (vente un niveau au-dessus de l'achat)", res.WinRate)
+	}
+
+	strategy.MaxConcurrentCycles = 3
+	cfg.Strategy = strategy
+	capped, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capped.PeakOpenCycles > 3 {
+		t.Errorf("plafond de 3 cycles : pic de %d", capped.PeakOpenCycles)
+	}
+}
+
+// TestEngineRiskLimits : les limites de risque arrêtent l'échelle de la grille au
+// premier achat qui les dépasserait, comme le bot.
+func TestEngineRiskLimits(t *testing.T) {
+	candles := makeCandles(10, func(i int) float64 { return 100 })
+	strategy := database.Strategy{
+		Name: "test", AlgorithmName: "grid", Enabled: true,
+		GridLowerPrice: 90, GridUpperPrice: 110, GridLevels: 11,
+		QuoteAmount: 20, BuyIntervalSeconds: 900,
+	}
+	series := map[string][]database.Candle{"15m": candles}
+
+	cases := []struct {
+		name   string
+		max    float64
+		limits risk.Limits
+		quote  float64
+		placed int
+		limit  risk.Limit
+	}{
+		{"capital de la stratégie", 50, risk.Limits{}, 0, 2, risk.LimitStrategyCapital},
+		{"capital de l'instance", 0, risk.Limits{MaxTotalCapital: 70}, 0, 3, risk.LimitTotalCapital},
+		{"dépense du jour", 0, risk.Limits{MaxDailySpend: 30}, 0, 1, risk.LimitDailySpend},
+		{"réserve de quote", 0, risk.Limits{MinQuoteReserve: 50}, 100, 2, risk.LimitQuoteReserve},
+		{"réserve sans solde connu", 0, risk.Limits{MinQuoteReserve: 50}, 0, 5, ""},
+	}
+	for _, tc := range cases {
+		s := strategy
+		s.MaxCapital = tc.max
+		res, err := Run(Config{
+			Pair: "BTC/USDC", Strategy: s, PriceTimeframe: "15m",
+			FeeRate:   0.001,
+			Precision: algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001},
+			EndMs:     tf15, // premier tick seulement
+			Limits:    tc.limits, QuoteBalance: tc.quote,
+		}, series)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.BuysPlaced != tc.placed {
+			t.Errorf("%s : %d achats posés, attendu %d", tc.name, res.BuysPlaced, tc.placed)
+		}
+		if tc.limit != "" && (res.BuysSkipped != 1 || res.SkippedByLimit[tc.limit] != 1) {
+			t.Errorf("%s : refus %d %v, attendu 1 sur %s", tc.name, res.BuysSkipped, res.SkippedByLimit, tc.limit)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package backtest
+
+import (
+	"math"
+	"time"
+)
+
+// EquityPoint est l'état du portefeuille simulé à la clôture d'une bougie du chemin
+// de prix. Les montants sont en quote et relatifs au départ (0 = capital initial).
+type EquityPoint struct {
+	TimeMs     int64
+	Realized   float64 // P&L réalisé cumulé, net de frais
+	Unrealized float64 // P&L latent des cycles ouverts au prix de clôture
+	Equity     float64 // Realized + Unrealized
+	Invested   float64 // notional des cycles à achat rempli, non vendus
+	Drawdown   float64 // écart au plus-haut d'équité précédent (<= 0)
+}
+
+// daysPerYear annualise les ratios : le marché crypto cote tous les jours.
+const daysPerYear = 365
+
+// computeRiskMetrics dérive les métriques de risque de la courbe d'équité.
+//
+// Le capital de référence est PeakCapital (notional maximal simultanément investi),
+// c.-à-d. le capital qu'il fallait réserver pour suivre la stratégie : les
+// pourcentages (drawdown, rendements journaliers, efficacité) lui sont rapportés.
+func computeRiskMetrics(res *Result) {
+	if len(res.Equity) == 0 {
+		return
+	}
+
+	// Drawdown et temps sous l'eau.
+	peak := 0.0 // l'équité part de 0 : un premier point négatif est déjà un drawdown
+	peakMs := res.Equity[0].TimeMs
+	exposed, underwater := 0, 0
+	var longestUnderwater int64
+	for i := range res.Equity {
+		p := &res.Equity[i]
+		if p.Equity >= peak {
+			peak, peakMs = p.Equity, p.TimeMs
+		} else {
+			underwater++
+			longestUnderwater = max(longestUnderwater, p.TimeMs-peakMs)
+		}
+		p.Drawdown = p.Equity - peak
+		if -p.Drawdown > res.MaxDrawdown {
+			res.MaxDrawdown = -p.Drawdown
+			if base := res.PeakCapital + peak; base > 0 {
+				res.MaxDrawdownPct = res.MaxDrawdown / base * 100
+			}
+		}
+		if p.Invested > 0 {
+			exposed++
+		}
+	}
+	n := float64(len(res.Equity))
+	res.ExposurePct = float64(exposed) / n * 100
+	res.UnderwaterPct = float64(underwater) / n * 100
+	res.MaxUnderwaterDays = float64(longestUnderwater) / 86400000.0
+
+	if res.PeakCapital > 0 {
+		last := res.Equity[len(res.Equity)-1]
+		res.CapitalEfficiencyPct = last.Equity / res.PeakCapital * 100
+	}
+
+	// Rendements journaliers (dernier point de chaque jour UTC), rapportés au capital
+	// de référence augmenté de l'équité de la veille.
+	returns := dailyReturns(res.Equity, res.PeakCapital)
+	res.SharpeRatio, res.SortinoRatio = sharpeSortino(returns)
+}
+
+// dailyReturns échantillonne la courbe d'équité au dernier point de chaque jour UTC
+// et renvoie les rendements d'un jour sur l'autre.
+func dailyReturns(equity []EquityPoint, capital float64) []float64 {
+	var closes []float64
+	lastDay := int64(math.MinInt64)
+	for _, p := range equity {
+		day := time.UnixMilli(p.TimeMs).UTC().Truncate(24 * time.Hour).UnixMilli()
+		if day != lastDay {
+			closes = append(closes, p.Equity)
+			lastDay = day
+		} else {
+			closes[len(closes)-1] = p.Equity
+		}
+	}
+
+	var returns []float64
+	for i := 1; i < len(closes); i++ {
+		base := capital + closes[i-1]
+		if base <= 0 {
+			continue
+		}
+		returns = append(returns, (closes[i]-closes[i-1])/base)
+	}
+	return returns
+}
+
+// sharpeSortino calcule les ratios de Sharpe et de Sortino annualisés (taux sans
+// risque nul). 0 quand ils ne sont pas définis (moins de deux rendements, volatilité nulle).
+func sharpeSortino(returns []float64) (sharpe, sortino float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	variance, downside := 0.0, 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	downDev := math.Sqrt(downside / float64(len(returns)))
+
+	annual := math.Sqrt(daysPerYear)
+	if std > 0 {
+		sharpe = mean / std * annual
+	}
+	if downDev > 0 {
+		sortino = mean / downDev * annual
+	}
+	return sharpe, sortino
+}
@@ -0,0 +1,104 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"bot/internal/algorithms"
+	"bot/internal/core/database"
+)
+
+const dayMs = int64(86400000)
+
+// Courbe construite à la main : plus-haut à 10, creux à -5 (drawdown 15), retour à
+// 12 ; un point sur deux exposé.
+func TestComputeRiskMetrics(t *testing.T) {
+	equity := []float64{0, 10, 4, -5, 2, 12}
+	res := Result{PeakCapital: 100}
+	for i, e := range equity {
+		invested := 0.0
+		if i%2 == 1 {
+			invested = 50
+		}
+		res.Equity = append(res.Equity, EquityPoint{TimeMs: int64(i) * dayMs, Equity: e, Realized: e, Invested: invested})
+	}
+	computeRiskMetrics(&res)
+
+	if res.MaxDrawdown != 15 {
+		t.Errorf("MaxDrawdown = %.2f, attendu 15", res.MaxDrawdown)
+	}
+	if want := 15.0 / 110 * 100; math.Abs(res.MaxDrawdownPct-want) > 1e-9 {
+		t.Errorf("MaxDrawdownPct = %.4f, attendu %.4f", res.MaxDrawdownPct, want)
+	}
+	if res.MaxUnderwaterDays != 3 {
+		t.Errorf("MaxUnderwaterDays = %.2f, attendu 3", res.MaxUnderwaterDays)
+	}
+	if res.UnderwaterPct != 50 || res.ExposurePct != 50 {
+		t.Errorf("sous l'eau %.0f%% / exposition %.0f%%, attendu 50/50", res.UnderwaterPct, res.ExposurePct)
+	}
+	if res.CapitalEfficiencyPct != 12 {
+		t.Errorf("CapitalEfficiencyPct = %.2f, attendu 12", res.CapitalEfficiencyPct)
+	}
+	if res.Equity[3].Drawdown != -15 {
+		t.Errorf("drawdown au creux = %.2f, attendu -15", res.Equity[3].Drawdown)
+	}
+	if res.SharpeRatio <= 0 || res.SortinoRatio <= 0 {
+		t.Errorf("Sharpe %.2f / Sortino %.2f, attendus positifs (équité finale au-dessus du départ)", res.SharpeRatio, res.SortinoRatio)
+	}
+}
+
+func TestSharpeSortino(t *testing.T) {
+	sharpe, sortino := sharpeSortino([]float64{0.01, -0.01, 0.02, 0})
+	// moyenne 0,005 ; écart-type (n-1) 0,0129099 ; écart à la baisse sqrt(0,0001/4) = 0,005
+	if want := 0.005 / 0.012909944487358056 * math.Sqrt(365); math.Abs(sharpe-want) > 1e-9 {
+		t.Errorf("Sharpe = %.6f, attendu %.6f", sharpe, want)
+	}
+	if want := math.Sqrt(365); math.Abs(sortino-want) > 1e-9 {
+		t.Errorf("Sortino = %.6f, attendu %.6f", sortino, want)
+	}
+	if s, so := sharpeSortino([]float64{0.01}); s != 0 || so != 0 {
+		t.Errorf("un seul rendement : %.2f / %.2f, attendu 0 / 0", s, so)
+	}
+}
+
+// La courbe du moteur compte une valeur par bougie et se termine sur le P&L total.
+func TestEngineEquityCurve(t *testing.T) {
+	candles := makeCandles(200, func(i int) float64 { return 100 + 2*math.Sin(float64(i)/3.0) })
+	threshold, period, volPeriod, adj := 100.0, 14, 7, 0.0
+	cfg := Config{
+		Pair: "BTC/USDC", PriceTimeframe: "15m", FeeRate: 0.001,
+		Precision: algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001},
+		Strategy: database.Strategy{
+			Name: "test", AlgorithmName: "rsi_dca", Enabled: true,
+			RSIThreshold: &threshold, RSIPeriod: &period, RSITimeframe: "15m",
+			ProfitTarget: 1.0, TrailingStopDelta: 0.1, SellOffset: 0.1,
+			VolatilityPeriod: &volPeriod, VolatilityAdjustment: &adj, VolatilityTimeframe: "15m",
+			QuoteAmount: 20, BuyIntervalSeconds: 3600,
+		},
+	}
+	series := map[string][]database.Candle{"15m": candles}
+
+	res, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Equity != nil {
+		t.Error("courbe conservée sans KeepEquity")
+	}
+
+	cfg.KeepEquity = true
+	kept, err := Run(cfg, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kept.Equity) != len(candles) {
+		t.Fatalf("%d points d'équité, attendu %d", len(kept.Equity), len(candles))
+	}
+	last := kept.Equity[len(kept.Equity)-1]
+	if math.Abs(last.Equity-(kept.RealizedPnL+kept.UnrealizedPnL)) > 1e-9 {
+		t.Errorf("équité finale %.4f, attendu %.4f", last.Equity, kept.RealizedPnL+kept.UnrealizedPnL)
+	}
+	if kept.MaxDrawdown != res.MaxDrawdown || kept.ExposurePct <= 0 {
+		t.Errorf("métriques : drawdown %.4f vs %.4f, exposition %.1f%%", kept.MaxDrawdown, res.MaxDrawdown, kept.ExposurePct)
+	}
+}
@@ -0,0 +1,159 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+
+	"bot/internal/core/database"
+)
+
+// Candidate est une combinaison de paramètres candidate à l'optimisation. Config.StartMs
+// et Config.EndMs sont ignorés : la walk-forward impose les bornes de chaque fenêtre.
+type Candidate struct {
+	Label  string
+	Config Config
+}
+
+// WalkForwardConfig découpe l'historique en fenêtres glissantes : InSampleMs pour
+// choisir la meilleure combinaison, puis OutSampleMs pour l'évaluer. Les fenêtres
+// avancent de OutSampleMs, de sorte que les périodes hors échantillon se suivent
+// sans se recouvrir. StartMs/EndMs bornent l'historique utilisé (0 = tout).
+type WalkForwardConfig struct {
+	InSampleMs     int64
+	OutSampleMs    int64
+	StartMs, EndMs int64
+}
+
+// WalkForwardWindow décrit une fenêtre : la combinaison retenue en échantillon et
+// son résultat hors échantillon.
+type WalkForwardWindow struct {
+	InStartMs, InEndMs   int64
+	OutStartMs, OutEndMs int64
+	Best                 string // libellé de la combinaison retenue
+	InSample             Result // résultat de la combinaison retenue en échantillon
+	OutSample            Result // même combinaison, fenêtre suivante
+	OutPnL               float64
+	Equity               float64 // P&L hors échantillon cumulé à la fin de la fenêtre
+}
+
+// WalkForwardResult agrège les fenêtres : courbe de P&L hors échantillon mise bout à
+// bout et stabilité des paramètres retenus.
+type WalkForwardResult struct {
+	Windows    []WalkForwardWindow
+	InPnL      float64 // somme des P&L en échantillon des combinaisons retenues
+	OutPnL     float64 // somme des P&L hors échantillon (= équité finale)
+	InDays     float64
+	OutDays    float64
+	Efficiency float64        // (P&L/jour hors échantillon) / (P&L/jour en échantillon) ; 0 si non défini
+	Picks      map[string]int // nombre de fenêtres où chaque combinaison a été retenue
+	Changes    int            // changements de combinaison entre deux fenêtres consécutives
+}
+
+// windowPnL est le score d'une fenêtre : P&L réalisé plus P&L latent des cycles encore
+// ouverts, valorisés au dernier prix (les positions ne sont pas reportées d'une fenêtre
+// à l'autre, elles sont réputées soldées à la clôture de la fenêtre).
+func windowPnL(r Result) float64 {
+	return r.RealizedPnL + r.UnrealizedPnL
+}
+
+// WalkForward exécute une optimisation walk-forward : sur chaque fenêtre en échantillon,
+// toutes les combinaisons sont rejouées via Run et la meilleure (P&L réalisé + latent)
+// est retenue, puis évaluée seule sur la fenêtre hors échantillon qui suit. Les
+// indicateurs voient tout l'historique antérieur à chaque décision (pas de préchauffage
+// à prévoir) ; seuls les ordres sont bornés à la fenêtre.
+func WalkForward(wf WalkForwardConfig, candidates []Candidate, candlesByTF map[string][]database.Candle) (WalkForwardResult, error) {
+	if len(candidates) == 0 {
+		return WalkForwardResult{}, fmt.Errorf("aucune combinaison à évaluer")
+	}
+	if wf.InSampleMs <= 0 || wf.OutSampleMs <= 0 {
+		return WalkForwardResult{}, fmt.Errorf("fenêtres en/hors échantillon invalides (%d / %d ms)", wf.InSampleMs, wf.OutSampleMs)
+	}
+
+	priceTF := candidates[0].Config.PriceTimeframe
+	price := candlesByTF[priceTF]
+	if len(price) == 0 {
+		return WalkForwardResult{}, fmt.Errorf("aucune bougie pour le chemin de prix %s", priceTF)
+	}
+	dur := TimeframeMillis(priceTF)
+	firstMs, lastMs := price[0].Timestamp+dur, price[0].Timestamp+dur
+	for _, c := range price {
+		firstMs = min(firstMs, c.Timestamp+dur)
+		lastMs = max(lastMs, c.Timestamp+dur)
+	}
+	if wf.StartMs > firstMs {
+		firstMs = wf.StartMs
+	}
+	if wf.EndMs > 0 && wf.EndMs < lastMs {
+		lastMs = wf.EndMs
+	}
+
+	res := WalkForwardResult{Picks: make(map[string]int)}
+	for inStart := firstMs; inStart+wf.InSampleMs+wf.OutSampleMs <= lastMs+1; inStart += wf.OutSampleMs {
+		w := WalkForwardWindow{
+			InStartMs:  inStart,
+			InEndMs:    inStart + wf.InSampleMs - 1,
+			OutStartMs: inStart + wf.InSampleMs,
+			OutEndMs:   inStart + wf.InSampleMs + wf.OutSampleMs - 1,
+		}
+
+		best := -1
+		for i, cand := range candidates {
+			cfg := cand.Config
+			cfg.StartMs, cfg.EndMs = w.InStartMs, w.InEndMs
+			r, err := Run(cfg, candlesByTF)
+			if err != nil {
+				return WalkForwardResult{}, fmt.Errorf("%s (en échantillon) : %w", cand.Label, err)
+			}
+			if best < 0 || windowPnL(r) > windowPnL(w.InSample) {
+				best, w.InSample = i, r
+			}
+		}
+
+		cfg := candidates[best].Config
+		cfg.StartMs, cfg.EndMs = w.OutStartMs, w.OutEndMs
+		out, err := Run(cfg, candlesByTF)
+		if err != nil {
+			return WalkForwardResult{}, fmt.Errorf("%s (hors échantillon) : %w", candidates[best].Label, err)
+		}
+		w.Best = candidates[best].Label
+		w.OutSample = out
+		w.OutPnL = windowPnL(out)
+
+		res.InPnL += windowPnL(w.InSample)
+		res.OutPnL += w.OutPnL
+		res.InDays += w.InSample.Days
+		res.OutDays += out.Days
+		w.Equity = res.OutPnL
+
+		if n := len(res.Windows); n > 0 && res.Windows[n-1].Best != w.Best {
+			res.Changes++
+		}
+		res.Picks[w.Best]++
+		res.Windows = append(res.Windows, w)
+	}
+
+	if len(res.Windows) == 0 {
+		return WalkForwardResult{}, fmt.Errorf("historique trop court pour une fenêtre de %.0f + %.0f jours",
+			float64(wf.InSampleMs)/86400000.0, float64(wf.OutSampleMs)/86400000.0)
+	}
+	if res.InDays > 0 && res.OutDays > 0 && res.InPnL > 0 {
+		res.Efficiency = (res.OutPnL / res.OutDays) / (res.InPnL / res.InDays)
+	}
+	return res, nil
+}
+
+// PickCounts retourne les combinaisons retenues, de la plus souvent choisie à la moins
+// souvent choisie (ordre alphabétique à égalité).
+func (r WalkForwardResult) PickCounts() []string {
+	labels := make([]string, 0, len(r.Picks))
+	for label := range r.Picks {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if r.Picks[labels[i]] != r.Picks[labels[j]] {
+			return r.Picks[labels[i]] > r.Picks[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
@@ -0,0 +1,82 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"bot/internal/algorithms"
+	"bot/internal/core/database"
+)
+
+// TestWalkForward : fenêtres hors échantillon contiguës, équité cumulée fenêtre par
+// fenêtre, et comptage des combinaisons retenues.
+func TestWalkForward(t *testing.T) {
+	candles := makeCandles(200, func(i int) float64 { return 100 + 2*math.Sin(float64(i)/3.0) })
+	series := map[string][]database.Candle{"15m": candles}
+
+	var candidates []Candidate
+	for _, profit := range []float64{0.5, 1.0, 3.0} {
+		threshold := 100.0
+		period, volPeriod, adj := 14, 7, 0.0
+		candidates = append(candidates, Candidate{
+			Label: fmt.Sprintf("profit%.1f", profit),
+			Config: Config{
+				Pair: "BTC/USDC", PriceTimeframe: "15m", FeeRate: 0.001,
+				Precision: algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001},
+				StartMs:   -1, EndMs: -1, // ignorés : bornes imposées par la fenêtre
+				Strategy: database.Strategy{
+					Name: "test", AlgorithmName: "rsi_dca", Enabled: true,
+					RSIThreshold: &threshold, RSIPeriod: &period, RSITimeframe: "15m",
+					ProfitTarget: profit, TrailingStopDelta: 0.1, SellOffset: 0.1,
+					VolatilityPeriod: &volPeriod, VolatilityAdjustment: &adj, VolatilityTimeframe: "15m",
+					QuoteAmount: 20, BuyIntervalSeconds: 3600,
+				},
+			},
+		})
+	}
+
+	wf := WalkForwardConfig{InSampleMs: 48 * tf15, OutSampleMs: 24 * tf15}
+	res, err := WalkForward(wf, candidates, series)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Historique : clôtures de tf15 à 200*tf15 -> fenêtres tant que 72 bougies tiennent.
+	wantWindows := 0
+	for start := tf15; start+72*tf15 <= 200*tf15+1; start += 24 * tf15 {
+		wantWindows++
+	}
+	if len(res.Windows) != wantWindows {
+		t.Fatalf("%d fenêtres, attendu %d", len(res.Windows), wantWindows)
+	}
+
+	equity, picks := 0.0, 0
+	for i, w := range res.Windows {
+		if w.OutStartMs != w.InEndMs+1 {
+			t.Errorf("fenêtre %d : hors échantillon démarre à %d, attendu %d", i, w.OutStartMs, w.InEndMs+1)
+		}
+		if i > 0 && w.OutStartMs != res.Windows[i-1].OutEndMs+1 {
+			t.Errorf("fenêtre %d : hors échantillon non contigu à la précédente", i)
+		}
+		equity += w.OutPnL
+		if math.Abs(w.Equity-equity) > 1e-9 {
+			t.Errorf("fenêtre %d : équité %.4f, attendu %.4f", i, w.Equity, equity)
+		}
+	}
+	for _, n := range res.Picks {
+		picks += n
+	}
+	if picks != len(res.Windows) {
+		t.Errorf("%d choix comptés pour %d fenêtres", picks, len(res.Windows))
+	}
+	if math.Abs(res.OutPnL-equity) > 1e-9 {
+		t.Errorf("P&L hors échantillon %.4f, attendu %.4f", res.OutPnL, equity)
+	}
+
+	// Historique trop court pour une seule fenêtre.
+	wf.InSampleMs = 300 * tf15
+	if _, err := WalkForward(wf, candidates, series); err == nil {
+		t.Error("attendu une erreur quand aucune fenêtre ne tient dans l'historique")
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"bot/internal/api"
 	"bot/internal/core/config"
 	"bot/internal/core/database"
+	"bot/internal/decimal"
+	"bot/internal/events"
 	"bot/internal/logger"
 	"bot/internal/market"
+	"bot/internal/metrics"
+	"bot/internal/risk"
 	"bot/internal/scheduler"
-	"bot/internal/telegram"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -28,11 +31,15 @@ type Exchange interface {
 	GetMarket(pair string) Market
 	GetMarketsList() []Market
 	FetchBalance() (map[string]Balance, error)
-	PlaceLimitBuyOrder(pair string, amount float64, price float64) (Order, error)
-	PlaceLimitSellOrder(pair string, amount float64, price float64) (Order, error)
+	// clientOrderID : identifiant client transmis à l'exchange ("" = aucun)
+	PlaceLimitBuyOrder(pair string, amount float64, price float64, clientOrderID string) (Order, error)
+	PlaceLimitSellOrder(pair string, amount float64, price float64, clientOrderID string) (Order, error)
 	FetchOrder(id string, symbol string) (Order, error)
+	FetchOrderByClientID(clientOrderID string, symbol string) (Order, error)
+	FetchOpenOrders(pair string) ([]Order, error)
 	CancelOrder(id string, symbol string) (Order, error)
 	GetPrice(pair string) (float64, error)
+	FetchTicker(pair string) (Ticker, error)
 	FetchCandles(pair string, timeframe string, since *int64, limit int64) ([]Candle, error)
 	FetchMyTrades(pair string, since *int64, until *int64, limit int64) ([]Trade, error)
 	FetchTradesForOrder(id string, pair string) ([]Trade, error)
@@ -50,6 +57,15 @@ type Market struct {
 		Amount         float64
 		AmountDecimals int
 	}
+	// Limites de l'exchange, 0 = pas de limite
+	Limits struct {
+		MinAmount float64
+		MaxAmount float64
+		MinCost   float64
+		MaxCost   float64
+		MinPrice  float64
+		MaxPrice  float64
+	}
 }
 
 type Balance struct {
@@ -59,10 +75,23 @@ type Balance struct {
 }
 
 type Order struct {
-	Id        *string
-	Price     *float64
-	Amount    *float64
-	Status    *string
+	Id            *string
+	ClientOrderId *string // identifiant client transmis à la pose (nil si aucun)
+	Side          *string // "buy" ou "sell"
+	Price         *float64
+	Amount        *float64
+	Filled        *float64 // quantité déjà exécutée (remplissage partiel possible)
+	Average       *float64 // prix moyen d'exécution
+	Status        *string
+	Timestamp     *int64
+}
+
+// Ticker est le dernier relevé de prix d'une paire. Last est toujours renseigné ; Bid,
+// Ask et Timestamp (ms) sont nil quand l'exchange ne les fournit pas.
+type Ticker struct {
+	Last      *float64
+	Bid       *float64
+	Ask       *float64
 	Timestamp *int64
 }
 
@@ -91,10 +120,13 @@ type Candle struct {
 }
 
 type Bot struct {
-	Config            config.BotConfig
-	db                *database.DB
-	exchange          Exchange
-	market            Market
+	Config   config.BotConfig
+	db       *database.DB
+	exchange Exchange
+	// markets : marché (actifs, précision) de chaque paire tradée, chargé à la
+	// demande auprès de l'exchange (cf. marketFor).
+	markets           map[string]*Market
+	marketsMu         sync.Mutex
 	marketCollector   *market.MarketDataCollector
 	Calculator        *market.Calculator
 	algorithmRegistry *algorithms.AlgorithmRegistry
@@ -103,6 +135,11 @@ type Bot struct {
 	// paused suspend le déclenchement de nouvelles stratégies (achats et ventes).
 	// La surveillance des ordres et le suivi du prix max continuent de tourner.
 	paused atomic.Bool
+	// breaker surveille prix et erreurs exchange ; halt est le déclenchement en cours du
+	// disjoncteur (nil = achats autorisés). Comme paused, halt suspend les achats, mais
+	// pas les ventes, jusqu'au réarmement par l'opérateur (cf. breaker.go).
+	breaker *risk.Breaker
+	halt    atomic.Pointer[database.CircuitTrip]
 	// startedAt : heure de démarrage du bot ; lastCheck : horodatage (unix nanos)
 	// du dernier price-check réussi. Servent au heartbeat affiché dans /status.
 	startedAt time.Time
@@ -110,6 +147,11 @@ type Bot struct {
 	// lastPatternCandleTs : timestamp (ms) de la dernière bougie 1h déjà évaluée par le
 	// moniteur de retournement, pour ne notifier qu'une fois par bougie.
 	lastPatternCandleTs atomic.Int64
+	// events : bus des événements du cycle de vie (ordres, cycles, disjoncteur...),
+	// persistés en base et transmis à Telegram et au webhook configuré (cf. events.go).
+	events *events.Bus
+	// stream : état du mode streaming (STREAMING=1, cf. stream.go), nil en polling seul.
+	stream *stream
 	// État du throttling des alertes d'erreur (accédé uniquement depuis run()).
 	lastAlertCount int64
 	lastAlertAt    time.Time
@@ -120,23 +162,29 @@ func NewBot(config config.BotConfig, db *database.DB, exchange Exchange) (*Bot,
 		Config:   config,
 		db:       db,
 		exchange: exchange,
+		markets:  make(map[string]*Market),
 		done:     make(chan bool),
+		breaker:  risk.NewBreaker(config.Breaker),
+		events:   newEventBus(config, db),
 	}
+	bot.restoreBreaker()
 
-	if err := bot.initializeMarketPrecision(); err != nil {
-		return nil, err
+	logger.Infof("[%s] Fetching market data...", config.ExchangeName)
+	pairs := bot.tradedPairs()
+	for _, pair := range pairs {
+		bot.marketFor(pair)
 	}
 
 	// Initialize market data services with adapter
 	exchangeAdapter := newBotExchangeAdapter(exchange)
-	bot.marketCollector = market.NewMarketDataCollector(config.ExchangeName, config.Pair, db, exchangeAdapter)
+	bot.marketCollector = market.NewMarketDataCollector(config.ExchangeName, db, exchangeAdapter)
 	bot.Calculator = market.NewCalculator(db, bot.marketCollector)
 
 	// Initialize algorithm registry
 	bot.algorithmRegistry = algorithms.NewAlgorithmRegistry()
 	logger.Infof("[%s] Algorithm registry initialized with %d algorithms", config.ExchangeName, len(bot.algorithmRegistry.List()))
 
-	strategyScheduler, err := scheduler.NewStrategyScheduler(config.ExchangeName, config.Pair, db, &bot.market, bot.marketCollector, bot.Calculator, bot.algorithmRegistry, bot)
+	strategyScheduler, err := scheduler.NewStrategyScheduler(config.ExchangeName, db, bot, bot.marketCollector, bot.Calculator, bot.algorithmRegistry, bot, config.RiskLimits, bot.events)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create strategy scheduler: %w", err)
 	}
@@ -145,17 +193,21 @@ func NewBot(config config.BotConfig, db *database.DB, exchange Exchange) (*Bot,
 
 	// Initial market data collection
 	logger.Infof("[%s] Initializing market data collection...", config.ExchangeName)
-	err = bot.marketCollector.CollectCandles(config.Pair, "4h", 200) // Collect initial historical data
-	if err != nil {
-		logger.Warnf("[%s] Failed to collect initial market data: %v", config.ExchangeName, err)
-	} else {
-		logger.Infof("[%s] Market data collection initialized successfully", config.ExchangeName)
+	for _, pair := range pairs {
+		err = bot.marketCollector.CollectCandles(pair, "4h", 200) // Collect initial historical data
+		if err != nil {
+			logger.Warnf("[%s] Failed to collect initial market data for %s: %v", config.ExchangeName, pair, err)
+		} else {
+			logger.Infof("[%s] Market data collection initialized successfully for %s", config.ExchangeName, pair)
+		}
 	}
 
 	return bot, nil
 }
 
 func (b *Bot) Cleanup() {
+	// Laisse les abonnés transmettre les derniers événements avant de fermer la base
+	b.events.Close()
 	if b.db != nil {
 		logger.Infof("[%s] Close database connection...", b.Config.ExchangeName)
 		_ = b.db.Close()
@@ -167,22 +219,79 @@ func (b *Bot) ExchangeName() string {
 	return b.Config.ExchangeName
 }
 
-func (b *Bot) initializeMarketPrecision() error {
-	logger.Infof("[%s] Fetching market data...", b.Config.ExchangeName)
-	b.market = b.exchange.GetMarket(b.Config.Pair)
-	logger.Infof("[%s] Base Asset: %s, Quote Asset: %s", b.Config.ExchangeName, b.market.BaseAsset, b.market.QuoteAsset)
-	logger.Infof("[%s] Market precision: price=%f, amount=%f", b.Config.ExchangeName, b.market.Precision.Price, b.market.Precision.Amount)
-	return nil
+// marketFor retourne le marché d'une paire (actifs et précision), récupéré auprès de
+// l'exchange au premier usage puis gardé en cache. Une paire vide désigne la paire
+// par défaut de l'instance (TRADING_PAIR).
+func (b *Bot) marketFor(pair string) *Market {
+	if pair == "" {
+		pair = b.Config.Pair
+	}
+
+	b.marketsMu.Lock()
+	defer b.marketsMu.Unlock()
+	if m, ok := b.markets[pair]; ok {
+		return m
+	}
+
+	m := b.exchange.GetMarket(pair)
+	logger.Infof("[%s] %s : Base Asset: %s, Quote Asset: %s", b.Config.ExchangeName, pair, m.BaseAsset, m.QuoteAsset)
+	logger.Infof("[%s] %s : Market precision: price=%f, amount=%f", b.Config.ExchangeName, pair, m.Precision.Price, m.Precision.Amount)
+	logger.Infof("[%s] %s : Market limits: amount=[%g, %g], cost=[%g, %g], price=[%g, %g]", b.Config.ExchangeName, pair,
+		m.Limits.MinAmount, m.Limits.MaxAmount, m.Limits.MinCost, m.Limits.MaxCost, m.Limits.MinPrice, m.Limits.MaxPrice)
+	// Limites partagées avec la WebUI (formulaire des stratégies)
+	if b.db != nil {
+		if err := b.db.SaveMarketLimits(database.MarketLimits{
+			Pair:      pair,
+			MinAmount: m.Limits.MinAmount,
+			MaxAmount: m.Limits.MaxAmount,
+			MinCost:   m.Limits.MinCost,
+			MaxCost:   m.Limits.MaxCost,
+			MinPrice:  m.Limits.MinPrice,
+			MaxPrice:  m.Limits.MaxPrice,
+		}); err != nil {
+			logger.Warnf("[%s] %s : %v", b.Config.ExchangeName, pair, err)
+		}
+	}
+	if b.markets == nil {
+		b.markets = make(map[string]*Market)
+	}
+	b.markets[pair] = &m
+	return &m
+}
+
+// MarketFor implémente scheduler.StrategyMarkets.
+func (b *Bot) MarketFor(pair string) scheduler.StrategyMarket {
+	return b.marketFor(pair)
+}
+
+// tradedPairs retourne les paires distinctes des stratégies, ou la paire par défaut
+// de l'instance si aucune stratégie n'en déclare encore.
+func (b *Bot) tradedPairs() []string {
+	pairs, err := b.db.GetStrategyPairs()
+	if err != nil {
+		logger.Errorf("[%s] Failed to get strategy pairs: %v", b.Config.ExchangeName, err)
+	}
+	if len(pairs) == 0 {
+		return []string{b.Config.Pair}
+	}
+	return pairs
 }
 
 func (b *Bot) Start(buyAtLaunch bool) error {
 	logger.Infof("[%s] Starting bot...", b.Config.ExchangeName)
 
 	b.startedAt = time.Now()
+	metrics.Default.OnCollect(b.collectMetrics)
+	b.resolveOrderIntents(time.Now())
+	b.reconcileAtStart()
 	b.handleOrderCheck()
 	b.handlePriceCheck()
+	b.checkDailyLoss()
 	b.executeBuyStrategies()
 	b.ShowStatistics()
+	if b.Config.Streaming {
+		b.startStream()
+	}
 
 	// buyAtLaunch is now handled by the strategy scheduler
 	logger.Debug("Starting cron-based strategy scheduler...")
@@ -204,6 +313,7 @@ func (b *Bot) Pause() error {
 	}
 
 	logger.Infof("[%s] ⏸ Bot mis en pause (achats/ventes suspendus)", b.Config.ExchangeName)
+	b.events.Publish(events.Event{Type: events.BotPaused})
 	if b.strategyScheduler != nil {
 		if err := b.strategyScheduler.Stop(); err != nil {
 			logger.Warnf("[%s] Échec de l'arrêt du scheduler lors de la pause : %v", b.Config.ExchangeName, err)
@@ -220,6 +330,7 @@ func (b *Bot) Resume() error {
 	}
 
 	logger.Infof("[%s] ▶️ Reprise du bot", b.Config.ExchangeName)
+	b.events.Publish(events.Event{Type: events.BotResumed})
 	return b.ReloadStrategies()
 }
 
@@ -236,6 +347,10 @@ func (b *Bot) IsPaused() bool {
 // touche. Comme l'ancre du cooldown est le dernier cycle, cet achat repousse aussi le
 // prochain achat automatique. Retourne un résumé lisible pour Telegram.
 func (b *Bot) ForceBuy() (string, error) {
+	if err := b.buysHalted(); err != nil {
+		return "", err
+	}
+
 	strategies, err := b.db.GetAllStrategies()
 	if err != nil {
 		return "", fmt.Errorf("lecture des stratégies : %w", err)
@@ -257,21 +372,22 @@ func (b *Bot) ForceBuy() (string, error) {
 		return "", err
 	}
 
-	quote := b.market.QuoteAsset
+	m := b.marketFor(target.Pair)
+	quote := m.QuoteAsset
 	cost := res.Amount * res.LimitPrice
 	msg := fmt.Sprintf(
-		"🛒 Achat manuel posé (%s)\nMontant : %s %s @ %s %s (≈ %.2f %s)\nCible de vente : %s %s",
-		target.Name,
-		b.market.FormatAmount(res.Amount), b.market.GetBaseAsset(),
-		b.market.FormatPrice(res.LimitPrice), quote,
+		"🛒 Achat manuel posé (%s, %s)\nMontant : %s %s @ %s %s (≈ %.2f %s)\nCible de vente : %s %s",
+		target.Name, target.Pair,
+		m.FormatAmount(res.Amount), m.GetBaseAsset(),
+		m.FormatPrice(res.LimitPrice), quote,
 		cost, quote,
-		b.market.FormatPrice(res.TargetPrice), quote,
+		m.FormatPrice(res.TargetPrice), quote,
 	)
 	logger.Infof("[%s] %s", b.Config.ExchangeName, msg)
 	return msg, nil
 }
 
-// checkErrorAlerts envoie une notification Telegram quand de nouvelles erreurs
+// checkErrorAlerts publie une alerte (notifiée sur Telegram) quand de nouvelles erreurs
 // sont apparues depuis la dernière alerte, au plus une fois par errorAlertCooldown.
 // Capture toute la catégorie « process vivant mais dysfonctionnel » (ordre refusé,
 // clé API invalide…) que le heartbeat/dead-man's switch ne détecte pas.
@@ -289,11 +405,12 @@ func (b *Bot) checkErrorAlerts() {
 
 	// Alerte courte : un résumé d'une ligne + renvoi vers /status pour le détail
 	// (la bannière /status porte déjà le message complet, inutile de le dupliquer).
-	text := fmt.Sprintf("⚠️ [%s] Le bot rencontre des erreurs (%d) — ouvre /status\n%s",
-		b.Config.ExchangeName, count, firstLine(msg, 120))
-	if err := telegram.SendMessage(text); err != nil {
-		logger.Errorf("Failed to send error alert to Telegram: %v", err)
-	}
+	b.events.Publish(events.Event{
+		Type: events.ErrorRaised,
+		Message: fmt.Sprintf("⚠️ [%s] Le bot rencontre des erreurs (%d) — ouvre /status\n%s",
+			b.Config.ExchangeName, count, firstLine(msg, 120)),
+		Data: map[string]any{"count": count, "error": msg},
+	})
 }
 
 // firstLine retourne la première ligne de s, tronquée à max runes (avec « … »).
@@ -329,11 +446,14 @@ func (b *Bot) pingHealthcheck() {
 func (b *Bot) run() {
 	logger.Infof("[%s] Starting cron-based strategy scheduler...", b.Config.ExchangeName)
 
-	// Start the strategy scheduler with real cron
-	err := b.strategyScheduler.Start()
-	if err != nil {
-		logger.Fatalf("Failed to start strategy scheduler: %v", err)
-		return
+	// Start the strategy scheduler with real cron (sauf disjoncteur déclenché : le
+	// réarmement le relancera)
+	if b.halt.Load() == nil {
+		err := b.strategyScheduler.Start()
+		if err != nil {
+			logger.Fatalf("Failed to start strategy scheduler: %v", err)
+			return
+		}
 	}
 
 	// Strategy scheduler manages buy signals, we handle ongoing position/order management
@@ -342,6 +462,12 @@ func (b *Bot) run() {
 
 	logger.Infof("[%s] Cron scheduler active, strategies will execute according to their cron expressions", b.Config.ExchangeName)
 
+	// Mises à jour des flux (nil sans streaming : ce cas du select ne se déclenche pas)
+	var streamEvents <-chan streamEvent
+	if b.stream != nil {
+		streamEvents = b.stream.events
+	}
+
 	for {
 		select {
 		case <-b.done:
@@ -354,14 +480,31 @@ func (b *Bot) run() {
 				}
 			}
 			return
+		case ev := <-streamEvents:
+			b.handleStreamEvent(ev) // Prix max, ventes et exécutions dès réception
 		case <-checkTicker.C:
-			b.handlePriceCheck()     // Update position max prices + trailing stop
+			tickStart := time.Now()
+			// Flux connectés : prix et ordres arrivent par le streaming, le polling
+			// n'est qu'un filet de sécurité. Sinon (coupure), polling complet.
+			pairs := b.tradedPairs()
+			streaming := b.streamLive(pairs)
+			if streaming {
+				b.streamHeartbeat(pairs)
+			} else {
+				b.handlePriceCheck() // Update position max prices + trailing stop
+			}
+			b.checkDailyLoss()       // Disjoncteur : perte réalisée du jour
 			b.executeBuyStrategies() // Achats périodiques (stratégies sans cron)
-			b.handleOrderCheck()     // Check pending orders status
+			if !streaming || b.streamOrderPollDue() {
+				b.handleOrderCheck() // Check pending orders status
+			} else {
+				b.resolveOrderIntents(time.Now().Add(-intentResolveDelay))
+			}
 			b.handleStaleBuyOrders() // Annule les ordres d'achat en attente trop vieux
 			b.checkReversalSignal()  // Notif Telegram si un creux (marteau/étoile 1h) se forme
 			b.ShowStatistics()
 			b.checkErrorAlerts() // Alerte Telegram throttlée si nouvelles erreurs
+			observeTick(tickStart)
 		}
 	}
 }
@@ -372,21 +515,50 @@ func (b *Bot) run() {
 func (b *Bot) handlePriceCheck() {
 	logger.Debug("Checking prices and positions...")
 
-	currentPrice, err := b.exchange.GetPrice(b.Config.Pair)
-	if err != nil {
-		logger.Errorf("Failed to get current price: %v", err)
+	// Un prix par paire tradée
+	pairs := b.tradedPairs()
+	prices := make(map[string]float64, len(pairs))
+	for _, pair := range pairs {
+		ticker, err := b.exchange.FetchTicker(pair)
+		if err != nil {
+			logger.Errorf("Failed to get current price for %s: %v", pair, err)
+			b.tripBreaker(b.breaker.ObserveError(err))
+			continue
+		}
+		b.breaker.ObserveSuccess()
+
+		currentPrice := b.observeTicker(pair, ticker)
+		prices[pair] = currentPrice
+		logger.Infof("[%s] Current price %s: %s", b.Config.ExchangeName, pair, b.marketFor(pair).FormatPrice(currentPrice))
+	}
+	if len(prices) == 0 {
 		return
 	}
 
-	currentPrice = b.roundToPrecision(currentPrice, b.market.Precision.Price)
-	logger.Infof("[%s] Current price: %s", b.Config.ExchangeName, b.market.FormatPrice(currentPrice))
+	// Price-check réussi (boucle vivante + exchange joignable pour toutes les paires) :
+	// on enregistre le heartbeat et on ping le dead-man's switch. Un échec plus haut
+	// saute ces deux étapes — c'est précisément ce qui déclenche l'alerte distante.
+	if len(prices) == len(pairs) {
+		b.lastCheck.Store(time.Now().UnixNano())
+		b.pingHealthcheck()
+	}
 
-	// Price-check réussi (boucle vivante + exchange joignable) : on enregistre le
-	// heartbeat et on ping le dead-man's switch. Un échec plus haut saute ces deux
-	// étapes — c'est précisément ce qui déclenche l'alerte distante.
-	b.lastCheck.Store(time.Now().UnixNano())
-	b.pingHealthcheck()
+	b.applyPrices(prices, prices)
+}
 
+// observeTicker soumet un ticker au disjoncteur et publie son prix, arrondi à la
+// précision du marché, qui est retourné.
+func (b *Bot) observeTicker(pair string, ticker Ticker) float64 {
+	b.tripBreaker(b.breaker.ObserveTicker(pair, tickerForBreaker(ticker), time.Now()))
+
+	currentPrice := b.roundToPrecision(*ticker.Last, b.marketFor(pair).Precision.Price)
+	priceGauge.Set(currentPrice, pair)
+	return currentPrice
+}
+
+// applyPrices met à jour le prix max des cycles ouverts avec le plus haut observé de
+// chaque paire (peaks), puis évalue les ventes au prix courant (prices).
+func (b *Bot) applyPrices(prices, peaks map[string]float64) {
 	// Get all open positions (from all strategies)
 	cycles, err := b.db.GetOpenCycles()
 	if err != nil {
@@ -396,24 +568,29 @@ func (b *Bot) handlePriceCheck() {
 
 	logger.Debugf("Updating max price for %d open cycles", len(cycles))
 	for _, cycle := range cycles {
+		peak, ok := peaks[cycle.Pair()]
+		if !ok {
+			continue
+		}
 		// Update max price if current price is higher
-		if currentPrice > cycle.MaxPrice {
-			err := b.db.UpdateCycleMaxPrice(cycle.ID, currentPrice)
+		if peak > cycle.MaxPrice {
+			err := b.db.UpdateCycleMaxPrice(cycle.ID, peak)
 			if err != nil {
 				logger.Errorf("Failed to update max price for cycle %d: %v", cycle.ID, err)
 				continue
 			}
-			cycle.MaxPrice = currentPrice
+			cycle.MaxPrice = peak
 			logger.Infof("[%s] Cycle %d updated MaxPrice → %s",
-				b.Config.ExchangeName, cycle.ID, b.market.FormatPrice(cycle.MaxPrice))
+				b.Config.ExchangeName, cycle.ID, b.marketFor(cycle.Pair()).FormatPrice(cycle.MaxPrice))
 		}
 	}
 
 	logger.Debug("Check for sell signals")
-	b.executeSellStrategies(currentPrice)
+	b.executeSellStrategies(prices)
 }
 
-func (b *Bot) executeSellStrategies(currentPrice float64) {
+// executeSellStrategies évalue les ventes de chaque stratégie au prix courant de sa paire.
+func (b *Bot) executeSellStrategies(prices map[string]float64) {
 	if b.paused.Load() {
 		logger.Debugf("[%s] En pause : ventes suspendues", b.Config.ExchangeName)
 		return
@@ -430,6 +607,12 @@ func (b *Bot) executeSellStrategies(currentPrice float64) {
 	strategyManager := b.strategyScheduler.GetStrategyManager()
 
 	for _, strategy := range strategies {
+		currentPrice, ok := prices[strategy.Pair]
+		if !ok {
+			logger.Debugf("[%s] No current price for %s, skipping sell signals of strategy %s", b.Config.ExchangeName, strategy.Pair, strategy.Name)
+			continue
+		}
+
 		logger.Debugf("[%s] Checking sell signals for strategy: %s", b.Config.ExchangeName, strategy.Name)
 
 		// Execute sell logic only
@@ -451,6 +634,10 @@ func (b *Bot) executeBuyStrategies() {
 		logger.Debugf("[%s] En pause : achats périodiques suspendus", b.Config.ExchangeName)
 		return
 	}
+	if err := b.buysHalted(); err != nil {
+		logger.Debugf("[%s] %v", b.Config.ExchangeName, err)
+		return
+	}
 
 	strategies, err := b.db.GetAllStrategies()
 	if err != nil {
@@ -489,6 +676,8 @@ func (b *Bot) executeBuyStrategies() {
 func (b *Bot) handleOrderCheck() {
 	logger.Debug("Checking orders...")
 
+	b.resolveOrderIntents(time.Now().Add(-intentResolveDelay))
+
 	pendingOrders, err := b.db.GetPendingOrders()
 	if err != nil {
 		logger.Errorf("Failed to get pending orders: %v", err)
@@ -532,7 +721,7 @@ func (b *Bot) handleStaleBuyOrders() {
 		logger.Infof("[%s] Ordre d'achat %s (stratégie %s) en attente depuis %s (> %s) : annulation automatique",
 			b.Config.ExchangeName, dbOrder.ExternalID, strategy.Name, age.Round(time.Minute), maxAge)
 
-		if _, err := b.exchange.CancelOrder(dbOrder.ExternalID, b.Config.Pair); err != nil {
+		if _, err := b.exchange.CancelOrder(dbOrder.ExternalID, dbOrder.Pair); err != nil {
 			// L'ordre a pu se remplir entre-temps : le cancel échoue, handleOrderCheck
 			// (tick courant ou suivant) traitera le remplissage. On ne force rien ici.
 			logger.Errorf("[%s] Échec annulation ordre d'achat périmé %s : %v", b.Config.ExchangeName, dbOrder.ExternalID, err)
@@ -547,21 +736,31 @@ func (b *Bot) handleStaleBuyOrders() {
 }
 
 func (b *Bot) processOrder(dbOrder database.Order) {
-	order, err := b.exchange.FetchOrder(dbOrder.ExternalID, b.Config.Pair)
+	order, err := b.exchange.FetchOrder(dbOrder.ExternalID, dbOrder.Pair)
 	if err != nil {
 		logger.Errorf("Failed to fetch Order (ID=%v): %v", dbOrder.ExternalID, err)
+		// Un ordre inconnu de l'exchange relève de la réconciliation, pas d'une panne
+		if !isOrderNotFound(err) {
+			b.tripBreaker(b.breaker.ObserveError(err))
+		}
 		return
 	}
+	b.breaker.ObserveSuccess()
+	b.applyOrder(dbOrder, order)
+}
 
+// applyOrder applique l'état d'un ordre relu sur l'exchange (polling) ou poussé par
+// le flux d'ordres (streaming) à l'ordre en attente correspondant.
+func (b *Bot) applyOrder(dbOrder database.Order, order Order) {
 	if order.Status != nil {
 		switch *order.Status {
 		case "closed":
 			b.handleClosedOrder(dbOrder, order)
-		case "canceled":
+		case "canceled", "expired":
 			b.handleCanceledOrder(dbOrder, order)
 		case "open":
 			// Removed automatic cancel logic - orders will remain active until filled or manually cancelled
-			logger.Debugf("Order %v still open", dbOrder.ExternalID)
+			b.handlePartialFill(dbOrder, order)
 		default:
 			logger.Warnf("Unsupported Order Status: %v", *order.Status)
 		}
@@ -570,7 +769,58 @@ func (b *Bot) processOrder(dbOrder database.Order) {
 	}
 }
 
+// orderFill retourne la quantité exécutée et le prix moyen d'exécution d'un ordre.
+// À défaut d'information de l'exchange, un ordre "closed" est considéré rempli en
+// totalité au prix limite, et un ordre ouvert ou annulé comme non rempli.
+func orderFill(dbOrder database.Order, order Order) (filled, price float64) {
+	switch {
+	case order.Filled != nil:
+		filled = *order.Filled
+	case order.Status != nil && *order.Status == "closed":
+		filled = dbOrder.Amount
+		if order.Amount != nil {
+			filled = *order.Amount
+		}
+	}
+
+	price = dbOrder.Price
+	if order.Average != nil && *order.Average > 0 {
+		price = *order.Average
+	} else if order.Price != nil && *order.Price > 0 {
+		price = *order.Price
+	}
+	return filled, price
+}
+
+// handlePartialFill enregistre la progression d'un ordre encore ouvert mais déjà
+// partiellement exécuté, pour que la base reflète la quantité réellement détenue.
+func (b *Bot) handlePartialFill(dbOrder database.Order, order Order) {
+	filled, price := orderFill(dbOrder, order)
+	if filled <= 0 || (dbOrder.FilledAmount != nil && *dbOrder.FilledAmount == filled) {
+		logger.Debugf("Order %v still open", dbOrder.ExternalID)
+		return
+	}
+
+	if err := b.db.UpdateOrderFill(dbOrder.ExternalID, filled, price); err != nil {
+		logger.Errorf("Failed to update order fill in database: %v", err)
+		return
+	}
+
+	m := b.marketFor(dbOrder.Pair)
+	logger.Infof("[%s] Order %s partially filled: %s / %s %s at %s %s",
+		b.Config.ExchangeName, dbOrder.ExternalID,
+		m.FormatAmount(filled), m.FormatAmount(dbOrder.Amount), m.BaseAsset,
+		m.FormatPrice(price), m.QuoteAsset)
+}
+
 func (b *Bot) handleClosedOrder(dbOrder database.Order, order Order) {
+	filled, price := orderFill(dbOrder, order)
+	if err := b.db.UpdateOrderFill(dbOrder.ExternalID, filled, price); err != nil {
+		logger.Errorf("Failed to update order fill in database: %v", err)
+		return
+	}
+	b.recordOrderFees(dbOrder)
+
 	err := b.db.UpdateOrderStatus(dbOrder.ExternalID, database.Filled)
 	if err != nil {
 		logger.Errorf("Failed to update order status in database: %v", err)
@@ -579,44 +829,80 @@ func (b *Bot) handleClosedOrder(dbOrder database.Order, order Order) {
 
 	switch dbOrder.Side {
 	case database.Buy:
-		b.handleFilledBuyOrder(dbOrder, order)
+		b.handleFilledBuyOrder(dbOrder, filled, price)
 	case database.Sell:
-		b.handleFilledSellOrder(dbOrder, order)
+		b.handleFilledSellOrder(dbOrder, filled, price)
 	}
 }
 
 func (b *Bot) handleCanceledOrder(dbOrder database.Order, order Order) {
+	filled, price := orderFill(dbOrder, order)
+	m := b.marketFor(dbOrder.Pair)
+
+	if filled > 0 {
+		if err := b.db.UpdateOrderFill(dbOrder.ExternalID, filled, price); err != nil {
+			logger.Errorf("Failed to update order fill in database: %v", err)
+			return
+		}
+		b.recordOrderFees(dbOrder)
+
+		// Achat partiellement rempli puis annulé : la quantité achetée est bien détenue.
+		// L'ordre est considéré exécuté (pour sa partie remplie) et le cycle devient Open,
+		// sinon les coins achetés seraient orphelins.
+		if dbOrder.Side == database.Buy {
+			if err := b.db.UpdateOrderStatus(dbOrder.ExternalID, database.Filled); err != nil {
+				logger.Errorf("Failed to update order status in database: %v", err)
+				return
+			}
+			logger.Infof("[%s] Buy Order %s cancelled after partial fill (%s / %s %s): cycle kept open",
+				b.Config.ExchangeName, dbOrder.ExternalID,
+				m.FormatAmount(filled), m.FormatAmount(dbOrder.Amount), m.BaseAsset)
+			b.handleFilledBuyOrder(dbOrder, filled, price)
+			return
+		}
+
+		// Vente partiellement remplie puis annulée : le cycle redevient vendable pour la
+		// quantité restante (cf. StrategyManager.heldAmount).
+		logger.Warnf("[%s] Sell Order %s cancelled after partial fill (%s / %s %s): remaining amount will be sold again",
+			b.Config.ExchangeName, dbOrder.ExternalID,
+			m.FormatAmount(filled), m.FormatAmount(dbOrder.Amount), m.BaseAsset)
+	}
+
 	err := b.db.UpdateOrderStatus(dbOrder.ExternalID, database.Cancelled)
 	if err != nil {
 		logger.Errorf("Failed to update order status in database: %v", err)
 		return
 	}
 
-	logger.Infof("[%s] Order %v Cancelled (cancelled manually on exchange)", b.Config.ExchangeName, order.Id)
+	logger.Infof("[%s] Order %v Cancelled (cancelled manually on exchange)", b.Config.ExchangeName, dbOrder.ExternalID)
+	b.publishOrderEvent(events.OrderCancelled, dbOrder, 0, filled, price, "")
 }
 
-func (b *Bot) handleFilledBuyOrder(dbOrder database.Order, order Order) {
+func (b *Bot) handleFilledBuyOrder(dbOrder database.Order, filled, price float64) {
 	dbCycle, err := b.db.GetCycleForBuyOrder(dbOrder.ID)
 	if err != nil {
 		logger.Errorf("Failed to get cycle from buy order %v: %v", dbOrder.ID, err)
+		return
 	}
 
+	m := b.marketFor(dbOrder.Pair)
 	message := ""
-	message += fmt.Sprintf("🌀 Cycle on %s [%d] UPDATE", b.Config.ExchangeName, dbCycle.ID)
-	message += fmt.Sprintf("\n✅ Buy Order Filled: %s", *order.Id)
-	message += fmt.Sprintf("\n💰 Quantity: %s %s", b.market.FormatAmount(*order.Amount), b.market.BaseAsset)
-	message += fmt.Sprintf("\n📉 Buy Price: %s %s", b.market.FormatPrice(*order.Price), b.market.QuoteAsset)
-	message += fmt.Sprintf("\n💲 Value: %.2f %s", *order.Amount**order.Price, b.market.QuoteAsset)
-
-	err = telegram.SendMessage(message)
-	if err != nil {
-		logger.Errorf("Failed to send notification to Telegram: %v", err)
+	message += fmt.Sprintf("🌀 Cycle on %s %s [%d] UPDATE", b.Config.ExchangeName, dbOrder.Pair, dbCycle.ID)
+	if filled < dbOrder.Amount {
+		message += fmt.Sprintf("\n✅ Buy Order Partially Filled: %s", dbOrder.ExternalID)
+	} else {
+		message += fmt.Sprintf("\n✅ Buy Order Filled: %s", dbOrder.ExternalID)
 	}
+	message += fmt.Sprintf("\n💰 Quantity: %s %s", m.FormatAmount(filled), m.BaseAsset)
+	message += fmt.Sprintf("\n📉 Buy Price: %s %s", m.FormatPrice(price), m.QuoteAsset)
+	message += fmt.Sprintf("\n💲 Value: %.2f %s", filled*price, m.QuoteAsset)
+
+	b.publishOrderEvent(events.OrderFilled, dbOrder, dbCycle.ID, filled, price, message)
 
 	logger.Infof("[%s] Buy Order Filled: %s %s at %s %s (ID=%v)",
 		b.Config.ExchangeName,
-		b.market.FormatAmount(*order.Amount), b.market.BaseAsset, b.market.FormatPrice(*order.Price), b.market.QuoteAsset,
-		order.Id)
+		m.FormatAmount(filled), m.BaseAsset, m.FormatPrice(price), m.QuoteAsset,
+		dbOrder.ExternalID)
 
 	// Le prix cible est défini par la stratégie
 	strategy, err := b.db.GetStrategy(dbCycle.StrategyID)
@@ -625,7 +911,7 @@ func (b *Bot) handleFilledBuyOrder(dbOrder database.Order, order Order) {
 		return
 	}
 
-	targetPrice := *order.Price * (1.0 + strategy.ProfitTarget/100.0)
+	targetPrice := price * (1.0 + strategy.ProfitTarget/100.0)
 
 	err = b.db.UpdateCycleTargetPrice(dbCycle.ID, targetPrice)
 	if err != nil {
@@ -633,37 +919,47 @@ func (b *Bot) handleFilledBuyOrder(dbOrder database.Order, order Order) {
 	}
 }
 
-func (b *Bot) handleFilledSellOrder(dbOrder database.Order, order Order) {
+func (b *Bot) handleFilledSellOrder(dbOrder database.Order, filled, price float64) {
 	dbCycle, err := b.db.GetCycleForSellOrder(dbOrder.ID)
 	if err != nil {
 		logger.Errorf("Failed to get cycle from sell order %v: %v", dbOrder.ID, err)
+		return
 	}
 
+	m := b.marketFor(dbOrder.Pair)
 	message := ""
-	message += fmt.Sprintf("🌀 Cycle on %s [%d] COMPLETE", b.Config.ExchangeName, dbCycle.ID)
-	message += fmt.Sprintf("\n✅ Sell Order Filled: %s", *order.Id)
-	message += fmt.Sprintf("\n💰 Quantity: %s %s", b.market.FormatAmount(*order.Amount), b.market.BaseAsset)
-	message += fmt.Sprintf("\n📈 Sell Price: %s %s", b.market.FormatPrice(*order.Price), b.market.QuoteAsset)
-	message += fmt.Sprintf("\n💲 Value: %.2f %s", *order.Amount**order.Price, b.market.QuoteAsset)
-
-	buyValue := dbCycle.BuyOrder.Price * dbCycle.BuyOrder.Amount
-	win := *dbCycle.Profit
+	message += fmt.Sprintf("🌀 Cycle on %s %s [%d] COMPLETE", b.Config.ExchangeName, dbOrder.Pair, dbCycle.ID)
+	message += fmt.Sprintf("\n✅ Sell Order Filled: %s", dbOrder.ExternalID)
+	message += fmt.Sprintf("\n💰 Quantity: %s %s", m.FormatAmount(filled), m.BaseAsset)
+	message += fmt.Sprintf("\n📈 Sell Price: %s %s", m.FormatPrice(price), m.QuoteAsset)
+	message += fmt.Sprintf("\n💲 Value: %.2f %s", filled*price, m.QuoteAsset)
+
+	buyValue := dbCycle.BuyOrder.ExecutedPrice() * filled
+	fees := dbCycle.BuyOrder.Fees + dbCycle.SellOrder.Fees
+	win := *dbCycle.Profit // net des frais d'achat et de vente
 	winPercent := (win / buyValue) * 100
-	message += fmt.Sprintf("\n🤑 Profit: %.2f %s (%+.1f%%)", win, b.market.QuoteAsset, winPercent)
-
-	err = telegram.SendMessage(message)
-	if err != nil {
-		logger.Errorf("Failed to send notification to Telegram: %v", err)
-	}
+	message += fmt.Sprintf("\n💸 Fees: %.4f %s", fees, m.QuoteAsset)
+	message += fmt.Sprintf("\n🤑 Net Profit: %.2f %s (%+.1f%%)", win, m.QuoteAsset, winPercent)
+
+	b.publishOrderEvent(events.OrderFilled, dbOrder, dbCycle.ID, filled, price, "")
+	b.events.Publish(events.Event{
+		Type:       events.CycleCompleted,
+		Pair:       dbOrder.Pair,
+		StrategyID: dbCycle.StrategyID,
+		CycleID:    dbCycle.ID,
+		OrderID:    dbOrder.ExternalID,
+		Message:    message,
+		Data:       map[string]any{"profit": win, "profit_pct": winPercent, "fees": fees},
+	})
 
 	logger.Infof("[%s] Sell Order Filled: %s %s at %s %s (ID=%s)",
 		b.Config.ExchangeName,
-		b.market.FormatAmount(*order.Amount), b.market.BaseAsset, b.market.FormatPrice(*order.Price), b.market.QuoteAsset,
-		*order.Id)
+		m.FormatAmount(filled), m.BaseAsset, m.FormatPrice(price), m.QuoteAsset,
+		dbOrder.ExternalID)
 }
 
 func (b *Bot) ShowStatistics() {
-	if stats, err := b.db.GetStats(); err == nil {
+	if stats, err := b.db.GetStats(""); err == nil {
 		logger.Infof("[%s] Profit[Average=%.2f, Total=%.2f] Cycles[Active=%d, Completed=%d, Count=%d] Orders[Pending=%d, Filled=%d, Cancelled=%d]",
 			b.Config.ExchangeName,
 
@@ -699,13 +995,14 @@ func (b *Bot) ReloadStrategies() error {
 	// Create a new strategy scheduler instead of restarting the old one
 	strategyScheduler, err := scheduler.NewStrategyScheduler(
 		b.Config.ExchangeName,
-		b.Config.Pair,
 		b.db,
-		&b.market,
+		b,
 		b.marketCollector,
 		b.Calculator,
 		b.algorithmRegistry,
-		b)
+		b,
+		b.Config.RiskLimits,
+		b.events)
 	if err != nil {
 		logger.Errorf("Failed to create new strategy scheduler: %v", err)
 		return err
@@ -714,6 +1011,12 @@ func (b *Bot) ReloadStrategies() error {
 	// Replace the old scheduler with the new one
 	b.strategyScheduler = strategyScheduler
 
+	// Disjoncteur déclenché : le scheduler reste arrêté jusqu'au réarmement
+	if b.halt.Load() != nil {
+		logger.Infof("[%s] Strategies reloaded, scheduler kept stopped (circuit breaker tripped)", b.Config.ExchangeName)
+		return nil
+	}
+
 	// Start the new scheduler with fresh strategies from database
 	err = b.strategyScheduler.Start()
 	if err != nil {
@@ -766,11 +1069,11 @@ func (b *Bot) roundToPrecision(value, precision float64) float64 {
 }
 
 func (m *Market) FormatAmount(amount float64) string {
-	return strconv.FormatFloat(amount, 'f', m.Precision.AmountDecimals, 64)
+	return decimal.FromFloat(amount).StringFixed(int32(m.Precision.AmountDecimals))
 }
 
 func (m *Market) FormatPrice(price float64) string {
-	return strconv.FormatFloat(price, 'f', m.Precision.PriceDecimals, 64)
+	return decimal.FromFloat(price).StringFixed(int32(m.Precision.PriceDecimals))
 }
 
 func (m *Market) GetBaseAsset() string {
@@ -787,6 +1090,12 @@ func (m *Market) GetPrecision() algorithms.MarketPrecision {
 		PriceDecimals:  m.Precision.PriceDecimals,
 		Amount:         m.Precision.Amount,
 		AmountDecimals: m.Precision.AmountDecimals,
+		MinAmount:      m.Limits.MinAmount,
+		MaxAmount:      m.Limits.MaxAmount,
+		MinCost:        m.Limits.MinCost,
+		MaxCost:        m.Limits.MaxCost,
+		MinPrice:       m.Limits.MinPrice,
+		MaxPrice:       m.Limits.MaxPrice,
 	}
 }
 
@@ -854,11 +1163,12 @@ func (b *Bot) GetPrice(pair string) (float64, error) {
 }
 
 // FetchBalances implémente api.BotInterface : retourne les soldes non nuls (free/used/total),
-// la devise de base, la devise de cotation et le prix courant de la paire configurée.
-func (b *Bot) FetchBalances() (map[string]api.BalanceAmounts, string, string, float64, error) {
+// la devise de cotation de la paire par défaut et le prix courant, dans cette devise, de
+// l'actif de base de chaque paire tradée qui la partage.
+func (b *Bot) FetchBalances() (map[string]api.BalanceAmounts, string, map[string]float64, error) {
 	rawBalances, err := b.exchange.FetchBalance()
 	if err != nil {
-		return nil, "", "", 0, fmt.Errorf("fetch balance: %w", err)
+		return nil, "", nil, fmt.Errorf("fetch balance: %w", err)
 	}
 
 	balances := make(map[string]api.BalanceAmounts)
@@ -868,22 +1178,37 @@ func (b *Bot) FetchBalances() (map[string]api.BalanceAmounts, string, string, fl
 		}
 	}
 
-	currentPrice, err := b.exchange.GetPrice(b.market.Symbol)
-	if err != nil {
-		// Prix non disponible : on retourne quand même les soldes
-		logger.Warnf("[%s] Could not fetch price for portfolio valuation: %v", b.ExchangeName(), err)
-		currentPrice = 0
+	quote := b.marketFor(b.Config.Pair).QuoteAsset
+	prices := make(map[string]float64)
+	for _, pair := range b.tradedPairs() {
+		m := b.marketFor(pair)
+		if m.QuoteAsset != quote {
+			continue // autre devise de cotation : non valorisable dans la devise de référence
+		}
+		currentPrice, err := b.exchange.GetPrice(m.Symbol)
+		if err != nil {
+			// Prix non disponible : on retourne quand même les soldes
+			logger.Warnf("[%s] Could not fetch price of %s for portfolio valuation: %v", b.ExchangeName(), pair, err)
+			continue
+		}
+		prices[m.BaseAsset] = currentPrice
 	}
 
-	return balances, b.market.BaseAsset, b.market.QuoteAsset, currentPrice, nil
+	return balances, quote, prices, nil
 }
 
-func (b *Bot) PlaceLimitBuyOrder(pair string, amount float64, price float64) (scheduler.ExchangeOrder, error) {
+func (b *Bot) PlaceLimitBuyOrder(pair string, amount float64, price float64, clientOrderID string) (scheduler.ExchangeOrder, error) {
+	// Dernier rempart : aucun achat ne part pendant un déclenchement du disjoncteur
+	if err := b.buysHalted(); err != nil {
+		return scheduler.ExchangeOrder{}, err
+	}
+
 	// Round according to market precision
-	amount = b.roundToPrecision(amount, b.market.Precision.Amount)
-	price = b.roundToPrecision(price, b.market.Precision.Price)
+	m := b.marketFor(pair)
+	amount = b.roundToPrecision(amount, m.Precision.Amount)
+	price = b.roundToPrecision(price, m.Precision.Price)
 
-	botOrder, err := b.exchange.PlaceLimitBuyOrder(pair, amount, price)
+	botOrder, err := b.exchange.PlaceLimitBuyOrder(pair, amount, price, clientOrderID)
 	if err != nil {
 		return scheduler.ExchangeOrder{}, err
 	}
@@ -896,12 +1221,13 @@ func (b *Bot) PlaceLimitBuyOrder(pair string, amount float64, price float64) (sc
 	}, nil
 }
 
-func (b *Bot) PlaceLimitSellOrder(pair string, amount float64, price float64) (scheduler.ExchangeOrder, error) {
+func (b *Bot) PlaceLimitSellOrder(pair string, amount float64, price float64, clientOrderID string) (scheduler.ExchangeOrder, error) {
 	// Round according to market precision
-	amount = b.roundToPrecision(amount, b.market.Precision.Amount)
-	price = b.roundToPrecision(price, b.market.Precision.Price)
+	m := b.marketFor(pair)
+	amount = b.roundToPrecision(amount, m.Precision.Amount)
+	price = b.roundToPrecision(price, m.Precision.Price)
 
-	botOrder, err := b.exchange.PlaceLimitSellOrder(pair, amount, price)
+	botOrder, err := b.exchange.PlaceLimitSellOrder(pair, amount, price, clientOrderID)
 	if err != nil {
 		return scheduler.ExchangeOrder{}, err
 	}
@@ -0,0 +1,217 @@
+package bot
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/events"
+	"bot/internal/logger"
+	"bot/internal/risk"
+	"fmt"
+	"time"
+)
+
+// Disjoncteur : la boucle principale transmet chaque ticker et chaque erreur exchange au
+// risk.Breaker ; au premier déclenchement, les achats sont suspendus comme en pause
+// (scheduler cron arrêté, achats périodiques et manuels refusés) mais les ventes et le
+// suivi des ordres continuent. Le déclenchement est persisté (il survit à un redémarrage)
+// et publié sur le bus d'événements (notifié sur Telegram) ; seul un réarmement explicite de l'opérateur lève la suspension.
+
+// restoreBreaker recharge le déclenchement encore actif au démarrage du bot.
+func (b *Bot) restoreBreaker() {
+	trip, err := b.db.GetActiveCircuitTrip()
+	if err != nil {
+		logger.Errorf("[%s] Failed to get active circuit trip: %v", b.Config.ExchangeName, err)
+		return
+	}
+	if trip != nil {
+		b.halt.Store(trip)
+		logger.Warnf("[%s] 🚨 Disjoncteur déclenché le %s : achats suspendus jusqu'au réarmement (%s)",
+			b.Config.ExchangeName, trip.CreatedAt.Format("02/01/2006 15:04"), trip.Reason)
+	}
+}
+
+// BreakerTrip retourne le déclenchement en cours, nil si les achats sont autorisés.
+func (b *Bot) BreakerTrip() *database.CircuitTrip {
+	return b.halt.Load()
+}
+
+// buysHalted retourne une erreur si le disjoncteur suspend les achats.
+func (b *Bot) buysHalted() error {
+	if trip := b.halt.Load(); trip != nil {
+		return fmt.Errorf("achats suspendus par le disjoncteur : %s", trip.Reason)
+	}
+	return nil
+}
+
+// tripBreaker suspend les achats sur un déclenchement automatique. Sans effet si trip
+// est nil ou si le disjoncteur est déjà déclenché.
+func (b *Bot) tripBreaker(trip *risk.Trip) {
+	if trip == nil || b.halt.Load() != nil {
+		return
+	}
+
+	record, err := b.db.RecordCircuitTrip(string(trip.Kind), trip.Pair, trip.Reason, "")
+	if err != nil {
+		// On suspend quand même : la sécurité prime sur la trace en base
+		logger.Errorf("[%s] Failed to record circuit trip: %v", b.Config.ExchangeName, err)
+		record = &database.CircuitTrip{Kind: string(trip.Kind), Pair: trip.Pair, Reason: trip.Reason, CreatedAt: time.Now()}
+	}
+	if !b.halt.CompareAndSwap(nil, record) {
+		return
+	}
+	b.stopBuying()
+
+	logger.Warnf("[%s] 🚨 Disjoncteur déclenché (%s) : achats suspendus — %s", b.Config.ExchangeName, trip.Kind, trip.Reason)
+	b.events.Publish(events.Event{
+		Type: events.BreakerTripped,
+		Pair: trip.Pair,
+		Message: fmt.Sprintf("🚨 [%s] Disjoncteur déclenché : achats suspendus\n%s\nLes ventes continuent. Réarmement depuis la WebUI.",
+			b.Config.ExchangeName, trip.Reason),
+		Data: map[string]any{"kind": string(trip.Kind), "reason": trip.Reason},
+	})
+}
+
+// stopBuying arrête le scheduler cron, qui ne porte que des achats. Les achats
+// périodiques et manuels consultent halt à chaque déclenchement.
+func (b *Bot) stopBuying() {
+	if b.strategyScheduler == nil {
+		return
+	}
+	if err := b.strategyScheduler.Stop(); err != nil {
+		logger.Warnf("[%s] Échec de l'arrêt du scheduler sur déclenchement du disjoncteur : %v", b.Config.ExchangeName, err)
+	}
+}
+
+// KillSwitch est l'arrêt d'urgence de l'opérateur : il déclenche le disjoncteur (même
+// s'il l'est déjà) et annule tous les ordres d'achat en attente. Retourne le nombre
+// d'ordres annulés.
+func (b *Bot) KillSwitch(user string) (int, error) {
+	reason := "arrêt d'urgence"
+	if user != "" {
+		reason += " demandé par " + user
+	}
+	record, err := b.db.RecordCircuitTrip(string(risk.TripManual), "", reason, user)
+	if err != nil {
+		return 0, err
+	}
+	if b.halt.Swap(record) == nil {
+		b.stopBuying()
+	}
+	logger.Warnf("[%s] 🛑 Arrêt d'urgence (%s) : achats suspendus, annulation des achats en attente", b.Config.ExchangeName, reason)
+
+	cancelled, failed := b.cancelPendingBuys()
+	if err := b.db.SetCircuitTripCancelledOrders(record.ID, cancelled); err != nil {
+		logger.Errorf("[%s] %v", b.Config.ExchangeName, err)
+	}
+	record.CancelledOrders = cancelled
+
+	text := fmt.Sprintf("🛑 [%s] Arrêt d'urgence : achats suspendus\n%s\n%d ordre(s) d'achat annulé(s)",
+		b.Config.ExchangeName, reason, cancelled)
+	if failed > 0 {
+		text += fmt.Sprintf(", %d en échec (voir les logs)", failed)
+	}
+	b.events.Publish(events.Event{
+		Type:    events.BreakerTripped,
+		Message: text,
+		Data: map[string]any{
+			"kind": string(risk.TripManual), "reason": reason, "user": user,
+			"cancelled_orders": cancelled, "failed_orders": failed,
+		},
+	})
+	return cancelled, nil
+}
+
+// cancelPendingBuys annule tous les ordres d'achat en attente, puis resynchronise chacun
+// depuis l'exchange (un ordre rempli juste avant l'annulation suit son cours normal).
+func (b *Bot) cancelPendingBuys() (cancelled, failed int) {
+	orders, err := b.db.GetPendingOrders()
+	if err != nil {
+		logger.Errorf("[%s] Failed to get pending orders for kill switch: %v", b.Config.ExchangeName, err)
+		return 0, 0
+	}
+	for _, dbOrder := range orders {
+		if dbOrder.Side != database.Buy {
+			continue
+		}
+		if _, err := b.exchange.CancelOrder(dbOrder.ExternalID, dbOrder.Pair); err != nil {
+			logger.Errorf("[%s] Arrêt d'urgence : échec annulation ordre d'achat %s : %v", b.Config.ExchangeName, dbOrder.ExternalID, err)
+			failed++
+			continue
+		}
+		b.processOrder(dbOrder)
+		cancelled++
+	}
+	return cancelled, failed
+}
+
+// ResetBreaker réarme le disjoncteur : les achats reprennent (scheduler cron relancé,
+// sauf si le bot est en pause) et l'historique des prix et erreurs repart de zéro.
+func (b *Bot) ResetBreaker(user string) error {
+	if b.halt.Load() == nil {
+		return nil // pas déclenché
+	}
+	if _, err := b.db.ResetCircuitTrips(user); err != nil {
+		return err
+	}
+	b.breaker.Reset()
+	b.halt.Store(nil)
+
+	logger.Infof("[%s] ✅ Disjoncteur réarmé%s : reprise des achats", b.Config.ExchangeName, byUser(user))
+	b.events.Publish(events.Event{
+		Type:    events.BreakerReset,
+		Message: fmt.Sprintf("✅ [%s] Disjoncteur réarmé%s : reprise des achats", b.Config.ExchangeName, byUser(user)),
+		Data:    map[string]any{"user": user},
+	})
+
+	if b.paused.Load() {
+		return nil // Resume relancera le scheduler
+	}
+	return b.ReloadStrategies()
+}
+
+func byUser(user string) string {
+	if user == "" {
+		return ""
+	}
+	return " par " + user
+}
+
+// checkDailyLoss déclenche le disjoncteur si la perte réalisée depuis minuit UTC (ou
+// depuis le dernier réarmement, s'il est plus récent) dépasse la limite.
+func (b *Bot) checkDailyLoss() {
+	if b.Config.Breaker.MaxDailyLoss <= 0 || b.halt.Load() != nil {
+		return
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	lastReset, err := b.db.GetLastCircuitReset()
+	if err != nil {
+		logger.Errorf("[%s] %v", b.Config.ExchangeName, err)
+		return
+	}
+	if lastReset != nil && lastReset.After(since) {
+		since = *lastReset
+	}
+
+	realized, err := b.db.GetRealizedProfitSince(since)
+	if err != nil {
+		logger.Errorf("[%s] %v", b.Config.ExchangeName, err)
+		return
+	}
+	b.tripBreaker(b.breaker.CheckDailyLoss(realized))
+}
+
+// tickerForBreaker convertit un ticker exchange pour le disjoncteur.
+func tickerForBreaker(t Ticker) risk.Ticker {
+	rt := risk.Ticker{Last: *t.Last}
+	if t.Bid != nil {
+		rt.Bid = *t.Bid
+	}
+	if t.Ask != nil {
+		rt.Ask = *t.Ask
+	}
+	if t.Timestamp != nil && *t.Timestamp > 0 {
+		rt.Time = time.UnixMilli(*t.Timestamp)
+	}
+	return rt
+}
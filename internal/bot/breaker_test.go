@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"testing"
+
+	"bot/internal/core/database"
+	"bot/internal/risk"
+)
+
+// Un déclenchement automatique est persisté et suspend les achats ; les suivants sont
+// ignorés tant que le disjoncteur n'est pas réarmé.
+func TestBreaker_TripPersistsAndHaltsBuys(t *testing.T) {
+	b, db := newReconcileBot(t, &fakeExchange{})
+
+	b.tripBreaker(&risk.Trip{Kind: risk.TripPriceDrop, Pair: "BTC/USDC", Reason: "chute de BTC/USDC"})
+	b.tripBreaker(&risk.Trip{Kind: risk.TripExchangeErrors, Reason: "erreurs"})
+
+	trip := b.BreakerTrip()
+	if trip == nil || trip.Kind != string(risk.TripPriceDrop) {
+		t.Fatalf("déclenchement en cours : %+v", trip)
+	}
+	if trips, _ := db.GetCircuitTrips(10); len(trips) != 1 {
+		t.Errorf("%d déclenchements enregistrés, attendu 1", len(trips))
+	}
+	if _, err := b.PlaceLimitBuyOrder("BTC/USDC", 0.01, 100, ""); err == nil {
+		t.Error("achat posé malgré le disjoncteur")
+	}
+	if _, err := b.ForceBuy(); err == nil {
+		t.Error("achat manuel accepté malgré le disjoncteur")
+	}
+
+	// Un nouveau bot (redémarrage) reprend le déclenchement actif
+	restarted := &Bot{db: db}
+	restarted.restoreBreaker()
+	if restarted.BreakerTrip() == nil {
+		t.Error("déclenchement perdu au redémarrage")
+	}
+}
+
+// L'arrêt d'urgence annule les achats en attente (pas les ventes) ; le réarmement lève
+// la suspension.
+func TestBreaker_KillSwitchAndReset(t *testing.T) {
+	ex := &fakeExchange{
+		orders: map[string]Order{
+			"pending-buy": {Id: ptr("pending-buy"), Status: ptr("canceled"), Filled: ptr(0.0)},
+		},
+	}
+	b, db := newReconcileBot(t, ex)
+
+	buy, err := db.CreateOrder("pending-buy", database.Buy, 0.01, 95, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateCycle(buy.ID, 97); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateOrder("pending-sell", database.Sell, 0.01, 110, 0, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, err := b.KillSwitch("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled != 1 {
+		t.Errorf("%d achats annulés, attendu 1", cancelled)
+	}
+	pending, _ := db.GetPendingOrders()
+	if len(pending) != 1 || pending[0].ExternalID != "pending-sell" {
+		t.Errorf("ordres en attente après arrêt d'urgence : %+v", pending)
+	}
+	trip, _ := db.GetActiveCircuitTrip()
+	if trip == nil || trip.Kind != string(risk.TripManual) || trip.TriggeredBy != "alice" || trip.CancelledOrders != 1 {
+		t.Fatalf("déclenchement enregistré : %+v", trip)
+	}
+
+	// En pause, le réarmement ne relance pas le scheduler (Resume s'en chargera)
+	b.paused.Store(true)
+	if err := b.ResetBreaker("bob"); err != nil {
+		t.Fatal(err)
+	}
+	if b.BreakerTrip() != nil {
+		t.Error("disjoncteur toujours déclenché après réarmement")
+	}
+	if trip, _ := db.GetActiveCircuitTrip(); trip != nil {
+		t.Errorf("déclenchement encore actif en base : %+v", trip)
+	}
+}
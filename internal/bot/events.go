@@ -0,0 +1,81 @@
+package bot
+
+import (
+	"bot/internal/core/config"
+	"bot/internal/core/database"
+	"bot/internal/events"
+	"bot/internal/logger"
+	"bot/internal/notify"
+	"strings"
+)
+
+// newEventBus crée le bus d'événements de l'instance : chaque événement est persisté
+// dans la table events (relue par le flux SSE de la WebUI), notifié sur les canaux
+// configurés s'il porte un message, et posté sur le webhook EVENTS_WEBHOOK_URL s'il est
+// configuré.
+func newEventBus(cfg config.BotConfig, db *database.DB) *events.Bus {
+	bus := events.NewBus(cfg.ExchangeName, db)
+	subscribeNotifiers(bus, cfg)
+
+	if wh := cfg.EventWebhook; wh.URL != "" {
+		types, unknown := events.ParseTypes(wh.Types)
+		if len(unknown) > 0 {
+			logger.Warnf("[%s] EVENTS_WEBHOOK_TYPES : types inconnus ignorés (%s)", cfg.ExchangeName, strings.Join(unknown, ", "))
+			if len(types) == 0 {
+				return bus // aucun type valide : ne pas tout envoyer par erreur
+			}
+		}
+		bus.Subscribe(&events.WebhookSink{URL: wh.URL, Secret: wh.Secret}, types...)
+		logger.Infof("[%s] Webhook d'événements activé (%d type(s), 0 = tous)", cfg.ExchangeName, len(types))
+	}
+	return bus
+}
+
+// subscribeNotifiers abonne au bus chaque canal de notification configuré, pour les types
+// qui lui sont routés.
+func subscribeNotifiers(bus *events.Bus, cfg config.BotConfig) {
+	retry := notify.DefaultRetry
+	if cfg.NotifyRetries > 0 {
+		retry.Attempts = cfg.NotifyRetries
+	}
+	for _, nc := range cfg.Notifiers {
+		types, unknown := events.ParseTypes(nc.Types)
+		if len(unknown) > 0 {
+			logger.Warnf("[%s] Notifications %s : types inconnus ignorés (%s)", cfg.ExchangeName, nc.Kind, strings.Join(unknown, ", "))
+			if len(types) == 0 {
+				continue // aucun type valide : ne pas tout envoyer par erreur
+			}
+		}
+		n, err := notify.New(notify.Config{Kind: nc.Kind, URL: nc.URL, Token: nc.Token})
+		if err != nil {
+			logger.Errorf("[%s] Canal de notification ignoré : %v", cfg.ExchangeName, err)
+			continue
+		}
+		notify.Subscribe(bus, n, retry, types...)
+		if nc.Kind != notify.KindTelegram {
+			logger.Infof("[%s] Notifications %s activées (%d type(s), 0 = tous)", cfg.ExchangeName, nc.Kind, len(types))
+		}
+	}
+}
+
+// publishOrderEvent publie un événement d'ordre (exécuté, annulé) avec la quantité
+// remplie et le prix moyen. message non vide = notifié sur Telegram.
+func (b *Bot) publishOrderEvent(t events.Type, dbOrder database.Order, cycleID int, filled, price float64, message string) {
+	e := events.Event{
+		Type:    t,
+		Pair:    dbOrder.Pair,
+		CycleID: cycleID,
+		OrderID: dbOrder.ExternalID,
+		Message: message,
+		Data: map[string]any{
+			"side":   string(dbOrder.Side),
+			"amount": dbOrder.Amount,
+			"filled": filled,
+			"price":  price,
+		},
+	}
+	if dbOrder.StrategyID != nil {
+		e.StrategyID = *dbOrder.StrategyID
+	}
+	b.events.Publish(e)
+}
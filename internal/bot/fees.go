@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+
+	"bot/internal/core/database"
+	"bot/internal/logger"
+)
+
+// feesInQuote additionne les frais des trades d'un ordre, convertis en devise de
+// cotation :
+//   - frais en quote : pris tels quels ;
+//   - frais en base : valorisés au prix du trade ;
+//   - frais dans un jeton tiers (ex. MX sur MEXC) : valorisés au ticker TOKEN/QUOTE
+//     obtenu via priceOf (appelé une seule fois par jeton).
+//
+// Un trade sans devise de frais est supposé facturé en quote. Une conversion
+// impossible (ticker indisponible) est remontée en erreur : mieux vaut ne rien
+// enregistrer que sous-estimer les frais.
+func feesInQuote(trades []Trade, base, quote string, priceOf func(asset string) (float64, error)) (float64, error) {
+	total := 0.0
+	rates := make(map[string]float64)
+
+	for _, trade := range trades {
+		if trade.Fee == nil || *trade.Fee == 0 {
+			continue
+		}
+		fee := *trade.Fee
+
+		token := quote
+		if trade.FeeToken != nil && *trade.FeeToken != "" {
+			token = strings.ToUpper(*trade.FeeToken)
+		}
+
+		switch token {
+		case quote:
+			total += fee
+		case base:
+			if trade.Price == nil {
+				return 0, fmt.Errorf("fee in %s without trade price", base)
+			}
+			total += fee * *trade.Price
+		default:
+			rate, ok := rates[token]
+			if !ok {
+				var err error
+				rate, err = priceOf(token)
+				if err != nil {
+					return 0, fmt.Errorf("failed to price fee token %s in %s: %w", token, quote, err)
+				}
+				rates[token] = rate
+			}
+			total += fee * rate
+		}
+	}
+
+	return total, nil
+}
+
+// recordOrderFees récupère les trades d'un ordre exécuté (totalement ou en partie)
+// et enregistre le total des frais, converti en quote, dans orders.fees. Les profits
+// (cycle, statistiques, dashboard, Telegram) sont ainsi nets de frais.
+func (b *Bot) recordOrderFees(dbOrder database.Order) {
+	trades, err := b.exchange.FetchTradesForOrder(dbOrder.ExternalID, dbOrder.Pair)
+	if err != nil {
+		logger.Errorf("[%s] Failed to fetch trades for order %s, fees not recorded: %v", b.Config.ExchangeName, dbOrder.ExternalID, err)
+		return
+	}
+
+	m := b.marketFor(dbOrder.Pair)
+	base, quote := m.BaseAsset, m.QuoteAsset
+	fees, err := feesInQuote(trades, base, quote, func(asset string) (float64, error) {
+		return b.exchange.GetPrice(asset + "/" + quote)
+	})
+	if err != nil {
+		logger.Errorf("[%s] Failed to compute fees for order %s: %v", b.Config.ExchangeName, dbOrder.ExternalID, err)
+		return
+	}
+
+	if err := b.db.UpdateOrderFees(dbOrder.ExternalID, fees); err != nil {
+		logger.Errorf("Failed to update order fees in database: %v", err)
+		return
+	}
+
+	logger.Debugf("[%s] Order %s: %d trade(s), fees %.6f %s", b.Config.ExchangeName, dbOrder.ExternalID, len(trades), fees, quote)
+}
@@ -0,0 +1,47 @@
+package bot
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// feesInQuote convertit chaque frais dans la devise de cotation selon le jeton
+// facturé, et n'interroge le ticker d'un jeton tiers qu'une fois.
+func TestFeesInQuote(t *testing.T) {
+	trades := []Trade{
+		{Price: ptr(50000.0), Fee: ptr(0.05), FeeToken: ptr("USDC")},    // quote
+		{Price: ptr(50000.0), Fee: ptr(0.000001), FeeToken: ptr("btc")}, // base, valorisé au prix du trade
+		{Price: ptr(50000.0), Fee: ptr(2.0), FeeToken: ptr("MX")},       // jeton tiers
+		{Price: ptr(50000.0), Fee: ptr(1.0), FeeToken: ptr("MX")},
+		{Price: ptr(50000.0), Fee: ptr(0.01)}, // devise inconnue : supposée quote
+		{Price: ptr(50000.0)},                 // pas de frais
+	}
+
+	calls := 0
+	priceOf := func(asset string) (float64, error) {
+		calls++
+		if asset != "MX" {
+			t.Fatalf("ticker demandé pour %s, attendu MX", asset)
+		}
+		return 3.0, nil
+	}
+
+	got, err := feesInQuote(trades, "BTC", "USDC", priceOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0.05 + 0.000001*50000 + 3*3.0 + 0.01
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("frais = %.6f, attendu %.6f", got, want)
+	}
+	if calls != 1 {
+		t.Errorf("%d appels au ticker, attendu 1 (cache par jeton)", calls)
+	}
+
+	// Ticker indisponible : erreur plutôt que des frais sous-estimés.
+	_, err = feesInQuote(trades, "BTC", "USDC", func(string) (float64, error) { return 0, errors.New("no ticker") })
+	if err == nil {
+		t.Error("attendu une erreur quand le jeton tiers ne peut être valorisé")
+	}
+}
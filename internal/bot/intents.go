@@ -0,0 +1,82 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/logger"
+)
+
+// ===============================
+// RÉSOLUTION DES INTENTIONS D'ORDRE
+// ===============================
+//
+// Le scheduler enregistre chaque ordre comme intention (outbox) avant de l'envoyer à
+// l'exchange avec un identifiant client. Une intention restée en attente signifie que
+// le bot ignore si l'ordre est parti : crash entre la pose et l'enregistrement, erreur
+// réseau, écriture en base en échec. Elle est résolue en cherchant l'ordre par son
+// identifiant client : trouvé, il est enregistré avec son cycle ; introuvable,
+// l'intention est abandonnée.
+
+// intentResolveDelay : âge minimum d'une intention résolue bot en marche, pour ne pas
+// interférer avec une pose en cours.
+const intentResolveDelay = time.Minute
+
+// intentGiveUpAfter : au-delà, une intention que l'exchange ne permet pas de retrouver
+// est abandonnée, pour ne pas bloquer indéfiniment les ordres de sa stratégie ; un
+// ordre éventuellement posé est alors rattrapé par la réconciliation.
+const intentGiveUpAfter = time.Hour
+
+// resolveOrderIntents résout les intentions en attente créées avant la date donnée.
+func (b *Bot) resolveOrderIntents(before time.Time) {
+	intents, err := b.db.GetPendingOrderIntents(before)
+	if err != nil {
+		logger.Errorf("Failed to get pending order intents: %v", err)
+		return
+	}
+	for _, intent := range intents {
+		if err := b.resolveOrderIntent(intent); err != nil {
+			logger.Warnf("[%s] Intention d'ordre %s non résolue : %v", b.Config.ExchangeName, intent.ClientOrderID, err)
+		}
+	}
+}
+
+func (b *Bot) resolveOrderIntent(intent database.OrderIntent) error {
+	side := strings.ToLower(string(intent.Side))
+	order, err := b.exchange.FetchOrderByClientID(intent.ClientOrderID, intent.Pair)
+	if isOrderNotFound(err) {
+		logger.Infof("[%s] Intention %s (%s %s) : aucun ordre sur l'exchange, abandonnée",
+			b.Config.ExchangeName, intent.ClientOrderID, side, intent.Pair)
+		return b.db.FailOrderIntent(intent.ClientOrderID, "ordre introuvable sur l'exchange")
+	}
+	if err != nil {
+		if time.Since(intent.CreatedAt) > intentGiveUpAfter {
+			logger.Warnf("[%s] Intention %s (%s %s) non résolue depuis %s, abandonnée : lancer une réconciliation pour vérifier l'exchange",
+				b.Config.ExchangeName, intent.ClientOrderID, side, intent.Pair, intentGiveUpAfter)
+			return b.db.FailOrderIntent(intent.ClientOrderID, fmt.Sprintf("non résolue : %v", err))
+		}
+		return err
+	}
+	if order.Id == nil {
+		return fmt.Errorf("ordre retrouvé sans identifiant")
+	}
+
+	amount, price := intent.Amount, intent.Price
+	if order.Amount != nil {
+		amount = *order.Amount
+	}
+	if order.Price != nil {
+		price = *order.Price
+	}
+	// Enregistré en attente : handleOrderCheck applique ensuite son état réel
+	// (rempli, annulé) comme pour tout ordre.
+	dbOrder, cycle, err := b.db.RecordOrderIntent(intent.ClientOrderID, *order.Id, amount, price)
+	if err != nil {
+		return err
+	}
+	logger.Infof("[%s] Intention %s : %s %s retrouvé sur l'exchange (ordre %s) et enregistré dans le cycle %d",
+		b.Config.ExchangeName, intent.ClientOrderID, side, intent.Pair, dbOrder.ExternalID, cycle.ID)
+	return nil
+}
@@ -0,0 +1,53 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"bot/internal/core/database"
+)
+
+// Au démarrage, une intention dont l'ordre est retrouvé sur l'exchange par son
+// identifiant client est enregistrée avec son cycle ; une intention sans ordre est
+// abandonnée.
+func TestResolveOrderIntents(t *testing.T) {
+	ex := &fakeExchange{orders: map[string]Order{}}
+	b, db := newReconcileBot(t, ex)
+
+	placed, err := db.CreateOrderIntent(database.OrderIntent{StrategyID: 1, Pair: "BTC/USDC", Side: database.Buy, Amount: 0.01, Price: 95, TargetPrice: 97})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost, err := db.CreateOrderIntent(database.OrderIntent{StrategyID: 1, Pair: "BTC/USDC", Side: database.Buy, Amount: 0.01, Price: 94, TargetPrice: 96})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ex.orders["ex-42"] = Order{Id: ptr("ex-42"), ClientOrderId: ptr(placed.ClientOrderID), Side: ptr("buy"),
+		Amount: ptr(0.01), Price: ptr(95.0), Status: ptr("open")}
+
+	// Intentions trop récentes : une pose est peut-être en cours
+	b.resolveOrderIntents(time.Now().Add(-intentResolveDelay))
+	if n, _ := db.CountPendingOrderIntents(1); n != 2 {
+		t.Fatalf("intentions en attente = %d, attendu 2", n)
+	}
+
+	b.resolveOrderIntents(time.Now().Add(time.Minute))
+
+	order, err := db.GetOrderByExternalID("ex-42")
+	if err != nil || order.Status != database.Pending || order.Price != 95 {
+		t.Fatalf("ordre retrouvé : %+v, %v", order, err)
+	}
+	cycle, err := db.GetCycleForBuyOrder(order.ID)
+	if err != nil || cycle.TargetPrice != 97 {
+		t.Fatalf("cycle : %+v, %v", cycle, err)
+	}
+	if got, _ := db.GetOrderIntent(placed.ClientOrderID); got.Status != database.IntentPlaced || got.ExternalID != "ex-42" {
+		t.Errorf("intention posée = %+v", got)
+	}
+	if got, _ := db.GetOrderIntent(lost.ClientOrderID); got.Status != database.IntentFailed {
+		t.Errorf("intention sans ordre = %+v", got)
+	}
+	if n, _ := db.CountPendingOrderIntents(1); n != 0 {
+		t.Errorf("intentions en attente = %d, attendu 0", n)
+	}
+}
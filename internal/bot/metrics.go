@@ -0,0 +1,119 @@
+package bot
+
+import (
+	"bot/internal/logger"
+	"bot/internal/metrics"
+	"strconv"
+	"time"
+)
+
+// Métriques du démon, publiées sur /metrics par l'API du bot. Les valeurs tirées de
+// la base (cycles, profit, ordres en attente) sont recalculées à chaque scrape par
+// collectMetrics ; le prix et la durée des ticks sont relevés par la boucle principale.
+var (
+	priceGauge = metrics.Default.NewGauge("simplebot_price",
+		"Dernier prix relevé, par paire.", "pair")
+	tickDuration = metrics.Default.NewHistogram("simplebot_tick_duration_seconds",
+		"Durée d'un tick de la boucle principale (prix, achats périodiques, ordres, statistiques).", metrics.DefaultBuckets)
+	lastTickGauge = metrics.Default.NewGauge("simplebot_last_tick_timestamp_seconds",
+		"Horodatage de fin du dernier tick.")
+	startTimeGauge = metrics.Default.NewGauge("simplebot_start_time_seconds",
+		"Horodatage de démarrage du bot.")
+	pausedGauge = metrics.Default.NewGauge("simplebot_paused",
+		"1 si le bot est en pause (achats et ventes suspendus).")
+	circuitTrippedGauge = metrics.Default.NewGauge("simplebot_circuit_tripped",
+		"1 si le disjoncteur est déclenché (achats suspendus jusqu'au réarmement).")
+	strategyEnabledGauge = metrics.Default.NewGauge("simplebot_strategy_enabled",
+		"1 si la stratégie est activée.", "strategy_id", "strategy", "pair")
+	cyclesGauge = metrics.Default.NewGauge("simplebot_cycles",
+		"Cycles par stratégie et statut (new, open, running, completed).", "strategy_id", "strategy", "pair", "status")
+	realizedProfitGauge = metrics.Default.NewGauge("simplebot_realized_profit",
+		"Profit réalisé cumulé des cycles terminés (quote, net de frais).", "strategy_id", "strategy", "pair")
+	pendingOrdersGauge = metrics.Default.NewGauge("simplebot_pending_orders",
+		"Ordres en attente, par stratégie et sens.", "strategy_id", "side")
+	pendingOrderAgeGauge = metrics.Default.NewGauge("simplebot_pending_order_oldest_age_seconds",
+		"Âge du plus vieil ordre en attente, par stratégie et sens.", "strategy_id", "side")
+)
+
+func init() {
+	metrics.Default.NewCounterFunc("simplebot_log_errors_total",
+		"Erreurs journalisées depuis le démarrage (logger.LastError).", func() float64 {
+			_, _, count := logger.LastError()
+			return float64(count)
+		})
+	metrics.Default.NewGaugeFunc("simplebot_last_error_timestamp_seconds",
+		"Horodatage de la dernière erreur journalisée (0 = aucune).", func() float64 {
+			_, at, count := logger.LastError()
+			if count == 0 {
+				return 0
+			}
+			return float64(at.Unix())
+		})
+}
+
+// observeTick enregistre la durée d'un tick de run().
+func observeTick(start time.Time) {
+	end := time.Now()
+	tickDuration.Observe(end.Sub(start).Seconds())
+	lastTickGauge.Set(float64(end.Unix()))
+}
+
+// collectMetrics recalcule, au moment du scrape, les jauges dérivées de la base.
+func (b *Bot) collectMetrics() {
+	startTimeGauge.Set(float64(b.startedAt.Unix()))
+	paused := 0.0
+	if b.paused.Load() {
+		paused = 1
+	}
+	pausedGauge.Set(paused)
+	tripped := 0.0
+	if b.halt.Load() != nil {
+		tripped = 1
+	}
+	circuitTrippedGauge.Set(tripped)
+
+	strategies, err := b.db.GetStrategyMetrics()
+	if err != nil {
+		logger.Warnf("[%s] Métriques : lecture des stratégies impossible : %v", b.Config.ExchangeName, err)
+		return
+	}
+	strategyEnabledGauge.Reset()
+	cyclesGauge.Reset()
+	realizedProfitGauge.Reset()
+	for _, s := range strategies {
+		id := strconv.Itoa(s.StrategyID)
+		enabled := 0.0
+		if s.Enabled {
+			enabled = 1
+		}
+		strategyEnabledGauge.Set(enabled, id, s.Name, s.Pair)
+		cyclesGauge.Set(float64(s.NewCycles), id, s.Name, s.Pair, "new")
+		cyclesGauge.Set(float64(s.OpenCycles), id, s.Name, s.Pair, "open")
+		cyclesGauge.Set(float64(s.RunningCycles), id, s.Name, s.Pair, "running")
+		cyclesGauge.Set(float64(s.CompletedCycles), id, s.Name, s.Pair, "completed")
+		realizedProfitGauge.Set(s.RealizedProfit, id, s.Name, s.Pair)
+	}
+
+	orders, err := b.db.GetPendingOrders()
+	if err != nil {
+		logger.Warnf("[%s] Métriques : lecture des ordres en attente impossible : %v", b.Config.ExchangeName, err)
+		return
+	}
+	type key struct{ strategy, side string }
+	counts := make(map[key]int)
+	oldest := make(map[key]time.Duration)
+	for _, o := range orders {
+		k := key{"", string(o.Side)}
+		if o.StrategyID != nil {
+			k.strategy = strconv.Itoa(*o.StrategyID)
+		}
+		counts[k]++
+		oldest[k] = max(oldest[k], time.Since(o.CreatedAt))
+	}
+	pendingOrdersGauge.Reset()
+	pendingOrderAgeGauge.Reset()
+	for k, n := range counts {
+		pendingOrdersGauge.Set(float64(n), k.strategy, k.side)
+		pendingOrderAgeGauge.Set(oldest[k].Seconds(), k.strategy, k.side)
+	}
+}
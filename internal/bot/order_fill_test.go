@@ -0,0 +1,40 @@
+package bot
+
+import (
+	"testing"
+
+	"bot/internal/core/database"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// orderFill privilégie les informations de l'exchange (filled/average) et ne
+// suppose un remplissage total au prix limite que pour un ordre "closed".
+func TestOrderFill(t *testing.T) {
+	dbOrder := database.Order{Amount: 2, Price: 100}
+
+	cases := []struct {
+		name       string
+		order      Order
+		wantFilled float64
+		wantPrice  float64
+	}{
+		{"annulé après remplissage partiel",
+			Order{Status: ptr("canceled"), Price: ptr(100.0), Filled: ptr(0.5), Average: ptr(99.5)}, 0.5, 99.5},
+		{"annulé sans remplissage",
+			Order{Status: ptr("canceled"), Price: ptr(100.0), Filled: ptr(0.0)}, 0, 100},
+		{"closed sans détail de remplissage",
+			Order{Status: ptr("closed"), Price: ptr(100.0), Amount: ptr(2.0)}, 2, 100},
+		{"ouvert, partiellement rempli",
+			Order{Status: ptr("open"), Price: ptr(100.0), Filled: ptr(1.2), Average: ptr(99.9)}, 1.2, 99.9},
+		{"annulé sans information",
+			Order{Status: ptr("canceled")}, 0, 100},
+	}
+
+	for _, tc := range cases {
+		filled, price := orderFill(dbOrder, tc.order)
+		if filled != tc.wantFilled || price != tc.wantPrice {
+			t.Errorf("%s : orderFill = %v @ %v, attendu %v @ %v", tc.name, filled, price, tc.wantFilled, tc.wantPrice)
+		}
+	}
+}
@@ -6,9 +6,9 @@ import (
 	"time"
 
 	"bot/internal/core/database"
+	"bot/internal/events"
 	"bot/internal/logger"
 	"bot/internal/market"
-	"bot/internal/telegram"
 )
 
 // Le moniteur de retournement surveille les BOUGIES 1h et notifie sur Telegram quand
@@ -85,6 +85,7 @@ func evaluateReversalSignal(candles []database.Candle, now time.Time) (p market.
 
 // checkReversalSignal évalue la dernière bougie 1h clôturée et envoie une notif Telegram
 // si un pattern de creux s'y forme. Dédup par bougie : un signal donné n'est notifié qu'une fois.
+// Le moniteur surveille la paire par défaut de l'instance (TRADING_PAIR).
 func (b *Bot) checkReversalSignal() {
 	pair := b.Config.Pair
 
@@ -143,12 +144,19 @@ func (b *Bot) checkReversalSignal() {
 		"🔔 [%s] Signal de retournement haussier — %s (1h)\n%s%s\nClôture : %s %s\nCreux possible. /status ou bouton 🛒 Acheter pour agir.",
 		b.Config.ExchangeName, pair,
 		header, detailLine,
-		b.market.FormatPrice(closed.ClosePrice), b.market.QuoteAsset,
+		b.marketFor(pair).FormatPrice(closed.ClosePrice), b.marketFor(pair).QuoteAsset,
 	)
-	if err := telegram.SendMessage(msg); err != nil {
-		logger.Errorf("[%s] Échec notif pattern Telegram : %v", b.Config.ExchangeName, err)
-		return
-	}
+	b.events.Publish(events.Event{
+		Type:    events.ReversalSignal,
+		Pair:    pair,
+		Message: msg,
+		Data: map[string]any{
+			"pattern":       string(notifiablePatterns[p]),
+			"close":         closed.ClosePrice,
+			"rsi_oversold":  rsiOversold,
+			"vol_confirmed": volConfirmed,
+		},
+	})
 	logger.Infof("[%s] Signal de retournement notifié (%s) sur bougie 1h %s", b.Config.ExchangeName,
 		notifiablePatterns[p], time.UnixMilli(closedTs).UTC().Format("2006-01-02 15:04"))
 }
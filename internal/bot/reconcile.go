@@ -0,0 +1,478 @@
+package bot
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/events"
+	"bot/internal/logger"
+)
+
+// ===============================
+// RÉCONCILIATION DB / EXCHANGE
+// ===============================
+//
+// Après un crash ou une intervention manuelle sur l'exchange, la table orders peut
+// diverger de la réalité : ordres annulés à la main, ventes posées manuellement,
+// achats jamais enregistrés (CreateOrder a échoué après un PlaceLimitBuyOrder réussi).
+// Reconcile compare les ordres ouverts et les trades récents de l'exchange avec les
+// ordres en attente et les cycles de la base, puis rapporte ou répare les écarts.
+
+// Types d'action de réconciliation
+const (
+	ReconcileAdopt = "adopt" // ordre inconnu de la base, adopté (nouveau cycle)
+	ReconcileLink  = "link"  // vente inconnue rattachée à un cycle ouvert
+	ReconcileClose = "close" // ordre « fantôme » : en attente en base, terminé sur l'exchange
+	ReconcileFlag  = "flag"  // écart signalé, non réparable automatiquement
+)
+
+// ReconcileOptions paramètre une réconciliation.
+type ReconcileOptions struct {
+	// DryRun : rapport seul, aucune écriture en base.
+	DryRun bool
+	// Pair : paire réconciliée (vide = paire par défaut de l'instance pour Reconcile,
+	// toutes les paires tradées pour ReconcileAll).
+	Pair string
+	// Lookback : fenêtre des trades récents examinés (défaut 7 jours).
+	Lookback time.Duration
+	// StrategyID : stratégie à laquelle rattacher les ordres adoptés
+	// (0 = première stratégie activée de la paire).
+	StrategyID int
+	// BalanceTolerancePct : écart relatif toléré (%) entre le solde de base attendu
+	// et le solde réel avant de signaler une anomalie (défaut 1 %, les frais
+	// prélevés sur l'actif de base créent un léger déficit normal).
+	BalanceTolerancePct float64
+}
+
+// ReconcileAction décrit un écart détecté et, hors dry-run, sa réparation.
+type ReconcileAction struct {
+	Kind    string
+	OrderID string
+	Detail  string
+	Applied bool
+}
+
+// ReconcileReport est le résultat d'une réconciliation.
+type ReconcileReport struct {
+	Pair            string
+	DryRun          bool
+	Actions         []ReconcileAction
+	ExpectedBase    float64 // quantité de base détenue selon les cycles ouverts
+	ActualBase      float64 // solde total de base sur l'exchange
+	BalanceMismatch bool
+}
+
+// String formate le rapport pour les logs, la CLI et Telegram.
+func (r *ReconcileReport) String() string {
+	var sb strings.Builder
+	mode := "réparation"
+	if r.DryRun {
+		mode = "dry-run"
+	}
+	fmt.Fprintf(&sb, "Réconciliation %s (%s) : %d écart(s)", r.Pair, mode, len(r.Actions))
+	for _, a := range r.Actions {
+		status := ""
+		if a.Applied {
+			status = " ✓"
+		}
+		fmt.Fprintf(&sb, "\n  [%s] %s : %s%s", a.Kind, a.OrderID, a.Detail, status)
+	}
+	fmt.Fprintf(&sb, "\n  Solde de base : attendu %.8f, exchange %.8f", r.ExpectedBase, r.ActualBase)
+	if r.BalanceMismatch {
+		sb.WriteString(" ⚠️ écart hors tolérance")
+	}
+	return sb.String()
+}
+
+// HasIssues indique si la réconciliation a relevé au moins un écart.
+func (r *ReconcileReport) HasIssues() bool {
+	return len(r.Actions) > 0 || r.BalanceMismatch
+}
+
+func (r *ReconcileReport) add(kind, orderId, detail string, applied bool) {
+	r.Actions = append(r.Actions, ReconcileAction{Kind: kind, OrderID: orderId, Detail: detail, Applied: applied})
+}
+
+// isOrderNotFound reconnaît l'erreur « ordre inexistant » de l'exchange
+// (type ccxt OrderNotFound, conservé dans le message par cleanCCXTError).
+func isOrderNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "OrderNotFound")
+}
+
+// ReconcileAll réconcilie la paire demandée, ou chaque paire tradée si opts.Pair est vide.
+func (b *Bot) ReconcileAll(opts ReconcileOptions) ([]*ReconcileReport, error) {
+	pairs := []string{opts.Pair}
+	if opts.Pair == "" {
+		pairs = b.tradedPairs()
+	}
+
+	var reports []*ReconcileReport
+	for _, pair := range pairs {
+		opts.Pair = pair
+		report, err := b.Reconcile(opts)
+		if err != nil {
+			return reports, fmt.Errorf("%s: %w", pair, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Reconcile compare l'état de l'exchange et celui de la base pour une paire, et répare
+// les écarts sauf en mode dry-run. Ne doit pas tourner en parallèle de la boucle run() :
+// elle est appelée au démarrage (avant run) ou depuis la CLI, bot arrêté.
+func (b *Bot) Reconcile(opts ReconcileOptions) (*ReconcileReport, error) {
+	if opts.Lookback <= 0 {
+		opts.Lookback = 7 * 24 * time.Hour
+	}
+	if opts.BalanceTolerancePct <= 0 {
+		opts.BalanceTolerancePct = 1
+	}
+	pair := opts.Pair
+	if pair == "" {
+		pair = b.Config.Pair
+	}
+	m := b.marketFor(pair)
+	report := &ReconcileReport{Pair: pair, DryRun: opts.DryRun}
+
+	known, err := b.db.GetOrders("all", pair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get orders: %w", err)
+	}
+	knownIds := make(map[string]database.Order, len(known))
+	for _, o := range known {
+		knownIds[o.ExternalID] = o
+	}
+
+	openOrders, err := b.exchange.FetchOpenOrders(pair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
+	}
+	openIds := make(map[string]Order, len(openOrders))
+	for _, o := range openOrders {
+		if o.Id != nil {
+			openIds[*o.Id] = o
+		}
+	}
+
+	// 1. Ordres fantômes : en attente en base mais plus ouverts sur l'exchange.
+	for _, dbOrder := range known {
+		if dbOrder.Status != database.Pending {
+			continue
+		}
+		if _, open := openIds[dbOrder.ExternalID]; open {
+			continue
+		}
+		b.reconcileGhostOrder(report, dbOrder, opts.DryRun)
+	}
+
+	strategy, strategyErr := b.adoptionStrategy(opts.StrategyID, pair)
+
+	// 2. Trades récents d'ordres inconnus et déjà terminés (achat exécuté jamais enregistré).
+	since := time.Now().Add(-opts.Lookback).UnixMilli()
+	trades, err := b.exchange.FetchMyTrades(pair, &since, nil, 1000)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch recent trades: %w", err)
+	}
+	byOrder := make(map[string][]Trade)
+	var orderIds []string
+	for _, t := range trades {
+		if t.OrderId == nil {
+			continue
+		}
+		id := *t.OrderId
+		if _, ok := knownIds[id]; ok {
+			continue
+		}
+		if _, ok := openIds[id]; ok {
+			continue // ordre encore ouvert : traité à l'étape 3
+		}
+		if _, seen := byOrder[id]; !seen {
+			orderIds = append(orderIds, id)
+		}
+		byOrder[id] = append(byOrder[id], t)
+	}
+	sort.Strings(orderIds)
+	for _, id := range orderIds {
+		if strategyErr != nil {
+			report.add(ReconcileFlag, id, fmt.Sprintf("trades d'un ordre inconnu, non adoptés : %v", strategyErr), false)
+			continue
+		}
+		b.reconcileUnknownFilledOrder(report, m, id, byOrder[id], strategy, opts.DryRun)
+	}
+
+	// 3. Ordres ouverts inconnus de la base (après l'étape 2 : une vente manuelle peut
+	// ainsi être rattachée au cycle d'un achat tout juste adopté).
+	for _, order := range openOrders {
+		if order.Id == nil {
+			continue
+		}
+		if _, ok := knownIds[*order.Id]; ok {
+			continue
+		}
+		if strategyErr != nil {
+			report.add(ReconcileFlag, *order.Id, fmt.Sprintf("ordre ouvert inconnu, non adopté : %v", strategyErr), false)
+			continue
+		}
+		b.reconcileUnknownOpenOrder(report, m, order, strategy, opts.DryRun)
+	}
+
+	// 4. Solde de base : ce que les cycles disent détenir vs ce que l'exchange détient.
+	if err := b.reconcileBalance(report, m, opts.BalanceTolerancePct); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// reconcileGhostOrder resynchronise un ordre en attente en base qui n'est plus ouvert
+// sur l'exchange : rempli ou annulé entre-temps (processOrder applique le traitement
+// habituel), ou inexistant (marqué annulé).
+func (b *Bot) reconcileGhostOrder(report *ReconcileReport, dbOrder database.Order, dryRun bool) {
+	order, err := b.exchange.FetchOrder(dbOrder.ExternalID, dbOrder.Pair)
+	if err != nil {
+		if !isOrderNotFound(err) {
+			report.add(ReconcileFlag, dbOrder.ExternalID, fmt.Sprintf("ordre en attente non vérifiable : %v", err), false)
+			return
+		}
+		applied := false
+		if !dryRun {
+			if err := b.db.UpdateOrderStatus(dbOrder.ExternalID, database.Cancelled); err != nil {
+				logger.Errorf("Failed to update order status in database: %v", err)
+			} else {
+				applied = true
+			}
+		}
+		report.add(ReconcileClose, dbOrder.ExternalID, "introuvable sur l'exchange, marqué annulé", applied)
+		return
+	}
+
+	if order.Status == nil || *order.Status == "open" {
+		return // ouvert malgré tout (liste des ordres ouverts incomplète) : rien à faire
+	}
+
+	filled, price := orderFill(dbOrder, order)
+	m := b.marketFor(dbOrder.Pair)
+	detail := fmt.Sprintf("%s %s sur l'exchange (rempli %s @ %s)",
+		strings.ToLower(string(dbOrder.Side)), *order.Status, m.FormatAmount(filled), m.FormatPrice(price))
+	if !dryRun {
+		b.processOrder(dbOrder)
+	}
+	report.add(ReconcileClose, dbOrder.ExternalID, detail, !dryRun)
+}
+
+// reconcileUnknownOpenOrder adopte un ordre ouvert absent de la base : un achat donne
+// un nouveau cycle, une vente est rattachée au cycle ouvert de même quantité.
+func (b *Bot) reconcileUnknownOpenOrder(report *ReconcileReport, m *Market, order Order, strategy *database.Strategy, dryRun bool) {
+	id := *order.Id
+	if order.Side == nil || order.Amount == nil || order.Price == nil {
+		report.add(ReconcileFlag, id, "ordre ouvert inconnu, informations incomplètes", false)
+		return
+	}
+	amount, price := *order.Amount, *order.Price
+
+	switch strings.ToLower(*order.Side) {
+	case "buy":
+		detail := fmt.Sprintf("achat ouvert inconnu %s @ %s adopté dans la stratégie %s",
+			m.FormatAmount(amount), m.FormatPrice(price), strategy.Name)
+		applied := false
+		if !dryRun {
+			applied = b.adoptBuyOrder(id, amount, price, strategy) == nil
+		}
+		report.add(ReconcileAdopt, id, detail, applied)
+
+	case "sell":
+		cycle := b.findCycleForSell(amount, m)
+		if cycle == nil {
+			report.add(ReconcileFlag, id, fmt.Sprintf("vente ouverte inconnue %s @ %s sans cycle ouvert correspondant",
+				m.FormatAmount(amount), m.FormatPrice(price)), false)
+			return
+		}
+		detail := fmt.Sprintf("vente ouverte inconnue %s @ %s rattachée au cycle %d",
+			m.FormatAmount(amount), m.FormatPrice(price), cycle.ID)
+		applied := false
+		if !dryRun {
+			applied = b.linkSellOrder(id, amount, price, cycle) == nil
+		}
+		report.add(ReconcileLink, id, detail, applied)
+	}
+}
+
+// reconcileUnknownFilledOrder traite les trades récents d'un ordre absent de la base
+// et déjà terminé. Un achat exécuté est adopté (cycle Open) ; une vente manuelle ne
+// peut pas être attribuée de façon sûre à un cycle et est seulement signalée.
+func (b *Bot) reconcileUnknownFilledOrder(report *ReconcileReport, m *Market, id string, trades []Trade, strategy *database.Strategy, dryRun bool) {
+	var amount, cost float64
+	side := ""
+	for _, t := range trades {
+		if t.Amount == nil || t.Price == nil {
+			continue
+		}
+		amount += *t.Amount
+		cost += *t.Amount * *t.Price
+		if t.Side != nil {
+			side = strings.ToLower(*t.Side)
+		}
+	}
+	if amount <= 0 {
+		return
+	}
+	price := cost / amount
+
+	if side != "buy" {
+		report.add(ReconcileFlag, id, fmt.Sprintf("%s exécuté inconnu de la base : %s @ %s (intervention manuelle ?)",
+			side, m.FormatAmount(amount), m.FormatPrice(price)), false)
+		return
+	}
+
+	detail := fmt.Sprintf("achat exécuté inconnu %s @ %s adopté dans la stratégie %s (cycle ouvert)",
+		m.FormatAmount(amount), m.FormatPrice(price), strategy.Name)
+	if dryRun {
+		report.add(ReconcileAdopt, id, detail, false)
+		return
+	}
+
+	fees, err := feesInQuote(trades, m.BaseAsset, m.QuoteAsset, func(asset string) (float64, error) {
+		return b.exchange.GetPrice(asset + "/" + m.QuoteAsset)
+	})
+	if err != nil {
+		logger.Warnf("[%s] Réconciliation : frais de l'ordre %s non valorisés : %v", b.Config.ExchangeName, id, err)
+	}
+
+	applied := b.adoptBuyOrder(id, amount, price, strategy) == nil &&
+		b.db.UpdateOrderFill(id, amount, price) == nil &&
+		b.db.UpdateOrderFees(id, fees) == nil &&
+		b.db.UpdateOrderStatus(id, database.Filled) == nil
+	report.add(ReconcileAdopt, id, detail, applied)
+}
+
+// reconcileBalance compare la quantité de base détenue selon les cycles de la paire
+// (achat exécuté, vente non terminée) au solde total de l'exchange.
+func (b *Bot) reconcileBalance(report *ReconcileReport, m *Market, tolerancePct float64) error {
+	cycles, err := b.db.GetCycles("active", report.Pair)
+	if err != nil {
+		return fmt.Errorf("failed to get active cycles: %w", err)
+	}
+	for _, c := range cycles {
+		report.ExpectedBase += c.HeldAmount()
+	}
+
+	balances, err := b.exchange.FetchBalance()
+	if err != nil {
+		return fmt.Errorf("failed to fetch balance: %w", err)
+	}
+	report.ActualBase = balances[m.BaseAsset].Total
+
+	tolerance := math.Max(report.ExpectedBase*tolerancePct/100, m.Precision.Amount)
+	if math.Abs(report.ActualBase-report.ExpectedBase) > tolerance {
+		report.BalanceMismatch = true
+	}
+	return nil
+}
+
+// adoptionStrategy retourne la stratégie de rattachement des ordres adoptés sur la paire.
+func (b *Bot) adoptionStrategy(strategyID int, pair string) (*database.Strategy, error) {
+	if strategyID > 0 {
+		strategy, err := b.db.GetStrategy(strategyID)
+		if err != nil {
+			return nil, err
+		}
+		if strategy.Pair != pair {
+			return nil, fmt.Errorf("la stratégie %s trade %s, pas %s", strategy.Name, strategy.Pair, pair)
+		}
+		return strategy, nil
+	}
+	strategies, err := b.db.GetEnabledStrategies()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get enabled strategies: %w", err)
+	}
+	for i := range strategies {
+		if strategies[i].Pair == pair {
+			return &strategies[i], nil
+		}
+	}
+	return nil, fmt.Errorf("aucune stratégie activée sur %s pour rattacher l'ordre", pair)
+}
+
+// adoptBuyOrder enregistre un achat inconnu et crée son cycle, avec le prix cible
+// de la stratégie.
+func (b *Bot) adoptBuyOrder(id string, amount, price float64, strategy *database.Strategy) error {
+	dbOrder, err := b.db.CreateOrder(id, database.Buy, amount, price, 0.0, strategy.ID)
+	if err != nil {
+		logger.Errorf("[%s] Réconciliation : échec de l'adoption de l'achat %s : %v", b.Config.ExchangeName, id, err)
+		return err
+	}
+	targetPrice := price * (1.0 + strategy.ProfitTarget/100.0)
+	if _, err := b.db.CreateCycle(dbOrder.ID, targetPrice); err != nil {
+		logger.Errorf("[%s] Réconciliation : échec de la création du cycle pour %s : %v", b.Config.ExchangeName, id, err)
+		return err
+	}
+	return nil
+}
+
+// findCycleForSell cherche un cycle ouvert de la paire (achat exécuté, sans vente en
+// cours) dont la quantité détenue correspond à la quantité vendue, à la précision du
+// marché près.
+func (b *Bot) findCycleForSell(amount float64, m *Market) *database.CycleEnhanced {
+	cycles, err := b.db.GetOpenCycles()
+	if err != nil {
+		logger.Errorf("Failed to get open cycles: %v", err)
+		return nil
+	}
+	tolerance := math.Max(m.Precision.Amount, 1e-12)
+	for i := range cycles {
+		if cycles[i].Pair() != m.Symbol {
+			continue
+		}
+		if math.Abs(cycles[i].HeldAmount()-amount) <= tolerance {
+			return &cycles[i]
+		}
+	}
+	return nil
+}
+
+// linkSellOrder enregistre une vente inconnue et la rattache au cycle.
+func (b *Bot) linkSellOrder(id string, amount, price float64, cycle *database.CycleEnhanced) error {
+	dbOrder, err := b.db.CreateOrder(id, database.Sell, amount, price, 0.0, cycle.StrategyID)
+	if err != nil {
+		logger.Errorf("[%s] Réconciliation : échec de l'enregistrement de la vente %s : %v", b.Config.ExchangeName, id, err)
+		return err
+	}
+	if err := b.db.UpdateCycleSellOrder(cycle.ID, dbOrder.ID); err != nil {
+		logger.Errorf("[%s] Réconciliation : échec du rattachement de la vente %s au cycle %d : %v", b.Config.ExchangeName, id, cycle.ID, err)
+		return err
+	}
+	return nil
+}
+
+// reconcileAtStart exécute la réconciliation configurée par RECONCILE_AT_START
+// ("report" ou "apply") et notifie les écarts sur Telegram.
+func (b *Bot) reconcileAtStart() {
+	mode := b.Config.ReconcileAtStart
+	if mode != "report" && mode != "apply" {
+		return
+	}
+
+	reports, err := b.ReconcileAll(ReconcileOptions{DryRun: mode != "apply"})
+	if err != nil {
+		logger.Errorf("[%s] Réconciliation au démarrage impossible : %v", b.Config.ExchangeName, err)
+	}
+	for _, report := range reports {
+		if !report.HasIssues() {
+			logger.Infof("[%s] ✓ Réconciliation %s : base et exchange cohérents", b.Config.ExchangeName, report.Pair)
+			continue
+		}
+
+		logger.Warnf("[%s] %s", b.Config.ExchangeName, report.String())
+		b.events.Publish(events.Event{
+			Type:    events.ReconcileReport,
+			Pair:    report.Pair,
+			Message: fmt.Sprintf("🔎 %s\n%s", b.Config.ExchangeName, report.String()),
+			Data:    map[string]any{"dry_run": mode != "apply"},
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package bot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bot/internal/core/config"
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"bot/internal/risk"
+)
+
+// TestMain initialise le logger (utilisé par la base et le traitement des ordres).
+func TestMain(m *testing.M) {
+	if err := logger.InitLogger("error", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeExchange est un exchange en mémoire : ordres connus par id, ordres ouverts,
+// trades récents et soldes fixés par le test.
+type fakeExchange struct {
+	orders  map[string]Order
+	open    []Order
+	trades  []Trade
+	balance map[string]Balance
+}
+
+func (f *fakeExchange) GetMarket(pair string) Market              { return Market{Symbol: pair} }
+func (f *fakeExchange) GetMarketsList() []Market                  { return nil }
+func (f *fakeExchange) FetchBalance() (map[string]Balance, error) { return f.balance, nil }
+func (f *fakeExchange) PlaceLimitBuyOrder(string, float64, float64, string) (Order, error) {
+	return Order{}, fmt.Errorf("non supporté")
+}
+func (f *fakeExchange) PlaceLimitSellOrder(string, float64, float64, string) (Order, error) {
+	return Order{}, fmt.Errorf("non supporté")
+}
+func (f *fakeExchange) FetchOrder(id string, _ string) (Order, error) {
+	if o, ok := f.orders[id]; ok {
+		return o, nil
+	}
+	return Order{}, fmt.Errorf("[OrderNotFound] order %s does not exist", id)
+}
+func (f *fakeExchange) FetchOrderByClientID(clientOrderID string, _ string) (Order, error) {
+	for _, o := range f.orders {
+		if o.ClientOrderId != nil && *o.ClientOrderId == clientOrderID {
+			return o, nil
+		}
+	}
+	return Order{}, fmt.Errorf("[OrderNotFound] client order %s does not exist", clientOrderID)
+}
+func (f *fakeExchange) FetchOpenOrders(string) ([]Order, error)   { return f.open, nil }
+func (f *fakeExchange) CancelOrder(string, string) (Order, error) { return Order{}, nil }
+func (f *fakeExchange) GetPrice(string) (float64, error)          { return 100, nil }
+func (f *fakeExchange) FetchTicker(string) (Ticker, error) {
+	last := 100.0
+	return Ticker{Last: &last}, nil
+}
+func (f *fakeExchange) FetchCandles(string, string, *int64, int64) ([]Candle, error) {
+	return nil, nil
+}
+func (f *fakeExchange) FetchMyTrades(string, *int64, *int64, int64) ([]Trade, error) {
+	return f.trades, nil
+}
+func (f *fakeExchange) FetchTradesForOrder(id string, _ string) ([]Trade, error) {
+	var res []Trade
+	for _, t := range f.trades {
+		if *t.OrderId == id {
+			res = append(res, t)
+		}
+	}
+	return res, nil
+}
+
+func newReconcileBot(t *testing.T, ex *fakeExchange) (*Bot, *database.DB) {
+	t.Helper()
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("NewDB : %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := db.SetDefaultPair("BTC/USDC"); err != nil {
+		t.Fatalf("SetDefaultPair : %v", err)
+	}
+
+	m := Market{Symbol: "BTC/USDC", BaseAsset: "BTC", QuoteAsset: "USDC"}
+	m.Precision.Amount = 0.0001
+	m.Precision.AmountDecimals = 4
+	m.Precision.Price = 0.01
+	m.Precision.PriceDecimals = 2
+
+	b := &Bot{
+		Config:   config.BotConfig{ExchangeName: "fake", Pair: "BTC/USDC"},
+		db:       db,
+		exchange: ex,
+		markets:  map[string]*Market{"BTC/USDC": &m},
+		breaker:  risk.NewBreaker(risk.BreakerConfig{}),
+	}
+	return b, db
+}
+
+func trade(orderId, side string, amount, price float64) Trade {
+	return Trade{OrderId: ptr(orderId), Side: ptr(side), Amount: ptr(amount), Price: ptr(price), Fee: ptr(0.0)}
+}
+
+// Scénario : un ordre annulé à la main, un ordre disparu, un achat rempli jamais
+// enregistré, une vente manuelle posée pour ce même achat et un achat ouvert inconnu.
+// Le dry-run ne modifie rien ; la réparation aligne la base sur l'exchange.
+func TestReconcile_DryRunThenApply(t *testing.T) {
+	ex := &fakeExchange{
+		orders: map[string]Order{
+			"cancelled-by-hand": {Id: ptr("cancelled-by-hand"), Status: ptr("canceled"), Filled: ptr(0.0)},
+		},
+		open: []Order{
+			{Id: ptr("unknown-sell"), Side: ptr("sell"), Amount: ptr(0.02), Price: ptr(110.0), Status: ptr("open")},
+			{Id: ptr("unknown-buy"), Side: ptr("buy"), Amount: ptr(0.05), Price: ptr(90.0), Status: ptr("open")},
+		},
+		trades: []Trade{
+			trade("unsaved-buy", "buy", 0.01, 99),
+			trade("unsaved-buy", "buy", 0.01, 101),
+		},
+		balance: map[string]Balance{"BTC": {Total: 0.02}},
+	}
+	b, db := newReconcileBot(t, ex)
+
+	for _, id := range []string{"cancelled-by-hand", "vanished"} {
+		o, err := db.CreateOrder(id, database.Buy, 0.01, 95, 0, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.CreateCycle(o.ID, 97); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	opts := ReconcileOptions{DryRun: true, StrategyID: 1}
+	report, err := b.Reconcile(opts)
+	if err != nil {
+		t.Fatalf("Reconcile (dry-run) : %v", err)
+	}
+	// 2 fantômes + achat rempli inconnu + 2 ordres ouverts inconnus. En dry-run, la
+	// vente ne trouve pas encore de cycle (l'achat n'est pas adopté) : elle est signalée.
+	if len(report.Actions) != 5 {
+		t.Fatalf("dry-run : %d actions, attendu 5\n%s", len(report.Actions), report)
+	}
+	for _, a := range report.Actions {
+		if a.Applied {
+			t.Errorf("dry-run : action appliquée %+v", a)
+		}
+	}
+	if pending, _ := db.GetPendingOrders(); len(pending) != 2 {
+		t.Errorf("dry-run : %d ordres en attente, attendu 2 (inchangés)", len(pending))
+	}
+
+	opts.DryRun = false
+	report, err = b.Reconcile(opts)
+	if err != nil {
+		t.Fatalf("Reconcile : %v", err)
+	}
+	kinds := map[string]int{}
+	for _, a := range report.Actions {
+		if !a.Applied {
+			t.Errorf("action non appliquée : %+v", a)
+		}
+		kinds[a.Kind]++
+	}
+	if kinds[ReconcileClose] != 2 || kinds[ReconcileAdopt] != 2 || kinds[ReconcileLink] != 1 {
+		t.Errorf("actions = %v, attendu 2 close, 2 adopt, 1 link\n%s", kinds, report)
+	}
+
+	for _, id := range []string{"cancelled-by-hand", "vanished"} {
+		if o, _ := db.GetOrderByExternalID(id); o.Status != database.Cancelled {
+			t.Errorf("%s : statut %s, attendu CANCELLED", id, o.Status)
+		}
+	}
+
+	adopted, err := db.GetOrderByExternalID("unsaved-buy")
+	if err != nil {
+		t.Fatalf("achat rempli non adopté : %v", err)
+	}
+	if adopted.Status != database.Filled || adopted.ExecutedAmount() != 0.02 || adopted.ExecutedPrice() != 100 {
+		t.Errorf("achat adopté = %+v, attendu FILLED 0.02 @ 100", adopted)
+	}
+	cycle, err := db.GetCycleForBuyOrder(adopted.ID)
+	if err != nil || cycle.SellOrder == nil || cycle.SellOrder.ExternalID != "unknown-sell" {
+		t.Errorf("la vente manuelle doit être rattachée au cycle de l'achat adopté : %v / %+v", err, cycle)
+	}
+
+	if o, err := db.GetOrderByExternalID("unknown-buy"); err != nil || o.Status != database.Pending {
+		t.Errorf("achat ouvert non adopté : %v / %+v", err, o)
+	}
+
+	if report.BalanceMismatch || report.ExpectedBase != 0.02 {
+		t.Errorf("solde : attendu %.4f / réel %.4f, mismatch=%v", report.ExpectedBase, report.ActualBase, report.BalanceMismatch)
+	}
+
+	// Une seconde passe ne trouve plus rien à réparer.
+	report, err = b.Reconcile(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.HasIssues() {
+		t.Errorf("seconde passe : écarts restants\n%s", report)
+	}
+}
@@ -0,0 +1,334 @@
+package bot
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/logger"
+)
+
+// Streamer est implémenté par les exchanges capables de pousser prix, ordres et
+// exécutions en continu (WebSocket, cf. ccxt pro). Chaque appel bloque jusqu'à la
+// prochaine mise à jour de la paire ; une erreur signale une coupure, l'appel suivant
+// reconnecte.
+type Streamer interface {
+	WatchTicker(pair string) (Ticker, error)
+	WatchOrders(pair string) ([]Order, error)
+	WatchMyTrades(pair string) ([]Trade, error)
+}
+
+// Types de flux suivis pour chaque paire.
+const (
+	feedTicker = "ticker"
+	feedOrders = "orders"
+	feedTrades = "trades"
+)
+
+// Délais de reconnexion d'un flux coupé (doublés à chaque échec). Variables pour
+// que les tests puissent les raccourcir.
+var (
+	streamMinBackoff = time.Second
+	streamMaxBackoff = time.Minute
+)
+
+// streamOrderPollEvery : tant que le streaming est actif, les ordres en attente ne
+// sont relus par polling qu'un tick sur streamOrderPollEvery, en filet de sécurité
+// contre un message perdu.
+const streamOrderPollEvery = 12
+
+// streamFeed identifie un flux : type (ticker, orders, trades) et paire.
+type streamFeed struct {
+	kind string
+	pair string
+}
+
+// streamEvent est une mise à jour reçue d'un flux, traitée par la boucle principale.
+type streamEvent struct {
+	feed   streamFeed
+	ticker Ticker
+	orders []Order
+	trades []Trade
+	// resync : le flux d'ordres vient de se reconnecter, les mises à jour manquées
+	// pendant la coupure sont relues par polling.
+	resync bool
+}
+
+// streamPrice accumule les tickers d'une paire entre deux évaluations des ventes.
+type streamPrice struct {
+	ticker    Ticker
+	peak      float64   // plus haut reçu depuis la dernière évaluation
+	evaluated time.Time // dernière évaluation (prix max + ventes)
+}
+
+// stream est l'état du mode streaming : les goroutines de flux publient sur events,
+// consommé par run() ; l'état de connexion est partagé, le reste n'est accédé que
+// depuis run().
+type stream struct {
+	streamer Streamer
+	events   chan streamEvent
+
+	mu         sync.Mutex
+	started    map[streamFeed]bool
+	up         map[streamFeed]bool // absent = jamais connecté
+	lastTicker map[string]time.Time
+
+	prices map[string]*streamPrice
+	// ticks : ticks passés sans polling des ordres ; pollNext force le prochain.
+	ticks    int
+	pollNext bool
+}
+
+func newStream(s Streamer) *stream {
+	return &stream{
+		streamer:   s,
+		events:     make(chan streamEvent, 256),
+		started:    make(map[streamFeed]bool),
+		up:         make(map[streamFeed]bool),
+		lastTicker: make(map[string]time.Time),
+		prices:     make(map[string]*streamPrice),
+	}
+}
+
+// setUp enregistre l'état d'un flux et indique s'il a changé ; reconnected est vrai
+// quand un flux déjà connecté une fois revient après une coupure.
+func (s *stream) setUp(feed streamFeed, up bool) (changed, reconnected bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	was, seen := s.up[feed]
+	s.up[feed] = up
+	if feed.kind == feedTicker && up {
+		s.lastTicker[feed.pair] = time.Now()
+	}
+	return !seen || was != up, seen && !was && up
+}
+
+// live indique si les flux de prix et d'ordres de toutes les paires sont connectés :
+// le polling peut alors être allégé. Une paire sans flux repasse tout en polling.
+func (s *stream) live(pairs []string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pair := range pairs {
+		if !s.up[streamFeed{feedTicker, pair}] || !s.up[streamFeed{feedOrders, pair}] {
+			return false
+		}
+	}
+	return true
+}
+
+// fresh indique si chaque paire a reçu un ticker depuis since.
+func (s *stream) fresh(pairs []string, since time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, pair := range pairs {
+		if s.lastTicker[pair].Before(since) {
+			return false
+		}
+	}
+	return true
+}
+
+// startStream active le mode streaming si l'exchange le supporte. Sans Streamer, le
+// bot reste en polling.
+func (b *Bot) startStream() {
+	s, ok := b.exchange.(Streamer)
+	if !ok {
+		logger.Warnf("[%s] Streaming non supporté par l'exchange : polling toutes les %s", b.Config.ExchangeName, b.Config.CheckInterval)
+		return
+	}
+	b.stream = newStream(s)
+	b.watchPairs(b.tradedPairs())
+	logger.Infof("[%s] Streaming activé (évaluation des ventes toutes les %s au plus)", b.Config.ExchangeName, b.Config.StreamSellInterval)
+}
+
+// watchPairs lance les flux des paires qui n'en ont pas encore (nouvelle stratégie).
+func (b *Bot) watchPairs(pairs []string) {
+	b.stream.mu.Lock()
+	defer b.stream.mu.Unlock()
+	for _, pair := range pairs {
+		for _, kind := range []string{feedTicker, feedOrders, feedTrades} {
+			feed := streamFeed{kind, pair}
+			if b.stream.started[feed] {
+				continue
+			}
+			b.stream.started[feed] = true
+			go b.watchFeed(feed)
+		}
+	}
+}
+
+// watchFeed lit un flux en boucle et publie ses mises à jour jusqu'à l'arrêt du bot.
+// Une coupure est retentée avec un délai croissant ; pendant ce temps, run() repasse
+// en polling. Un flux que l'exchange ne fournit pas est abandonné.
+func (b *Bot) watchFeed(feed streamFeed) {
+	backoff := streamMinBackoff
+	for {
+		select {
+		case <-b.done:
+			return
+		default:
+		}
+
+		ev := streamEvent{feed: feed}
+		var err error
+		switch feed.kind {
+		case feedTicker:
+			ev.ticker, err = b.stream.streamer.WatchTicker(feed.pair)
+			if err == nil && ev.ticker.Last == nil {
+				continue
+			}
+		case feedOrders:
+			ev.orders, err = b.stream.streamer.WatchOrders(feed.pair)
+		case feedTrades:
+			ev.trades, err = b.stream.streamer.WatchMyTrades(feed.pair)
+		}
+
+		if err != nil {
+			if strings.Contains(err.Error(), "NotSupported") {
+				logger.Warnf("[%s] Flux %s %s non fourni par l'exchange : %v", b.Config.ExchangeName, feed.kind, feed.pair, err)
+				b.stream.setUp(feed, false)
+				return
+			}
+			if changed, _ := b.stream.setUp(feed, false); changed {
+				logger.Warnf("[%s] Flux %s %s coupé, retour au polling : %v", b.Config.ExchangeName, feed.kind, feed.pair, err)
+			}
+			select {
+			case <-b.done:
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, streamMaxBackoff)
+			continue
+		}
+
+		backoff = streamMinBackoff
+		if changed, reconnected := b.stream.setUp(feed, true); changed {
+			logger.Infof("[%s] Flux %s %s connecté", b.Config.ExchangeName, feed.kind, feed.pair)
+			ev.resync = reconnected && feed.kind == feedOrders
+		}
+		select {
+		case b.stream.events <- ev:
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// streamLive indique si le streaming couvre toutes les paires tradées (et lance les
+// flux d'une paire apparue depuis le démarrage).
+func (b *Bot) streamLive(pairs []string) bool {
+	if b.stream == nil {
+		return false
+	}
+	b.watchPairs(pairs)
+	return b.stream.live(pairs)
+}
+
+// streamHeartbeat remplace le heartbeat du price-check quand les prix arrivent par
+// le flux : il n'est enregistré que si toutes les paires ont reçu un ticker depuis
+// le tick précédent.
+func (b *Bot) streamHeartbeat(pairs []string) {
+	if !b.stream.fresh(pairs, time.Now().Add(-b.Config.CheckInterval)) {
+		logger.Warnf("[%s] Aucun ticker reçu depuis %s sur au moins une paire", b.Config.ExchangeName, b.Config.CheckInterval)
+		return
+	}
+	b.lastCheck.Store(time.Now().UnixNano())
+	b.pingHealthcheck()
+}
+
+// streamOrderPollDue indique si les ordres en attente doivent être relus par polling
+// à ce tick, malgré le streaming actif.
+func (b *Bot) streamOrderPollDue() bool {
+	b.stream.ticks++
+	if b.stream.pollNext || b.stream.ticks >= streamOrderPollEvery {
+		b.stream.pollNext = false
+		b.stream.ticks = 0
+		return true
+	}
+	return false
+}
+
+// handleStreamEvent traite une mise à jour d'un flux dans la boucle principale.
+func (b *Bot) handleStreamEvent(ev streamEvent) {
+	if ev.resync {
+		logger.Infof("[%s] Flux d'ordres %s reconnecté : relecture des ordres en attente", b.Config.ExchangeName, ev.feed.pair)
+		b.handleOrderCheck()
+	}
+
+	switch ev.feed.kind {
+	case feedTicker:
+		b.applyStreamTicker(ev.feed.pair, ev.ticker)
+	case feedOrders:
+		for _, order := range ev.orders {
+			b.applyStreamOrder(order)
+		}
+	case feedTrades:
+		b.applyStreamTrades(ev.trades)
+	}
+}
+
+// applyStreamTicker accumule un ticker poussé par le flux. Prix max et ventes sont
+// évalués au plus une fois par StreamSellInterval et par paire, avec le plus haut
+// reçu entre-temps : un pic entre deux évaluations relève quand même le prix max.
+func (b *Bot) applyStreamTicker(pair string, ticker Ticker) {
+	p := b.stream.prices[pair]
+	if p == nil {
+		p = &streamPrice{}
+		b.stream.prices[pair] = p
+	}
+	p.ticker = ticker
+	p.peak = max(p.peak, *ticker.Last)
+
+	if time.Since(p.evaluated) < b.Config.StreamSellInterval {
+		return
+	}
+	p.evaluated = time.Now()
+
+	currentPrice := b.observeTicker(pair, p.ticker)
+	peak := b.roundToPrecision(p.peak, b.marketFor(pair).Precision.Price)
+	p.peak = 0
+	logger.Debugf("[%s] Current price %s: %s (streaming)", b.Config.ExchangeName, pair, b.marketFor(pair).FormatPrice(currentPrice))
+
+	b.applyPrices(map[string]float64{pair: currentPrice}, map[string]float64{pair: peak})
+}
+
+// applyStreamOrder applique une mise à jour d'ordre poussée par le flux. Un ordre
+// encore inconnu de la base (pose en cours d'enregistrement) est relu au prochain tick.
+func (b *Bot) applyStreamOrder(order Order) {
+	if order.Id == nil {
+		return
+	}
+	dbOrder, err := b.db.GetOrderByExternalID(*order.Id)
+	if err != nil {
+		if order.ClientOrderId != nil {
+			if intent, _ := b.db.GetOrderIntent(*order.ClientOrderId); intent != nil && intent.Status == database.IntentPending {
+				b.stream.pollNext = true
+			}
+		}
+		logger.Debugf("[%s] Ordre %s reçu du flux, inconnu de la base : ignoré", b.Config.ExchangeName, *order.Id)
+		return
+	}
+	if dbOrder.Status != database.Pending {
+		return
+	}
+	b.applyOrder(*dbOrder, order)
+}
+
+// applyStreamTrades relit sur l'exchange les ordres en attente concernés par des
+// exécutions poussées par le flux (état complet, frais compris).
+func (b *Bot) applyStreamTrades(trades []Trade) {
+	seen := make(map[string]bool)
+	for _, trade := range trades {
+		if trade.OrderId == nil || seen[*trade.OrderId] {
+			continue
+		}
+		seen[*trade.OrderId] = true
+
+		dbOrder, err := b.db.GetOrderByExternalID(*trade.OrderId)
+		if err != nil || dbOrder.Status != database.Pending {
+			continue
+		}
+		b.processOrder(*dbOrder)
+	}
+}
@@ -0,0 +1,78 @@
+package bot
+
+import "sync"
+
+// LocalStream est un Streamer en mémoire qui enveloppe un Exchange : à la place d'une
+// connexion WebSocket, on y publie tickers, ordres et exécutions (tests, outils
+// locaux), et Disconnect simule une coupure.
+type LocalStream struct {
+	Exchange
+
+	mu    sync.Mutex
+	feeds map[streamFeed]chan localMessage
+}
+
+// localMessage est un message d'un flux local : une mise à jour ou une coupure.
+type localMessage struct {
+	ticker Ticker
+	orders []Order
+	trades []Trade
+	err    error
+}
+
+// NewLocalStream crée un flux local au-dessus de l'exchange donné, qui continue de
+// servir les appels REST.
+func NewLocalStream(ex Exchange) *LocalStream {
+	return &LocalStream{Exchange: ex, feeds: make(map[streamFeed]chan localMessage)}
+}
+
+func (l *LocalStream) feed(kind, pair string) chan localMessage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	key := streamFeed{kind, pair}
+	ch, ok := l.feeds[key]
+	if !ok {
+		ch = make(chan localMessage, 64)
+		l.feeds[key] = ch
+	}
+	return ch
+}
+
+// PushTicker publie un ticker sur le flux de prix de la paire.
+func (l *LocalStream) PushTicker(pair string, ticker Ticker) {
+	l.feed(feedTicker, pair) <- localMessage{ticker: ticker}
+}
+
+// PushOrders publie des mises à jour d'ordres sur le flux d'ordres de la paire.
+func (l *LocalStream) PushOrders(pair string, orders ...Order) {
+	l.feed(feedOrders, pair) <- localMessage{orders: orders}
+}
+
+// PushTrades publie des exécutions sur le flux de trades de la paire.
+func (l *LocalStream) PushTrades(pair string, trades ...Trade) {
+	l.feed(feedTrades, pair) <- localMessage{trades: trades}
+}
+
+// Disconnect coupe tous les flux ouverts : leur prochaine lecture retourne err.
+func (l *LocalStream) Disconnect(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, ch := range l.feeds {
+		ch <- localMessage{err: err}
+	}
+}
+
+func (l *LocalStream) WatchTicker(pair string) (Ticker, error) {
+	msg := <-l.feed(feedTicker, pair)
+	return msg.ticker, msg.err
+}
+
+func (l *LocalStream) WatchOrders(pair string) ([]Order, error) {
+	msg := <-l.feed(feedOrders, pair)
+	return msg.orders, msg.err
+}
+
+func (l *LocalStream) WatchMyTrades(pair string) ([]Trade, error) {
+	msg := <-l.feed(feedTrades, pair)
+	return msg.trades, msg.err
+}
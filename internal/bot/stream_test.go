@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"bot/internal/core/database"
+)
+
+// nextStreamEvent attend la prochaine mise à jour publiée par les flux.
+func nextStreamEvent(t *testing.T, b *Bot) streamEvent {
+	t.Helper()
+	select {
+	case ev := <-b.stream.events:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("aucune mise à jour reçue du flux")
+		return streamEvent{}
+	}
+}
+
+// Via un flux local : l'exécution d'un achat poussée par le flux d'ordres est appliquée
+// sans polling, le prix max suit le plus haut reçu entre deux évaluations, et une
+// coupure repasse en polling jusqu'à la reconnexion, qui relit les ordres en attente.
+func TestStreaming_FillsPricesAndFallback(t *testing.T) {
+	streamMinBackoff = 10 * time.Millisecond
+	t.Cleanup(func() { streamMinBackoff = time.Second })
+
+	b, db := newReconcileBot(t, &fakeExchange{})
+	local := NewLocalStream(b.exchange)
+	b.exchange = local
+	b.done = make(chan bool)
+	t.Cleanup(func() { close(b.done) })
+	b.Config.Streaming = true
+	b.Config.StreamSellInterval = time.Hour
+	b.paused.Store(true) // ventes suspendues : seul le suivi du prix max est testé
+
+	order, err := db.CreateOrder("ex-1", database.Buy, 0.01, 95, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cycle, err := db.CreateCycle(order.ID, 97)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.startStream()
+	pairs := []string{"BTC/USDC"}
+
+	local.PushOrders("BTC/USDC", Order{Id: ptr("ex-1"), Status: ptr("closed"), Filled: ptr(0.01), Average: ptr(94.5)})
+	b.handleStreamEvent(nextStreamEvent(t, b))
+	if got, _ := db.GetOrderByExternalID("ex-1"); got.Status != database.Filled || *got.AvgFillPrice != 94.5 {
+		t.Fatalf("ordre après exécution poussée = %+v", got)
+	}
+
+	// 105 est évalué tout de suite ; 120 puis 110 attendent l'intervalle, 108 déclenche
+	// l'évaluation suivante avec le plus haut reçu (120).
+	for _, price := range []float64{105, 120, 110} {
+		local.PushTicker("BTC/USDC", Ticker{Last: ptr(price)})
+		b.handleStreamEvent(nextStreamEvent(t, b))
+	}
+	if got, _ := db.GetCycle(cycle.ID); got.MaxPrice != 105 {
+		t.Errorf("prix max avant l'intervalle = %v, attendu 105", got.MaxPrice)
+	}
+	b.stream.prices["BTC/USDC"].evaluated = time.Time{}
+	local.PushTicker("BTC/USDC", Ticker{Last: ptr(108.0)})
+	b.handleStreamEvent(nextStreamEvent(t, b))
+	if got, _ := db.GetCycle(cycle.ID); got.MaxPrice != 120 {
+		t.Errorf("prix max après l'intervalle = %v, attendu 120", got.MaxPrice)
+	}
+
+	if !b.streamLive(pairs) {
+		t.Fatal("flux de prix et d'ordres connectés : streaming attendu")
+	}
+
+	local.Disconnect(errors.New("connexion perdue"))
+	deadline := time.Now().Add(2 * time.Second)
+	for b.streamLive(pairs) {
+		if time.Now().After(deadline) {
+			t.Fatal("coupure non détectée : polling attendu")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	local.PushOrders("BTC/USDC", Order{Id: ptr("unknown"), Status: ptr("open")})
+	if ev := nextStreamEvent(t, b); !ev.resync {
+		t.Errorf("reconnexion du flux d'ordres sans relecture : %+v", ev)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	"bot/internal/core/database"
 	"bot/internal/logger"
 	"bot/internal/telegram"
 	"bot/internal/version"
@@ -33,47 +34,16 @@ func (d *telegramDashboard) Status() (telegram.StatusSnapshot, error) {
 	snap := telegram.StatusSnapshot{
 		Version:   version.Version,
 		Exchange:  b.Config.ExchangeName,
-		Pair:      b.Config.Pair,
-		Quote:     b.market.QuoteAsset,
 		Paused:    b.IsPaused(),
 		UpdatedAt: time.Now(),
-		RSI:       "n/a",
 	}
-
-	// Prix courant
-	if price, err := b.exchange.GetPrice(b.Config.Pair); err != nil {
-		snap.Price = "n/a"
-	} else {
-		snap.Price = b.market.FormatPrice(b.roundToPrecision(price, b.market.Precision.Price))
-	}
-
-	// RSI : première stratégie activée disposant d'une configuration RSI.
-	if strategies, err := b.db.GetAllStrategies(); err == nil {
-		for _, s := range strategies {
-			if !s.Enabled || s.RSIPeriod == nil || *s.RSIPeriod <= 0 {
-				continue
-			}
-			tf := s.RSITimeframe
-			if tf == "" {
-				tf = "4h"
-			}
-			if rsi, err := b.Calculator.CalculateRSI(b.Config.Pair, tf, *s.RSIPeriod); err == nil {
-				snap.RSI = fmt.Sprintf("%.0f", rsi)
-				snap.RSITimeframe = tf
-			}
-			break
-		}
+	if trip := b.BreakerTrip(); trip != nil {
+		snap.Halted = trip.Reason
 	}
 
-	if stats, err := b.db.GetStats(); err == nil {
-		snap.ActiveCycles, _ = stats["active_cycles_count"].(int)
-		snap.TotalProfit, _ = stats["total_profit"].(float64)
-		snap.AvgProfit, _ = stats["average_profit"].(float64)
-	}
-
-	// Cycles « open » : achat rempli, vente pas encore placée.
-	if open, err := b.db.GetCycles("open"); err == nil {
-		snap.OpenCycles = len(open)
+	strategies, _ := b.db.GetAllStrategies()
+	for _, pair := range b.tradedPairs() {
+		snap.Pairs = append(snap.Pairs, d.pairStatus(pair, strategies))
 	}
 
 	// Heartbeat : uptime et fraîcheur du dernier price-check réussi.
@@ -93,57 +63,108 @@ func (d *telegramDashboard) Status() (telegram.StatusSnapshot, error) {
 	return snap, nil
 }
 
+// pairStatus collecte l'état d'une paire : prix, RSI, cycles et PnL.
+func (d *telegramDashboard) pairStatus(pair string, strategies []database.Strategy) telegram.PairStatus {
+	b := d.bot
+	m := b.marketFor(pair)
+
+	ps := telegram.PairStatus{Pair: pair, Quote: m.QuoteAsset, RSI: "n/a"}
+
+	// Prix courant
+	if price, err := b.exchange.GetPrice(pair); err != nil {
+		ps.Price = "n/a"
+	} else {
+		ps.Price = m.FormatPrice(b.roundToPrecision(price, m.Precision.Price))
+	}
+
+	// RSI : première stratégie activée de la paire disposant d'une configuration RSI.
+	for _, s := range strategies {
+		if !s.Enabled || s.Pair != pair || s.RSIPeriod == nil || *s.RSIPeriod <= 0 {
+			continue
+		}
+		tf := s.RSITimeframe
+		if tf == "" {
+			tf = "4h"
+		}
+		if rsi, err := b.Calculator.CalculateRSI(pair, tf, *s.RSIPeriod); err == nil {
+			ps.RSI = fmt.Sprintf("%.0f", rsi)
+			ps.RSITimeframe = tf
+		}
+		break
+	}
+
+	if stats, err := b.db.GetStats(pair); err == nil {
+		ps.ActiveCycles, _ = stats["active_cycles_count"].(int)
+		ps.TotalProfit, _ = stats["total_profit"].(float64)
+		ps.AvgProfit, _ = stats["average_profit"].(float64)
+	}
+
+	// Cycles « open » : achat rempli, vente pas encore placée.
+	if open, err := b.db.GetCycles("open", pair); err == nil {
+		ps.OpenCycles = len(open)
+	}
+
+	return ps
+}
+
 func (d *telegramDashboard) Cycles() ([]telegram.CycleView, error) {
 	b := d.bot
 
-	cycles, err := b.db.GetCycles("active")
+	cycles, err := b.db.GetCycles("active", "")
 	if err != nil {
 		return nil, err
 	}
 
 	views := make([]telegram.CycleView, 0, len(cycles))
 	for _, c := range cycles {
+		m := b.marketFor(c.Pair())
 		views = append(views, telegram.CycleView{
 			ID:       c.ID,
+			Pair:     c.Pair(),
 			Status:   string(c.Status),
-			Amount:   b.market.FormatAmount(c.BuyOrder.Amount),
-			BuyPrice: b.market.FormatPrice(c.BuyOrder.Price),
-			Target:   b.market.FormatPrice(c.TargetPrice),
+			Amount:   m.FormatAmount(c.BuyOrder.ExecutedAmount()),
+			BuyPrice: m.FormatPrice(c.BuyOrder.ExecutedPrice()),
+			Target:   m.FormatPrice(c.TargetPrice),
 			Age:      time.Since(c.CreatedAt).Round(time.Minute).String(),
 		})
 	}
 	return views, nil
 }
 
-func (d *telegramDashboard) PnL() (telegram.PnLSnapshot, error) {
+func (d *telegramDashboard) PnL() ([]telegram.PnLSnapshot, error) {
 	b := d.bot
 
-	snap := telegram.PnLSnapshot{Quote: b.market.QuoteAsset}
-	if stats, err := b.db.GetStats(); err == nil {
-		snap.Completed, _ = stats["completed_cycles_count"].(int)
-		snap.TotalProfit, _ = stats["total_profit"].(float64)
-		snap.AvgProfit, _ = stats["average_profit"].(float64)
+	var snaps []telegram.PnLSnapshot
+	for _, pair := range b.tradedPairs() {
+		snap := telegram.PnLSnapshot{Pair: pair, Quote: b.marketFor(pair).QuoteAsset}
+		if stats, err := b.db.GetStats(pair); err == nil {
+			snap.Completed, _ = stats["completed_cycles_count"].(int)
+			snap.TotalProfit, _ = stats["total_profit"].(float64)
+			snap.AvgProfit, _ = stats["average_profit"].(float64)
+		}
+		snaps = append(snaps, snap)
 	}
-	return snap, nil
+	return snaps, nil
 }
 
 func (d *telegramDashboard) Balance() (telegram.BalanceSnapshot, error) {
 	b := d.bot
 
-	balances, base, quote, price, err := b.FetchBalances()
+	balances, quote, prices, err := b.FetchBalances()
 	if err != nil {
 		return telegram.BalanceSnapshot{}, err
 	}
 
 	snap := telegram.BalanceSnapshot{Exchange: b.Config.ExchangeName}
 
-	// Ordre stable : base, puis quote, puis le reste trié alphabétiquement.
+	// Ordre stable : actifs de base valorisés, puis quote, puis le reste, chacun
+	// trié alphabétiquement.
 	assets := make([]string, 0, len(balances))
 	for a := range balances {
 		assets = append(assets, a)
 	}
 	sort.SliceStable(assets, func(i, j int) bool {
-		ri, rj := assetRank(assets[i], base, quote), assetRank(assets[j], base, quote)
+		ri, rj := assetRank(assets[i], quote, prices), assetRank(assets[j], quote, prices)
 		if ri != rj {
 			return ri < rj
 		}
@@ -155,11 +176,13 @@ func (d *telegramDashboard) Balance() (telegram.BalanceSnapshot, error) {
 	for _, asset := range assets {
 		amounts := balances[asset]
 		line := telegram.BalanceLine{Asset: asset}
-		switch asset {
-		case base:
-			line.Amount = b.market.FormatAmount(amounts.Total)
+		price, priced := prices[asset]
+		switch {
+		case priced:
+			m := b.marketFor(asset + "/" + quote)
+			line.Amount = m.FormatAmount(amounts.Total)
 			if amounts.Used > 0 {
-				line.Locked = b.market.FormatAmount(amounts.Used)
+				line.Locked = m.FormatAmount(amounts.Used)
 			}
 			if price > 0 {
 				v := amounts.Total * price
@@ -167,7 +190,7 @@ func (d *telegramDashboard) Balance() (telegram.BalanceSnapshot, error) {
 				total += v
 				hasTotal = true
 			}
-		case quote:
+		case asset == quote:
 			line.Amount = fmt.Sprintf("%.2f", amounts.Total)
 			if amounts.Used > 0 {
 				line.Locked = fmt.Sprintf("%.2f", amounts.Used)
@@ -175,7 +198,7 @@ func (d *telegramDashboard) Balance() (telegram.BalanceSnapshot, error) {
 			total += amounts.Total
 			hasTotal = true
 		default:
-			// Autres actifs : pas de prix connu (le bot ne suit que la paire configurée).
+			// Autres actifs : pas de prix connu (le bot ne suit que les paires de ses stratégies).
 			line.Amount = strconv.FormatFloat(amounts.Total, 'f', -1, 64)
 			if amounts.Used > 0 {
 				line.Locked = strconv.FormatFloat(amounts.Used, 'f', -1, 64)
@@ -190,16 +213,15 @@ func (d *telegramDashboard) Balance() (telegram.BalanceSnapshot, error) {
 	return snap, nil
 }
 
-// assetRank ordonne les actifs : base (0), quote (1), reste (2).
-func assetRank(asset, base, quote string) int {
-	switch asset {
-	case base:
+// assetRank ordonne les actifs : bases valorisées (0), quote (1), reste (2).
+func assetRank(asset, quote string, prices map[string]float64) int {
+	if _, ok := prices[asset]; ok {
 		return 0
-	case quote:
+	}
+	if asset == quote {
 		return 1
-	default:
-		return 2
 	}
+	return 2
 }
 
 func (d *telegramDashboard) Pause() error  { return d.bot.Pause() }
@@ -84,7 +84,8 @@ depuis l'exchange et lancer des backtests sur le vrai moteur de simulation. Règ
   dernières bougies dans un backtest. Inutile de rafraîchir pour une analyse purement
   historique — n'appelle pas cet outil sans raison (il sollicite l'exchange).
 - Un backtest se juge sur plusieurs métriques à la fois : PnL réalisé, PnL latent
-  (stock non vendu), cycles/jour, capital de pic (capital mobilisé), win rate.
+  (stock non vendu), cycles/jour, capital de pic (capital mobilisé), win rate, et le
+  risque : drawdown max, temps sous l'eau, Sharpe/Sortino, exposition.
   Méfie-toi d'un PnL réalisé flatteur obtenu en accumulant du stock latent.
 - Pour lire les conditions actuelles (RSI, volatilité, tendance), utilise
   get_market_snapshot plutôt que de deviner. Pour comparer plusieurs réglages,
@@ -87,7 +87,7 @@ func toolDefs() []anthropic.ToolUnionParam {
 			[]string{"strategy_id"}),
 
 		tool("run_backtest",
-			"Rejoue une stratégie sur les bougies historiques en base et renvoie les métriques (cycles/jour, capital de pic, PnL réalisé/latent, win rate...). Part d'une stratégie existante (strategy_id) puis applique les surcharges fournies. C'est l'outil clé pour comparer des variantes de paramètres AVANT de recommander un changement.",
+			"Rejoue une stratégie sur les bougies historiques en base et renvoie les métriques (cycles/jour, capital de pic, PnL réalisé/latent, win rate, drawdown max, temps sous l'eau, Sharpe/Sortino, exposition, efficacité du capital...). Part d'une stratégie existante (strategy_id) puis applique les surcharges fournies. C'est l'outil clé pour comparer des variantes de paramètres AVANT de recommander un changement.",
 			map[string]any{
 				"strategy_id":           map[string]any{"type": "integer", "description": "Stratégie de base à backtester"},
 				"rsi_timeframe":         map[string]any{"type": "string", "description": "Surcharge du timeframe RSI (ex: 15m, 1h)"},
@@ -100,6 +100,7 @@ func toolDefs() []anthropic.ToolUnionParam {
 				"fee_pct":               map[string]any{"type": "number", "description": "Frais par côté en %% (défaut 0.1)"},
 				"from":                  map[string]any{"type": "string", "description": "Date de début YYYY-MM-DD (optionnel)"},
 				"to":                    map[string]any{"type": "string", "description": "Date de fin YYYY-MM-DD (optionnel)"},
+				"include_equity":        map[string]any{"type": "boolean", "description": "Joindre la courbe d'équité journalière (réalisé, latent, investi, drawdown) pour comparer le profil de risque"},
 			},
 			[]string{"strategy_id"}),
 	}
@@ -214,11 +215,11 @@ func (a *Agent) toolGetPerformanceStats(rawInput string) (string, bool) {
 		return jsonResult(stats)
 	}
 
-	stats, err := a.db.GetStats()
+	stats, err := a.db.GetStats("")
 	if err != nil {
 		return fmt.Sprintf("erreur DB : %v", err), true
 	}
-	avg, total, err := a.db.GetProfitStats()
+	avg, total, err := a.db.GetProfitStats("")
 	if err == nil {
 		stats["avg_profit"] = avg
 		stats["total_profit"] = total
@@ -466,8 +467,9 @@ func (a *Agent) toolSweepBacktest(rawInput string) (string, bool) {
 					}
 					net := res.RealizedPnL + res.UnrealizedPnL
 					rows = append(rows, row{
-						line: fmt.Sprintf("| %s | %.0f | %.2f | %d | %.2f | %+.1f | %+.1f | %+.1f | %.0f | %.0f |",
-							tf, th, pf, itv/3600, res.CyclesPerDay, res.RealizedPnL, res.UnrealizedPnL, net, res.PeakCapital, res.WinRate),
+						line: fmt.Sprintf("| %s | %.0f | %.2f | %d | %.2f | %+.1f | %+.1f | %+.1f | %.0f | %.0f | %.1f | %.2f | %.0f |",
+							tf, th, pf, itv/3600, res.CyclesPerDay, res.RealizedPnL, res.UnrealizedPnL, net, res.PeakCapital, res.WinRate,
+							res.MaxDrawdown, res.SharpeRatio, res.ExposurePct),
 						net: net,
 					})
 				}
@@ -478,8 +480,8 @@ func (a *Agent) toolSweepBacktest(rawInput string) (string, bool) {
 
 	var b strings.Builder
 	fmt.Fprintf(&b, "Sweep %s — chemin de prix %s — %d combinaisons — trié par PnL net (réalisé+latent) décroissant.\n\n", a.pair, priceTF, total)
-	b.WriteString("| rsi_tf | seuil | profit% | interval_h | cycles/j | pnl_réalisé | pnl_latent | net | capital_pic | win% |\n")
-	b.WriteString("|---|---|---|---|---|---|---|---|---|---|\n")
+	b.WriteString("| rsi_tf | seuil | profit% | interval_h | cycles/j | pnl_réalisé | pnl_latent | net | capital_pic | win% | dd_max | sharpe | expo% |\n")
+	b.WriteString("|---|---|---|---|---|---|---|---|---|---|---|---|---|\n")
 	for _, r := range rows {
 		b.WriteString(r.line)
 		b.WriteByte('\n')
@@ -500,6 +502,7 @@ func (a *Agent) toolRunBacktest(rawInput string) (string, bool) {
 		FeePct              *float64 `json:"fee_pct"`
 		From                *string  `json:"from"`
 		To                  *string  `json:"to"`
+		IncludeEquity       bool     `json:"include_equity"`
 	}
 	if err := json.Unmarshal([]byte(rawInput), &in); err != nil {
 		return fmt.Sprintf("arguments invalides : %v", err), true
@@ -559,14 +562,34 @@ func (a *Agent) toolRunBacktest(rawInput string) (string, bool) {
 		Precision:      precision,
 		StartMs:        parseDay(in.From, false),
 		EndMs:          parseDay(in.To, true),
+		KeepEquity:     in.IncludeEquity,
 	}
 	res, err := backtest.Run(cfg, candlesByTF)
 	if err != nil {
 		return fmt.Sprintf("échec du backtest : %v", err), true
 	}
+	// Une valeur par bougie serait bien trop longue : on ne garde que la clôture de
+	// chaque jour.
+	res.Equity = dailyEquity(res.Equity)
 	return jsonResult(res)
 }
 
+// dailyEquity réduit une courbe d'équité au dernier point de chaque jour UTC.
+func dailyEquity(points []backtest.EquityPoint) []backtest.EquityPoint {
+	var daily []backtest.EquityPoint
+	lastDay := ""
+	for _, p := range points {
+		day := time.UnixMilli(p.TimeMs).UTC().Format("2006-01-02")
+		if day == lastDay {
+			daily[len(daily)-1] = p
+			continue
+		}
+		daily = append(daily, p)
+		lastDay = day
+	}
+	return daily
+}
+
 // parseDay convertit "YYYY-MM-DD" en millisecondes epoch (heure locale). Chaîne
 // vide ou nil -> 0 (borne ouverte). endOfDay place le curseur à la fin de la
 // journée.
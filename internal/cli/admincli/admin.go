@@ -1,15 +1,19 @@
-// Package admincli implémente la sous-commande « admin » : stats, cycles, orders, export.
+// Package admincli implémente la sous-commande « admin » : stats, cycles, orders, export,
+// grand livre (ledger), export fiscal (tax-export) et gestion des utilisateurs de la WebUI.
 package admincli
 
 import (
 	"bot/internal/core/database"
+	"bot/internal/ledger"
 	"bot/internal/loader"
 	"bot/internal/logger"
+	"bufio"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"text/tabwriter"
 	"time"
 )
@@ -18,12 +22,18 @@ import (
 // sont gérés en amont par le dispatcher (cmd/simple-bot).
 func Main(args []string) {
 	var (
-		command = flag.String("cmd", "stats", "Commande : stats, cycles, orders, export")
-		format  = flag.String("format", "table", "Format de sortie : table, json")
+		command  = flag.String("cmd", "stats", "Commande : stats, cycles, orders, export, ledger, tax-export, users, user-add, user-passwd, user-role, user-del")
+		format   = flag.String("format", "table", "Format de sortie : table, json (ledger : aussi csv ; tax-export : koinly, cointracking, generic)")
+		period   = flag.String("period", "monthly", "PnL réalisé par jour, mois ou année : daily, monthly, yearly (ledger)")
+		year     = flag.Int("year", 0, "Année UTC à restituer, 0 = toutes (ledger, tax-export)")
+		pair     = flag.String("pair", "", "Paire à restituer, vide = toutes (ledger)")
+		report   = flag.String("report", "journal", "Contenu du CSV : journal (exécutions) ou pnl (ledger --format csv)")
+		username = flag.String("user", "", "Utilisateur WebUI (commandes user-*)")
+		role     = flag.String("role", database.RoleViewer, "Rôle WebUI : viewer ou operator (user-add, user-role)")
 	)
 	flag.CommandLine.Parse(args)
 
-	_, db, err := loader.LoadConfig()
+	cfg, db, err := loader.LoadConfig()
 	if err != nil {
 		log.Fatalf("Échec du chargement de la configuration : %v", err)
 	}
@@ -42,15 +52,23 @@ func Main(args []string) {
 		showOrders(db, *format)
 	case "export":
 		exportData(db)
+	case "ledger":
+		showLedger(db, *format, ledger.Period(*period), *year, *pair, *report)
+	case "tax-export":
+		exportTax(db, cfg.ExchangeName, *format, *year)
+	case "users":
+		showUsers(db, *format)
+	case "user-add", "user-passwd", "user-role", "user-del":
+		manageUser(db, *command, *username, *role)
 	default:
 		fmt.Printf("Commande inconnue : %s\n", *command)
-		fmt.Println("Commandes disponibles : stats, cycles, orders, export")
+		fmt.Println("Commandes disponibles : stats, cycles, orders, export, ledger, tax-export, users, user-add, user-passwd, user-role, user-del")
 		os.Exit(1)
 	}
 }
 
 func showStats(db *database.DB, format string) {
-	stats, err := db.GetStats()
+	stats, err := db.GetStats("")
 	if err != nil {
 		logger.Fatalf("Failed to get stats: %v", err)
 	}
@@ -93,7 +111,7 @@ func showCycles(db *database.DB, format string) {
 			var sellPrice string
 			var profit string
 			if cycle.SellOrder != nil {
-				sellPrice = fmt.Sprintf("%.2f", cycle.SellOrder.Price)
+				sellPrice = fmt.Sprintf("%.2f", cycle.SellOrder.ExecutedPrice())
 				profit = fmt.Sprintf("%.2f", *cycle.Profit)
 			} else {
 				sellPrice = ""
@@ -101,7 +119,7 @@ func showCycles(db *database.DB, format string) {
 			}
 
 			fmt.Fprintf(w, "%d\t%s\t%.2f\t%.2f\t%s\t%s\t%s\t%s\n",
-				cycle.ID, cycle.Status, cycle.BuyOrder.Amount, cycle.BuyOrder.Price, sellPrice, profit,
+				cycle.ID, cycle.Status, cycle.BuyOrder.ExecutedAmount(), cycle.BuyOrder.ExecutedPrice(), sellPrice, profit,
 				formatDuration(age), cycle.CreatedAt.Format("2006-01-02 15:04:05"))
 		}
 		w.Flush()
@@ -164,7 +182,7 @@ func exportData(db *database.DB) {
 		export["pending_orders"] = orders
 	}
 
-	stats, err := db.GetStats()
+	stats, err := db.GetStats("")
 	if err != nil {
 		logger.Warnf("Warning: Failed to export stats: %v", err)
 	} else {
@@ -187,6 +205,179 @@ func exportData(db *database.DB) {
 	fmt.Printf("Data exported to: %s\n", filename)
 }
 
+// showLedger restitue le grand livre : positions et coût moyen, PnL réalisé FIFO par
+// période et, en CSV, le journal des exécutions ou le PnL pour l'expert-comptable.
+func showLedger(db *database.DB, format string, period ledger.Period, year int, pair, report string) {
+	if period != ledger.Daily && period != ledger.Monthly && period != ledger.Yearly {
+		logger.Fatalf("--period invalide : %s (daily, monthly ou yearly)", period)
+	}
+	trades, err := db.GetTrades(pair)
+	if err != nil {
+		logger.Fatalf("Failed to get trades: %v", err)
+	}
+	l := ledger.Build(trades)
+	pnl := l.PnL(period, year)
+
+	switch format {
+	case "csv":
+		if report == "pnl" {
+			err = ledger.WritePnLCSV(os.Stdout, pnl)
+		} else {
+			err = ledger.WriteJournalCSV(os.Stdout, l.EntriesForYear(year))
+		}
+		if err != nil {
+			logger.Fatalf("Failed to write CSV: %v", err)
+		}
+	case "json":
+		data, _ := json.MarshalIndent(map[string]any{
+			"positions": l.Positions,
+			"pnl":       pnl,
+			"entries":   l.EntriesForYear(year),
+		}, "", "  ")
+		fmt.Println(string(data))
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Println("=== Positions (FIFO) ===")
+		if len(l.Positions) == 0 {
+			fmt.Println("No open position")
+		} else {
+			fmt.Fprintln(w, "Pair\tAmount\tAvg Cost\tCost\tLots")
+			fmt.Fprintln(w, "----\t------\t--------\t----\t----")
+			for _, p := range l.Positions {
+				fmt.Fprintf(w, "%s\t%.8f\t%.4f\t%.2f %s\t%d\n", p.Pair, p.Amount, p.AvgCost, p.Cost, p.Quote, p.Lots)
+			}
+			w.Flush()
+		}
+
+		fmt.Printf("\n=== Realized PnL (%s) ===\n", period)
+		if len(pnl) == 0 {
+			fmt.Println("No sell")
+			return
+		}
+		fmt.Fprintln(w, "Period\tSells\tProceeds\tCost Basis\tFees\tRealized")
+		fmt.Fprintln(w, "------\t-----\t--------\t----------\t----\t--------")
+		for _, p := range pnl {
+			fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2f\t%.2f\t%+.2f %s\n",
+				p.Period, p.Sells, p.Proceeds, p.CostBasis, p.Fees, p.Realized, p.Quote)
+		}
+		w.Flush()
+
+		for _, e := range l.EntriesForYear(year) {
+			if e.Unmatched > 0 {
+				fmt.Println("\nSome sells exceed the recorded buys (unmatched_amount in the CSV journal):")
+				fmt.Println("their realized PnL only covers the matched part.")
+				break
+			}
+		}
+	}
+}
+
+// exportTax écrit les achats et ventes exécutés de l'année au format CSV d'un outil de
+// déclaration (tax_<exchange>_<année>_<format>.csv) et affiche les plus-values réalisées
+// de l'année, calculées en FIFO sur tout l'historique.
+func exportTax(db *database.DB, exchange, format string, year int) {
+	if year == 0 {
+		logger.Fatalf("--year est requis pour tax-export")
+	}
+	if format == "table" {
+		format = "generic"
+	}
+	trades, err := db.GetTrades("")
+	if err != nil {
+		logger.Fatalf("Failed to get trades: %v", err)
+	}
+	l := ledger.Build(trades)
+	entries := l.EntriesForYear(year)
+
+	var buf strings.Builder
+	if err := ledger.WriteTaxCSV(&buf, format, exchange, entries); err != nil {
+		logger.Fatalf("%v (formats : %s)", err, strings.Join(ledger.TaxFormats, ", "))
+	}
+	filename := fmt.Sprintf("tax_%s_%d_%s.csv", exchange, year, format)
+	if err := os.WriteFile(filename, []byte(buf.String()), 0644); err != nil {
+		logger.Fatalf("Failed to write tax export file: %v", err)
+	}
+	fmt.Printf("%d trade(s) exported to: %s\n", len(entries), filename)
+
+	summary := l.PnL(ledger.Yearly, year)
+	fmt.Printf("\n=== Realized gains %d (FIFO) ===\n", year)
+	if len(summary) == 0 {
+		fmt.Println("No sell")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Quote\tSells\tProceeds\tCost Basis\tFees\tRealized")
+	fmt.Fprintln(w, "-----\t-----\t--------\t----------\t----\t--------")
+	for _, p := range summary {
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%.2f\t%.2f\t%+.2f\n", p.Quote, p.Sells, p.Proceeds, p.CostBasis, p.Fees, p.Realized)
+	}
+	w.Flush()
+	for _, p := range summary {
+		if p.Unmatched > 0 {
+			fmt.Printf("\n%d %s sell(s) exceed the recorded buys: the realized gain only covers the matched part.\n", p.Unmatched, p.Quote)
+		}
+	}
+}
+
+func showUsers(db *database.DB, format string) {
+	users, err := db.GetUsers()
+	if err != nil {
+		logger.Fatalf("Failed to get users: %v", err)
+	}
+
+	switch format {
+	case "json":
+		data, _ := json.MarshalIndent(users, "", "  ")
+		fmt.Println(string(data))
+	default:
+		if len(users) == 0 {
+			fmt.Println("No WebUI users")
+			return
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUser\tRole\tCreated At")
+		fmt.Fprintln(w, "--\t----\t----\t----------")
+		for _, u := range users {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", u.ID, u.Username, u.Role, u.CreatedAt.Format("2006-01-02 15:04:05"))
+		}
+		w.Flush()
+	}
+}
+
+// manageUser gère les comptes de la WebUI. Le mot de passe (user-add, user-passwd)
+// est lu sur l'entrée standard pour ne pas apparaître dans l'historique du shell.
+func manageUser(db *database.DB, command, username, role string) {
+	if username == "" {
+		logger.Fatalf("--user est requis pour %s", command)
+	}
+
+	var err error
+	switch command {
+	case "user-add":
+		_, err = db.CreateUser(username, readPassword(), role)
+	case "user-passwd":
+		err = db.SetUserPassword(username, readPassword())
+	case "user-role":
+		err = db.SetUserRole(username, role)
+	case "user-del":
+		err = db.DeleteUser(username)
+	}
+	if err != nil {
+		logger.Fatalf("%s %s : %v", command, username, err)
+	}
+	fmt.Printf("%s : %s OK\n", command, username)
+}
+
+func readPassword() string {
+	fmt.Fprint(os.Stderr, "Mot de passe : ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		logger.Fatalf("Lecture du mot de passe impossible : %v", err)
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
 func formatDuration(d time.Duration) string {
 	if d < time.Minute {
 		return fmt.Sprintf("%ds", int(d.Seconds()))
@@ -1,4 +1,4 @@
-// Commande backtest : rejoue une stratégie rsi_dca sur les bougies historiques
+// Commande backtest : rejoue une stratégie rsi_dca, bollinger_dca ou grid sur les bougies historiques
 // stockées en base, en réutilisant le vrai code de décision (algorithms) et la
 // vraie math d'indicateurs (market). Permet de balayer une grille de paramètres
 // pour comparer leur fréquence de cycles, leur capital mobilisé et leur P&L.
@@ -6,10 +6,13 @@
 package backtestcli
 
 import (
+	"encoding/csv"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -36,12 +39,20 @@ func Main(args []string) {
 		fromStr    = flag.String("from", "", "Début (YYYY-MM-DD), optionnel")
 		toStr      = flag.String("to", "", "Fin (YYYY-MM-DD), optionnel")
 		buyTTLBars = flag.Int("buy-ttl-bars", 0, "Annule un achat non rempli après N bougies (0 = jamais)")
+		walkFwd    = flag.Bool("walk-forward", false, "Optimisation walk-forward : meilleure combinaison en échantillon, évaluée hors échantillon")
+		wfIn       = flag.Int("wf-in", 90, "Walk-forward : durée de la fenêtre en échantillon (jours)")
+		wfOut      = flag.Int("wf-out", 30, "Walk-forward : durée de la fenêtre hors échantillon (jours)")
+		equityOut  = flag.String("equity-out", "", "Exporte la courbe d'équité par bougie (.json = JSON, sinon CSV)")
+		algo       = flag.String("algo", "", "Algorithme : rsi_dca, bollinger_dca, grid (vide = celui de la stratégie, sinon rsi_dca)")
 
 		// Axes de la grille (listes séparées par des virgules ; vide = valeur de base)
 		gRSITF     = flag.String("rsi-tf", "", "RSI timeframe(s), ex: 15m,1h")
 		gThreshold = flag.String("rsi-threshold", "", "Seuil(s) RSI, ex: 40,45,50")
 		gProfit    = flag.String("profit", "", "Cible(s) de profit en %%, ex: 0.5,1,2")
 		gInterval  = flag.String("interval", "", "Intervalle(s) d'achat en secondes, ex: 21600,43200,86400")
+		gBBTF      = flag.String("bb-tf", "", "Timeframe(s) Bollinger, ex: 1h,4h (bollinger_dca)")
+		gBBK       = flag.String("bb-k", "", "Multiplicateur(s) Bollinger, ex: 1.5,2,2.5 (bollinger_dca)")
+		gLevels    = flag.String("grid-levels", "", "Nombre(s) de niveaux de la grille, ex: 6,11,21 (grid)")
 
 		// Surcharges simples (valeur unique)
 		rsiPeriod  = flag.Int("rsi-period", 14, "Période RSI")
@@ -52,6 +63,20 @@ func Main(args []string) {
 		sellOffset = flag.Float64("sell-offset", 0.1, "Offset de vente en %%")
 		quote      = flag.Float64("quote", 20, "Montant par ordre (quote)")
 		maxCycles  = flag.Int("max-cycles", 0, "Cycles concurrents max (0 = illimité)")
+		bbPeriod   = flag.Int("bb-period", 0, "Période Bollinger (0 = garder la base)")
+		gridLower  = flag.Float64("grid-lower", 0, "Prix bas de la grille (0 = garder la base)")
+		gridUpper  = flag.Float64("grid-upper", 0, "Prix haut de la grille (0 = garder la base)")
+
+		// Sorties de protection (-1 = garder la base, 0 = désactivé)
+		stopLoss      = flag.Float64("stop-loss", -1, "Stop-loss en %% sous le prix d'achat")
+		maxAgeDays    = flag.Int("max-age-days", -1, "Âge maximal d'un cycle en jours avant sortie forcée")
+		maxAgeExit    = flag.String("max-age-exit", "", "Mode de sortie forcée : market ou break_even (vide = garder la base)")
+		breakEvenDays = flag.Int("break-even-days", -1, "Vente au prix de revient après N jours")
+
+		// Limites de risque (défaut : celles de l'instance, cf. MAX_TOTAL_CAPITAL, MAX_DAILY_SPEND, MIN_QUOTE_RESERVE)
+		maxCapital    = flag.Float64("max-capital", -1, "Capital quote max engagé par la stratégie (-1 = garder la base, 0 = illimité)")
+		maxDailySpend = flag.Float64("max-daily-spend", -1, "Dépense quote max par jour UTC (-1 = celle de l'instance, 0 = illimitée)")
+		quoteBalance  = flag.Float64("quote-balance", 0, "Solde quote de départ, pour la réserve minimale (0 = inconnu, réserve ignorée)")
 	)
 	flag.CommandLine.Parse(args)
 
@@ -75,7 +100,14 @@ func Main(args []string) {
 		base = *s
 	}
 	// Surcharges simples
-	base.AlgorithmName = "rsi_dca"
+	if *algo != "" {
+		base.AlgorithmName = *algo
+	}
+	bollinger := base.AlgorithmName == "bollinger_dca"
+	grid := base.AlgorithmName == "grid"
+	if !bollinger && !grid && base.AlgorithmName != "rsi_dca" {
+		log.Fatalf("Algorithme %q non supporté par le backtest (rsi_dca, bollinger_dca, grid)", base.AlgorithmName)
+	}
 	base.RSIPeriod = iptr(*rsiPeriod)
 	base.VolatilityPeriod = iptr(*volPeriod)
 	base.VolatilityTimeframe = *volTF
@@ -87,6 +119,34 @@ func Main(args []string) {
 	if *volAdj >= 0 {
 		base.VolatilityAdjustment = fptr(*volAdj)
 	}
+	if *stopLoss >= 0 {
+		base.StopLossPercent = *stopLoss
+	}
+	if *maxAgeDays >= 0 {
+		base.MaxCycleAgeDays = *maxAgeDays
+	}
+	if *maxAgeExit != "" {
+		base.MaxCycleAgeExit = *maxAgeExit
+	}
+	if *breakEvenDays >= 0 {
+		base.BreakEvenAfterDays = *breakEvenDays
+	}
+	if *bbPeriod > 0 {
+		base.BBPeriod = *bbPeriod
+	}
+	if *gridLower > 0 {
+		base.GridLowerPrice = *gridLower
+	}
+	if *gridUpper > 0 {
+		base.GridUpperPrice = *gridUpper
+	}
+	if *maxCapital >= 0 {
+		base.MaxCapital = *maxCapital
+	}
+	limits := cfg.RiskLimits
+	if *maxDailySpend >= 0 {
+		limits.MaxDailySpend = *maxDailySpend
+	}
 
 	// Charger toutes les bougies de la paire (toutes timeframes en base).
 	tfs, err := db.GetCandleTimeframes(*pair)
@@ -110,49 +170,91 @@ func Main(args []string) {
 
 	// Construire la grille.
 	rsiTFs := listOrDefaultStr(*gRSITF, orStr(base.RSITimeframe, "15m"))
-	thresholds := listOrDefaultFloat(*gThreshold, ptrOr(base.RSIThreshold, 45))
+	// Bollinger : le filtre RSI est optionnel, seuil 0 = sans filtre.
+	defaultThreshold := ptrOr(base.RSIThreshold, 45)
+	if bollinger {
+		defaultThreshold = ptrOr(base.RSIThreshold, 0)
+	}
+	thresholds := listOrDefaultFloat(*gThreshold, defaultThreshold)
 	profits := listOrDefaultFloat(*gProfit, orFloat(base.ProfitTarget, 1.0))
-	intervals := listOrDefaultInt(*gInterval, orInt(base.BuyIntervalSeconds, 86400))
+	defaultInterval := orInt(base.BuyIntervalSeconds, 86400)
+	if grid {
+		// La grille repose les achats des niveaux libérés à chaque bougie.
+		defaultInterval = int(backtest.TimeframeMillis(*priceTF) / 1000)
+	}
+	intervals := listOrDefaultInt(*gInterval, defaultInterval)
+	bbTFs := listOrDefaultStr(*gBBTF, orStr(base.BBTimeframe, "1h"))
+	bbKs := listOrDefaultFloat(*gBBK, orFloat(base.BBMultiplier, 2.0))
+	if !bollinger {
+		bbTFs, bbKs = bbTFs[:1], bbKs[:1] // sans effet sur rsi_dca
+	}
+	levels := listOrDefaultInt(*gLevels, orInt(base.GridLevels, 11))
+	if grid {
+		// La grille n'utilise ni RSI ni objectif de profit : un seul point sur ces axes.
+		rsiTFs, thresholds, profits = rsiTFs[:1], thresholds[:1], profits[:1]
+	} else {
+		levels = levels[:1]
+	}
 
 	precision := algorithms.MarketPrecision{Price: 0.01, Amount: 0.000001}
 
-	fmt.Printf("Backtest %s — chemin de prix %s — frais %.2f%%/côté\n", *pair, *priceTF, *feePct)
-	var results []backtest.Result
-	var labels []string
+	fmt.Printf("Backtest %s %s — chemin de prix %s — frais %.2f%%/côté\n", base.AlgorithmName, *pair, *priceTF, *feePct)
+	var candidates []backtest.Candidate
 	for _, tf := range rsiTFs {
 		for _, th := range thresholds {
 			for _, pf := range profits {
 				for _, itv := range intervals {
-					s := base
-					s.RSITimeframe = tf
-					s.RSIThreshold = fptr(th)
-					s.ProfitTarget = pf
-					s.BuyIntervalSeconds = itv
-					cfg := backtest.Config{
-						Pair:           *pair,
-						Strategy:       s,
-						PriceTimeframe: *priceTF,
-						FeeRate:        *feePct / 100.0,
-						Precision:      precision,
-						StartMs:        startMs,
-						EndMs:          endMs,
-						BuyTTLBars:     *buyTTLBars,
+					for _, bbTF := range bbTFs {
+						for _, bbK := range bbKs {
+							for _, lv := range levels {
+								s := base
+								s.RSITimeframe = tf
+								s.RSIThreshold = fptr(th)
+								if bollinger && th == 0 {
+									s.RSIThreshold = nil
+								}
+								s.ProfitTarget = pf
+								s.BuyIntervalSeconds = itv
+								s.BBTimeframe = bbTF
+								s.BBMultiplier = bbK
+								s.GridLevels = lv
+								cfg := backtest.Config{
+									Pair:           *pair,
+									Strategy:       s,
+									PriceTimeframe: *priceTF,
+									FeeRate:        *feePct / 100.0,
+									Precision:      precision,
+									StartMs:        startMs,
+									EndMs:          endMs,
+									BuyTTLBars:     *buyTTLBars,
+									KeepEquity:     *equityOut != "",
+									Limits:         limits,
+									QuoteBalance:   *quoteBalance,
+								}
+								candidates = append(candidates, backtest.Candidate{Label: configLabel(s, tf, th, pf, itv), Config: cfg})
+							}
+						}
 					}
-					r, err := backtest.Run(cfg, candlesByTF)
-					if err != nil {
-						log.Fatalf("Backtest (%s/%.0f/%.2f/%ds) : %v", tf, th, pf, itv, err)
-					}
-					results = append(results, r)
-					adj := 0.0
-					if s.VolatilityAdjustment != nil {
-						adj = *s.VolatilityAdjustment
-					}
-					labels = append(labels, fmt.Sprintf("%s/%.0f/%.2g%%/adj%.0f/%dh", tf, th, pf, adj, itv/3600))
 				}
 			}
 		}
 	}
 
+	if *walkFwd {
+		runWalkForward(candidates, candlesByTF, *wfIn, *wfOut, startMs, endMs, *equityOut)
+		return
+	}
+
+	results := make([]backtest.Result, len(candidates))
+	labels := make([]string, len(candidates))
+	for i, cand := range candidates {
+		r, err := backtest.Run(cand.Config, candlesByTF)
+		if err != nil {
+			log.Fatalf("Backtest (%s) : %v", cand.Label, err)
+		}
+		results[i], labels[i] = r, cand.Label
+	}
+
 	// Tri par cycles/jour décroissant.
 	idx := make([]int, len(results))
 	for i := range idx {
@@ -165,21 +267,160 @@ func Main(args []string) {
 			results[0].Days, msDay(results[0].StartMs), msDay(results[0].EndMs))
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
-	fmt.Fprintln(w, "config\tachats/j\tcycles/j\tdur_med_j\tpic_cyc\tcapital_pic\tstock_fin\tgain_net\tlatent\ttotal\twin%\tnon_vendus")
+	fmt.Fprintln(w, "config\tachats/j\tcycles/j\tdur_med_j\tpic_cyc\tcapital_pic\tstock_fin\tgain_net\tlatent\ttotal\twin%\tsorties_prot\tnon_vendus\tdd_max\tdd%\tsous_eau_j\tsharpe\tsortino\texpo%\teff%\trefus")
 	for _, i := range idx {
 		r := results[i]
 		// total = réalisé (cycles bouclés) + latent (stock invendu valorisé au dernier prix)
 		total := r.RealizedPnL + r.UnrealizedPnL
-		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\t%.0f\t%.0f\t%.1f\t%+.0f\t%+.0f\t%.0f\t%d\n",
+		fmt.Fprintf(w, "%s\t%.2f\t%.2f\t%.2f\t%d\t%.0f\t%.0f\t%.1f\t%+.0f\t%+.0f\t%.0f\t%d\t%d\t%.0f\t%.1f\t%.0f\t%.2f\t%.2f\t%.0f\t%+.1f\t%d\n",
 			labels[i], r.BuysPerDay, r.CyclesPerDay, r.MedianCycleDays, r.PeakOpenCycles,
 			r.PeakCapital, r.OpenNotional, r.RealizedPnL, r.UnrealizedPnL, total,
-			r.WinRate, r.CyclesOpenEnd)
+			r.WinRate, r.ProtectiveExits, r.CyclesOpenEnd,
+			r.MaxDrawdown, r.MaxDrawdownPct, r.MaxUnderwaterDays, r.SharpeRatio, r.SortinoRatio, r.ExposurePct, r.CapitalEfficiencyPct, r.BuysSkipped)
+	}
+	w.Flush()
+
+	if *equityOut != "" {
+		if err := writeEquity(*equityOut, labels, results); err != nil {
+			log.Fatalf("Export de la courbe d'équité : %v", err)
+		}
+		fmt.Printf("\nCourbe d'équité exportée dans %s\n", *equityOut)
+	}
+}
+
+// runWalkForward découpe l'historique en fenêtres glissantes (inDays en échantillon,
+// outDays hors échantillon), retient sur chaque fenêtre en échantillon la combinaison
+// au meilleur P&L, l'évalue sur la fenêtre suivante et affiche la courbe de P&L hors
+// échantillon mise bout à bout ainsi que la stabilité des paramètres retenus.
+//
+// equityOut (optionnel) reçoit la courbe d'équité hors échantillon mise bout à bout,
+// chaque fenêtre prolongeant l'équité de la précédente.
+func runWalkForward(candidates []backtest.Candidate, candlesByTF map[string][]database.Candle, inDays, outDays int, startMs, endMs int64, equityOut string) {
+	const dayMs = int64(24 * time.Hour / time.Millisecond)
+	wf, err := backtest.WalkForward(backtest.WalkForwardConfig{
+		InSampleMs:  int64(inDays) * dayMs,
+		OutSampleMs: int64(outDays) * dayMs,
+		StartMs:     startMs,
+		EndMs:       endMs,
+	}, candidates, candlesByTF)
+	if err != nil {
+		log.Fatalf("Walk-forward : %v", err)
+	}
+
+	fmt.Printf("Walk-forward : %d combinaisons, fenêtres %d j en échantillon / %d j hors échantillon, %d fenêtres\n\n",
+		len(candidates), inDays, outDays, len(wf.Windows))
+	w := tabwriter.NewWriter(os.Stdout, 0, 2, 2, ' ', 0)
+	fmt.Fprintln(w, "en_échantillon\thors_échantillon\tconfig_retenue\tgain_in\tgain_out\tcycles_out\tnon_vendus\téquité")
+	for _, win := range wf.Windows {
+		fmt.Fprintf(w, "%s → %s\t%s → %s\t%s\t%+.1f\t%+.1f\t%d\t%d\t%+.1f\n",
+			msDay(win.InStartMs), msDay(win.InEndMs), msDay(win.OutStartMs), msDay(win.OutEndMs), win.Best,
+			win.InSample.RealizedPnL+win.InSample.UnrealizedPnL, win.OutPnL,
+			win.OutSample.CyclesClosed, win.OutSample.CyclesOpenEnd, win.Equity)
+	}
+	w.Flush()
+
+	fmt.Printf("\nP&L hors échantillon : %+.1f sur %.0f jours (%+.2f/j)", wf.OutPnL, wf.OutDays, perDay(wf.OutPnL, wf.OutDays))
+	fmt.Printf(" — en échantillon : %+.1f sur %.0f jours (%+.2f/j)\n", wf.InPnL, wf.InDays, perDay(wf.InPnL, wf.InDays))
+	if wf.Efficiency != 0 {
+		fmt.Printf("Efficacité walk-forward (P&L/j hors / en échantillon) : %.0f%%\n", wf.Efficiency*100)
+	}
+	fmt.Printf("Stabilité : %d combinaison(s) distincte(s), %d changement(s) sur %d fenêtres\n",
+		len(wf.Picks), wf.Changes, len(wf.Windows))
+	for _, label := range wf.PickCounts() {
+		fmt.Printf("  %-40s %d/%d\n", label, wf.Picks[label], len(wf.Windows))
+	}
+
+	if equityOut != "" {
+		labels := make([]string, len(wf.Windows))
+		results := make([]backtest.Result, len(wf.Windows))
+		offset := 0.0
+		for i, win := range wf.Windows {
+			r := win.OutSample
+			r.Equity = append([]backtest.EquityPoint(nil), r.Equity...)
+			for j := range r.Equity {
+				r.Equity[j].Realized += offset
+				r.Equity[j].Equity += offset
+			}
+			labels[i], results[i] = win.Best, r
+			offset = win.Equity
+		}
+		if err := writeEquity(equityOut, labels, results); err != nil {
+			log.Fatalf("Export de la courbe d'équité : %v", err)
+		}
+		fmt.Printf("\nCourbe d'équité hors échantillon exportée dans %s\n", equityOut)
+	}
+}
+
+// writeEquity exporte les courbes d'équité des résultats : JSON (métriques + courbe
+// par combinaison) si le fichier se termine par .json, CSV (une ligne par bougie et
+// par combinaison) sinon.
+func writeEquity(path string, labels []string, results []backtest.Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		type entry struct {
+			Config string
+			Result backtest.Result
+		}
+		entries := make([]entry, len(results))
+		for i := range results {
+			entries[i] = entry{Config: labels[i], Result: results[i]}
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+	}
+
+	w := csv.NewWriter(f)
+	if err := w.Write([]string{"config", "time", "realized", "unrealized", "equity", "invested", "drawdown"}); err != nil {
+		return err
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+	for i, r := range results {
+		for _, p := range r.Equity {
+			row := []string{labels[i], time.UnixMilli(p.TimeMs).UTC().Format(time.RFC3339),
+				num(p.Realized), num(p.Unrealized), num(p.Equity), num(p.Invested), num(p.Drawdown)}
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
 	}
 	w.Flush()
+	return w.Error()
 }
 
 // ---- helpers ----
 
+func perDay(v, days float64) float64 {
+	if days <= 0 {
+		return 0
+	}
+	return v / days
+}
+
+// configLabel décrit une combinaison de la grille dans le tableau de résultats.
+func configLabel(s database.Strategy, rsiTF string, threshold, profit float64, interval int) string {
+	if s.AlgorithmName == "grid" {
+		return fmt.Sprintf("grille%g-%g/%dniv/%dh", s.GridLowerPrice, s.GridUpperPrice, s.GridLevels, interval/3600)
+	}
+	if s.AlgorithmName == "bollinger_dca" {
+		rsi := "sans_rsi"
+		if s.RSIThreshold != nil {
+			rsi = fmt.Sprintf("rsi%s<%.0f", rsiTF, threshold)
+		}
+		return fmt.Sprintf("bb%s/%d/k%.2g/%s/%.2g%%/%dh", s.BBTimeframe, s.BBPeriod, s.BBMultiplier, rsi, profit, interval/3600)
+	}
+	adj := 0.0
+	if s.VolatilityAdjustment != nil {
+		adj = *s.VolatilityAdjustment
+	}
+	return fmt.Sprintf("%s/%.0f/%.2g%%/adj%.0f/%dh", rsiTF, threshold, profit, adj, interval/3600)
+}
+
 func defaultStrategy(rsiPeriod, volPeriod int, volTF string) database.Strategy {
 	return database.Strategy{
 		Name:                 "backtest",
@@ -196,6 +437,9 @@ func defaultStrategy(rsiPeriod, volPeriod int, volTF string) database.Strategy {
 		VolatilityTimeframe:  volTF,
 		QuoteAmount:          20,
 		BuyIntervalSeconds:   86400,
+		BBPeriod:             20,
+		BBMultiplier:         2.0,
+		BBTimeframe:          "1h",
 	}
 }
 
@@ -2,7 +2,6 @@
 package ordercli
 
 import (
-	"bot/internal/exchange"
 	"bot/internal/loader"
 	"bot/internal/logger"
 	"encoding/json"
@@ -30,9 +29,9 @@ func Main(args []string) {
 		}
 	}()
 
-	exchg := exchange.NewExchange(cfg.ExchangeName)
-	if exchg == nil {
-		logger.Fatalf("Failed to create %s exchange instance", cfg.ExchangeName)
+	exchg, err := loader.NewExchange(cfg, db)
+	if err != nil {
+		logger.Fatalf("Failed to create %s exchange instance: %v", cfg.ExchangeName, err)
 	}
 
 	order, err := exchg.FetchOrder(orderId, cfg.TradingPair)
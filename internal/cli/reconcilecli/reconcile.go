@@ -0,0 +1,58 @@
+// Package reconcilecli implémente la sous-commande « reconcile » : compare les ordres
+// ouverts et les trades récents de l'exchange avec la base, et répare les écarts.
+package reconcilecli
+
+import (
+	"bot/internal/bot"
+	"bot/internal/loader"
+	"bot/internal/logger"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Main est le point d'entrée de la sous-commande « reconcile ». Le flag --root et le
+// chdir sont gérés en amont par le dispatcher (cmd/simple-bot).
+//
+// À lancer bot arrêté (ou en --dry-run) : la réparation écrit dans la base sans
+// coordination avec la boucle du daemon.
+func Main(args []string) {
+	var (
+		dryRun    = flag.Bool("dry-run", false, "Rapport seul, aucune modification de la base")
+		pair      = flag.String("pair", "", "Paire à réconcilier (vide = toutes les paires tradées)")
+		days      = flag.Int("days", 7, "Fenêtre des trades récents examinés (jours)")
+		strategy  = flag.Int("strategy", 0, "ID de la stratégie de rattachement des ordres adoptés (0 = première activée de la paire)")
+		tolerance = flag.Float64("tolerance", 1, "Écart toléré (%) entre solde de base attendu et réel")
+		format    = flag.String("format", "table", "Format de sortie : table, json")
+	)
+	flag.CommandLine.Parse(args)
+
+	tradingBot, err := loader.LoadBot()
+	if err != nil {
+		log.Fatalf("Échec du chargement du bot : %v", err)
+	}
+	defer tradingBot.Cleanup()
+
+	reports, err := tradingBot.ReconcileAll(bot.ReconcileOptions{
+		DryRun:              *dryRun,
+		Pair:                *pair,
+		Lookback:            time.Duration(*days) * 24 * time.Hour,
+		StrategyID:          *strategy,
+		BalanceTolerancePct: *tolerance,
+	})
+	if err != nil {
+		logger.Fatalf("Échec de la réconciliation : %v", err)
+	}
+
+	switch *format {
+	case "json":
+		data, _ := json.MarshalIndent(reports, "", "  ")
+		fmt.Println(string(data))
+	default:
+		for _, report := range reports {
+			fmt.Println(report.String())
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package strategycli implémente la sous-commande « strategy » : export et import des
+// stratégies dans un fichier YAML/JSON versionné (voir internal/strategyfile).
+package strategycli
+
+import (
+	"bot/internal/algorithms"
+	"bot/internal/core/database"
+	"bot/internal/loader"
+	"bot/internal/logger"
+	"bot/internal/strategyfile"
+	"bot/internal/web"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+const usage = `Usage :
+  simple-bot [--root DIR] strategy export [--format yaml|json] [--name NOM] [FICHIER]
+  simple-bot [--root DIR] strategy import [--dry-run] [--format table|json] FICHIER`
+
+// Main est le point d'entrée de la sous-commande « strategy ». Le flag --root et le
+// chdir sont gérés en amont par le dispatcher (cmd/simple-bot).
+func Main(args []string) {
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+	var (
+		format = flag.String("format", "", "export : format du fichier, yaml ou json (défaut : extension, sinon yaml) ; import : format du rapport, table ou json")
+		name   = flag.String("name", "", "export : n'exporter que cette stratégie")
+		dryRun = flag.Bool("dry-run", false, "import : affiche le diff avec la base sans rien modifier")
+	)
+	flag.CommandLine.Parse(args[1:])
+	path := flag.Arg(0)
+
+	_, db, err := loader.LoadConfig()
+	if err != nil {
+		log.Fatalf("Échec du chargement de la configuration : %v", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			logger.Fatalf("Échec de la fermeture de la DB : %v", err)
+		}
+	}()
+
+	switch args[0] {
+	case "export":
+		exportStrategies(db, path, *format, *name)
+	case "import":
+		if path == "" {
+			log.Fatal(usage)
+		}
+		importStrategies(db, path, *format, *dryRun)
+	default:
+		log.Fatalf("Commande inconnue : %s\n%s", args[0], usage)
+	}
+}
+
+// exportStrategies écrit les stratégies de la base dans path (stdout si vide).
+func exportStrategies(db *database.DB, path, format, name string) {
+	if format == "" {
+		format = strategyfile.FormatYAML
+		if path != "" {
+			if f, err := strategyfile.FormatFromPath(path); err == nil {
+				format = f
+			}
+		}
+	}
+
+	strategies, err := db.GetAllStrategies()
+	if err != nil {
+		logger.Fatalf("Failed to get strategies: %v", err)
+	}
+	if name != "" {
+		var selected []database.Strategy
+		for _, s := range strategies {
+			if s.Name == name {
+				selected = append(selected, s)
+			}
+		}
+		if len(selected) == 0 {
+			logger.Fatalf("Stratégie introuvable : %s", name)
+		}
+		strategies = selected
+	}
+
+	var out io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			logger.Fatalf("Impossible de créer %s : %v", path, err)
+		}
+		defer f.Close()
+		out = f
+	}
+	if err := strategyfile.Encode(out, strategies, format); err != nil {
+		logger.Fatalf("Échec de l'export : %v", err)
+	}
+	if path != "" {
+		logger.Infof("%d stratégie(s) exportée(s) vers %s", len(strategies), path)
+	}
+}
+
+// importStrategies valide le fichier, affiche le plan (création / mise à jour /
+// inchangée, avec le diff paramètre par paramètre) puis l'applique hors --dry-run.
+func importStrategies(db *database.DB, path, format string, dryRun bool) {
+	fileFormat, err := strategyfile.FormatFromPath(path)
+	if err != nil {
+		logger.Fatalf("%v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		logger.Fatalf("Impossible de lire %s : %v", path, err)
+	}
+	file, err := strategyfile.Decode(data, fileFormat)
+	if err != nil {
+		logger.Fatalf("%s : %v", path, err)
+	}
+
+	plan, err := strategyfile.Plan(db, algorithms.NewAlgorithmRegistry(), file)
+	if err != nil {
+		logger.Fatalf("%s : %v", path, err)
+	}
+
+	switch format {
+	case "json":
+		data, _ := json.MarshalIndent(plan, "", "  ")
+		fmt.Println(string(data))
+	default:
+		for _, item := range plan {
+			fmt.Println(item.String())
+		}
+	}
+
+	changed := 0
+	for _, item := range plan {
+		if item.Action != strategyfile.ActionUnchanged {
+			changed++
+		}
+	}
+	if dryRun || changed == 0 {
+		return
+	}
+
+	src := database.ChangeSource{Origin: database.OriginImport, User: os.Getenv("USER"), Comment: filepath.Base(path)}
+	if err := strategyfile.Apply(db, plan, src); err != nil {
+		logger.Fatalf("Échec de l'import : %v", err)
+	}
+	logger.Infof("%d stratégie(s) créée(s) ou mise(s) à jour depuis %s", changed, path)
+
+	// Même notification que la WebUI après modification d'une stratégie
+	if err := web.NewBotClient().NotifyReload(); err != nil {
+		logger.Warnf("Failed to notify bot of strategy import: %v", err)
+	}
+}
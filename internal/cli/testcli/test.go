@@ -3,7 +3,6 @@ package testcli
 
 import (
 	"bot/internal/bot"
-	"bot/internal/exchange"
 	"bot/internal/loader"
 	"bot/internal/logger"
 	"flag"
@@ -49,9 +48,9 @@ func Main(args []string) {
 
 	// 2. Créer l'instance de l'exchange
 	logStep("Creating exchange instance...")
-	exchg := exchange.NewExchange(cfg.ExchangeName)
-	if exchg == nil {
-		logger.Fatalf("Failed to create %s exchange instance", cfg.ExchangeName)
+	exchg, err := loader.NewExchange(cfg, db)
+	if err != nil {
+		logger.Fatalf("Failed to create %s exchange instance: %v", cfg.ExchangeName, err)
 	}
 	logger.Infof("✓ %s exchange initialized", cfg.ExchangeName)
 
@@ -127,7 +126,7 @@ func Main(args []string) {
 	limitPrice := currentPrice - testOffset
 	baseAmount := buyAmountInQuoteAsset / limitPrice
 
-	buyOrder, err := exchg.PlaceLimitBuyOrder(botConfig.Pair, baseAmount, limitPrice)
+	buyOrder, err := exchg.PlaceLimitBuyOrder(botConfig.Pair, baseAmount, limitPrice, "")
 	if err != nil {
 		logger.Errorf("Failed to place buy order: %v", err)
 	} else {
@@ -162,7 +161,7 @@ func Main(args []string) {
 		logger.Infof("   Sell price: %.2f %s (current + %.2f)", sellPrice, quoteAsset, sellOffset)
 		logger.Infof("   Sell amount: %.6f %s", sellAmountInBaseAsset, baseAsset)
 
-		sellOrder, err := exchg.PlaceLimitSellOrder(botConfig.Pair, sellAmountInBaseAsset, sellPrice)
+		sellOrder, err := exchg.PlaceLimitSellOrder(botConfig.Pair, sellAmountInBaseAsset, sellPrice, "")
 		if err != nil {
 			logger.Errorf("Failed to place sell order: %v", err)
 		} else {
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"bot/internal/risk"
 	"os"
 	"strconv"
 	"strings"
@@ -17,6 +18,39 @@ type AppConfig struct {
 	LogFile        string
 	WebPort        string
 	HealthcheckURL string
+	// ReconcileAtStart : "off" (défaut), "report" (rapport seul) ou "apply" (réparation)
+	ReconcileAtStart string
+	// RiskLimits : limites d'achat communes à toute l'instance (0 = pas de limite)
+	RiskLimits risk.Limits
+	// Breaker : seuils du disjoncteur (0 = condition désactivée)
+	Breaker risk.BreakerConfig
+	// EventWebhook : abonné webhook du bus d'événements (URL vide = désactivé)
+	EventWebhook EventWebhook
+	// Notifiers : canaux de notification (Telegram toujours présent, désactivé sans TELEGRAM=1)
+	Notifiers []Notifier
+	// NotifyRetries : nombre d'envois d'une notification avant abandon
+	NotifyRetries int
+	// Streaming : prix et ordres poussés par WebSocket (STREAMING=1), polling en secours
+	Streaming bool
+	// StreamSellInterval : délai minimum entre deux évaluations des ventes d'une paire
+	StreamSellInterval time.Duration
+}
+
+// Notifier décrit un canal de notification : Kind est telegram, webhook, discord, slack
+// ou ntfy ; Types restreint les événements notifiés sur ce canal (vide = tous).
+type Notifier struct {
+	Kind  string
+	URL   string
+	Token string
+	Types []string
+}
+
+// EventWebhook décrit l'abonné webhook du bus d'événements : chaque événement est
+// posté en JSON sur URL, signé en HMAC-SHA256 avec Secret s'il est renseigné.
+type EventWebhook struct {
+	URL    string
+	Secret string
+	Types  []string // types d'événements transmis (vide = tous)
 }
 
 // BotConfig contient les paramètres transmis au cœur du bot.
@@ -28,6 +62,26 @@ type BotConfig struct {
 	// HealthcheckURL : URL « dead-man's switch » pingée à chaque price-check.
 	// Vide = désactivé. Si les pings cessent, le service distant alerte.
 	HealthcheckURL string
+	// ReconcileAtStart : réconciliation DB / exchange au démarrage du bot.
+	// "off" = désactivée, "report" = rapport sans modification, "apply" = réparation.
+	ReconcileAtStart string
+	// RiskLimits : capital total engagé, dépense quotidienne et réserve de quote.
+	// Un achat qui dépasserait une limite est refusé (cf. package risk).
+	RiskLimits risk.Limits
+	// Breaker : chute de prix, erreurs exchange, ticker figé, fourchette et perte du
+	// jour au-delà desquels le disjoncteur suspend les achats (cf. risk.Breaker).
+	Breaker risk.BreakerConfig
+	// EventWebhook : URL, secret HMAC et types d'événements postés par le bus.
+	EventWebhook EventWebhook
+	// Notifiers : canaux recevant les notifications (exécutions, signaux, alertes), avec
+	// leur routage par type d'événement ; NotifyRetries : envois avant abandon.
+	Notifiers     []Notifier
+	NotifyRetries int
+	// Streaming : tickers et ordres reçus en continu (cf. bot.Streamer) ; le polling
+	// toutes les CheckInterval reprend dès qu'un flux est coupé. StreamSellInterval
+	// limite la fréquence d'évaluation des ventes (et du prix max) par paire.
+	Streaming          bool
+	StreamSellInterval time.Duration
 }
 
 // Load lit la configuration depuis les variables d'environnement.
@@ -38,16 +92,67 @@ func Load() AppConfig {
 		checkIntervalMins = 5
 	}
 
+	notifyRetries, err := strconv.Atoi(getenv("NOTIFY_RETRIES", "3"))
+	if err != nil || notifyRetries <= 0 {
+		notifyRetries = 3
+	}
+
+	streamSellSecs, err := strconv.Atoi(getenv("STREAM_SELL_INTERVAL_SECONDS", "5"))
+	if err != nil || streamSellSecs < 0 {
+		streamSellSecs = 5
+	}
+
 	return AppConfig{
-		ExchangeName:   getenv("EXCHANGE", "mexc"),
-		TradingPair:    getenv("TRADING_PAIR", "BTC/USDC"),
-		CheckInterval:  time.Duration(checkIntervalMins) * time.Minute,
-		DBPath:         getenv("DB_PATH", "db/bot.db"),
-		LogLevel:       strings.ToLower(getenv("LOG_LEVEL", "info")),
-		LogFile:        os.Getenv("LOG_FILE"),
-		WebPort:        getenv("WEB_PORT", ":8080"),
-		HealthcheckURL: os.Getenv("HEALTHCHECK_URL"),
+		ExchangeName:     getenv("EXCHANGE", "mexc"),
+		TradingPair:      getenv("TRADING_PAIR", "BTC/USDC"),
+		CheckInterval:    time.Duration(checkIntervalMins) * time.Minute,
+		DBPath:           getenv("DB_PATH", "db/bot.db"),
+		LogLevel:         strings.ToLower(getenv("LOG_LEVEL", "info")),
+		LogFile:          os.Getenv("LOG_FILE"),
+		WebPort:          getenv("WEB_PORT", ":8080"),
+		HealthcheckURL:   os.Getenv("HEALTHCHECK_URL"),
+		ReconcileAtStart: strings.ToLower(getenv("RECONCILE_AT_START", "off")),
+		RiskLimits: risk.Limits{
+			MaxTotalCapital: getenvAmount("MAX_TOTAL_CAPITAL"),
+			MaxDailySpend:   getenvAmount("MAX_DAILY_SPEND"),
+			MinQuoteReserve: getenvAmount("MIN_QUOTE_RESERVE"),
+		},
+		Breaker: risk.BreakerConfig{
+			PriceDropPct:      getenvAmount("BREAKER_PRICE_DROP_PCT"),
+			PriceDropWindow:   getenvMinutes("BREAKER_PRICE_DROP_WINDOW_MINUTES", 60),
+			MaxExchangeErrors: getenvCount("BREAKER_MAX_EXCHANGE_ERRORS"),
+			MaxTickerAge:      getenvMinutes("BREAKER_MAX_TICKER_AGE_MINUTES", 0),
+			MaxSpreadPct:      getenvAmount("BREAKER_MAX_SPREAD_PCT"),
+			MaxDailyLoss:      getenvAmount("BREAKER_MAX_DAILY_LOSS"),
+		},
+		EventWebhook: EventWebhook{
+			URL:    os.Getenv("EVENTS_WEBHOOK_URL"),
+			Secret: os.Getenv("EVENTS_WEBHOOK_SECRET"),
+			Types:  getenvList("EVENTS_WEBHOOK_TYPES"),
+		},
+		Notifiers:          loadNotifiers(),
+		NotifyRetries:      notifyRetries,
+		Streaming:          os.Getenv("STREAMING") == "1",
+		StreamSellInterval: time.Duration(streamSellSecs) * time.Second,
+	}
+}
+
+// loadNotifiers lit les canaux de notification : Telegram (TELEGRAM_TYPES), puis chaque
+// canal NOTIFY_<CANAL>_URL renseigné, avec son routage NOTIFY_<CANAL>_TYPES.
+func loadNotifiers() []Notifier {
+	notifiers := []Notifier{{Kind: "telegram", Types: getenvList("TELEGRAM_TYPES")}}
+	for _, kind := range []string{"webhook", "discord", "slack", "ntfy"} {
+		prefix := "NOTIFY_" + strings.ToUpper(kind) + "_"
+		if url := os.Getenv(prefix + "URL"); url != "" {
+			notifiers = append(notifiers, Notifier{
+				Kind:  kind,
+				URL:   url,
+				Token: os.Getenv(prefix + "TOKEN"),
+				Types: getenvList(prefix + "TYPES"),
+			})
+		}
 	}
+	return notifiers
 }
 
 func getenv(key, fallback string) string {
@@ -57,6 +162,44 @@ func getenv(key, fallback string) string {
 	return fallback
 }
 
+// getenvAmount lit un montant quote ; absent, invalide ou négatif = 0 (pas de limite).
+func getenvAmount(key string) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// getenvCount lit un nombre entier ; absent, invalide ou négatif = 0 (désactivé).
+func getenvCount(key string) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v < 0 {
+		return 0
+	}
+	return v
+}
+
+// getenvMinutes lit une durée en minutes ; absente ou invalide = fallback.
+func getenvMinutes(key string, fallback int) time.Duration {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || v < 0 {
+		v = fallback
+	}
+	return time.Duration(v) * time.Minute
+}
+
+// getenvList lit une liste séparée par des virgules ; absente = nil.
+func getenvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 // GetLogLevel retourne le niveau de log configuré.
 func (c AppConfig) GetLogLevel() string { return c.LogLevel }
 
@@ -66,10 +209,18 @@ func (c AppConfig) GetLogFile() string { return c.LogFile }
 // ToBotConfig convertit AppConfig en BotConfig.
 func (c AppConfig) ToBotConfig() BotConfig {
 	return BotConfig{
-		ExchangeName:   c.ExchangeName,
-		Pair:           c.TradingPair,
-		CheckInterval:  c.CheckInterval,
-		WebPort:        c.WebPort,
-		HealthcheckURL: c.HealthcheckURL,
+		ExchangeName:       c.ExchangeName,
+		Pair:               c.TradingPair,
+		CheckInterval:      c.CheckInterval,
+		WebPort:            c.WebPort,
+		HealthcheckURL:     c.HealthcheckURL,
+		ReconcileAtStart:   c.ReconcileAtStart,
+		RiskLimits:         c.RiskLimits,
+		Breaker:            c.Breaker,
+		EventWebhook:       c.EventWebhook,
+		Notifiers:          c.Notifiers,
+		NotifyRetries:      c.NotifyRetries,
+		Streaming:          c.Streaming,
+		StreamSellInterval: c.StreamSellInterval,
 	}
 }
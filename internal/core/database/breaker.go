@@ -0,0 +1,117 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// CircuitTrip est un déclenchement du disjoncteur : les achats restent suspendus tant
+// qu'il n'a pas été réarmé (ResetAt nil).
+type CircuitTrip struct {
+	ID     int    `json:"id"`
+	Kind   string `json:"kind"` // cf. risk.TripKind
+	Pair   string `json:"pair,omitempty"`
+	Reason string `json:"reason"`
+	// TriggeredBy : opérateur de l'arrêt d'urgence (vide = déclenchement automatique)
+	TriggeredBy     string     `json:"triggered_by,omitempty"`
+	CancelledOrders int        `json:"cancelled_orders"` // achats en attente annulés
+	CreatedAt       time.Time  `json:"created_at"`
+	ResetAt         *time.Time `json:"reset_at,omitempty"`
+	ResetBy         string     `json:"reset_by,omitempty"`
+}
+
+const circuitTripColumns = `id, kind, pair, reason, triggered_by, cancelled_orders, created_at, reset_at, reset_by`
+
+func scanCircuitTrip(row interface{ Scan(...any) error }) (*CircuitTrip, error) {
+	var t CircuitTrip
+	var resetAt sql.NullTime
+	if err := row.Scan(&t.ID, &t.Kind, &t.Pair, &t.Reason, &t.TriggeredBy, &t.CancelledOrders, &t.CreatedAt, &resetAt, &t.ResetBy); err != nil {
+		return nil, err
+	}
+	if resetAt.Valid {
+		t.ResetAt = &resetAt.Time
+	}
+	return &t, nil
+}
+
+// RecordCircuitTrip enregistre un déclenchement du disjoncteur.
+func (db *DB) RecordCircuitTrip(kind, pair, reason, triggeredBy string) (*CircuitTrip, error) {
+	result, err := db.conn.Exec(`INSERT INTO circuit_trips (kind, pair, reason, triggered_by) VALUES (?, ?, ?, ?)`,
+		kind, pair, reason, triggeredBy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record circuit trip: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circuit trip ID: %w", err)
+	}
+	t, err := scanCircuitTrip(db.conn.QueryRow(`SELECT `+circuitTripColumns+` FROM circuit_trips WHERE id = ?`, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circuit trip: %w", err)
+	}
+	return t, nil
+}
+
+// SetCircuitTripCancelledOrders enregistre le nombre d'achats annulés par un arrêt d'urgence.
+func (db *DB) SetCircuitTripCancelledOrders(id, cancelled int) error {
+	if _, err := db.conn.Exec(`UPDATE circuit_trips SET cancelled_orders = ? WHERE id = ?`, cancelled, id); err != nil {
+		return fmt.Errorf("failed to update circuit trip: %w", err)
+	}
+	return nil
+}
+
+// GetActiveCircuitTrip retourne le dernier déclenchement non réarmé, nil si les achats
+// sont autorisés.
+func (db *DB) GetActiveCircuitTrip() (*CircuitTrip, error) {
+	t, err := scanCircuitTrip(db.conn.QueryRow(`SELECT ` + circuitTripColumns + `
+		FROM circuit_trips WHERE reset_at IS NULL ORDER BY id DESC LIMIT 1`))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active circuit trip: %w", err)
+	}
+	return t, nil
+}
+
+// ResetCircuitTrips réarme le disjoncteur : tous les déclenchements actifs sont clos.
+func (db *DB) ResetCircuitTrips(resetBy string) (int64, error) {
+	result, err := db.conn.Exec(`UPDATE circuit_trips SET reset_at = CURRENT_TIMESTAMP, reset_by = ? WHERE reset_at IS NULL`, resetBy)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reset circuit trips: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// GetCircuitTrips retourne les derniers déclenchements, du plus récent au plus ancien.
+func (db *DB) GetCircuitTrips(limit int) ([]CircuitTrip, error) {
+	rows, err := db.conn.Query(`SELECT `+circuitTripColumns+` FROM circuit_trips ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get circuit trips: %w", err)
+	}
+	defer rows.Close()
+
+	var trips []CircuitTrip
+	for rows.Next() {
+		t, err := scanCircuitTrip(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan circuit trip: %w", err)
+		}
+		trips = append(trips, *t)
+	}
+	return trips, rows.Err()
+}
+
+// GetLastCircuitReset retourne l'heure du dernier réarmement, nil s'il n'y en a jamais eu.
+func (db *DB) GetLastCircuitReset() (*time.Time, error) {
+	var resetAt time.Time
+	err := db.conn.QueryRow(`SELECT reset_at FROM circuit_trips WHERE reset_at IS NOT NULL ORDER BY reset_at DESC LIMIT 1`).Scan(&resetAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last circuit reset: %w", err)
+	}
+	return &resetAt, nil
+}
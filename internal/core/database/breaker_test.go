@@ -0,0 +1,97 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+// Un déclenchement reste actif jusqu'au réarmement, qui clôt tous les déclenchements en cours.
+func TestCircuitTrips(t *testing.T) {
+	db := newTestDB(t)
+
+	if trip, err := db.GetActiveCircuitTrip(); err != nil || trip != nil {
+		t.Fatalf("aucun déclenchement attendu : %+v, %v", trip, err)
+	}
+	if last, _ := db.GetLastCircuitReset(); last != nil {
+		t.Fatalf("aucun réarmement attendu : %v", last)
+	}
+
+	if _, err := db.RecordCircuitTrip("price_drop", "BTC/USDC", "chute de BTC/USDC", ""); err != nil {
+		t.Fatal(err)
+	}
+	kill, err := db.RecordCircuitTrip("manual", "", "arrêt d'urgence", "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SetCircuitTripCancelledOrders(kill.ID, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := db.GetActiveCircuitTrip()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if active == nil || active.ID != kill.ID || active.TriggeredBy != "alice" || active.CancelledOrders != 3 {
+		t.Fatalf("déclenchement actif : %+v", active)
+	}
+
+	if n, err := db.ResetCircuitTrips("bob"); err != nil || n != 2 {
+		t.Fatalf("réarmement : %d déclenchements clos, %v", n, err)
+	}
+	if active, _ := db.GetActiveCircuitTrip(); active != nil {
+		t.Errorf("déclenchement encore actif après réarmement : %+v", active)
+	}
+	if last, _ := db.GetLastCircuitReset(); last == nil || time.Since(*last) > time.Minute {
+		t.Errorf("dernier réarmement : %v", last)
+	}
+
+	trips, err := db.GetCircuitTrips(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trips) != 2 || trips[0].ID != kill.ID || trips[0].ResetAt == nil || trips[0].ResetBy != "bob" {
+		t.Errorf("historique : %+v", trips)
+	}
+}
+
+// Profit réalisé : cycles dont la vente a été remplie depuis la date donnée, nets de frais.
+func TestGetRealizedProfitSince(t *testing.T) {
+	db := newTestDB(t)
+	id := db.createPairStrategy(t, "BTC DCA", "BTC/USDC")
+
+	cycle := func(n string, buyPrice, sellPrice float64) {
+		t.Helper()
+		buy, err := db.CreateOrder(n+"-buy", Buy, 1, buyPrice, 0.1, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.CreateCycle(buy.ID, sellPrice); err != nil {
+			t.Fatal(err)
+		}
+		sell, err := db.CreateOrder(n+"-sell", Sell, 1, sellPrice, 0.1, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.UpdateCycleSellOrderForBuyOrder(buy.ID, sell.ID); err != nil {
+			t.Fatal(err)
+		}
+		for _, ext := range []string{n + "-buy", n + "-sell"} {
+			if err := db.UpdateOrderStatus(ext, Filled); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	cycle("gain", 100, 110) // +9.8
+	cycle("stop", 100, 80)  // -20.2
+
+	profit, err := db.GetRealizedProfitSince(time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := -10.4; profit < want-1e-9 || profit > want+1e-9 {
+		t.Errorf("profit réalisé = %.4f, attendu %.4f", profit, want)
+	}
+	if profit, _ := db.GetRealizedProfitSince(time.Now().Add(time.Hour)); profit != 0 {
+		t.Errorf("profit réalisé futur = %.4f, attendu 0", profit)
+	}
+}
@@ -77,6 +77,20 @@ func (db *DB) GetLastCandle(pair, timeframe string) (*Candle, error) {
 	return &candle, nil
 }
 
+// GetLastPrice retourne le dernier cours de clôture connu d'une paire, tous timeframes
+// confondus, ou 0 si aucune bougie n'a encore été collectée.
+func (db *DB) GetLastPrice(pair string) (float64, error) {
+	var price float64
+	err := db.conn.QueryRow(`SELECT close_price FROM candles WHERE pair = ? ORDER BY timestamp DESC LIMIT 1`, pair).Scan(&price)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last price: %w", err)
+	}
+	return price, nil
+}
+
 // GetPairs retrieves the distinct list of pairs with candles
 func (db *DB) GetPairs() ([]string, error) {
 	query := `
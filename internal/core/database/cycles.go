@@ -3,13 +3,20 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
 // CreateCycle creates a new cycle in the database
 func (db *DB) CreateCycle(buyOrderId int, targetPrice float64) (*CycleEnhanced, error) {
-	query := `INSERT INTO cycles (buy_order_id, target_price) VALUES (?, ?)`
-	result, err := db.conn.Exec(query, buyOrderId, targetPrice)
+	// Rattacher le cycle à la révision de stratégie active au moment de l'achat
+	revisionID, err := db.currentRevisionForOrder(buyOrderId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cycle: %w", err)
+	}
+
+	query := `INSERT INTO cycles (buy_order_id, target_price, strategy_revision_id) VALUES (?, ?, ?)`
+	result, err := db.conn.Exec(query, buyOrderId, targetPrice, revisionID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create cycle: %w", err)
 	}
@@ -27,8 +34,8 @@ func (db *DB) GetCycle(id int) (*CycleEnhanced, error) {
 	query := `
 		SELECT
 			c.id, c.target_price, c.max_price, c.created_at, c.updated_at,
-			bo.id, bo.strategy_id, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.created_at, bo.updated_at,
-			so.id, so.strategy_id, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.created_at, so.updated_at
+			bo.id, bo.strategy_id, bo.pair, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.filled_amount, bo.avg_fill_price, bo.created_at, bo.updated_at,
+			so.id, so.strategy_id, so.pair, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.filled_amount, so.avg_fill_price, so.created_at, so.updated_at
 		FROM cycles c
 		JOIN orders bo ON c.buy_order_id = bo.id
 		LEFT JOIN orders so ON c.sell_order_id = so.id
@@ -76,8 +83,8 @@ func (db *DB) GetOpenCycles() ([]CycleEnhanced, error) {
 	query := `
 		SELECT
 			c.id, c.target_price, c.max_price, c.created_at, c.updated_at,
-			bo.id, bo.strategy_id, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.created_at, bo.updated_at,
-			so.id, so.strategy_id, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.created_at, so.updated_at
+			bo.id, bo.strategy_id, bo.pair, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.filled_amount, bo.avg_fill_price, bo.created_at, bo.updated_at,
+			so.id, so.strategy_id, so.pair, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.filled_amount, so.avg_fill_price, so.created_at, so.updated_at
 		FROM cycles c
 		JOIN orders bo ON c.buy_order_id = bo.id
 		LEFT JOIN orders so ON c.sell_order_id = so.id
@@ -91,41 +98,54 @@ func (db *DB) GetOpenCycles() ([]CycleEnhanced, error) {
 
 // GetAllCycles retrieves all cycles
 func (db *DB) GetAllCycles() ([]CycleEnhanced, error) {
-	return db.GetCycles("all")
+	return db.GetCycles("all", "")
 }
 
-// GetCycles retourne les cycles selon le filtre donné :
+// GetCycles retourne les cycles selon le filtre donné, limités à une paire si
+// pair n'est pas vide :
 //   - "all"       : tous les cycles
 //   - "active"    : cycles non terminés (New + Open + Running)
 //   - "new"       : achat en attente, pas de vente
 //   - "open"      : achat rempli, pas encore de vente
 //   - "running"   : vente placée, en attente de remplissage
 //   - "completed" : cycle terminé (achat et vente remplis)
-func (db *DB) GetCycles(filter string) ([]CycleEnhanced, error) {
-	var whereClause string
+func (db *DB) GetCycles(filter, pair string) ([]CycleEnhanced, error) {
+	var condition string
 	switch filter {
 	case "new":
-		whereClause = "WHERE bo.status = 'PENDING' AND c.sell_order_id IS NULL"
+		condition = "bo.status = 'PENDING' AND c.sell_order_id IS NULL"
 	case "cancelled":
-		whereClause = "WHERE bo.status = 'CANCELLED' AND c.sell_order_id IS NULL"
+		condition = "bo.status = 'CANCELLED' AND c.sell_order_id IS NULL"
 	case "open":
-		whereClause = "WHERE bo.status = 'FILLED' AND c.sell_order_id IS NULL"
+		condition = "bo.status = 'FILLED' AND c.sell_order_id IS NULL"
 	case "running":
-		whereClause = "WHERE bo.status = 'FILLED' AND so.status IN ('PENDING', 'CANCELLED')"
+		condition = "bo.status = 'FILLED' AND so.status IN ('PENDING', 'CANCELLED')"
 	case "completed":
-		whereClause = "WHERE bo.status = 'FILLED' AND so.status = 'FILLED'"
+		condition = "bo.status = 'FILLED' AND so.status = 'FILLED'"
 	case "active":
 		// Cycles « vivants » : exclut les terminés et les achats annulés
-		whereClause = "WHERE (c.sell_order_id IS NULL AND bo.status <> 'CANCELLED') OR so.status <> 'FILLED'"
-	default: // "all"
-		whereClause = ""
+		condition = "(c.sell_order_id IS NULL AND bo.status <> 'CANCELLED') OR so.status <> 'FILLED'"
+	}
+
+	var conditions []string
+	var args []interface{}
+	if condition != "" {
+		conditions = append(conditions, "("+condition+")")
+	}
+	if pair != "" {
+		conditions = append(conditions, "bo.pair = ?")
+		args = append(args, pair)
+	}
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	query := `
 		SELECT
 			c.id, c.target_price, c.max_price, c.created_at, c.updated_at,
-			bo.id, bo.strategy_id, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.created_at, bo.updated_at,
-			so.id, so.strategy_id, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.created_at, so.updated_at
+			bo.id, bo.strategy_id, bo.pair, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.filled_amount, bo.avg_fill_price, bo.created_at, bo.updated_at,
+			so.id, so.strategy_id, so.pair, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.filled_amount, so.avg_fill_price, so.created_at, so.updated_at
 		FROM cycles c
 		JOIN orders bo ON c.buy_order_id = bo.id
 		LEFT JOIN orders so ON c.sell_order_id = so.id
@@ -133,7 +153,7 @@ func (db *DB) GetCycles(filter string) ([]CycleEnhanced, error) {
 		ORDER BY c.created_at DESC
 	`
 
-	return db.executeCycleQuery(query)
+	return db.executeCycleQuery(query, args...)
 }
 
 // GetCycleForBuyOrder retrieves a cycle by its buy order ID
@@ -234,8 +254,8 @@ func (db *DB) GetOpenCyclesForStrategy(strategyId int) ([]CycleEnhanced, error)
 	query := `
 		SELECT
 			c.id, c.target_price, c.max_price, c.created_at, c.updated_at,
-			bo.id, bo.strategy_id, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.created_at, bo.updated_at,
-			so.id, so.strategy_id, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.created_at, so.updated_at
+			bo.id, bo.strategy_id, bo.pair, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.filled_amount, bo.avg_fill_price, bo.created_at, bo.updated_at,
+			so.id, so.strategy_id, so.pair, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.filled_amount, so.avg_fill_price, so.created_at, so.updated_at
 		FROM cycles c
 		JOIN orders bo ON c.buy_order_id = bo.id
 		LEFT JOIN orders so ON c.sell_order_id = so.id
@@ -246,6 +266,29 @@ func (db *DB) GetOpenCyclesForStrategy(strategyId int) ([]CycleEnhanced, error)
 	return db.executeCycleQuery(query, strategyId)
 }
 
+// GetActiveCyclesForStrategy retourne les cycles actifs d'une stratégie : achat en
+// attente, ou achat rempli dont la vente n'est pas encore exécutée (mêmes critères
+// que CountActiveCyclesForStrategy). Sert à la grille pour savoir quels niveaux sont occupés.
+func (db *DB) GetActiveCyclesForStrategy(strategyId int) ([]CycleEnhanced, error) {
+	query := `
+		SELECT
+			c.id, c.target_price, c.max_price, c.created_at, c.updated_at,
+			bo.id, bo.strategy_id, bo.pair, bo.external_id, bo.side, bo.amount, bo.price, bo.fees, bo.status, bo.filled_amount, bo.avg_fill_price, bo.created_at, bo.updated_at,
+			so.id, so.strategy_id, so.pair, so.external_id, so.side, so.amount, so.price, so.fees, so.status, so.filled_amount, so.avg_fill_price, so.created_at, so.updated_at
+		FROM cycles c
+		JOIN orders bo ON c.buy_order_id = bo.id
+		LEFT JOIN orders so ON c.sell_order_id = so.id
+		WHERE bo.strategy_id = ? AND (
+			(bo.status = 'PENDING') OR (
+				(bo.status = 'FILLED') AND (c.sell_order_id IS NULL OR so.status <> 'FILLED')
+			)
+		)
+		ORDER BY c.created_at DESC
+	`
+
+	return db.executeCycleQuery(query, strategyId)
+}
+
 // CountActiveCycles returns the number of New, Open and Running cycles for a strategy
 func (db *DB) CountActiveCyclesForStrategy(strategyId int) (int, error) {
 	query := `
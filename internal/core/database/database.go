@@ -1,6 +1,7 @@
 package database
 
 import (
+	"bot/internal/decimal"
 	"bot/internal/logger"
 	"database/sql"
 	"fmt"
@@ -39,6 +40,12 @@ const (
 	CycleCancelled CycleStatus = "Cancelled" // Achat annulé avant remplissage (cycle mort, jamais de position)
 )
 
+// Sortie forcée d'un cycle trop vieux (Strategy.MaxCycleAgeExit)
+const (
+	CycleAgeExitMarket    = "market"     // vente immédiate au prix du marché
+	CycleAgeExitBreakEven = "break_even" // vente limite au prix de revient (frais compris)
+)
+
 // Core data structures
 type Strategy struct {
 	ID            int    `json:"id"`
@@ -46,6 +53,8 @@ type Strategy struct {
 	Description   string `json:"description"`
 	Enabled       bool   `json:"enabled"`
 	AlgorithmName string `json:"algorithm_name"`
+	// Paire tradée par la stratégie (ex. BTC/USDC). Recopiée sur chacun de ses ordres.
+	Pair string `json:"pair"`
 	// Déclenchement des achats : soit une expression cron (heure fixe), soit un
 	// intervalle minimum entre deux achats (mode périodique). Exactement un des
 	// deux est renseigné — cron non vide XOR BuyIntervalSeconds > 0.
@@ -55,7 +64,10 @@ type Strategy struct {
 	MaxConcurrentCycles int     `json:"max_concurrent_cycles"`
 	// Âge maximal (heures) d'un ordre d'achat en attente avant annulation automatique.
 	// 0 = désactivé (l'ordre reste jusqu'à remplissage ou annulation manuelle).
-	MaxBuyOrderAgeHours   int      `json:"max_buy_order_age_hours"`
+	MaxBuyOrderAgeHours int `json:"max_buy_order_age_hours"`
+	// Capital quote maximal engagé simultanément par la stratégie (somme du notional
+	// de ses cycles ouverts, achats en attente compris). 0 = illimité.
+	MaxCapital            float64  `json:"max_capital"`
 	RSIThreshold          *float64 `json:"rsi_threshold,omitempty"`
 	RSIPeriod             *int     `json:"rsi_period,omitempty"`
 	RSITimeframe          string   `json:"rsi_timeframe"`
@@ -78,28 +90,60 @@ type Strategy struct {
 	TrendFilterTimeframe  string   `json:"trend_filter_timeframe"`
 	// Taille dynamique : montant par ordre proportionnel à la profondeur de baisse
 	// (petit ordre près des sommets, gros ordre au creux). Fallback sur QuoteAmount si désactivé.
-	DynamicSizingEnabled      bool       `json:"dynamic_sizing_enabled"`
-	DynamicSizingMin          *float64   `json:"dynamic_sizing_min,omitempty"`           // taille mini (USDT), près du sommet
-	DynamicSizingMax          *float64   `json:"dynamic_sizing_max,omitempty"`           // taille maxi (USDT), au plus profond
-	DynamicSizingWindowDays   *int       `json:"dynamic_sizing_window_days,omitempty"`   // fenêtre du plus-haut de référence (jours)
-	DynamicSizingFullDrawdown *float64   `json:"dynamic_sizing_full_drawdown,omitempty"` // profondeur (%) où la taille max est atteinte
-	LastExecutedAt            *time.Time `json:"last_executed_at,omitempty"`
-	NextExecutionAt           *time.Time `json:"next_execution_at,omitempty"`
-	CreatedAt                 time.Time  `json:"created_at"`
-	UpdatedAt                 time.Time  `json:"updated_at"`
+	DynamicSizingEnabled      bool     `json:"dynamic_sizing_enabled"`
+	DynamicSizingMin          *float64 `json:"dynamic_sizing_min,omitempty"`           // taille mini (USDT), près du sommet
+	DynamicSizingMax          *float64 `json:"dynamic_sizing_max,omitempty"`           // taille maxi (USDT), au plus profond
+	DynamicSizingWindowDays   *int     `json:"dynamic_sizing_window_days,omitempty"`   // fenêtre du plus-haut de référence (jours)
+	DynamicSizingFullDrawdown *float64 `json:"dynamic_sizing_full_drawdown,omitempty"` // profondeur (%) où la taille max est atteinte
+	// Sorties de protection, évaluées avant l'objectif de profit. 0 = désactivé.
+	StopLossPercent    float64 `json:"stop_loss_percent"`     // vente au marché si le prix chute de X % sous le prix d'achat
+	MaxCycleAgeDays    int     `json:"max_cycle_age_days"`    // sortie forcée des cycles plus vieux que N jours
+	MaxCycleAgeExit    string  `json:"max_cycle_age_exit"`    // mode de sortie forcée : CycleAgeExitMarket ou CycleAgeExitBreakEven
+	BreakEvenAfterDays int     `json:"break_even_after_days"` // après N jours, l'objectif descend au prix de revient
+	// Grille (algorithme grid) : GridLevels niveaux de prix répartis régulièrement entre
+	// GridLowerPrice et GridUpperPrice, QuoteAmount par niveau. 0 = non utilisé.
+	GridLowerPrice  float64    `json:"grid_lower_price"`
+	GridUpperPrice  float64    `json:"grid_upper_price"`
+	GridLevels      int        `json:"grid_levels"`
+	LastExecutedAt  *time.Time `json:"last_executed_at,omitempty"`
+	NextExecutionAt *time.Time `json:"next_execution_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 type Order struct {
 	ID         int         `json:"id"`
 	StrategyID *int        `json:"strategy_id,omitempty"`
+	Pair       string      `json:"pair"`
 	ExternalID string      `json:"external_id"` // ID de l'exchange
 	Side       OrderSide   `json:"side"`
-	Amount     float64     `json:"amount"`
-	Price      float64     `json:"price"`
+	Amount     float64     `json:"amount"` // quantité demandée
+	Price      float64     `json:"price"`  // prix limite demandé
 	Fees       float64     `json:"fees"`
 	Status     OrderStatus `json:"status"`
-	CreatedAt  time.Time   `json:"created_at"`
-	UpdatedAt  time.Time   `json:"updated_at"`
+	// Exécution réelle rapportée par l'exchange : quantité remplie et prix moyen.
+	// nil = inconnue (ordre encore en attente sans remplissage, ou historique).
+	FilledAmount *float64  `json:"filled_amount,omitempty"`
+	AvgFillPrice *float64  `json:"avg_fill_price,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ExecutedAmount retourne la quantité réellement exécutée : la quantité remplie si
+// elle est connue, sinon la quantité demandée (ordres antérieurs au suivi des fills).
+func (o Order) ExecutedAmount() float64 {
+	if o.FilledAmount != nil {
+		return *o.FilledAmount
+	}
+	return o.Amount
+}
+
+// ExecutedPrice retourne le prix moyen d'exécution s'il est connu, sinon le prix limite.
+func (o Order) ExecutedPrice() float64 {
+	if o.AvgFillPrice != nil && *o.AvgFillPrice > 0 {
+		return *o.AvgFillPrice
+	}
+	return o.Price
 }
 
 type Cycle struct {
@@ -112,6 +156,31 @@ type Cycle struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// HeldAmount retourne la quantité d'actif de base encore détenue pour le cycle :
+// la quantité exécutée à l'achat, diminuée de ce que la vente a déjà cédé (vente
+// en cours partiellement remplie, ou vente annulée après un remplissage partiel).
+// 0 si l'achat n'est pas exécuté ou si la vente est terminée.
+func (c Cycle) HeldAmount() float64 {
+	if c.BuyOrder.Status != Filled {
+		return 0
+	}
+	held := decimal.FromFloat(c.BuyOrder.ExecutedAmount())
+	if c.SellOrder != nil {
+		if c.SellOrder.Status == Filled {
+			return 0
+		}
+		if c.SellOrder.FilledAmount != nil {
+			held = held.Sub(decimal.FromFloat(*c.SellOrder.FilledAmount))
+		}
+	}
+	return held.Float64()
+}
+
+// Pair retourne la paire tradée par le cycle (celle de son ordre d'achat).
+func (c Cycle) Pair() string {
+	return c.BuyOrder.Pair
+}
+
 // Le status du cycle est déterminé par le status des ordres d'achat et de vente
 type CycleEnhanced struct {
 	Cycle
@@ -149,12 +218,15 @@ type Migration struct {
 type OrderScanResult struct {
 	ID         sql.NullInt64
 	StrategyID sql.NullInt64
+	Pair       sql.NullString
 	ExternalID sql.NullString
 	Side       sql.NullString
 	Amount     sql.NullString
 	Price      sql.NullString
 	Fees       sql.NullString
 	Status     sql.NullString
+	Filled     sql.NullFloat64
+	AvgPrice   sql.NullFloat64
 	CreatedAt  sql.NullTime
 	UpdatedAt  sql.NullTime
 }
@@ -500,6 +572,257 @@ var migrations = []Migration{
 			ALTER TABLE strategies ADD COLUMN max_buy_order_age_hours INTEGER NOT NULL DEFAULT 0;
 		`,
 	},
+	{
+		// Suivi des remplissages partiels : quantité réellement exécutée et prix moyen
+		// d'exécution, tels que rapportés par l'exchange. NULL = inconnu.
+		ID:   20,
+		Name: "add_fill_tracking_to_orders",
+		SQL: `
+			ALTER TABLE orders ADD COLUMN filled_amount REAL;
+			ALTER TABLE orders ADD COLUMN avg_fill_price REAL;
+		`,
+	},
+	{
+		// Historique : les ordres déjà exécutés l'ont été en totalité, au prix limite.
+		ID:   21,
+		Name: "backfill_filled_orders",
+		SQL: `
+			UPDATE orders SET filled_amount = amount, avg_fill_price = price
+			WHERE status = 'FILLED' AND filled_amount IS NULL;
+		`,
+	},
+	{
+		// Sorties de protection par stratégie : stop-loss (% sous le prix d'achat),
+		// âge maximal d'un cycle (sortie au marché ou au prix de revient) et passage
+		// de l'objectif au prix de revient après N jours. 0 = désactivé.
+		ID:   22,
+		Name: "add_exit_rules_to_strategies",
+		SQL: `
+			ALTER TABLE strategies ADD COLUMN stop_loss_percent REAL NOT NULL DEFAULT 0;
+			ALTER TABLE strategies ADD COLUMN max_cycle_age_days INTEGER NOT NULL DEFAULT 0;
+			ALTER TABLE strategies ADD COLUMN max_cycle_age_exit TEXT NOT NULL DEFAULT 'market';
+			ALTER TABLE strategies ADD COLUMN break_even_after_days INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		// Multi-paires : chaque stratégie trade sa propre paire, recopiée sur ses
+		// ordres. Les lignes existantes reçoivent la paire de l'instance (TRADING_PAIR)
+		// au démarrage, cf. SetDefaultPair.
+		ID:   23,
+		Name: "add_pair_to_strategies_and_orders",
+		SQL: `
+			ALTER TABLE strategies ADD COLUMN pair TEXT NOT NULL DEFAULT '';
+			ALTER TABLE orders ADD COLUMN pair TEXT NOT NULL DEFAULT '';
+			CREATE INDEX IF NOT EXISTS idx_orders_pair ON orders(pair);
+		`,
+	},
+	{
+		ID:   24,
+		Name: "add_grid_to_strategies",
+		SQL: `
+			ALTER TABLE strategies ADD COLUMN grid_lower_price REAL NOT NULL DEFAULT 0;
+			ALTER TABLE strategies ADD COLUMN grid_upper_price REAL NOT NULL DEFAULT 0;
+			ALTER TABLE strategies ADD COLUMN grid_levels INTEGER NOT NULL DEFAULT 0;
+		`,
+	},
+	{
+		// Authentification de la WebUI : utilisateurs locaux (mot de passe bcrypt, rôle
+		// viewer/operator) et sessions. Le jeton de session n'est stocké que haché
+		// (SHA-256) : une copie de la DB ne permet pas de reprendre une session.
+		ID:   25,
+		Name: "create_users_and_sessions",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS users (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				username TEXT NOT NULL UNIQUE,
+				password_hash TEXT NOT NULL,
+				role TEXT NOT NULL DEFAULT 'viewer',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE TABLE IF NOT EXISTS sessions (
+				token_hash TEXT PRIMARY KEY,
+				user_id INTEGER NOT NULL,
+				csrf_token TEXT NOT NULL,
+				expires_at DATETIME NOT NULL,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions(user_id);
+		`,
+	},
+	{
+		// Historique des stratégies : une révision par création, modification, bascule
+		// ou suppression, avec la configuration avant/après (JSON), l'origine et
+		// l'utilisateur. Pas de clé étrangère vers strategies : l'historique survit à la
+		// suppression. Chaque cycle retient la révision active au moment de son achat.
+		ID:   26,
+		Name: "create_strategy_revisions",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS strategy_revisions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				strategy_id INTEGER NOT NULL,
+				action TEXT NOT NULL,
+				origin TEXT NOT NULL,
+				username TEXT NOT NULL DEFAULT '',
+				comment TEXT NOT NULL DEFAULT '',
+				before_json TEXT,
+				after_json TEXT,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_strategy_revisions_strategy_id ON strategy_revisions(strategy_id);
+
+			ALTER TABLE cycles ADD COLUMN strategy_revision_id INTEGER REFERENCES strategy_revisions(id);
+		`,
+	},
+	{
+		// Limites de risque : capital quote maximal engagé par une stratégie (0 =
+		// illimité) et journal des achats refusés par une limite (stratégie, instance,
+		// dépense du jour, réserve). Les refus répétés d'une même limite, sans achat
+		// entre-temps, forment un seul épisode (count, updated_at).
+		ID:   27,
+		Name: "add_risk_limits",
+		SQL: `
+			ALTER TABLE strategies ADD COLUMN max_capital REAL NOT NULL DEFAULT 0;
+
+			CREATE TABLE IF NOT EXISTS buy_skips (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				strategy_id INTEGER NOT NULL,
+				limit_name TEXT NOT NULL,
+				reason TEXT NOT NULL,
+				amount REAL NOT NULL DEFAULT 0,
+				count INTEGER NOT NULL DEFAULT 1,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_buy_skips_strategy_id ON buy_skips(strategy_id);
+		`,
+	},
+	{
+		// Disjoncteur : chaque déclenchement (chute de prix, erreurs exchange, ticker
+		// figé, fourchette, perte du jour, arrêt d'urgence) suspend les achats jusqu'au
+		// réarmement par l'opérateur. Un déclenchement sans reset_at est toujours actif,
+		// y compris après un redémarrage du bot.
+		ID:   28,
+		Name: "create_circuit_trips",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS circuit_trips (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				kind TEXT NOT NULL,
+				pair TEXT NOT NULL DEFAULT '',
+				reason TEXT NOT NULL,
+				triggered_by TEXT NOT NULL DEFAULT '',
+				cancelled_orders INTEGER NOT NULL DEFAULT 0,
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				reset_at DATETIME,
+				reset_by TEXT NOT NULL DEFAULT ''
+			);
+		`,
+	},
+	{
+		// Bus d'événements : journal des événements du cycle de vie (ordres, cycles,
+		// refus d'achat, disjoncteur...). data contient le détail propre au type, en JSON.
+		// La WebUI le relit par id croissant pour son flux SSE.
+		ID:   29,
+		Name: "create_events",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS events (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				type TEXT NOT NULL,
+				pair TEXT NOT NULL DEFAULT '',
+				strategy_id INTEGER NOT NULL DEFAULT 0,
+				cycle_id INTEGER NOT NULL DEFAULT 0,
+				order_id TEXT NOT NULL DEFAULT '',
+				message TEXT NOT NULL DEFAULT '',
+				data TEXT NOT NULL DEFAULT '{}',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_events_type ON events(type);
+			CREATE INDEX IF NOT EXISTS idx_events_created_at ON events(created_at);
+		`,
+	},
+	{
+		// Grand livre : une ligne par ordre exécuté (y compris un ordre annulé après
+		// remplissage partiel), avec la quantité remplie, le prix moyen et les frais.
+		// Contrairement aux ordres, ces lignes ne sont jamais purgées : elles portent le
+		// coût de revient FIFO et le PnL réalisé. Les ordres déjà exécutés sont repris.
+		ID:   30,
+		Name: "create_trades",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS trades (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				order_id TEXT NOT NULL UNIQUE,
+				strategy_id INTEGER,
+				pair TEXT NOT NULL,
+				side TEXT NOT NULL,
+				amount REAL NOT NULL,
+				price REAL NOT NULL,
+				fee REAL NOT NULL DEFAULT 0,
+				executed_at DATETIME NOT NULL
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_trades_pair_executed_at ON trades(pair, executed_at);
+
+			INSERT OR IGNORE INTO trades (order_id, strategy_id, pair, side, amount, price, fee, executed_at)
+				SELECT external_id, strategy_id, pair, side, COALESCE(filled_amount, amount),
+					COALESCE(avg_fill_price, price), COALESCE(fees, 0), updated_at
+				FROM orders
+				WHERE status = 'FILLED' OR (status = 'CANCELLED' AND filled_amount > 0)
+				ORDER BY updated_at, id;
+		`,
+	},
+	{
+		// Limites de marché de l'exchange (quantité, montant, bande de prix) relevées par
+		// le bot au chargement de chaque paire : la WebUI, sans accès à l'exchange, s'en
+		// sert pour refuser un montant par ordre qui ne pourrait jamais être tradé.
+		ID:   31,
+		Name: "create_market_limits",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS market_limits (
+				pair TEXT PRIMARY KEY,
+				min_amount REAL NOT NULL DEFAULT 0,
+				max_amount REAL NOT NULL DEFAULT 0,
+				min_cost REAL NOT NULL DEFAULT 0,
+				max_cost REAL NOT NULL DEFAULT 0,
+				min_price REAL NOT NULL DEFAULT 0,
+				max_price REAL NOT NULL DEFAULT 0,
+				updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+			);
+		`,
+	},
+	{
+		// Outbox des ordres : l'intention est enregistrée AVANT l'appel à l'exchange,
+		// avec l'identifiant client transmis à l'ordre. Une intention restée en attente
+		// (crash, erreur réseau) est résolue au démarrage en interrogeant l'exchange
+		// par cet identifiant, pour que chaque ordre posé soit suivi une et une seule fois.
+		ID:   32,
+		Name: "create_order_intents",
+		SQL: `
+			CREATE TABLE IF NOT EXISTS order_intents (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				client_order_id TEXT UNIQUE,
+				strategy_id INTEGER NOT NULL,
+				cycle_id INTEGER NOT NULL DEFAULT 0,
+				pair TEXT NOT NULL,
+				side TEXT NOT NULL,
+				amount REAL NOT NULL,
+				price REAL NOT NULL,
+				target_price REAL NOT NULL DEFAULT 0,
+				status TEXT NOT NULL DEFAULT 'PENDING',
+				external_id TEXT NOT NULL DEFAULT '',
+				error TEXT NOT NULL DEFAULT '',
+				created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+				updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+			);
+
+			CREATE INDEX IF NOT EXISTS idx_order_intents_status ON order_intents(status);
+		`,
+	},
 }
 
 // NewDB creates a new database connection and applies migrations
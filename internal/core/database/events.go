@@ -0,0 +1,98 @@
+package database
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"bot/internal/events"
+)
+
+const eventColumns = `id, type, pair, strategy_id, cycle_id, order_id, message, data, created_at`
+
+func scanEvent(row interface{ Scan(...any) error }) (*events.Event, error) {
+	var e events.Event
+	var data string
+	if err := row.Scan(&e.ID, &e.Type, &e.Pair, &e.StrategyID, &e.CycleID, &e.OrderID, &e.Message, &data, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if data != "" && data != "{}" {
+		if err := json.Unmarshal([]byte(data), &e.Data); err != nil {
+			return nil, fmt.Errorf("invalid data for event %d: %w", e.ID, err)
+		}
+	}
+	return &e, nil
+}
+
+// RecordEvent persiste un événement du bus et renseigne son ID (implémente events.Store).
+func (db *DB) RecordEvent(e *events.Event) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	data := "{}"
+	if len(e.Data) > 0 {
+		b, err := json.Marshal(e.Data)
+		if err != nil {
+			return fmt.Errorf("failed to marshal event data: %w", err)
+		}
+		data = string(b)
+	}
+
+	result, err := db.conn.Exec(`INSERT INTO events (type, pair, strategy_id, cycle_id, order_id, message, data, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Type, e.Pair, e.StrategyID, e.CycleID, e.OrderID, e.Message, data, e.CreatedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get event ID: %w", err)
+	}
+	e.ID = id
+	return nil
+}
+
+// GetEvents retourne au plus limit événements d'ID strictement supérieur à afterID, par
+// ID croissant, éventuellement restreints aux types donnés. Avec afterID = 0, ce sont
+// les limit derniers événements (toujours par ID croissant).
+func (db *DB) GetEvents(afterID int64, limit int, types ...events.Type) ([]events.Event, error) {
+	where := []string{"id > ?"}
+	args := []any{afterID}
+	if len(types) > 0 {
+		where = append(where, "type IN (?"+strings.Repeat(", ?", len(types)-1)+")")
+		for _, t := range types {
+			args = append(args, t)
+		}
+	}
+	order := "ASC"
+	if afterID == 0 {
+		order = "DESC"
+	}
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(`SELECT `+eventColumns+` FROM events WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY id `+order+` LIMIT ?`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get events: %w", err)
+	}
+	defer rows.Close()
+
+	var list []events.Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		list = append(list, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if order == "DESC" {
+		for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+			list[i], list[j] = list[j], list[i]
+		}
+	}
+	return list, nil
+}
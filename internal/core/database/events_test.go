@@ -0,0 +1,49 @@
+package database
+
+import (
+	"testing"
+
+	"bot/internal/events"
+)
+
+// Les événements sont relus par ID croissant, à partir d'un curseur et filtrés par type.
+func TestEvents(t *testing.T) {
+	db := newTestDB(t)
+
+	for _, e := range []events.Event{
+		{Type: events.OrderPlaced, Pair: "BTC/USDC", StrategyID: 1, OrderID: "b1", Data: map[string]any{"side": "BUY", "price": 100.5}},
+		{Type: events.CycleOpened, Pair: "BTC/USDC", StrategyID: 1, CycleID: 4, OrderID: "b1"},
+		{Type: events.OrderFilled, Pair: "BTC/USDC", StrategyID: 1, CycleID: 4, OrderID: "b1", Message: "achat exécuté"},
+	} {
+		if err := db.RecordEvent(&e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID == 0 {
+			t.Fatal("ID non renseigné")
+		}
+	}
+
+	all, err := db.GetEvents(0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Type != events.OrderPlaced || all[2].Message != "achat exécuté" {
+		t.Fatalf("événements : %+v", all)
+	}
+	if all[0].Data["side"] != "BUY" || all[0].Data["price"] != 100.5 || all[0].CreatedAt.IsZero() {
+		t.Errorf("données relues : %+v", all[0])
+	}
+
+	last, _ := db.GetEvents(0, 2)
+	if len(last) != 2 || last[0].ID != all[1].ID {
+		t.Errorf("2 derniers événements : %+v", last)
+	}
+	after, _ := db.GetEvents(all[0].ID, 10)
+	if len(after) != 2 || after[0].ID != all[1].ID {
+		t.Errorf("événements après %d : %+v", all[0].ID, after)
+	}
+	filled, _ := db.GetEvents(0, 10, events.OrderFilled, events.CycleCompleted)
+	if len(filled) != 1 || filled[0].CycleID != 4 {
+		t.Errorf("filtre par type : %+v", filled)
+	}
+}
@@ -21,6 +21,9 @@ func (db *DB) buildOrderFromScan(scanResult OrderScanResult) *Order {
 		id := int(scanResult.StrategyID.Int64)
 		order.StrategyID = &id
 	}
+	if scanResult.Pair.Valid {
+		order.Pair = scanResult.Pair.String
+	}
 	if scanResult.ExternalID.Valid {
 		order.ExternalID = scanResult.ExternalID.String
 	}
@@ -45,6 +48,7 @@ func (db *DB) buildOrderFromScan(scanResult OrderScanResult) *Order {
 	if scanResult.Status.Valid {
 		order.Status = OrderStatus(scanResult.Status.String)
 	}
+	order.setFill(scanResult.Filled, scanResult.AvgPrice)
 	if scanResult.CreatedAt.Valid {
 		order.CreatedAt = scanResult.CreatedAt.Time
 	}
@@ -55,6 +59,18 @@ func (db *DB) buildOrderFromScan(scanResult OrderScanResult) *Order {
 	return order
 }
 
+// setFill renseigne la quantité remplie et le prix moyen à partir des colonnes nullables
+func (o *Order) setFill(filled, avgPrice sql.NullFloat64) {
+	if filled.Valid {
+		v := filled.Float64
+		o.FilledAmount = &v
+	}
+	if avgPrice.Valid {
+		v := avgPrice.Float64
+		o.AvgFillPrice = &v
+	}
+}
+
 // Helper function to build a CycleEnhanced from orders
 func (db *DB) buildCycleEnhancedFromOrders(id int, targetPrice, maxPrice float64, createdAt, updatedAt time.Time, buyOrder *Order, sellOrder *Order) (*CycleEnhanced, error) {
 	if buyOrder == nil {
@@ -97,9 +113,9 @@ func (db *DB) buildCycleEnhancedFromOrders(id int, targetPrice, maxPrice float64
 			cycle.Status = Completed
 		}
 
-		profit := (cycle.SellOrder.Price - cycle.BuyOrder.Price) * cycle.BuyOrder.Amount
-		profit -= cycle.BuyOrder.Fees
-		profit -= cycle.SellOrder.Fees
+		// Profit sur les quantités et prix réellement exécutés (remplissages partiels)
+		profit := cycleProfit(cycle.BuyOrder.ExecutedPrice(), cycle.SellOrder.ExecutedPrice(), cycle.SellOrder.ExecutedAmount(),
+			cycle.BuyOrder.Fees, cycle.SellOrder.Fees).Float64()
 		cycle.Profit = &profit
 	}
 
@@ -122,13 +138,13 @@ func (db *DB) scanCycleRow(rows *sql.Rows) (*CycleScanResult, error) {
 	err := rows.Scan(
 		&result.ID, &result.TargetPrice, &result.MaxPrice, &result.CreatedAt, &result.UpdatedAt,
 		// Buy Order
-		&result.BuyOrder.ID, &result.BuyOrder.StrategyID, &result.BuyOrder.ExternalID,
+		&result.BuyOrder.ID, &result.BuyOrder.StrategyID, &result.BuyOrder.Pair, &result.BuyOrder.ExternalID,
 		&result.BuyOrder.Side, &result.BuyOrder.Amount, &result.BuyOrder.Price,
-		&result.BuyOrder.Fees, &result.BuyOrder.Status, &result.BuyOrder.CreatedAt, &result.BuyOrder.UpdatedAt,
+		&result.BuyOrder.Fees, &result.BuyOrder.Status, &result.BuyOrder.Filled, &result.BuyOrder.AvgPrice, &result.BuyOrder.CreatedAt, &result.BuyOrder.UpdatedAt,
 		// Sell Order (nullable)
-		&result.SellOrder.ID, &result.SellOrder.StrategyID, &result.SellOrder.ExternalID,
+		&result.SellOrder.ID, &result.SellOrder.StrategyID, &result.SellOrder.Pair, &result.SellOrder.ExternalID,
 		&result.SellOrder.Side, &result.SellOrder.Amount, &result.SellOrder.Price,
-		&result.SellOrder.Fees, &result.SellOrder.Status, &result.SellOrder.CreatedAt, &result.SellOrder.UpdatedAt,
+		&result.SellOrder.Fees, &result.SellOrder.Status, &result.SellOrder.Filled, &result.SellOrder.AvgPrice, &result.SellOrder.CreatedAt, &result.SellOrder.UpdatedAt,
 	)
 
 	return &result, err
@@ -138,9 +154,10 @@ func (db *DB) scanCycleRow(rows *sql.Rows) (*CycleScanResult, error) {
 func (db *DB) scanSingleOrderRow(rows *sql.Rows) (*Order, error) {
 	var order Order
 	var strategyId sql.NullInt64
+	var filled, avgPrice sql.NullFloat64
 
 	err := rows.Scan(&order.ID, &order.ExternalID, &order.Side, &order.Amount, &order.Price, &order.Fees,
-		&order.Status, &strategyId, &order.CreatedAt, &order.UpdatedAt)
+		&order.Status, &strategyId, &order.Pair, &filled, &avgPrice, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan order: %w", err)
 	}
@@ -149,6 +166,7 @@ func (db *DB) scanSingleOrderRow(rows *sql.Rows) (*Order, error) {
 		id := int(strategyId.Int64)
 		order.StrategyID = &id
 	}
+	order.setFill(filled, avgPrice)
 
 	return &order, nil
 }
@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// IntentStatus est l'état d'une intention d'ordre (outbox).
+type IntentStatus string
+
+const (
+	IntentPending IntentStatus = "PENDING" // enregistrée, ordre pas encore confirmé en base
+	IntentPlaced  IntentStatus = "PLACED"  // ordre posé et enregistré dans orders
+	IntentFailed  IntentStatus = "FAILED"  // refusée par l'exchange ou jamais arrivée
+)
+
+// OrderIntent est un ordre sur le point d'être posé : il est enregistré avant l'appel
+// à l'exchange, et porte l'identifiant client transmis à l'ordre.
+type OrderIntent struct {
+	ID            int          `json:"id"`
+	ClientOrderID string       `json:"client_order_id"`
+	StrategyID    int          `json:"strategy_id"`
+	CycleID       int          `json:"cycle_id"` // vente : cycle auquel rattacher l'ordre
+	Pair          string       `json:"pair"`
+	Side          OrderSide    `json:"side"`
+	Amount        float64      `json:"amount"`
+	Price         float64      `json:"price"`
+	TargetPrice   float64      `json:"target_price"` // achat : prix cible du cycle créé
+	Status        IntentStatus `json:"status"`
+	ExternalID    string       `json:"external_id"`
+	Error         string       `json:"error"`
+	CreatedAt     time.Time    `json:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at"`
+}
+
+// clientOrderID construit l'identifiant client d'une intention : stratégie, côté,
+// numéro d'intention et seconde de création en base 36 (un identifiant reste unique
+// même après une remise à zéro de la base). Uniquement alphanumérique et court, pour
+// être accepté par tous les exchanges.
+func clientOrderID(id int64, strategyID int, side OrderSide, createdAt time.Time) string {
+	return fmt.Sprintf("sb%d%s%dt%s", strategyID, strings.ToLower(string(side))[:1], id,
+		strconv.FormatInt(createdAt.Unix(), 36))
+}
+
+// CreateOrderIntent enregistre une intention d'ordre en attente et lui attribue son
+// identifiant client.
+func (db *DB) CreateOrderIntent(intent OrderIntent) (*OrderIntent, error) {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO order_intents (strategy_id, cycle_id, pair, side, amount, price, target_price, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, intent.StrategyID, intent.CycleID, intent.Pair, intent.Side, intent.Amount, intent.Price, intent.TargetPrice, IntentPending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order intent: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	clientID := clientOrderID(id, intent.StrategyID, intent.Side, time.Now())
+	if _, err := tx.Exec(`UPDATE order_intents SET client_order_id = ? WHERE id = ?`, clientID, id); err != nil {
+		return nil, fmt.Errorf("failed to set client order id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit order intent: %w", err)
+	}
+
+	return db.GetOrderIntent(clientID)
+}
+
+const orderIntentColumns = `
+	id, client_order_id, strategy_id, cycle_id, pair, side, amount, price, target_price,
+	status, external_id, error, created_at, updated_at
+`
+
+// GetOrderIntent retourne une intention par identifiant client, nil si elle n'existe pas.
+func (db *DB) GetOrderIntent(clientOrderID string) (*OrderIntent, error) {
+	row := db.conn.QueryRow(`SELECT `+orderIntentColumns+` FROM order_intents WHERE client_order_id = ?`, clientOrderID)
+	intent, err := scanOrderIntent(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order intent: %w", err)
+	}
+	return intent, nil
+}
+
+// GetPendingOrderIntents retourne les intentions en attente créées avant la date donnée,
+// des plus anciennes aux plus récentes.
+func (db *DB) GetPendingOrderIntents(before time.Time) ([]OrderIntent, error) {
+	rows, err := db.conn.Query(`
+		SELECT `+orderIntentColumns+`
+		FROM order_intents
+		WHERE status = ? AND created_at < ?
+		ORDER BY id
+	`, IntentPending, before.UTC().Format("2006-01-02 15:04:05"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending order intents: %w", err)
+	}
+	defer rows.Close()
+
+	var intents []OrderIntent
+	for rows.Next() {
+		intent, err := scanOrderIntent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order intent: %w", err)
+		}
+		intents = append(intents, *intent)
+	}
+	return intents, rows.Err()
+}
+
+// CountPendingOrderIntents compte les intentions en attente d'une stratégie : tant
+// qu'elles ne sont pas résolues, on ignore si leurs ordres ont été posés.
+func (db *DB) CountPendingOrderIntents(strategyID int) (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM order_intents WHERE strategy_id = ? AND status = ?`, strategyID, IntentPending).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending order intents: %w", err)
+	}
+	return count, nil
+}
+
+// RecordOrderIntent enregistre l'ordre posé pour une intention en attente, en une seule
+// transaction : ligne orders, cycle créé (achat) ou rattaché (vente), intention marquée
+// posée. Une intention déjà posée n'est pas rejouée (erreur).
+func (db *DB) RecordOrderIntent(clientOrderID, externalID string, amount, price float64) (*Order, *CycleEnhanced, error) {
+	intent, err := db.GetOrderIntent(clientOrderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if intent == nil {
+		return nil, nil, fmt.Errorf("order intent %s not found", clientOrderID)
+	}
+	if intent.Status != IntentPending {
+		return nil, nil, fmt.Errorf("order intent %s already %s", clientOrderID, strings.ToLower(string(intent.Status)))
+	}
+
+	// Révision lue hors transaction : elle peut en créer une (connexion distincte)
+	var revisionID *int
+	if intent.Side == Buy {
+		if revisionID, err = db.currentRevision(intent.StrategyID); err != nil {
+			return nil, nil, fmt.Errorf("failed to record order intent: %w", err)
+		}
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO orders (external_id, side, amount, price, fees, status, strategy_id, pair)
+		VALUES (?, ?, ?, ?, 0, ?, ?, ?)
+	`, externalID, intent.Side, amount, price, Pending, intent.StrategyID, intent.Pair)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create order: %w", err)
+	}
+	orderID, err := result.LastInsertId()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	cycleID := int64(intent.CycleID)
+	switch intent.Side {
+	case Buy:
+		result, err = tx.Exec(`INSERT INTO cycles (buy_order_id, target_price, strategy_revision_id) VALUES (?, ?, ?)`,
+			orderID, intent.TargetPrice, revisionID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to create cycle: %w", err)
+		}
+		if cycleID, err = result.LastInsertId(); err != nil {
+			return nil, nil, fmt.Errorf("failed to get last insert id: %w", err)
+		}
+	case Sell:
+		_, err = tx.Exec(`UPDATE cycles SET sell_order_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`, orderID, intent.CycleID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to update cycle sell order: %w", err)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE order_intents SET status = ?, external_id = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE client_order_id = ?
+	`, IntentPlaced, externalID, clientOrderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to update order intent: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit order intent: %w", err)
+	}
+
+	order, err := db.GetOrder(int(orderID))
+	if err != nil {
+		return nil, nil, err
+	}
+	cycle, err := db.GetCycle(int(cycleID))
+	if err != nil {
+		return order, nil, err
+	}
+	return order, cycle, nil
+}
+
+// FailOrderIntent marque une intention en attente comme échouée (ordre refusé par
+// l'exchange, ou introuvable lors de la résolution).
+func (db *DB) FailOrderIntent(clientOrderID, reason string) error {
+	_, err := db.conn.Exec(`
+		UPDATE order_intents SET status = ?, error = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE client_order_id = ? AND status = ?
+	`, IntentFailed, reason, clientOrderID, IntentPending)
+	if err != nil {
+		return fmt.Errorf("failed to update order intent: %w", err)
+	}
+	return nil
+}
+
+func scanOrderIntent(row interface{ Scan(...any) error }) (*OrderIntent, error) {
+	var intent OrderIntent
+	var clientID sql.NullString
+	err := row.Scan(&intent.ID, &clientID, &intent.StrategyID, &intent.CycleID, &intent.Pair, &intent.Side,
+		&intent.Amount, &intent.Price, &intent.TargetPrice, &intent.Status, &intent.ExternalID, &intent.Error,
+		&intent.CreatedAt, &intent.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	intent.ClientOrderID = clientID.String
+	return &intent, nil
+}
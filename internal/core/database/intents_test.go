@@ -0,0 +1,76 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Une intention d'achat enregistrée devient, en une transaction, un ordre en attente et
+// son cycle ; la vente d'une seconde intention est rattachée au cycle.
+func TestOrderIntentLifecycle(t *testing.T) {
+	db := newTestDB(t)
+	strategies, err := db.GetAllStrategies()
+	if err != nil || len(strategies) == 0 {
+		t.Fatalf("stratégie de test introuvable : %v", err)
+	}
+	strategy := strategies[0]
+
+	buy, err := db.CreateOrderIntent(OrderIntent{StrategyID: strategy.ID, Pair: strategy.Pair, Side: Buy, Amount: 0.01, Price: 100, TargetPrice: 102})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.Status != IntentPending || !strings.HasPrefix(buy.ClientOrderID, "sb") || len(buy.ClientOrderID) > 32 {
+		t.Fatalf("intention = %+v", buy)
+	}
+
+	pending, err := db.GetPendingOrderIntents(time.Now().Add(time.Minute))
+	if err != nil || len(pending) != 1 || pending[0].ClientOrderID != buy.ClientOrderID {
+		t.Fatalf("intentions en attente = %+v, %v", pending, err)
+	}
+	if pending, _ := db.GetPendingOrderIntents(time.Now().Add(-time.Minute)); len(pending) != 0 {
+		t.Errorf("intention trop récente retournée : %+v", pending)
+	}
+
+	order, cycle, err := db.RecordOrderIntent(buy.ClientOrderID, "ex-1", 0.01, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ExternalID != "ex-1" || order.Status != Pending || order.Pair != strategy.Pair {
+		t.Errorf("ordre = %+v", order)
+	}
+	if cycle == nil || cycle.BuyOrder.ExternalID != "ex-1" || cycle.TargetPrice != 102 {
+		t.Errorf("cycle = %+v", cycle)
+	}
+	if _, _, err := db.RecordOrderIntent(buy.ClientOrderID, "ex-1", 0.01, 100); err == nil {
+		t.Error("une intention déjà posée ne doit pas être rejouée")
+	}
+	if got, _ := db.GetOrderIntent(buy.ClientOrderID); got.Status != IntentPlaced || got.ExternalID != "ex-1" {
+		t.Errorf("intention après enregistrement = %+v", got)
+	}
+
+	sell, err := db.CreateOrderIntent(OrderIntent{StrategyID: strategy.ID, CycleID: cycle.ID, Pair: strategy.Pair, Side: Sell, Amount: 0.01, Price: 102})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sell.ClientOrderID == buy.ClientOrderID {
+		t.Fatalf("identifiant client réutilisé : %s", sell.ClientOrderID)
+	}
+	if _, linked, err := db.RecordOrderIntent(sell.ClientOrderID, "ex-2", 0.01, 102); err != nil || linked.SellOrder == nil || linked.SellOrder.ExternalID != "ex-2" {
+		t.Fatalf("vente rattachée = %+v, %v", linked, err)
+	}
+
+	failed, err := db.CreateOrderIntent(OrderIntent{StrategyID: strategy.ID, Pair: strategy.Pair, Side: Buy, Amount: 1, Price: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.FailOrderIntent(failed.ClientOrderID, "solde insuffisant"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := db.GetOrderIntent(failed.ClientOrderID); got.Status != IntentFailed || got.Error != "solde insuffisant" {
+		t.Errorf("intention refusée = %+v", got)
+	}
+	if pending, _ := db.GetPendingOrderIntents(time.Now().Add(time.Minute)); len(pending) != 0 {
+		t.Errorf("plus aucune intention en attente attendue : %+v", pending)
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MarketLimits sont les limites de marché d'une paire sur l'exchange, 0 = pas de limite.
+type MarketLimits struct {
+	Pair      string    `json:"pair"`
+	MinAmount float64   `json:"min_amount"` // quantité de base
+	MaxAmount float64   `json:"max_amount"`
+	MinCost   float64   `json:"min_cost"` // montant en devise de cotation (notional)
+	MaxCost   float64   `json:"max_cost"`
+	MinPrice  float64   `json:"min_price"`
+	MaxPrice  float64   `json:"max_price"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SaveMarketLimits enregistre (ou remplace) les limites de marché d'une paire.
+func (db *DB) SaveMarketLimits(l MarketLimits) error {
+	query := `
+		INSERT INTO market_limits (pair, min_amount, max_amount, min_cost, max_cost, min_price, max_price, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(pair) DO UPDATE SET
+			min_amount = excluded.min_amount,
+			max_amount = excluded.max_amount,
+			min_cost = excluded.min_cost,
+			max_cost = excluded.max_cost,
+			min_price = excluded.min_price,
+			max_price = excluded.max_price,
+			updated_at = CURRENT_TIMESTAMP
+	`
+	_, err := db.conn.Exec(query, l.Pair, l.MinAmount, l.MaxAmount, l.MinCost, l.MaxCost, l.MinPrice, l.MaxPrice)
+	if err != nil {
+		return fmt.Errorf("failed to save market limits: %w", err)
+	}
+	return nil
+}
+
+// GetMarketLimits retourne les limites de marché d'une paire, nil si le bot ne les a
+// pas encore relevées.
+func (db *DB) GetMarketLimits(pair string) (*MarketLimits, error) {
+	row := db.conn.QueryRow(`
+		SELECT pair, min_amount, max_amount, min_cost, max_cost, min_price, max_price, updated_at
+		FROM market_limits
+		WHERE pair = ?
+	`, pair)
+	l, err := scanMarketLimits(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market limits: %w", err)
+	}
+	return l, nil
+}
+
+// GetAllMarketLimits retourne les limites de marché de toutes les paires relevées.
+func (db *DB) GetAllMarketLimits() ([]MarketLimits, error) {
+	rows, err := db.conn.Query(`
+		SELECT pair, min_amount, max_amount, min_cost, max_cost, min_price, max_price, updated_at
+		FROM market_limits
+		ORDER BY pair
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get market limits: %w", err)
+	}
+	defer rows.Close()
+
+	var limits []MarketLimits
+	for rows.Next() {
+		l, err := scanMarketLimits(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan market limits: %w", err)
+		}
+		limits = append(limits, *l)
+	}
+	return limits, rows.Err()
+}
+
+func scanMarketLimits(row interface{ Scan(...any) error }) (*MarketLimits, error) {
+	var l MarketLimits
+	err := row.Scan(&l.Pair, &l.MinAmount, &l.MaxAmount, &l.MinCost, &l.MaxCost, &l.MinPrice, &l.MaxPrice, &l.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
@@ -0,0 +1,38 @@
+package database
+
+import "testing"
+
+// Les limites relevées par le bot sont remplacées à chaque rechargement de la paire.
+func TestMarketLimits(t *testing.T) {
+	db := newTestDB(t)
+
+	if l, err := db.GetMarketLimits("BTC/USDC"); err != nil || l != nil {
+		t.Fatalf("paire inconnue : %+v, %v", l, err)
+	}
+
+	if err := db.SaveMarketLimits(MarketLimits{Pair: "BTC/USDC", MinAmount: 0.0001, MinCost: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveMarketLimits(MarketLimits{Pair: "BTC/USDC", MinAmount: 0.0001, MinCost: 1, MaxCost: 100000}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveMarketLimits(MarketLimits{Pair: "ETH/USDC", MinCost: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := db.GetMarketLimits("BTC/USDC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l == nil || l.MinCost != 1 || l.MaxCost != 100000 || l.MinAmount != 0.0001 {
+		t.Errorf("limites = %+v", l)
+	}
+
+	all, err := db.GetAllMarketLimits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2 || all[0].Pair != "BTC/USDC" || all[1].Pair != "ETH/USDC" {
+		t.Errorf("toutes les limites = %+v", all)
+	}
+}
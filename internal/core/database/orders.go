@@ -3,16 +3,18 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
-// CreateOrder creates a new order in the database
+// CreateOrder creates a new order in the database. La paire de l'ordre est celle
+// de sa stratégie.
 func (db *DB) CreateOrder(externalId string, side OrderSide, amount, price, fees float64, strategyId int) (*Order, error) {
 	query := `
-	  INSERT INTO orders (external_id, side, amount, price, fees, status, strategy_id) 
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+	  INSERT INTO orders (external_id, side, amount, price, fees, status, strategy_id, pair) 
+		VALUES (?, ?, ?, ?, ?, ?, ?, COALESCE((SELECT pair FROM strategies WHERE id = ?), ''))
 	`
-	result, err := db.conn.Exec(query, externalId, side, amount, price, fees, Pending, strategyId)
+	result, err := db.conn.Exec(query, externalId, side, amount, price, fees, Pending, strategyId, strategyId)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order: %w", err)
 	}
@@ -28,7 +30,7 @@ func (db *DB) CreateOrder(externalId string, side OrderSide, amount, price, fees
 // GetOrder retrieves an order by ID
 func (db *DB) GetOrder(id int) (*Order, error) {
 	query := `
-		SELECT id, strategy_id, external_id, side, amount, price, fees, status, created_at, updated_at
+		SELECT id, strategy_id, pair, external_id, side, amount, price, fees, status, filled_amount, avg_fill_price, created_at, updated_at
 		FROM orders
 		WHERE id = ?
 	`
@@ -36,8 +38,9 @@ func (db *DB) GetOrder(id int) (*Order, error) {
 
 	var order Order
 	var strategyId sql.NullInt64
-	err := row.Scan(&order.ID, &strategyId, &order.ExternalID, &order.Side, &order.Amount, &order.Price, &order.Fees,
-		&order.Status, &order.CreatedAt, &order.UpdatedAt)
+	var filled, avgPrice sql.NullFloat64
+	err := row.Scan(&order.ID, &strategyId, &order.Pair, &order.ExternalID, &order.Side, &order.Amount, &order.Price, &order.Fees,
+		&order.Status, &filled, &avgPrice, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("db.GetOrder() failed to scan order row: %w", err)
 	}
@@ -46,6 +49,7 @@ func (db *DB) GetOrder(id int) (*Order, error) {
 		id := int(strategyId.Int64)
 		order.StrategyID = &id
 	}
+	order.setFill(filled, avgPrice)
 
 	return &order, nil
 }
@@ -53,7 +57,7 @@ func (db *DB) GetOrder(id int) (*Order, error) {
 // GetOrderByExternalID retrieves an order by external ID
 func (db *DB) GetOrderByExternalID(externalId string) (*Order, error) {
 	query := `
-		SELECT id, external_id, side, amount, price, fees, status, strategy_id, created_at, updated_at 
+		SELECT id, external_id, side, amount, price, fees, status, strategy_id, pair, filled_amount, avg_fill_price, created_at, updated_at 
 		FROM orders 
 		WHERE external_id = ?
 	`
@@ -61,8 +65,9 @@ func (db *DB) GetOrderByExternalID(externalId string) (*Order, error) {
 
 	var order Order
 	var strategyId sql.NullInt64
+	var filled, avgPrice sql.NullFloat64
 	err := row.Scan(&order.ID, &order.ExternalID, &order.Side, &order.Amount, &order.Price, &order.Fees,
-		&order.Status, &strategyId, &order.CreatedAt, &order.UpdatedAt)
+		&order.Status, &strategyId, &order.Pair, &filled, &avgPrice, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get order by external id: %w", err)
 	}
@@ -71,39 +76,49 @@ func (db *DB) GetOrderByExternalID(externalId string) (*Order, error) {
 		id := int(strategyId.Int64)
 		order.StrategyID = &id
 	}
+	order.setFill(filled, avgPrice)
 
 	return &order, nil
 }
 
 // GetPendingOrders retrieves all pending orders
 func (db *DB) GetPendingOrders() ([]Order, error) {
-	return db.GetOrders("pending")
+	return db.GetOrders("pending", "")
 }
 
-// GetOrders retourne les ordres selon le filtre donné :
+// GetOrders retourne les ordres selon le filtre donné, limités à une paire si
+// pair n'est pas vide :
 //   - "pending"   : ordres en attente
 //   - "filled"    : ordres exécutés
 //   - "cancelled" : ordres annulés
 //   - "all"       : tous les ordres
-func (db *DB) GetOrders(filter string) ([]Order, error) {
-	query := `
-		SELECT id, external_id, side, amount, price, fees, status, strategy_id, created_at, updated_at
-		FROM orders
-	`
+func (db *DB) GetOrders(filter, pair string) ([]Order, error) {
+	var conditions []string
+	var args []interface{}
+	direction := "DESC"
 	switch filter {
 	case "pending":
-		query += "WHERE status = 'PENDING'\nORDER BY created_at ASC"
-		return db.executeOrderQuery(query)
+		conditions = append(conditions, "status = 'PENDING'")
+		direction = "ASC"
 	case "filled":
-		query += "WHERE status = 'FILLED'\nORDER BY created_at DESC"
-		return db.executeOrderQuery(query)
+		conditions = append(conditions, "status = 'FILLED'")
 	case "cancelled":
-		query += "WHERE status = 'CANCELLED'\nORDER BY created_at DESC"
-		return db.executeOrderQuery(query)
-	default: // "all"
-		query += "ORDER BY created_at DESC"
-		return db.executeOrderQuery(query)
+		conditions = append(conditions, "status = 'CANCELLED'")
+	}
+	if pair != "" {
+		conditions = append(conditions, "pair = ?")
+		args = append(args, pair)
+	}
+
+	query := `
+		SELECT id, external_id, side, amount, price, fees, status, strategy_id, pair, filled_amount, avg_fill_price, created_at, updated_at
+		FROM orders
+	`
+	if len(conditions) > 0 {
+		query += "WHERE " + strings.Join(conditions, " AND ") + "\n"
 	}
+	query += "ORDER BY created_at " + direction
+	return db.executeOrderQuery(query, args...)
 }
 
 // UpdateOrderStatus updates the status of an order
@@ -118,13 +133,53 @@ func (db *DB) UpdateOrderStatus(externalId string, status OrderStatus) error {
 	if err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
+	if status == Filled || status == Cancelled {
+		return db.recordTrade(externalId)
+	}
+	return nil
+}
+
+// UpdateOrderFill enregistre l'exécution rapportée par l'exchange : quantité remplie
+// et prix moyen d'exécution. Appelé à chaque remplissage (partiel ou total) constaté.
+func (db *DB) UpdateOrderFill(externalId string, filledAmount, avgFillPrice float64) error {
+	query := `
+		UPDATE orders SET
+			filled_amount = ?,
+			avg_fill_price = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE external_id = ?
+	`
+	_, err := db.conn.Exec(query, filledAmount, avgFillPrice, externalId)
+	if err != nil {
+		return fmt.Errorf("failed to update order fill: %w", err)
+	}
+	return nil
+}
+
+// UpdateOrderFees enregistre le total des frais (convertis en devise de cotation)
+// payés sur les trades de l'ordre.
+func (db *DB) UpdateOrderFees(externalId string, fees float64) error {
+	query := `
+		UPDATE orders SET
+			fees = ?,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE external_id = ?
+	`
+	_, err := db.conn.Exec(query, fees, externalId)
+	if err != nil {
+		return fmt.Errorf("failed to update order fees: %w", err)
+	}
+	// Frais connus après l'exécution : le grand livre suit
+	if _, err := db.conn.Exec(`UPDATE trades SET fee = ? WHERE order_id = ?`, fees, externalId); err != nil {
+		return fmt.Errorf("failed to update trade fees: %w", err)
+	}
 	return nil
 }
 
 // GetOldOrders retrieves old orders (older than the specified time)
 func (db *DB) GetOldOrders(olderThan time.Time) ([]Order, error) {
 	query := `
-		SELECT id, external_id, side, amount, price, fees, status, strategy_id, created_at, updated_at
+		SELECT id, external_id, side, amount, price, fees, status, strategy_id, pair, filled_amount, avg_fill_price, created_at, updated_at
 		FROM orders
 		WHERE status = ? AND created_at < ?
 	`
@@ -133,7 +188,7 @@ func (db *DB) GetOldOrders(olderThan time.Time) ([]Order, error) {
 
 // GetAllOrders retrieves all orders (not just pending ones)
 func (db *DB) GetAllOrders() ([]Order, error) {
-	return db.GetOrders("all")
+	return db.GetOrders("all", "")
 }
 
 // GetOrdersWithPagination retrieves orders with pagination
@@ -155,7 +210,7 @@ func (db *DB) GetOrdersWithPagination(status OrderStatus, limit, offset int) ([]
 
 	// Retrieve orders with the common function
 	query := `
-		SELECT id, external_id, side, amount, price, fees, status, strategy_id, created_at, updated_at
+		SELECT id, external_id, side, amount, price, fees, status, strategy_id, pair, filled_amount, avg_fill_price, created_at, updated_at
 		FROM orders
 	`
 
@@ -206,5 +261,20 @@ func (db *DB) CleanupOldData(olderThanDays int) error {
 		fmt.Printf("Cleaned up %d old orders\n", rowsAffected)
 	}
 
+	// Remove old buy skip episodes (risk limits)
+	if _, err := db.conn.Exec(`DELETE FROM buy_skips WHERE updated_at < ?`, cutoffDate); err != nil {
+		return fmt.Errorf("failed to cleanup old buy skips: %w", err)
+	}
+
+	// Remove old lifecycle events
+	if _, err := db.conn.Exec(`DELETE FROM events WHERE created_at < ?`, cutoffDate); err != nil {
+		return fmt.Errorf("failed to cleanup old events: %w", err)
+	}
+
+	// Remove resolved order intents (pending ones are kept until resolved)
+	if _, err := db.conn.Exec(`DELETE FROM order_intents WHERE status != ? AND updated_at < ?`, IntentPending, cutoffDate); err != nil {
+		return fmt.Errorf("failed to cleanup old order intents: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+// Un achat partiellement rempli puis clôturé (statut FILLED avec filled_amount)
+// donne un cycle Open sur la quantité réellement achetée ; le profit du cycle
+// terminé est calculé sur les quantités et prix exécutés, en Go comme en SQL.
+func TestPartialFill_CycleUsesExecutedAmount(t *testing.T) {
+	db := newTestDB(t)
+	const strategyID = 1 // « Legacy Strategy » semée par la migration 9
+
+	buy, err := db.CreateOrder("buy-1", Buy, 2.0, 100.0, 0, strategyID)
+	if err != nil {
+		t.Fatalf("CreateOrder (buy) : %v", err)
+	}
+	if buy.FilledAmount != nil || buy.ExecutedAmount() != 2.0 {
+		t.Fatalf("ordre neuf : filled=%v executed=%v, attendu nil / 2", buy.FilledAmount, buy.ExecutedAmount())
+	}
+	cycle, err := db.CreateCycle(buy.ID, 102.0)
+	if err != nil {
+		t.Fatalf("CreateCycle : %v", err)
+	}
+
+	// Remplissage partiel (0.5 @ 99.8) puis annulation du reste : l'achat est FILLED.
+	if err := db.UpdateOrderFill("buy-1", 0.5, 99.8); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("buy-1", Filled); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := db.GetCycle(cycle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Status != Open {
+		t.Errorf("statut du cycle = %s, attendu %s", c.Status, Open)
+	}
+	if c.BuyOrder.ExecutedAmount() != 0.5 || c.BuyOrder.ExecutedPrice() != 99.8 {
+		t.Errorf("achat exécuté = %v @ %v, attendu 0.5 @ 99.8", c.BuyOrder.ExecutedAmount(), c.BuyOrder.ExecutedPrice())
+	}
+	if c.BuyOrder.Amount != 2.0 {
+		t.Errorf("quantité demandée = %v, attendu 2 (conservée)", c.BuyOrder.Amount)
+	}
+
+	// Vente de la quantité détenue, remplie au-dessus de la limite.
+	sell, err := db.CreateOrder("sell-1", Sell, 0.5, 110.0, 0, strategyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateCycleSellOrder(cycle.ID, sell.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderFill("sell-1", 0.5, 110.2); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("sell-1", Filled); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err = db.GetCycle(cycle.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := (110.2 - 99.8) * 0.5
+	if c.Status != Completed || c.Profit == nil || math.Abs(*c.Profit-want) > 1e-9 {
+		t.Fatalf("cycle terminé : statut=%s profit=%v, attendu %s / %.4f", c.Status, c.Profit, Completed, want)
+	}
+
+	stats, err := db.GetStats("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := stats["total_profit"].(float64); math.Abs(total-want) > 1e-9 {
+		t.Errorf("total_profit = %.6f, attendu %.6f", total, want)
+	}
+}
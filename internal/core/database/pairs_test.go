@@ -0,0 +1,122 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+)
+
+// createPairStrategy crée une stratégie RSI DCA minimale sur la paire donnée.
+func (db *DB) createPairStrategy(t *testing.T, name, pair string) int {
+	t.Helper()
+	if err := db.CreateStrategyFromWeb(ChangeSource{Origin: OriginWeb},
+		name, "test", pair, "rsi_dca", "0 */4 * * *", 0, true,
+		25.0, 2.0, 0.1, 0.1,
+		nil, nil, "4h",
+		12, 26, 9, "4h",
+		20, 2.0, "1h",
+		nil, nil, "4h",
+		false, nil, nil, "1d",
+		false, nil, nil, nil, nil,
+		0, 0, "", 0,
+		0, 0, 0,
+		1, 0, 0,
+	); err != nil {
+		t.Fatalf("CreateStrategyFromWeb (%s) : %v", name, err)
+	}
+	return db.mustStrategyID(t, name)
+}
+
+// Les lignes antérieures au multi-paires (paire vide) sont rattachées à la paire
+// par défaut ; les ordres créés ensuite héritent de la paire de leur stratégie, et
+// ordres, cycles et statistiques se filtrent par paire.
+func TestPairs_DefaultAndFilters(t *testing.T) {
+	db := newTestDB(t)
+	const legacyID = 1 // « Legacy Strategy » semée par la migration 9, sans paire
+
+	legacyBuy, err := db.CreateOrder("legacy-buy", Buy, 0.01, 60000, 0, legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if legacyBuy.Pair != "" {
+		t.Fatalf("ordre legacy : paire %q, attendu vide avant SetDefaultPair", legacyBuy.Pair)
+	}
+	if _, err := db.CreateCycle(legacyBuy.ID, 61000); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.SetDefaultPair("BTC/USDC"); err != nil {
+		t.Fatalf("SetDefaultPair : %v", err)
+	}
+	if o, _ := db.GetOrder(legacyBuy.ID); o.Pair != "BTC/USDC" {
+		t.Errorf("ordre legacy : paire %q, attendu BTC/USDC", o.Pair)
+	}
+	if s, _ := db.GetStrategy(legacyID); s.Pair != "BTC/USDC" {
+		t.Errorf("stratégie legacy : paire %q, attendu BTC/USDC", s.Pair)
+	}
+
+	ethID := db.createPairStrategy(t, "ETH DCA", "ETH/USDC")
+	ethBuy, err := db.CreateOrder("eth-buy", Buy, 0.5, 3000, 0, ethID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ethBuy.Pair != "ETH/USDC" {
+		t.Errorf("ordre ETH : paire %q, attendu ETH/USDC (héritée de la stratégie)", ethBuy.Pair)
+	}
+	if _, err := db.CreateCycle(ethBuy.ID, 3100); err != nil {
+		t.Fatal(err)
+	}
+
+	pairs, err := db.GetStrategyPairs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"BTC/USDC", "ETH/USDC"}; !reflect.DeepEqual(pairs, want) {
+		t.Errorf("GetStrategyPairs = %v, attendu %v", pairs, want)
+	}
+
+	for pair, want := range map[string]int{"": 2, "BTC/USDC": 1, "ETH/USDC": 1, "SOL/USDC": 0} {
+		orders, err := db.GetOrders("all", pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != want {
+			t.Errorf("GetOrders(all, %q) : %d ordres, attendu %d", pair, len(orders), want)
+		}
+		for _, o := range orders {
+			if pair != "" && o.Pair != pair {
+				t.Errorf("GetOrders(all, %q) : ordre %s sur %s", pair, o.ExternalID, o.Pair)
+			}
+		}
+
+		cycles, err := db.GetCycles("active", pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cycles) != want {
+			t.Errorf("GetCycles(active, %q) : %d cycles, attendu %d", pair, len(cycles), want)
+		}
+
+		stats, err := db.GetStats(pair)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats["pending_orders"] != want || stats["cycles_count"] != want {
+			t.Errorf("GetStats(%q) : %v ordres / %v cycles, attendu %d", pair, stats["pending_orders"], stats["cycles_count"], want)
+		}
+	}
+
+	if err := db.UpdateStrategy(ChangeSource{Origin: OriginWeb}, ethID, "ETH DCA", "test", "", "rsi_dca", "0 */4 * * *", 0, true,
+		25.0, 2.0, 0.1, 0.1,
+		nil, nil, "4h",
+		12, 26, 9, "4h",
+		20, 2.0, "1h",
+		nil, nil, "4h",
+		false, nil, nil, "1d",
+		false, nil, nil, nil, nil,
+		0, 0, "", 0,
+		0, 0, 0,
+		1, 0, 0,
+	); err == nil {
+		t.Error("UpdateStrategy sans paire accepté")
+	}
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// Origine d'une modification de stratégie (StrategyRevision.Origin)
+const (
+	OriginWeb    = "web"    // formulaires et boutons de la WebUI
+	OriginAPI    = "api"    // appels directs aux routes /api
+	OriginImport = "import" // simple-bot strategy import
+	OriginChat   = "chat"   // assistant IA
+	OriginSystem = "system" // révision de référence créée par le bot lui-même
+)
+
+// Action enregistrée par une révision de stratégie
+const (
+	RevisionCreate = "create"
+	RevisionUpdate = "update"
+	RevisionToggle = "toggle"
+	RevisionDelete = "delete"
+	// RevisionSnapshot fige la configuration d'une stratégie antérieure à l'historique,
+	// au premier cycle ouvert : chaque cycle peut ainsi être rattaché à une révision.
+	RevisionSnapshot = "snapshot"
+)
+
+// ChangeSource identifie l'auteur d'une modification de stratégie, enregistré dans
+// son historique.
+type ChangeSource struct {
+	Origin  string // OriginWeb, OriginAPI, OriginImport, OriginChat
+	User    string // utilisateur WebUI, ou utilisateur système pour un import
+	Comment string // ex. « retour à la révision #12 », fichier importé
+}
+
+// StrategyRevision est une entrée de l'historique d'une stratégie : la configuration
+// avant et après le changement (Before nil pour une création, After nil pour une
+// suppression), et le bilan des cycles dont l'achat a été placé sous cette révision.
+type StrategyRevision struct {
+	ID              int       `json:"id"`
+	StrategyID      int       `json:"strategy_id"`
+	Action          string    `json:"action"`
+	Origin          string    `json:"origin"`
+	User            string    `json:"user"`
+	Comment         string    `json:"comment"`
+	Before          *Strategy `json:"before,omitempty"`
+	After           *Strategy `json:"after,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+	Cycles          int       `json:"cycles"`
+	CompletedCycles int       `json:"completed_cycles"`
+	RealizedProfit  float64   `json:"realized_profit"`
+}
+
+// dbtx est implémenté par *sql.DB et *sql.Tx
+type dbtx interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// getStrategy lit une stratégie par ID, dans une transaction ou non.
+func getStrategy(q dbtx, id int) (*Strategy, error) {
+	s, err := scanStrategy(q.QueryRow(`SELECT`+strategyColumns+` FROM strategies WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("strategy not found")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy: %w", err)
+	}
+	return &s, nil
+}
+
+// sameConfig compare deux configurations en ignorant l'état d'exécution (ID, dates).
+func sameConfig(a, b Strategy) bool {
+	for _, s := range []*Strategy{&a, &b} {
+		s.ID = 0
+		s.LastExecutedAt, s.NextExecutionAt = nil, nil
+		s.CreatedAt, s.UpdatedAt = time.Time{}, time.Time{}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// recordRevision ajoute une révision à l'historique de la stratégie.
+func recordRevision(q dbtx, strategyID int, action string, src ChangeSource, before, after *Strategy) (int, error) {
+	var beforeJSON, afterJSON []byte
+	var err error
+	if before != nil {
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return 0, fmt.Errorf("failed to encode strategy revision: %w", err)
+		}
+	}
+	if after != nil {
+		if afterJSON, err = json.Marshal(after); err != nil {
+			return 0, fmt.Errorf("failed to encode strategy revision: %w", err)
+		}
+	}
+
+	result, err := q.Exec(`
+		INSERT INTO strategy_revisions (strategy_id, action, origin, username, comment, before_json, after_json)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		strategyID, action, src.Origin, src.User, src.Comment, nullableJSON(beforeJSON), nullableJSON(afterJSON))
+	if err != nil {
+		return 0, fmt.Errorf("failed to record strategy revision: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	return int(id), nil
+}
+
+func nullableJSON(data []byte) any {
+	if data == nil {
+		return nil
+	}
+	return string(data)
+}
+
+// currentRevisionForOrder retourne la révision active de la stratégie d'un ordre
+// (nil pour un ordre sans stratégie). Une stratégie antérieure à l'historique reçoit
+// d'abord une révision de référence (RevisionSnapshot).
+func (db *DB) currentRevisionForOrder(orderID int) (*int, error) {
+	var strategyID sql.NullInt64
+	err := db.conn.QueryRow(`SELECT strategy_id FROM orders WHERE id = ?`, orderID).Scan(&strategyID)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get order strategy: %w", err)
+	}
+	if !strategyID.Valid {
+		return nil, nil
+	}
+	return db.currentRevision(int(strategyID.Int64))
+}
+
+// currentRevision retourne la révision active d'une stratégie, créée au besoin comme
+// pour currentRevisionForOrder.
+func (db *DB) currentRevision(strategyID int) (*int, error) {
+	var revisionID sql.NullInt64
+	err := db.conn.QueryRow(`SELECT MAX(id) FROM strategy_revisions WHERE strategy_id = ?`, strategyID).Scan(&revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current strategy revision: %w", err)
+	}
+	if revisionID.Valid {
+		id := int(revisionID.Int64)
+		return &id, nil
+	}
+
+	s, err := getStrategy(db.conn, strategyID)
+	if err != nil {
+		return nil, nil // stratégie supprimée entre-temps : cycle sans révision
+	}
+	id, err := recordRevision(db.conn, s.ID, RevisionSnapshot, ChangeSource{Origin: OriginSystem}, nil, s)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+const revisionColumns = `
+	r.id, r.strategy_id, r.action, r.origin, r.username, r.comment, r.before_json, r.after_json, r.created_at,
+	COUNT(c.id),
+	COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND so.status = 'FILLED' THEN 1 ELSE 0 END), 0),
+	COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND so.status = 'FILLED' THEN
+		(COALESCE(so.avg_fill_price, so.price) - COALESCE(bo.avg_fill_price, bo.price)) * COALESCE(so.filled_amount, so.amount) - bo.fees - so.fees
+	END), 0)`
+
+const revisionJoins = `
+	FROM strategy_revisions r
+	LEFT JOIN (cycles c JOIN orders bo ON c.buy_order_id = bo.id) ON c.strategy_revision_id = r.id
+	LEFT JOIN orders so ON c.sell_order_id = so.id`
+
+func scanRevision(row rowScanner) (StrategyRevision, error) {
+	var r StrategyRevision
+	var before, after sql.NullString
+	err := row.Scan(&r.ID, &r.StrategyID, &r.Action, &r.Origin, &r.User, &r.Comment, &before, &after, &r.CreatedAt,
+		&r.Cycles, &r.CompletedCycles, &r.RealizedProfit)
+	if err != nil {
+		return r, err
+	}
+	for _, snap := range []struct {
+		raw  sql.NullString
+		dest **Strategy
+	}{{before, &r.Before}, {after, &r.After}} {
+		if !snap.raw.Valid {
+			continue
+		}
+		var s Strategy
+		if err := json.Unmarshal([]byte(snap.raw.String), &s); err != nil {
+			return r, fmt.Errorf("failed to decode strategy revision %d: %w", r.ID, err)
+		}
+		*snap.dest = &s
+	}
+	return r, nil
+}
+
+// GetStrategyRevisions retourne l'historique d'une stratégie, de la plus récente à la
+// plus ancienne révision.
+func (db *DB) GetStrategyRevisions(strategyID int) ([]StrategyRevision, error) {
+	rows, err := db.conn.Query(`SELECT`+revisionColumns+revisionJoins+`
+		WHERE r.strategy_id = ?
+		GROUP BY r.id
+		ORDER BY r.id DESC`, strategyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []StrategyRevision
+	for rows.Next() {
+		r, err := scanRevision(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan strategy revision: %w", err)
+		}
+		revisions = append(revisions, r)
+	}
+	return revisions, rows.Err()
+}
+
+// GetStrategyRevision retourne une révision par ID (nil si elle n'existe pas).
+func (db *DB) GetStrategyRevision(id int) (*StrategyRevision, error) {
+	r, err := scanRevision(db.conn.QueryRow(`SELECT`+revisionColumns+revisionJoins+`
+		WHERE r.id = ?
+		GROUP BY r.id`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy revision: %w", err)
+	}
+	return &r, nil
+}
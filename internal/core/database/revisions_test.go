@@ -0,0 +1,108 @@
+package database
+
+import "testing"
+
+// Création, modification, bascule et suppression sont historisées avec leur origine ;
+// une sauvegarde sans changement ne crée pas de révision.
+func TestStrategyRevisions_History(t *testing.T) {
+	db := newTestDB(t)
+	id := db.createPairStrategy(t, "BTC DCA", "BTC/USDC")
+
+	alice := ChangeSource{Origin: OriginWeb, User: "alice"}
+	update := func(profitTarget float64) {
+		t.Helper()
+		if err := db.UpdateStrategy(alice, id, "BTC DCA", "test", "BTC/USDC", "rsi_dca", "0 */4 * * *", 0, true,
+			25.0, profitTarget, 0.1, 0.1,
+			nil, nil, "4h",
+			12, 26, 9, "4h",
+			20, 2.0, "1h",
+			nil, nil, "4h",
+			false, nil, nil, "1d",
+			false, nil, nil, nil, nil,
+			0, 0, "", 0,
+			0, 0, 0,
+			1, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	update(2.0) // identique à la création
+	update(3.0)
+	if err := db.ToggleStrategyEnabled(alice, id); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := db.GetStrategyRevisions(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var actions []string
+	for _, r := range revisions {
+		actions = append(actions, r.Action)
+	}
+	if len(actions) != 3 || actions[0] != RevisionToggle || actions[1] != RevisionUpdate || actions[2] != RevisionCreate {
+		t.Fatalf("actions = %v, attendu [toggle update create]", actions)
+	}
+	upd := revisions[1]
+	if upd.User != "alice" || upd.Origin != OriginWeb || upd.Before.ProfitTarget != 2.0 || upd.After.ProfitTarget != 3.0 {
+		t.Errorf("révision de modification : %+v", upd)
+	}
+	if revisions[2].Before != nil || revisions[0].After.Enabled {
+		t.Error("snapshots avant/après incorrects")
+	}
+
+	if err := db.DeleteStrategy(alice, id); err != nil {
+		t.Fatal(err)
+	}
+	revisions, _ = db.GetStrategyRevisions(id)
+	if len(revisions) != 4 || revisions[0].Action != RevisionDelete || revisions[0].After != nil || revisions[0].Before.Name != "BTC DCA" {
+		t.Errorf("historique après suppression : %+v", revisions[0])
+	}
+}
+
+// Chaque cycle retient la révision active à l'achat ; une stratégie antérieure à
+// l'historique reçoit une révision de référence à son premier cycle.
+func TestStrategyRevisions_CycleLink(t *testing.T) {
+	db := newTestDB(t)
+	const legacyID = 1 // « Legacy Strategy » semée par la migration 9
+
+	buy, err := db.CreateOrder("legacy-buy", Buy, 1, 100, 0.1, legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateCycle(buy.ID, 110); err != nil {
+		t.Fatal(err)
+	}
+	sell, err := db.CreateOrder("legacy-sell", Sell, 1, 110, 0.1, legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateCycleSellOrderForBuyOrder(buy.ID, sell.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{"legacy-buy", "legacy-sell"} {
+		if err := db.UpdateOrderStatus(ext, Filled); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	revisions, err := db.GetStrategyRevisions(legacyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 1 || revisions[0].Action != RevisionSnapshot || revisions[0].Origin != OriginSystem {
+		t.Fatalf("révision de référence attendue : %+v", revisions)
+	}
+	r := revisions[0]
+	if r.Cycles != 1 || r.CompletedCycles != 1 || r.RealizedProfit != 9.8 {
+		t.Errorf("bilan de la révision : %d cycles, %d terminés, profit %.2f", r.Cycles, r.CompletedCycles, r.RealizedProfit)
+	}
+
+	// Un second cycle réutilise la même révision
+	buy2, _ := db.CreateOrder("legacy-buy-2", Buy, 1, 100, 0.1, legacyID)
+	if _, err := db.CreateCycle(buy2.ID, 110); err != nil {
+		t.Fatal(err)
+	}
+	if revisions, _ := db.GetStrategyRevisions(legacyID); len(revisions) != 1 || revisions[0].Cycles != 2 {
+		t.Errorf("second cycle : %+v", revisions)
+	}
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// BuySkip est un épisode de refus d'achat par une limite de risque : les refus
+// successifs d'une même limite, sans achat de la stratégie entre-temps, sont cumulés.
+type BuySkip struct {
+	ID         int       `json:"id"`
+	StrategyID int       `json:"strategy_id"`
+	Limit      string    `json:"limit"`
+	Reason     string    `json:"reason"` // raison du dernier refus
+	Amount     float64   `json:"amount"` // montant quote de l'achat refusé
+	Count      int       `json:"count"`
+	CreatedAt  time.Time `json:"created_at"` // premier refus de l'épisode
+	UpdatedAt  time.Time `json:"updated_at"` // dernier refus
+}
+
+// GetOpenCapital retourne le capital quote engagé par stratégie : notional (quantité ×
+// prix limite) des cycles actifs, achats en attente compris, comme pour le plafond de
+// cycles concurrents.
+func (db *DB) GetOpenCapital() (map[int]float64, error) {
+	query := `
+		SELECT bo.strategy_id, SUM(bo.amount * bo.price)
+		FROM cycles c
+		JOIN orders bo ON c.buy_order_id = bo.id
+		LEFT JOIN orders so ON c.sell_order_id = so.id
+		WHERE (bo.status = 'PENDING') OR (
+			(bo.status = 'FILLED') AND (c.sell_order_id IS NULL OR so.status <> 'FILLED')
+		)
+		GROUP BY bo.strategy_id
+	`
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get open capital: %w", err)
+	}
+	defer rows.Close()
+
+	capital := make(map[int]float64)
+	for rows.Next() {
+		var strategyID int
+		var notional float64
+		if err := rows.Scan(&strategyID, &notional); err != nil {
+			return nil, fmt.Errorf("failed to scan open capital: %w", err)
+		}
+		capital[strategyID] = notional
+	}
+	return capital, rows.Err()
+}
+
+// GetBuySpendSince retourne le montant quote des achats posés depuis since, toutes
+// stratégies confondues. Un achat annulé ne compte que pour sa partie exécutée.
+func (db *DB) GetBuySpendSince(since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(CASE
+			WHEN status = 'CANCELLED' THEN COALESCE(filled_amount, 0) * COALESCE(avg_fill_price, price)
+			ELSE amount * price
+		END), 0)
+		FROM orders
+		WHERE side = ? AND created_at >= ?
+	`
+	var spend float64
+	if err := db.conn.QueryRow(query, Buy, since.UTC()).Scan(&spend); err != nil {
+		return 0, fmt.Errorf("failed to get buy spend: %w", err)
+	}
+	return spend, nil
+}
+
+// RecordBuySkip enregistre un achat refusé par une limite. Si le dernier épisode de la
+// stratégie concerne la même limite et qu'aucun achat n'a été posé depuis son début,
+// il est prolongé et repeated vaut true (pour ne notifier qu'une fois par épisode).
+func (db *DB) RecordBuySkip(strategyID int, limit, reason string, amount float64) (repeated bool, err error) {
+	var id int
+	err = db.conn.QueryRow(`
+		SELECT b.id FROM buy_skips b
+		WHERE b.strategy_id = ? AND b.limit_name = ?
+			AND b.id = (SELECT MAX(id) FROM buy_skips WHERE strategy_id = b.strategy_id)
+			AND NOT EXISTS (
+				SELECT 1 FROM orders o
+				WHERE o.strategy_id = b.strategy_id AND o.side = ? AND o.created_at > b.created_at
+			)
+	`, strategyID, limit, Buy).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		_, err = db.conn.Exec(`INSERT INTO buy_skips (strategy_id, limit_name, reason, amount) VALUES (?, ?, ?, ?)`,
+			strategyID, limit, reason, amount)
+		if err != nil {
+			return false, fmt.Errorf("failed to record buy skip: %w", err)
+		}
+		return false, nil
+	case err != nil:
+		return false, fmt.Errorf("failed to get last buy skip: %w", err)
+	}
+
+	_, err = db.conn.Exec(`
+		UPDATE buy_skips SET reason = ?, amount = ?, count = count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, reason, amount, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to update buy skip: %w", err)
+	}
+	return true, nil
+}
+
+// GetActiveBuySkips retourne, par stratégie, l'épisode de refus en cours : le dernier,
+// s'il n'a été suivi d'aucun achat.
+func (db *DB) GetActiveBuySkips() (map[int]BuySkip, error) {
+	rows, err := db.conn.Query(`
+		SELECT b.id, b.strategy_id, b.limit_name, b.reason, b.amount, b.count, b.created_at, b.updated_at
+		FROM buy_skips b
+		WHERE b.id = (SELECT MAX(id) FROM buy_skips WHERE strategy_id = b.strategy_id)
+			AND NOT EXISTS (
+				SELECT 1 FROM orders o
+				WHERE o.strategy_id = b.strategy_id AND o.side = ? AND o.created_at > b.updated_at
+			)
+	`, Buy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get buy skips: %w", err)
+	}
+	defer rows.Close()
+
+	skips := make(map[int]BuySkip)
+	for rows.Next() {
+		var s BuySkip
+		if err := rows.Scan(&s.ID, &s.StrategyID, &s.Limit, &s.Reason, &s.Amount, &s.Count, &s.CreatedAt, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan buy skip: %w", err)
+		}
+		skips[s.StrategyID] = s
+	}
+	return skips, rows.Err()
+}
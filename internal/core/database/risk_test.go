@@ -0,0 +1,113 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+// Capital engagé : notional des cycles actifs (achat en attente ou position non vendue) ;
+// dépense du jour : achats posés, hors partie non exécutée des achats annulés.
+func TestRisk_OpenCapitalAndSpend(t *testing.T) {
+	db := newTestDB(t)
+	a := db.createPairStrategy(t, "A", "BTC/USDC")
+	b := db.createPairStrategy(t, "B", "ETH/USDC")
+
+	buy := func(ext string, amount, price float64, strategyID int) *Order {
+		t.Helper()
+		o, err := db.CreateOrder(ext, Buy, amount, price, 0, strategyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.CreateCycle(o.ID, price*1.02); err != nil {
+			t.Fatal(err)
+		}
+		return o
+	}
+	buy("a-pending", 0.1, 100, a)
+	closed := buy("a-closed", 0.2, 100, a)
+	buy("b-pending", 0.5, 100, b)
+
+	sell, err := db.CreateOrder("a-sell", Sell, 0.2, 102, 0, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateCycleSellOrderForBuyOrder(closed.ID, sell.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, ext := range []string{"a-closed", "a-sell"} {
+		if err := db.UpdateOrderStatus(ext, Filled); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	capital, err := db.GetOpenCapital()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if capital[a] != 10 || capital[b] != 50 {
+		t.Errorf("capital engagé = %v, attendu A=10 B=50", capital)
+	}
+
+	midnight := time.Now().UTC().Truncate(24 * time.Hour)
+	if spend, _ := db.GetBuySpendSince(midnight); spend != 80 {
+		t.Errorf("dépense du jour = %.2f, attendu 80", spend)
+	}
+
+	// Achat annulé sans exécution : ni capital engagé, ni dépense
+	if err := db.UpdateOrderStatus("b-pending", Cancelled); err != nil {
+		t.Fatal(err)
+	}
+	capital, _ = db.GetOpenCapital()
+	if _, ok := capital[b]; ok {
+		t.Errorf("achat annulé compté dans le capital engagé : %v", capital)
+	}
+	if spend, _ := db.GetBuySpendSince(midnight); spend != 30 {
+		t.Errorf("dépense après annulation = %.2f, attendu 30", spend)
+	}
+	if spend, _ := db.GetBuySpendSince(time.Now().Add(time.Hour)); spend != 0 {
+		t.Errorf("dépense future = %.2f, attendu 0", spend)
+	}
+}
+
+// Les refus successifs d'une même limite forment un épisode, clos par un achat.
+func TestRisk_BuySkipEpisodes(t *testing.T) {
+	db := newTestDB(t)
+	id := db.createPairStrategy(t, "A", "BTC/USDC")
+
+	record := func(limit string) bool {
+		t.Helper()
+		repeated, err := db.RecordBuySkip(id, limit, "raison "+limit, 25)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return repeated
+	}
+	if record("daily_spend") || !record("daily_spend") {
+		t.Fatal("le second refus de la même limite doit prolonger l'épisode")
+	}
+	if record("quote_reserve") {
+		t.Fatal("une autre limite ouvre un nouvel épisode")
+	}
+
+	skips, err := db.GetActiveBuySkips()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := skips[id]; s.Limit != "quote_reserve" || s.Count != 1 || s.Reason != "raison quote_reserve" {
+		t.Errorf("épisode en cours : %+v", s)
+	}
+
+	// Un achat posé après le refus clôt l'épisode
+	if _, err := db.conn.Exec(`UPDATE buy_skips SET created_at = datetime('now', '-1 hour'), updated_at = datetime('now', '-1 hour')`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.CreateOrder("a-buy", Buy, 0.1, 100, 0, id); err != nil {
+		t.Fatal(err)
+	}
+	if skips, _ := db.GetActiveBuySkips(); len(skips) != 0 {
+		t.Errorf("épisode toujours actif après un achat : %+v", skips)
+	}
+	if record("quote_reserve") {
+		t.Error("un refus après un achat ouvre un nouvel épisode")
+	}
+}
@@ -1,18 +1,20 @@
 package database
 
 import (
-	"database/sql"
 	"fmt"
 	"time"
+
+	"bot/internal/decimal"
 )
 
-// GetStats retrieves general database statistics
-func (db *DB) GetStats() (map[string]interface{}, error) {
+// GetStats retrieves general database statistics, limitées à une paire si pair
+// n'est pas vide.
+func (db *DB) GetStats(pair string) (map[string]interface{}, error) {
 	stats := make(map[string]interface{})
 
 	// Nombre d'ordres en attente
 	var pendingCount int
-	err := db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ?`, Pending).Scan(&pendingCount)
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ? AND (? = '' OR pair = ?)`, Pending, pair, pair).Scan(&pendingCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get pending orders count: %w", err)
 	}
@@ -20,7 +22,7 @@ func (db *DB) GetStats() (map[string]interface{}, error) {
 
 	// Nombre d'ordres exécutés
 	var filledCount int
-	err = db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ?`, Filled).Scan(&filledCount)
+	err = db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ? AND (? = '' OR pair = ?)`, Filled, pair, pair).Scan(&filledCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get filled orders count: %w", err)
 	}
@@ -28,7 +30,7 @@ func (db *DB) GetStats() (map[string]interface{}, error) {
 
 	// Nombre d'ordres annulés
 	var cancelledCount int
-	err = db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ?`, Cancelled).Scan(&cancelledCount)
+	err = db.conn.QueryRow(`SELECT COUNT(*) FROM orders WHERE status = ? AND (? = '' OR pair = ?)`, Cancelled, pair, pair).Scan(&cancelledCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cancelled orders count: %w", err)
 	}
@@ -36,7 +38,11 @@ func (db *DB) GetStats() (map[string]interface{}, error) {
 
 	// Nombre de cycles
 	var cyclesCount int
-	err = db.conn.QueryRow(`SELECT COUNT(*) FROM cycles`).Scan(&cyclesCount)
+	err = db.conn.QueryRow(`
+		SELECT COUNT(*)
+		FROM cycles c
+		JOIN orders bo ON c.buy_order_id = bo.id
+		WHERE (? = '' OR bo.pair = ?)`, pair, pair).Scan(&cyclesCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cycles count: %w", err)
 	}
@@ -50,7 +56,8 @@ func (db *DB) GetStats() (map[string]interface{}, error) {
 		FROM cycles c
 		JOIN orders bo ON c.buy_order_id = bo.id
 		LEFT JOIN orders so ON c.sell_order_id = so.id
-		WHERE (c.sell_order_id IS NULL AND bo.status <> 'CANCELLED') OR so.status <> 'FILLED'`).Scan(&activeCyclesCount)
+		WHERE ((c.sell_order_id IS NULL AND bo.status <> 'CANCELLED') OR so.status <> 'FILLED')
+		  AND (? = '' OR bo.pair = ?)`, pair, pair).Scan(&activeCyclesCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get active cycles count: %w", err)
 	}
@@ -62,118 +69,100 @@ func (db *DB) GetStats() (map[string]interface{}, error) {
 		SELECT COUNT(*)
 		FROM cycles c
 		JOIN orders so ON c.sell_order_id = so.id
-		WHERE so.status = 'FILLED'`).Scan(&completedCyclesCount)
+		WHERE so.status = 'FILLED' AND (? = '' OR so.pair = ?)`, pair, pair).Scan(&completedCyclesCount)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get completed cycles count: %w", err)
 	}
 	stats["completed_cycles_count"] = completedCyclesCount
 
-	// Calcul du profit moyen (uniquement le profit réalisé : achat et vente exécutés)
-	var avgProfit sql.NullFloat64
-	err = db.conn.QueryRow(`
-		SELECT AVG((so.price - bo.price) * bo.amount - bo.fees - so.fees)
-		FROM cycles c
-		JOIN orders bo ON c.buy_order_id = bo.id
-		JOIN orders so ON c.sell_order_id = so.id
-		WHERE bo.status = 'FILLED' AND so.status = 'FILLED'
-	`).Scan(&avgProfit)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get average profit: %w", err)
-	}
-	if avgProfit.Valid {
-		stats["average_profit"] = avgProfit.Float64
-	} else {
-		stats["average_profit"] = 0.0
-	}
-
-	// Calcul du profit total (uniquement le profit réalisé : achat et vente exécutés)
-	var totalProfit sql.NullFloat64
-	err = db.conn.QueryRow(`
-		SELECT SUM((so.price - bo.price) * bo.amount - bo.fees - so.fees)
-		FROM cycles c
-		JOIN orders bo ON c.buy_order_id = bo.id
-		JOIN orders so ON c.sell_order_id = so.id
-		WHERE bo.status = 'FILLED' AND so.status = 'FILLED'
-	`).Scan(&totalProfit)
+	// Profit moyen et total (uniquement le profit réalisé : achat et vente exécutés)
+	profits, err := db.completedCycleProfits(`(? = '' OR bo.pair = ?)`, pair, pair)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total profit: %w", err)
-	}
-	if totalProfit.Valid {
-		stats["total_profit"] = totalProfit.Float64
-	} else {
-		stats["total_profit"] = 0.0
+		return nil, err
 	}
+	avgProfit, totalProfit := profitStats(profits)
+	stats["average_profit"] = avgProfit
+	stats["total_profit"] = totalProfit
 
 	return stats, nil
 }
 
-// GetProfitStats calculates profit statistics for completed cycles
-func (db *DB) GetProfitStats() (avgProfit, totalProfit float64, err error) {
-	query := `
-		SELECT 
-			(so.price - bo.price) * bo.amount - bo.fees - so.fees as profit
-		FROM cycles c 
-		JOIN orders bo ON c.buy_order_id = bo.id 
-		JOIN orders so ON c.sell_order_id = so.id 
-		WHERE so.id IS NOT NULL AND bo.status = 'FILLED' AND so.status = 'FILLED'
-	`
-
-	rows, err := db.conn.Query(query)
+// GetProfitStats calculates profit statistics for completed cycles, limitées à une
+// paire si pair n'est pas vide.
+func (db *DB) GetProfitStats(pair string) (avgProfit, totalProfit float64, err error) {
+	profits, err := db.completedCycleProfits(`(? = '' OR bo.pair = ?)`, pair, pair)
 	if err != nil {
-		return 0, 0, fmt.Errorf("failed to get profit stats: %w", err)
-	}
-	defer rows.Close()
-
-	profits := []float64{}
-	for rows.Next() {
-		var profit float64
-		if err := rows.Scan(&profit); err != nil {
-			continue
-		}
-		profits = append(profits, profit)
-		totalProfit += profit
+		return 0, 0, err
 	}
+	avgProfit, totalProfit = profitStats(profits)
+	return avgProfit, totalProfit, nil
+}
 
-	if len(profits) > 0 {
-		avgProfit = totalProfit / float64(len(profits))
+// GetRealizedProfitSince retourne le profit net de frais des cycles terminés depuis
+// since (date de remplissage de la vente), toutes paires confondues. Négatif = perte.
+func (db *DB) GetRealizedProfitSince(since time.Time) (float64, error) {
+	profits, err := db.completedCycleProfits(`so.updated_at >= ?`, since.UTC())
+	if err != nil {
+		return 0, err
 	}
-
-	return avgProfit, totalProfit, nil
+	_, total := profitStats(profits)
+	return total, nil
 }
 
 // CalculateProfitStats calculates profit statistics for completed cycles
 func (db *DB) CalculateProfitStats() (avgProfit float64, totalProfit float64) {
-	// Requête pour calculer les profits des cycles terminés
+	profits, err := db.completedCycleProfits(`1 = 1`)
+	if err != nil {
+		return 0, 0
+	}
+	return profitStats(profits)
+}
+
+// completedCycleProfits retourne le profit net de frais de chaque cycle terminé (achat
+// et vente exécutés) vérifiant la condition where, calculé en décimal (cf. cycleProfit).
+func (db *DB) completedCycleProfits(where string, args ...any) ([]decimal.Decimal, error) {
 	query := `
-		SELECT 
-			(so.price - bo.price) * bo.amount - bo.fees - coalesce(so.fees, 0) as profit
-		FROM cycles c 
-		JOIN orders bo ON c.buy_order_id = bo.id 
-		LEFT JOIN orders so ON c.sell_order_id = so.id 
-		WHERE so.id IS NOT NULL AND bo.status = 'FILLED' AND so.status = 'FILLED'
-	`
+		SELECT COALESCE(bo.avg_fill_price, bo.price), COALESCE(so.avg_fill_price, so.price),
+			COALESCE(so.filled_amount, so.amount), bo.fees, so.fees
+		FROM cycles c
+		JOIN orders bo ON c.buy_order_id = bo.id
+		JOIN orders so ON c.sell_order_id = so.id
+		WHERE bo.status = 'FILLED' AND so.status = 'FILLED' AND ` + where
 
-	rows, err := db.conn.Query(query)
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
-		return 0, 0
+		return nil, fmt.Errorf("failed to get profit stats: %w", err)
 	}
 	defer rows.Close()
 
-	profits := []float64{}
+	var profits []decimal.Decimal
 	for rows.Next() {
-		var profit float64
-		if err := rows.Scan(&profit); err != nil {
-			continue
+		var buyPrice, sellPrice, amount, buyFees, sellFees float64
+		if err := rows.Scan(&buyPrice, &sellPrice, &amount, &buyFees, &sellFees); err != nil {
+			return nil, fmt.Errorf("failed to scan cycle profit: %w", err)
 		}
-		profits = append(profits, profit)
-		totalProfit += profit
+		profits = append(profits, cycleProfit(buyPrice, sellPrice, amount, buyFees, sellFees))
 	}
+	return profits, rows.Err()
+}
 
-	if len(profits) > 0 {
-		avgProfit = totalProfit / float64(len(profits))
-	}
+// cycleProfit retourne le profit net d'un cycle : (prix de vente - prix d'achat) ×
+// quantité vendue, moins les frais d'achat et de vente, sans erreur d'arrondi flottant.
+func cycleProfit(buyPrice, sellPrice, amount, buyFees, sellFees float64) decimal.Decimal {
+	return decimal.FromFloat(sellPrice).Sub(decimal.FromFloat(buyPrice)).Mul(decimal.FromFloat(amount)).
+		Sub(decimal.FromFloat(buyFees)).Sub(decimal.FromFloat(sellFees))
+}
 
-	return avgProfit, totalProfit
+// profitStats retourne le profit moyen et total d'une liste de profits (0 si vide).
+func profitStats(profits []decimal.Decimal) (avg, total float64) {
+	if len(profits) == 0 {
+		return 0, 0
+	}
+	sum := decimal.Zero
+	for _, p := range profits {
+		sum = sum.Add(p)
+	}
+	return sum.Quo(decimal.FromInt(int64(len(profits)))).Float64(), sum.Float64()
 }
 
 // GetRecentActivity retrieves recent activity for the dashboard
@@ -243,15 +232,16 @@ func (db *DB) GetRecentActivity(limit int) ([]map[string]interface{}, error) {
 	return activities, nil
 }
 
-// GetDashboardMetrics retrieves all metrics for the dashboard
-func (db *DB) GetDashboardMetrics() (map[string]interface{}, error) {
-	stats, err := db.GetStats()
+// GetDashboardMetrics retrieves all metrics for the dashboard, limitées à une paire
+// si pair n'est pas vide (l'activité récente reste globale).
+func (db *DB) GetDashboardMetrics(pair string) (map[string]interface{}, error) {
+	stats, err := db.GetStats(pair)
 	if err != nil {
 		return nil, err
 	}
 
 	// Ajouter les profits
-	avgProfit, totalProfit, err := db.GetProfitStats()
+	avgProfit, totalProfit, err := db.GetProfitStats(pair)
 	if err != nil {
 		return nil, err
 	}
@@ -279,3 +269,52 @@ func (db *DB) GetDashboardMetrics() (map[string]interface{}, error) {
 
 	return stats, nil
 }
+
+// StrategyMetrics résume l'état des cycles d'une stratégie (export Prometheus).
+type StrategyMetrics struct {
+	StrategyID      int
+	Name            string
+	Pair            string
+	Enabled         bool
+	NewCycles       int     // achat en attente
+	OpenCycles      int     // achat rempli, pas de vente posée
+	RunningCycles   int     // vente posée (ou annulée), non remplie
+	CompletedCycles int     // achat et vente remplis
+	RealizedProfit  float64 // profit net de frais des cycles terminés
+}
+
+// GetStrategyMetrics compte les cycles de chaque stratégie par statut et cumule le
+// profit réalisé, en une seule requête. Les stratégies sans cycle sont incluses.
+func (db *DB) GetStrategyMetrics() ([]StrategyMetrics, error) {
+	query := `
+		SELECT s.id, s.name, s.pair, s.enabled,
+			COALESCE(SUM(CASE WHEN bo.status = 'PENDING' AND c.sell_order_id IS NULL THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND c.sell_order_id IS NULL THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND so.status IN ('PENDING', 'CANCELLED') THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND so.status = 'FILLED' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN bo.status = 'FILLED' AND so.status = 'FILLED' THEN
+				(COALESCE(so.avg_fill_price, so.price) - COALESCE(bo.avg_fill_price, bo.price)) * COALESCE(so.filled_amount, so.amount) - bo.fees - so.fees
+			END), 0)
+		FROM strategies s
+		LEFT JOIN (cycles c JOIN orders bo ON c.buy_order_id = bo.id) ON bo.strategy_id = s.id
+		LEFT JOIN orders so ON c.sell_order_id = so.id
+		GROUP BY s.id, s.name, s.pair, s.enabled
+		ORDER BY s.id`
+
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy metrics: %w", err)
+	}
+	defer rows.Close()
+
+	var metrics []StrategyMetrics
+	for rows.Next() {
+		var m StrategyMetrics
+		if err := rows.Scan(&m.StrategyID, &m.Name, &m.Pair, &m.Enabled,
+			&m.NewCycles, &m.OpenCycles, &m.RunningCycles, &m.CompletedCycles, &m.RealizedProfit); err != nil {
+			return nil, fmt.Errorf("failed to scan strategy metrics: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
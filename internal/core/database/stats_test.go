@@ -0,0 +1,72 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+// Un cycle par statut sur une stratégie, aucun sur l'autre : comptes par statut et
+// profit réalisé net de frais du seul cycle terminé.
+func TestGetStrategyMetrics(t *testing.T) {
+	db := newTestDB(t)
+	btcID := db.createPairStrategy(t, "BTC DCA", "BTC/USDC")
+	db.createPairStrategy(t, "ETH DCA", "ETH/USDC")
+
+	newCycle := func(ext string, buyStatus OrderStatus, sellStatus OrderStatus) {
+		buy, err := db.CreateOrder(ext+"-buy", Buy, 1, 100, 0.1, btcID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := db.CreateCycle(buy.ID, 110)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buyStatus != Pending {
+			if err := db.UpdateOrderStatus(ext+"-buy", buyStatus); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if sellStatus == "" {
+			return
+		}
+		sell, err := db.CreateOrder(ext+"-sell", Sell, 1, 110, 0.1, btcID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.UpdateCycleSellOrder(c.ID, sell.ID); err != nil {
+			t.Fatal(err)
+		}
+		if sellStatus != Pending {
+			if err := db.UpdateOrderStatus(ext+"-sell", sellStatus); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	newCycle("new", Pending, "")
+	newCycle("open", Filled, "")
+	newCycle("running", Filled, Pending)
+	newCycle("done", Filled, Filled)
+
+	metrics, err := db.GetStrategyMetrics()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]StrategyMetrics)
+	for _, m := range metrics {
+		byName[m.Name] = m
+	}
+
+	btc := byName["BTC DCA"]
+	if btc.NewCycles != 1 || btc.OpenCycles != 1 || btc.RunningCycles != 1 || btc.CompletedCycles != 1 {
+		t.Errorf("BTC : new=%d open=%d running=%d completed=%d, attendu 1 partout",
+			btc.NewCycles, btc.OpenCycles, btc.RunningCycles, btc.CompletedCycles)
+	}
+	if want := (110.0-100.0)*1 - 0.1 - 0.1; math.Abs(btc.RealizedProfit-want) > 1e-9 {
+		t.Errorf("BTC : profit réalisé %.4f, attendu %.4f", btc.RealizedProfit, want)
+	}
+
+	eth, ok := byName["ETH DCA"]
+	if !ok || eth.Pair != "ETH/USDC" || eth.NewCycles+eth.OpenCycles+eth.RunningCycles+eth.CompletedCycles != 0 || eth.RealizedProfit != 0 {
+		t.Errorf("ETH (sans cycle) : %+v", eth)
+	}
+}
@@ -8,12 +8,14 @@ import (
 
 // colonnes SELECT communes à toutes les requêtes de stratégie
 const strategyColumns = `
-	id, name, description, enabled, algorithm_name, cron_expression, buy_interval_seconds, quote_amount, max_concurrent_cycles, max_buy_order_age_hours,
+	id, name, description, enabled, algorithm_name, pair, cron_expression, buy_interval_seconds, quote_amount, max_concurrent_cycles, max_buy_order_age_hours, max_capital,
 	rsi_threshold, rsi_period, rsi_timeframe, macd_fast_period, macd_slow_period, macd_signal_period, macd_timeframe,
 	bb_period, bb_multiplier, bb_timeframe, profit_target, trailing_stop_delta, sell_offset,
 	volatility_period, volatility_adjustment, volatility_timeframe,
 	trend_filter_enabled, trend_filter_fast_period, trend_filter_slow_period, trend_filter_timeframe,
 	dynamic_sizing_enabled, dynamic_sizing_min, dynamic_sizing_max, dynamic_sizing_window_days, dynamic_sizing_full_drawdown,
+	stop_loss_percent, max_cycle_age_days, max_cycle_age_exit, break_even_after_days,
+	grid_lower_price, grid_upper_price, grid_levels,
 	last_executed_at, next_execution_at, created_at, updated_at`
 
 // rowScanner est implémenté par *sql.Row et *sql.Rows
@@ -35,13 +37,15 @@ func scanStrategy(row rowScanner) (Strategy, error) {
 
 	err := row.Scan(
 		&s.ID, &s.Name, &s.Description, &s.Enabled,
-		&s.AlgorithmName, &s.CronExpression, &s.BuyIntervalSeconds, &s.QuoteAmount, &s.MaxConcurrentCycles, &s.MaxBuyOrderAgeHours,
+		&s.AlgorithmName, &s.Pair, &s.CronExpression, &s.BuyIntervalSeconds, &s.QuoteAmount, &s.MaxConcurrentCycles, &s.MaxBuyOrderAgeHours, &s.MaxCapital,
 		&rsiThreshold, &rsiPeriod, &s.RSITimeframe, &s.MACDFastPeriod, &s.MACDSlowPeriod,
 		&s.MACDSignalPeriod, &s.MACDTimeframe, &s.BBPeriod, &s.BBMultiplier, &s.BBTimeframe,
 		&s.ProfitTarget, &s.TrailingStopDelta, &s.SellOffset,
 		&volatilityPeriod, &volatilityAdjustment, &s.VolatilityTimeframe,
 		&s.TrendFilterEnabled, &trendFilterFastPeriod, &trendFilterSlowPeriod, &s.TrendFilterTimeframe,
 		&s.DynamicSizingEnabled, &dynamicSizingMin, &dynamicSizingMax, &dynamicSizingWindowDays, &dynamicSizingFullDrawdown,
+		&s.StopLossPercent, &s.MaxCycleAgeDays, &s.MaxCycleAgeExit, &s.BreakEvenAfterDays,
+		&s.GridLowerPrice, &s.GridUpperPrice, &s.GridLevels,
 		&lastExecutedAt, &nextExecutionAt, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if err != nil {
@@ -122,6 +126,19 @@ func (db *DB) GetStrategy(id int) (*Strategy, error) {
 	return &s, nil
 }
 
+// GetStrategyByName retrieves a strategy by its unique name (nil if not found)
+func (db *DB) GetStrategyByName(name string) (*Strategy, error) {
+	query := `SELECT` + strategyColumns + ` FROM strategies WHERE name = ?`
+	s, err := scanStrategy(db.conn.QueryRow(query, name))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy %s: %w", name, err)
+	}
+	return &s, nil
+}
+
 // GetEnabledStrategies retrieves all enabled strategies
 func (db *DB) GetEnabledStrategies() ([]Strategy, error) {
 	query := `SELECT` + strategyColumns + ` FROM strategies WHERE enabled = 1 ORDER BY created_at DESC`
@@ -218,8 +235,9 @@ func (db *DB) GetStrategyStats(strategyId int) (map[string]interface{}, error) {
 	return stats, nil
 }
 
-// CreateStrategyFromWeb creates a strategy from web interface with full parameters
-func (db *DB) CreateStrategyFromWeb(name, description, algorithm, cron string, buyIntervalSeconds int, enabled bool,
+// CreateStrategyFromWeb creates a strategy from web interface with full parameters.
+// The creation is recorded in the strategy history (strategy_revisions) with src.
+func (db *DB) CreateStrategyFromWeb(src ChangeSource, name, description, pair, algorithm, cron string, buyIntervalSeconds int, enabled bool,
 	quoteAmount, profitTarget, trailingStopDelta, sellOffset float64,
 	rsiThreshold *float64, rsiPeriod *int, rsiTimeframe string,
 	macdFastPeriod, macdSlowPeriod, macdSignalPeriod int, macdTimeframe string,
@@ -227,11 +245,23 @@ func (db *DB) CreateStrategyFromWeb(name, description, algorithm, cron string, b
 	volatilityPeriod *int, volatilityAdjustment *float64, volatilityTimeframe string,
 	trendFilterEnabled bool, trendFilterFastPeriod *int, trendFilterSlowPeriod *int, trendFilterTimeframe string,
 	dynamicSizingEnabled bool, dynamicSizingMin, dynamicSizingMax *float64, dynamicSizingWindowDays *int, dynamicSizingFullDrawdown *float64,
-	concurrentCycles, maxBuyOrderAgeHours int) error {
+	stopLossPercent float64, maxCycleAgeDays int, maxCycleAgeExit string, breakEvenAfterDays int,
+	gridLowerPrice, gridUpperPrice float64, gridLevels int,
+	concurrentCycles, maxBuyOrderAgeHours int, maxCapital float64) error {
+
+	if pair == "" {
+		return fmt.Errorf("strategy %s: pair is required", name)
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
 	// Check if strategy exists
 	var count int
-	err := db.conn.QueryRow(`SELECT COUNT(*) FROM strategies WHERE name = ?`, name).Scan(&count)
+	err = tx.QueryRow(`SELECT COUNT(*) FROM strategies WHERE name = ?`, name).Scan(&count)
 	if err != nil {
 		return fmt.Errorf("failed to check strategy existence: %w", err)
 	}
@@ -255,11 +285,14 @@ func (db *DB) CreateStrategyFromWeb(name, description, algorithm, cron string, b
 	if trendFilterTimeframe == "" {
 		trendFilterTimeframe = "1d"
 	}
+	if maxCycleAgeExit == "" {
+		maxCycleAgeExit = CycleAgeExitMarket
+	}
 
 	// Insert strategy with all web form parameters
 	query := `
 		INSERT INTO strategies (
-			name, description, enabled, algorithm_name, cron_expression, buy_interval_seconds, quote_amount,
+			name, description, enabled, algorithm_name, pair, cron_expression, buy_interval_seconds, quote_amount,
 			rsi_threshold, rsi_period, rsi_timeframe,
 			macd_fast_period, macd_slow_period, macd_signal_period, macd_timeframe,
 			bb_period, bb_multiplier, bb_timeframe,
@@ -267,11 +300,13 @@ func (db *DB) CreateStrategyFromWeb(name, description, algorithm, cron string, b
 			volatility_period, volatility_adjustment, volatility_timeframe,
 			trend_filter_enabled, trend_filter_fast_period, trend_filter_slow_period, trend_filter_timeframe,
 			dynamic_sizing_enabled, dynamic_sizing_min, dynamic_sizing_max, dynamic_sizing_window_days, dynamic_sizing_full_drawdown,
-			max_concurrent_cycles, max_buy_order_age_hours
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			stop_loss_percent, max_cycle_age_days, max_cycle_age_exit, break_even_after_days,
+			grid_lower_price, grid_upper_price, grid_levels,
+			max_concurrent_cycles, max_buy_order_age_hours, max_capital
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	_, err = db.conn.Exec(query, name, description, enabled, algorithm, cron, buyIntervalSeconds, quoteAmount,
+	result, err := tx.Exec(query, name, description, enabled, algorithm, pair, cron, buyIntervalSeconds, quoteAmount,
 		rsiThreshold, rsiPeriod, rsiTimeframe,
 		macdFastPeriod, macdSlowPeriod, macdSignalPeriod, macdTimeframe,
 		bbPeriod, bbMultiplier, bbTimeframe,
@@ -279,16 +314,30 @@ func (db *DB) CreateStrategyFromWeb(name, description, algorithm, cron string, b
 		volatilityPeriod, volatilityAdjustment, volatilityTimeframe,
 		trendFilterEnabled, trendFilterFastPeriod, trendFilterSlowPeriod, trendFilterTimeframe,
 		dynamicSizingEnabled, dynamicSizingMin, dynamicSizingMax, dynamicSizingWindowDays, dynamicSizingFullDrawdown,
-		concurrentCycles, maxBuyOrderAgeHours)
+		stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays,
+		gridLowerPrice, gridUpperPrice, gridLevels,
+		concurrentCycles, maxBuyOrderAgeHours, maxCapital)
 	if err != nil {
 		return fmt.Errorf("failed to create strategy: %w", err)
 	}
 
-	return nil
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	after, err := getStrategy(tx, int(id))
+	if err != nil {
+		return err
+	}
+	if _, err := recordRevision(tx, int(id), RevisionCreate, src, nil, after); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// UpdateStrategy updates an existing strategy
-func (db *DB) UpdateStrategy(id int, name, description, algorithm, cron string, buyIntervalSeconds int, enabled bool,
+// UpdateStrategy updates an existing strategy. The change is recorded in the strategy
+// history with src, unless the configuration is left as is.
+func (db *DB) UpdateStrategy(src ChangeSource, id int, name, description, pair, algorithm, cron string, buyIntervalSeconds int, enabled bool,
 	quoteAmount, profitTarget, trailingStopDelta, sellOffset float64,
 	rsiThreshold *float64, rsiPeriod *int, rsiTimeframe string,
 	macdFastPeriod, macdSlowPeriod, macdSignalPeriod int, macdTimeframe string,
@@ -296,7 +345,13 @@ func (db *DB) UpdateStrategy(id int, name, description, algorithm, cron string,
 	volatilityPeriod *int, volatilityAdjustment *float64, volatilityTimeframe string,
 	trendFilterEnabled bool, trendFilterFastPeriod *int, trendFilterSlowPeriod *int, trendFilterTimeframe string,
 	dynamicSizingEnabled bool, dynamicSizingMin, dynamicSizingMax *float64, dynamicSizingWindowDays *int, dynamicSizingFullDrawdown *float64,
-	maxConcurrentCycles, maxBuyOrderAgeHours int) error {
+	stopLossPercent float64, maxCycleAgeDays int, maxCycleAgeExit string, breakEvenAfterDays int,
+	gridLowerPrice, gridUpperPrice float64, gridLevels int,
+	maxConcurrentCycles, maxBuyOrderAgeHours int, maxCapital float64) error {
+
+	if pair == "" {
+		return fmt.Errorf("strategy %s: pair is required", name)
+	}
 
 	// Set defaults for timeframes if empty
 	if rsiTimeframe == "" {
@@ -314,11 +369,26 @@ func (db *DB) UpdateStrategy(id int, name, description, algorithm, cron string,
 	if trendFilterTimeframe == "" {
 		trendFilterTimeframe = "1d"
 	}
+	if maxCycleAgeExit == "" {
+		maxCycleAgeExit = CycleAgeExitMarket
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := getStrategy(tx, id)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE strategies SET
       name = ?,
 			description = ?,
+			pair = ?,
 			algorithm_name = ?,
 			cron_expression = ?,
 			buy_interval_seconds = ?,
@@ -349,13 +419,21 @@ func (db *DB) UpdateStrategy(id int, name, description, algorithm, cron string,
 			dynamic_sizing_max = ?,
 			dynamic_sizing_window_days = ?,
 			dynamic_sizing_full_drawdown = ?,
+			stop_loss_percent = ?,
+			max_cycle_age_days = ?,
+			max_cycle_age_exit = ?,
+			break_even_after_days = ?,
+			grid_lower_price = ?,
+			grid_upper_price = ?,
+			grid_levels = ?,
 			max_concurrent_cycles = ?,
 			max_buy_order_age_hours = ?,
+			max_capital = ?,
 			updated_at = CURRENT_TIMESTAMP
     WHERE id = ?
 	`
 
-	_, err := db.conn.Exec(query, name, description, algorithm, cron, buyIntervalSeconds, enabled,
+	_, err = tx.Exec(query, name, description, pair, algorithm, cron, buyIntervalSeconds, enabled,
 		quoteAmount, profitTarget, trailingStopDelta, sellOffset,
 		rsiThreshold, rsiPeriod, rsiTimeframe,
 		macdFastPeriod, macdSlowPeriod, macdSignalPeriod, macdTimeframe,
@@ -363,9 +441,63 @@ func (db *DB) UpdateStrategy(id int, name, description, algorithm, cron string,
 		volatilityPeriod, volatilityAdjustment, volatilityTimeframe,
 		trendFilterEnabled, trendFilterFastPeriod, trendFilterSlowPeriod, trendFilterTimeframe,
 		dynamicSizingEnabled, dynamicSizingMin, dynamicSizingMax, dynamicSizingWindowDays, dynamicSizingFullDrawdown,
-		maxConcurrentCycles, maxBuyOrderAgeHours, id)
+		stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays,
+		gridLowerPrice, gridUpperPrice, gridLevels,
+		maxConcurrentCycles, maxBuyOrderAgeHours, maxCapital, id)
+	if err != nil {
+		return fmt.Errorf("failed to update strategy: %w", err)
+	}
 
-	return err
+	after, err := getStrategy(tx, id)
+	if err != nil {
+		return err
+	}
+	if !sameConfig(*before, *after) {
+		if _, err := recordRevision(tx, id, RevisionUpdate, src, before, after); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// GetStrategyPairs retourne les paires distinctes tradées par les stratégies
+// (activées ou non : une stratégie désactivée peut encore avoir des cycles à vendre).
+func (db *DB) GetStrategyPairs() ([]string, error) {
+	rows, err := db.conn.Query(`SELECT DISTINCT pair FROM strategies WHERE pair <> '' ORDER BY pair`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get strategy pairs: %w", err)
+	}
+	defer rows.Close()
+
+	var pairs []string
+	for rows.Next() {
+		var pair string
+		if err := rows.Scan(&pair); err != nil {
+			return nil, fmt.Errorf("failed to scan strategy pair: %w", err)
+		}
+		pairs = append(pairs, pair)
+	}
+	return pairs, nil
+}
+
+// SetDefaultPair attribue la paire donnée (TRADING_PAIR) aux stratégies et aux ordres
+// qui n'en ont pas encore, c'est-à-dire ceux créés avant le support multi-paires.
+// Les ordres reprennent la paire de leur stratégie.
+func (db *DB) SetDefaultPair(pair string) error {
+	if pair == "" {
+		return nil
+	}
+	if _, err := db.conn.Exec(`UPDATE strategies SET pair = ? WHERE pair = ''`, pair); err != nil {
+		return fmt.Errorf("failed to set default strategy pair: %w", err)
+	}
+	query := `
+		UPDATE orders SET pair = COALESCE(
+			(SELECT s.pair FROM strategies s WHERE s.id = orders.strategy_id AND s.pair <> ''), ?)
+		WHERE pair = ''`
+	if _, err := db.conn.Exec(query, pair); err != nil {
+		return fmt.Errorf("failed to set default order pair: %w", err)
+	}
+	return nil
 }
 
 // GetLastBuyTime retourne la date de création du dernier cycle (= dernier achat)
@@ -393,18 +525,35 @@ func (db *DB) GetLastBuyTime(strategyId int) (*time.Time, error) {
 	return &lastBuy, nil
 }
 
-// ToggleStrategyEnabled toggles the enabled status of a strategy
-func (db *DB) ToggleStrategyEnabled(id int) error {
-	query := `UPDATE strategies SET enabled = NOT enabled, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
-	_, err := db.conn.Exec(query, id)
+// ToggleStrategyEnabled toggles the enabled status of a strategy (recorded in its history)
+func (db *DB) ToggleStrategyEnabled(src ChangeSource, id int) error {
+	tx, err := db.conn.Begin()
 	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	before, err := getStrategy(tx, id)
+	if err != nil {
+		return err
+	}
+	query := `UPDATE strategies SET enabled = NOT enabled, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	if _, err := tx.Exec(query, id); err != nil {
 		return fmt.Errorf("failed to toggle strategy enabled status: %w", err)
 	}
-	return nil
+	after, err := getStrategy(tx, id)
+	if err != nil {
+		return err
+	}
+	if _, err := recordRevision(tx, id, RevisionToggle, src, before, after); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-// DeleteStrategy deletes a strategy if it has no associated orders
-func (db *DB) DeleteStrategy(id int) error {
+// DeleteStrategy deletes a strategy if it has no associated orders. Its history is
+// kept, ending with a delete revision holding the last configuration.
+func (db *DB) DeleteStrategy(src ChangeSource, id int) error {
 	ordersCount, err := db.CountOrdersForStrategy(id)
 	if err != nil {
 		return fmt.Errorf("failed to check orders for strategy: %w", err)
@@ -413,17 +562,24 @@ func (db *DB) DeleteStrategy(id int) error {
 		return fmt.Errorf("cannot delete strategy: %d orders are associated with this strategy", ordersCount)
 	}
 
-	// Safe to delete
-	query := `DELETE FROM strategies WHERE id = ?`
-	result, err := db.conn.Exec(query, id)
+	tx, err := db.conn.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to delete strategy: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return fmt.Errorf("strategy not found")
+	before, err := getStrategy(tx, id)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// Safe to delete
+	query := `DELETE FROM strategies WHERE id = ?`
+	if _, err := tx.Exec(query, id); err != nil {
+		return fmt.Errorf("failed to delete strategy: %w", err)
+	}
+	if _, err := recordRevision(tx, id, RevisionDelete, src, before, nil); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
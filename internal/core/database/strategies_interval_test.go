@@ -36,8 +36,8 @@ func TestCreateStrategyInterval_RoundTrip(t *testing.T) {
 	db := newTestDB(t)
 
 	const interval = 24 * 3600 // 24 h
-	err := db.CreateStrategyFromWeb(
-		"Periodic DCA", "test", "rsi_dca", "" /*cron*/, interval /*buyIntervalSeconds*/, true,
+	err := db.CreateStrategyFromWeb(ChangeSource{Origin: OriginWeb},
+		"Periodic DCA", "test", "BTC/USDC", "rsi_dca", "" /*cron*/, interval /*buyIntervalSeconds*/, true,
 		25.0, 2.0, 0.1, 0.1,
 		nil, nil, "4h",
 		12, 26, 9, "4h",
@@ -45,7 +45,9 @@ func TestCreateStrategyInterval_RoundTrip(t *testing.T) {
 		nil, nil, "4h",
 		false, nil, nil, "1d",
 		false, nil, nil, nil, nil,
-		1, 0,
+		0, 0, "", 0,
+		0, 0, 0,
+		1, 0, 0,
 	)
 	if err != nil {
 		t.Fatalf("CreateStrategyFromWeb a échoué : %v", err)
@@ -81,8 +83,8 @@ func TestGetLastBuyTime_AndCooldown(t *testing.T) {
 	db := newTestDB(t)
 
 	const interval = 24 * 3600
-	if err := db.CreateStrategyFromWeb(
-		"Periodic DCA", "test", "rsi_dca", "", interval, true,
+	if err := db.CreateStrategyFromWeb(ChangeSource{Origin: OriginWeb},
+		"Periodic DCA", "test", "BTC/USDC", "rsi_dca", "", interval, true,
 		25.0, 2.0, 0.1, 0.1,
 		nil, nil, "4h",
 		12, 26, 9, "4h",
@@ -90,7 +92,9 @@ func TestGetLastBuyTime_AndCooldown(t *testing.T) {
 		nil, nil, "4h",
 		false, nil, nil, "1d",
 		false, nil, nil, nil, nil,
-		1, 0,
+		0, 0, "", 0,
+		0, 0, 0,
+		1, 0, 0,
 	); err != nil {
 		t.Fatalf("CreateStrategyFromWeb a échoué : %v", err)
 	}
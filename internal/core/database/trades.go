@@ -0,0 +1,66 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Trade est une ligne du grand livre : l'exécution d'un ordre, pour la quantité
+// réellement remplie, au prix moyen d'exécution, frais en devise de cotation.
+type Trade struct {
+	ID         int       `json:"id"`
+	OrderID    string    `json:"order_id"` // identifiant exchange de l'ordre
+	StrategyID *int      `json:"strategy_id,omitempty"`
+	Pair       string    `json:"pair"`
+	Side       OrderSide `json:"side"`
+	Amount     float64   `json:"amount"`
+	Price      float64   `json:"price"`
+	Fee        float64   `json:"fee"`
+	ExecutedAt time.Time `json:"executed_at"`
+}
+
+// recordTrade ajoute au grand livre l'ordre qui vient d'être clos, s'il a été rempli
+// (même partiellement). Sans effet si l'ordre y figure déjà.
+func (db *DB) recordTrade(externalId string) error {
+	_, err := db.conn.Exec(`
+		INSERT OR IGNORE INTO trades (order_id, strategy_id, pair, side, amount, price, fee, executed_at)
+		SELECT external_id, strategy_id, pair, side, COALESCE(filled_amount, amount),
+			COALESCE(avg_fill_price, price), COALESCE(fees, 0), CURRENT_TIMESTAMP
+		FROM orders
+		WHERE external_id = ? AND (status = ? OR (status = ? AND filled_amount > 0))`,
+		externalId, Filled, Cancelled)
+	if err != nil {
+		return fmt.Errorf("failed to record trade: %w", err)
+	}
+	return nil
+}
+
+// GetTrades retourne les lignes du grand livre par ordre chronologique, limitées à une
+// paire si pair n'est pas vide.
+func (db *DB) GetTrades(pair string) ([]Trade, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, order_id, strategy_id, pair, side, amount, price, fee, executed_at
+		FROM trades
+		WHERE ? = '' OR pair = ?
+		ORDER BY executed_at, id`, pair, pair)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades: %w", err)
+	}
+	defer rows.Close()
+
+	var trades []Trade
+	for rows.Next() {
+		var t Trade
+		var strategyId sql.NullInt64
+		if err := rows.Scan(&t.ID, &t.OrderID, &strategyId, &t.Pair, &t.Side, &t.Amount, &t.Price, &t.Fee, &t.ExecutedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		}
+		if strategyId.Valid {
+			id := int(strategyId.Int64)
+			t.StrategyID = &id
+		}
+		trades = append(trades, t)
+	}
+	return trades, rows.Err()
+}
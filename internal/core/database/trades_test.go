@@ -0,0 +1,65 @@
+package database
+
+import "testing"
+
+// Chaque ordre exécuté, y compris annulé après remplissage partiel, entre au grand livre
+// avec la quantité remplie, le prix moyen et ses frais ; il survit à la purge des ordres.
+func TestTrades(t *testing.T) {
+	db := newTestDB(t)
+	id := db.createPairStrategy(t, "BTC DCA", "BTC/USDC")
+
+	for _, ext := range []string{"buy", "partial", "cancelled"} {
+		if _, err := db.CreateOrder(ext, Buy, 1, 100, 0, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.UpdateOrderFill("buy", 1, 99.5); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderFees("buy", 0.1); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("buy", Filled); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderFill("partial", 0.4, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("partial", Cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("cancelled", Cancelled); err != nil {
+		t.Fatal(err)
+	}
+	// Frais connus après coup, statut réécrit : pas de doublon
+	if err := db.UpdateOrderFees("partial", 0.04); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateOrderStatus("buy", Filled); err != nil {
+		t.Fatal(err)
+	}
+
+	trades, err := db.GetTrades("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("%d lignes au grand livre, attendu 2 : %+v", len(trades), trades)
+	}
+	if tr := trades[0]; tr.OrderID != "buy" || tr.Pair != "BTC/USDC" || tr.Side != Buy || tr.Amount != 1 || tr.Price != 99.5 || tr.Fee != 0.1 {
+		t.Errorf("achat : %+v", tr)
+	}
+	if tr := trades[1]; tr.OrderID != "partial" || tr.Amount != 0.4 || tr.Fee != 0.04 {
+		t.Errorf("achat partiel : %+v", tr)
+	}
+
+	if err := db.CleanupOldData(-1); err != nil {
+		t.Fatal(err)
+	}
+	if trades, _ := db.GetTrades("BTC/USDC"); len(trades) != 2 {
+		t.Errorf("grand livre purgé avec les ordres : %d lignes", len(trades))
+	}
+	if trades, _ := db.GetTrades("ETH/USDC"); len(trades) != 0 {
+		t.Errorf("filtre par paire : %d lignes", len(trades))
+	}
+}
@@ -0,0 +1,258 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Rôles de la WebUI. Un operator a tous les droits d'un viewer.
+const (
+	RoleViewer   = "viewer"   // consultation : dashboard, cycles, ordres, logs
+	RoleOperator = "operator" // + édition des stratégies, achats manuels, assistant IA
+)
+
+// MinPasswordLength est la longueur minimale d'un mot de passe WebUI.
+const MinPasswordLength = 8
+
+// ErrInvalidCredentials est renvoyée par AuthenticateUser quand l'utilisateur est
+// inconnu ou le mot de passe faux (sans distinguer les deux cas).
+var ErrInvalidCredentials = errors.New("identifiants invalides")
+
+// User est un compte local de la WebUI.
+type User struct {
+	ID           int       `json:"id"`
+	Username     string    `json:"username"`
+	PasswordHash string    `json:"-"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// HasRole indique si l'utilisateur dispose au moins des droits du rôle demandé.
+func (u User) HasRole(role string) bool {
+	switch role {
+	case RoleViewer:
+		return u.Role == RoleViewer || u.Role == RoleOperator
+	case RoleOperator:
+		return u.Role == RoleOperator
+	}
+	return false
+}
+
+// Session est une session WebUI ouverte, avec son utilisateur et le jeton CSRF à
+// présenter sur chaque requête modifiante.
+type Session struct {
+	User      User
+	CSRFToken string
+	ExpiresAt time.Time
+}
+
+// ValidRole indique si role est un rôle WebUI connu.
+func ValidRole(role string) bool {
+	return role == RoleViewer || role == RoleOperator
+}
+
+// hashSessionToken : seul le condensat du jeton de session est stocké en base.
+func hashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("mot de passe trop court (%d caractères minimum)", MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+// CreateUser crée un utilisateur WebUI ; le mot de passe est haché avec bcrypt.
+func (db *DB) CreateUser(username, password, role string) (*User, error) {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return nil, fmt.Errorf("nom d'utilisateur requis")
+	}
+	if !ValidRole(role) {
+		return nil, fmt.Errorf("rôle inconnu %q (attendu %s ou %s)", role, RoleViewer, RoleOperator)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `INSERT INTO users (username, password_hash, role) VALUES (?, ?, ?)`
+	result, err := db.conn.Exec(query, username, hash, role)
+	if err != nil {
+		if strings.Contains(err.Error(), "UNIQUE constraint failed") {
+			return nil, fmt.Errorf("l'utilisateur %s existe déjà", username)
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user ID: %w", err)
+	}
+	return db.getUser(`WHERE id = ?`, id)
+}
+
+func (db *DB) getUser(where string, args ...any) (*User, error) {
+	query := `SELECT id, username, password_hash, role, created_at FROM users ` + where
+	var u User
+	err := db.conn.QueryRow(query, args...).Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &u, nil
+}
+
+// GetUserByUsername retourne l'utilisateur, ou nil s'il n'existe pas.
+func (db *DB) GetUserByUsername(username string) (*User, error) {
+	return db.getUser(`WHERE username = ?`, username)
+}
+
+// GetUsers retourne tous les utilisateurs WebUI, par nom.
+func (db *DB) GetUsers() ([]User, error) {
+	rows, err := db.conn.Query(`SELECT id, username, password_hash, role, created_at FROM users ORDER BY username`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var u User
+		if err := rows.Scan(&u.ID, &u.Username, &u.PasswordHash, &u.Role, &u.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+// CountUsers retourne le nombre d'utilisateurs WebUI.
+func (db *DB) CountUsers() (int, error) {
+	var n int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	return n, nil
+}
+
+// AuthenticateUser vérifie le couple identifiant / mot de passe. Renvoie
+// ErrInvalidCredentials si l'un des deux est faux.
+func (db *DB) AuthenticateUser(username, password string) (*User, error) {
+	u, err := db.GetUserByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+// SetUserPassword change le mot de passe d'un utilisateur et ferme ses sessions.
+func (db *DB) SetUserPassword(username, password string) error {
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return db.updateUser(username, `UPDATE users SET password_hash = ?, updated_at = CURRENT_TIMESTAMP WHERE username = ?`, hash, username)
+}
+
+// SetUserRole change le rôle d'un utilisateur et ferme ses sessions.
+func (db *DB) SetUserRole(username, role string) error {
+	if !ValidRole(role) {
+		return fmt.Errorf("rôle inconnu %q (attendu %s ou %s)", role, RoleViewer, RoleOperator)
+	}
+	return db.updateUser(username, `UPDATE users SET role = ?, updated_at = CURRENT_TIMESTAMP WHERE username = ?`, role, username)
+}
+
+// DeleteUser supprime un utilisateur et ses sessions.
+func (db *DB) DeleteUser(username string) error {
+	return db.updateUser(username, `DELETE FROM users WHERE username = ?`, username)
+}
+
+// updateUser applique une modification de compte puis invalide les sessions de
+// l'utilisateur : un changement de mot de passe ou de rôle prend effet immédiatement.
+func (db *DB) updateUser(username, query string, args ...any) error {
+	u, err := db.GetUserByUsername(username)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return fmt.Errorf("utilisateur %s introuvable", username)
+	}
+	if _, err := db.conn.Exec(`DELETE FROM sessions WHERE user_id = ?`, u.ID); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
+	}
+	if _, err := db.conn.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// CreateSession enregistre une session pour l'utilisateur. token est le jeton remis
+// au navigateur (cookie) ; seul son condensat est conservé.
+func (db *DB) CreateSession(userID int, token, csrfToken string, expiresAt time.Time) error {
+	query := `INSERT INTO sessions (token_hash, user_id, csrf_token, expires_at) VALUES (?, ?, ?, ?)`
+	if _, err := db.conn.Exec(query, hashSessionToken(token), userID, csrfToken, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	return nil
+}
+
+// GetSession retourne la session associée au jeton, ou nil si elle n'existe pas ou
+// a expiré.
+func (db *DB) GetSession(token string) (*Session, error) {
+	query := `
+		SELECT u.id, u.username, u.password_hash, u.role, u.created_at, s.csrf_token, s.expires_at
+		FROM sessions s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.token_hash = ?`
+	var s Session
+	err := db.conn.QueryRow(query, hashSessionToken(token)).Scan(
+		&s.User.ID, &s.User.Username, &s.User.PasswordHash, &s.User.Role, &s.User.CreatedAt,
+		&s.CSRFToken, &s.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	if !time.Now().Before(s.ExpiresAt) {
+		return nil, nil
+	}
+	return &s, nil
+}
+
+// DeleteSession ferme une session (déconnexion).
+func (db *DB) DeleteSession(token string) error {
+	if _, err := db.conn.Exec(`DELETE FROM sessions WHERE token_hash = ?`, hashSessionToken(token)); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredSessions purge les sessions expirées.
+func (db *DB) DeleteExpiredSessions() error {
+	if _, err := db.conn.Exec(`DELETE FROM sessions WHERE expires_at < ?`, time.Now().UTC()); err != nil {
+		return fmt.Errorf("failed to delete expired sessions: %w", err)
+	}
+	return nil
+}
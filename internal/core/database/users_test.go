@@ -0,0 +1,96 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUsers_CreateAndAuthenticate(t *testing.T) {
+	db := newTestDB(t)
+
+	if _, err := db.CreateUser("alice", "court", RoleOperator); err == nil {
+		t.Error("mot de passe trop court accepté")
+	}
+	if _, err := db.CreateUser("alice", "motdepasse", "admin"); err == nil {
+		t.Error("rôle inconnu accepté")
+	}
+	u, err := db.CreateUser("alice", "motdepasse", RoleOperator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.PasswordHash == "motdepasse" || u.Role != RoleOperator {
+		t.Errorf("utilisateur mal enregistré : %+v", u)
+	}
+	if _, err := db.CreateUser("alice", "autremotdepasse", RoleViewer); err == nil {
+		t.Error("doublon de nom d'utilisateur accepté")
+	}
+
+	if _, err := db.AuthenticateUser("alice", "mauvais"); err != ErrInvalidCredentials {
+		t.Errorf("mauvais mot de passe : err = %v, attendu ErrInvalidCredentials", err)
+	}
+	if _, err := db.AuthenticateUser("bob", "motdepasse"); err != ErrInvalidCredentials {
+		t.Errorf("utilisateur inconnu : err = %v, attendu ErrInvalidCredentials", err)
+	}
+	got, err := db.AuthenticateUser("alice", "motdepasse")
+	if err != nil || got.ID != u.ID {
+		t.Fatalf("authentification : %+v, %v", got, err)
+	}
+}
+
+func TestUser_HasRole(t *testing.T) {
+	viewer, operator := User{Role: RoleViewer}, User{Role: RoleOperator}
+	if !viewer.HasRole(RoleViewer) || viewer.HasRole(RoleOperator) {
+		t.Error("viewer : droits incorrects")
+	}
+	if !operator.HasRole(RoleViewer) || !operator.HasRole(RoleOperator) {
+		t.Error("operator : doit avoir aussi les droits viewer")
+	}
+}
+
+// Les sessions expirent, se ferment à la déconnexion et sont invalidées par un
+// changement de mot de passe ou de rôle.
+func TestSessions(t *testing.T) {
+	db := newTestDB(t)
+	u, err := db.CreateUser("alice", "motdepasse", RoleViewer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.CreateSession(u.ID, "jeton", "csrf", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	s, err := db.GetSession("jeton")
+	if err != nil || s == nil {
+		t.Fatalf("session introuvable : %v", err)
+	}
+	if s.User.Username != "alice" || s.CSRFToken != "csrf" {
+		t.Errorf("session : %+v", s)
+	}
+	if s, _ := db.GetSession("autre"); s != nil {
+		t.Error("jeton inconnu accepté")
+	}
+
+	if err := db.CreateSession(u.ID, "expire", "csrf2", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := db.GetSession("expire"); s != nil {
+		t.Error("session expirée acceptée")
+	}
+
+	if err := db.SetUserRole("alice", RoleOperator); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := db.GetSession("jeton"); s != nil {
+		t.Error("session conservée après changement de rôle")
+	}
+
+	if err := db.CreateSession(u.ID, "jeton2", "csrf3", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteSession("jeton2"); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := db.GetSession("jeton2"); s != nil {
+		t.Error("session conservée après déconnexion")
+	}
+}
@@ -0,0 +1,287 @@
+// Package decimal fournit un nombre décimal exact pour les prix, quantités, frais et
+// PnL. Un float64 ne représente pas 0.1 : les arrondis au tick par multiplication par
+// 1/pas tombent un tick trop bas (ou débordent sur les actifs à très petit prix) et les
+// sommes de PnL accumulent l'erreur.
+//
+// Les valeurs restent des float64 aux frontières (ccxt, colonnes REAL de SQLite, JSON,
+// templates) : FromFloat reprend l'écriture décimale la plus courte du float, celle que
+// l'exchange a envoyée, et Float64 la restitue sans perte jusqu'à 15 chiffres
+// significatifs. Les calculs, eux, se font en Decimal.
+package decimal
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode choisit le sens d'arrondi au tick (prix) ou au pas (quantité).
+type RoundingMode int
+
+const (
+	Down     RoundingMode = iota // vers zéro (troncature)
+	Up                           // à l'opposé de zéro
+	Floor                        // vers -∞
+	Ceil                         // vers +∞
+	HalfUp                       // au plus proche, milieu à l'opposé de zéro
+	HalfEven                     // au plus proche, milieu vers le chiffre pair
+)
+
+// DivScale est le nombre de décimales conservées par Quo.
+const DivScale = 18
+
+// Decimal vaut value × 10^-scale. Valeur immuable ; la valeur zéro du type vaut 0.
+type Decimal struct {
+	value *big.Int
+	scale int32
+}
+
+// Zero vaut 0.
+var Zero = Decimal{}
+
+// New retourne value × 10^-scale.
+func New(value int64, scale int32) Decimal {
+	v := big.NewInt(value)
+	if scale < 0 {
+		return Decimal{v.Mul(v, pow10(-scale)), 0}
+	}
+	return Decimal{v, scale}
+}
+
+// FromInt retourne l'entier v.
+func FromInt(v int64) Decimal {
+	return New(v, 0)
+}
+
+// FromFloat convertit un float64 via son écriture décimale la plus courte (0.1 et non
+// 0.1000000000000000055511151231257827). NaN et ±Inf valent 0.
+func FromFloat(f float64) Decimal {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return Zero
+	}
+	d, _ := Parse(strconv.FormatFloat(f, 'g', -1, 64))
+	return d
+}
+
+// Parse lit une écriture décimale : « 42 », « -0.001 », « 1.5e-8 ».
+func Parse(s string) (Decimal, error) {
+	str := strings.TrimSpace(s)
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return Zero, fmt.Errorf("decimal: invalid exponent in %q", s)
+		}
+		exp, str = e, str[:i]
+	}
+	scale := 0
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		scale = len(str) - i - 1
+		str = str[:i] + str[i+1:]
+	}
+	if str == "" || str == "-" || str == "+" || strings.ContainsAny(str, "_.") {
+		return Zero, fmt.Errorf("decimal: invalid number %q", s)
+	}
+	v, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return Zero, fmt.Errorf("decimal: invalid number %q", s)
+	}
+	scale -= exp
+	if scale < 0 {
+		return Decimal{v.Mul(v, pow10(int32(-scale))), 0}, nil
+	}
+	return Decimal{v, int32(scale)}, nil
+}
+
+// MustParse est Parse pour les constantes : panique si s est invalide.
+func MustParse(s string) Decimal {
+	d, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func (d Decimal) int() *big.Int {
+	if d.value == nil {
+		return new(big.Int)
+	}
+	return d.value
+}
+
+// rescale retourne la valeur entière de d à l'échelle scale (>= d.scale).
+func (d Decimal) rescale(scale int32) *big.Int {
+	v := new(big.Int).Set(d.int())
+	if scale > d.scale {
+		v.Mul(v, pow10(scale-d.scale))
+	}
+	return v
+}
+
+func align(a, b Decimal) (*big.Int, *big.Int, int32) {
+	scale := a.scale
+	if b.scale > scale {
+		scale = b.scale
+	}
+	return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Add retourne d + e.
+func (d Decimal) Add(e Decimal) Decimal {
+	x, y, scale := align(d, e)
+	return Decimal{x.Add(x, y), scale}
+}
+
+// Sub retourne d - e.
+func (d Decimal) Sub(e Decimal) Decimal {
+	x, y, scale := align(d, e)
+	return Decimal{x.Sub(x, y), scale}
+}
+
+// Mul retourne d × e (exact).
+func (d Decimal) Mul(e Decimal) Decimal {
+	return Decimal{new(big.Int).Mul(d.int(), e.int()), d.scale + e.scale}
+}
+
+// Div retourne d / e arrondi à scale décimales selon mode. Panique si e vaut 0.
+func (d Decimal) Div(e Decimal, scale int32, mode RoundingMode) Decimal {
+	if e.IsZero() {
+		panic("decimal: division by zero")
+	}
+	num, den := new(big.Int).Set(d.int()), new(big.Int).Set(e.int())
+	if k := scale - d.scale + e.scale; k >= 0 {
+		num.Mul(num, pow10(k))
+	} else {
+		den.Mul(den, pow10(-k))
+	}
+	return Decimal{roundQuo(num, den, mode), scale}
+}
+
+// Quo retourne d / e avec DivScale décimales, arrondi au plus proche.
+func (d Decimal) Quo(e Decimal) Decimal {
+	return d.Div(e, DivScale, HalfEven)
+}
+
+// Neg retourne -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{new(big.Int).Neg(d.int()), d.scale}
+}
+
+// Abs retourne |d|.
+func (d Decimal) Abs() Decimal {
+	return Decimal{new(big.Int).Abs(d.int()), d.scale}
+}
+
+// Cmp retourne -1, 0 ou +1 selon que d est inférieur, égal ou supérieur à e.
+func (d Decimal) Cmp(e Decimal) int {
+	x, y, _ := align(d, e)
+	return x.Cmp(y)
+}
+
+// Sign retourne -1, 0 ou +1 selon le signe de d.
+func (d Decimal) Sign() int {
+	return d.int().Sign()
+}
+
+// IsZero indique si d vaut 0.
+func (d Decimal) IsZero() bool {
+	return d.Sign() == 0
+}
+
+// Round arrondit d à scale décimales selon mode.
+func (d Decimal) Round(scale int32, mode RoundingMode) Decimal {
+	if scale >= d.scale {
+		return d
+	}
+	return Decimal{roundQuo(d.int(), pow10(d.scale-scale), mode), scale}
+}
+
+// RoundStep arrondit d à un multiple de step (tick de prix, pas de quantité) selon
+// mode. Un pas nul ou négatif laisse d inchangé.
+func (d Decimal) RoundStep(step Decimal, mode RoundingMode) Decimal {
+	if step.Sign() <= 0 {
+		return d
+	}
+	return d.Div(step, 0, mode).Mul(step)
+}
+
+// Float64 retourne le float64 le plus proche de d.
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// String retourne l'écriture décimale de d, sans zéros inutiles (« 0.3 », « 12 »).
+func (d Decimal) String() string {
+	s := d.digits(d.scale)
+	if strings.IndexByte(s, '.') >= 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// StringFixed retourne d arrondi au plus proche avec exactement places décimales
+// (affichage selon les décimales du marché).
+func (d Decimal) StringFixed(places int32) string {
+	if places < 0 {
+		places = 0
+	}
+	r := d.Round(places, HalfUp)
+	return Decimal{r.rescale(places), places}.digits(places)
+}
+
+// digits écrit la valeur entière de d en plaçant la virgule à scale chiffres.
+func (d Decimal) digits(scale int32) string {
+	v := d.int()
+	abs := new(big.Int).Abs(v).String()
+	if scale > 0 {
+		if pad := int(scale) + 1 - len(abs); pad > 0 {
+			abs = strings.Repeat("0", pad) + abs
+		}
+		abs = abs[:len(abs)-int(scale)] + "." + abs[len(abs)-int(scale):]
+	}
+	if v.Sign() < 0 {
+		return "-" + abs
+	}
+	return abs
+}
+
+// roundQuo retourne num / den arrondi selon mode.
+func roundQuo(num, den *big.Int, mode RoundingMode) *big.Int {
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	sign := int64(num.Sign() * den.Sign()) // signe du quotient exact
+	away := false
+	switch mode {
+	case Up:
+		away = true
+	case Floor:
+		away = sign < 0
+	case Ceil:
+		away = sign > 0
+	case HalfUp, HalfEven:
+		half := new(big.Int).Abs(r)
+		half.Lsh(half, 1)
+		switch half.Cmp(new(big.Int).Abs(den)) {
+		case 1:
+			away = true
+		case 0:
+			away = mode == HalfUp || q.Bit(0) == 1
+		}
+	}
+	if away {
+		q.Add(q, big.NewInt(sign))
+	}
+	return q
+}
+
+func pow10(n int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
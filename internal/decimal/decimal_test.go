@@ -0,0 +1,120 @@
+package decimal
+
+import "testing"
+
+func TestParseAndString(t *testing.T) {
+	cases := map[string]string{
+		"0":          "0",
+		"42":         "42",
+		"-0.001":     "-0.001",
+		"1.50":       "1.5",
+		"1.5e-8":     "0.000000015",
+		"2.5E3":      "2500",
+		"0.00000000": "0",
+		"-0.0":       "0",
+	}
+	for in, want := range cases {
+		d, err := Parse(in)
+		if err != nil {
+			t.Fatalf("Parse(%q) : %v", in, err)
+		}
+		if got := d.String(); got != want {
+			t.Errorf("Parse(%q).String() = %q, attendu %q", in, got, want)
+		}
+	}
+	for _, in := range []string{"", "-", "abc", "1.2.3", "1e", "0x10", "1_000"} {
+		if _, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) accepté", in)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	for f, want := range map[float64]string{0.1: "0.1", 1e-8: "0.00000001", 123456.789: "123456.789", -2: "-2", 1e21: "1000000000000000000000"} {
+		if got := FromFloat(f).String(); got != want {
+			t.Errorf("FromFloat(%v) = %s, attendu %s", f, got, want)
+		}
+	}
+	// L'erreur flottante classique disparaît
+	if got := FromFloat(0.1).Add(FromFloat(0.2)).Float64(); got != 0.3 {
+		t.Errorf("0.1 + 0.2 = %v", got)
+	}
+	if got := FromFloat(1).Sub(FromFloat(0.7)).String(); got != "0.3" {
+		t.Errorf("1 - 0.7 = %s", got)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := MustParse("1.25"), MustParse("-0.5")
+	if got := a.Mul(b).String(); got != "-0.625" {
+		t.Errorf("Mul = %s", got)
+	}
+	if got := a.Quo(MustParse("3")).String(); got != "0.416666666666666667" {
+		t.Errorf("Quo = %s", got)
+	}
+	if got := a.Div(MustParse("3"), 2, Down).String(); got != "0.41" {
+		t.Errorf("Div Down = %s", got)
+	}
+	if a.Cmp(b) != 1 || b.Cmp(a) != -1 || a.Cmp(MustParse("1.250")) != 0 {
+		t.Error("Cmp")
+	}
+	if b.Abs().Cmp(MustParse("0.5")) != 0 || b.Neg().Sign() != 1 || !Zero.IsZero() {
+		t.Error("Abs/Neg/Sign/IsZero")
+	}
+}
+
+func TestRound(t *testing.T) {
+	cases := []struct {
+		in   string
+		mode RoundingMode
+		want string
+	}{
+		{"2.345", Down, "2.34"}, {"-2.345", Down, "-2.34"},
+		{"2.341", Up, "2.35"}, {"-2.341", Up, "-2.35"},
+		{"-2.341", Floor, "-2.35"}, {"-2.349", Ceil, "-2.34"},
+		{"2.345", HalfUp, "2.35"}, {"-2.345", HalfUp, "-2.35"},
+		{"2.345", HalfEven, "2.34"}, {"2.355", HalfEven, "2.36"}, {"2.3451", HalfEven, "2.35"},
+		{"2.3", Down, "2.3"},
+	}
+	for _, tc := range cases {
+		if got := MustParse(tc.in).Round(2, tc.mode).String(); got != tc.want {
+			t.Errorf("Round(%s, %d) = %s, attendu %s", tc.in, tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestRoundStep(t *testing.T) {
+	cases := []struct {
+		in, step string
+		mode     RoundingMode
+		want     string
+	}{
+		// 0.29 / 0.01 vaut 28.999999999999996 en float64 : un tick trop bas en int64(price*factor)
+		{"0.29", "0.01", Down, "0.29"},
+		{"1.005", "0.01", Down, "1"},
+		{"0.123", "0.05", Up, "0.15"},
+		{"0.15", "0.05", Up, "0.15"},
+		{"103.7", "5", HalfUp, "105"},
+		// Actif à très petit prix : pas de débordement int64
+		{"0.0000000012345678", "0.0000000000000001", Down, "0.0000000012345678"},
+		{"123456789012.5", "0.00000001", Down, "123456789012.5"},
+		{"7", "0", Down, "7"},
+	}
+	for _, tc := range cases {
+		if got := MustParse(tc.in).RoundStep(MustParse(tc.step), tc.mode).String(); got != tc.want {
+			t.Errorf("RoundStep(%s, %s) = %s, attendu %s", tc.in, tc.step, got, tc.want)
+		}
+	}
+}
+
+func TestStringFixed(t *testing.T) {
+	for _, tc := range []struct {
+		in     string
+		places int32
+		want   string
+	}{{"1.5", 4, "1.5000"}, {"0.000123456", 6, "0.000123"}, {"-0.0049", 2, "0.00"}, {"2.345", 2, "2.35"}, {"12", 0, "12"}} {
+		if got := MustParse(tc.in).StringFixed(tc.places); got != tc.want {
+			t.Errorf("StringFixed(%s, %d) = %s, attendu %s", tc.in, tc.places, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Package events diffuse les événements du bot (ordres, cycles, refus d'achat,
+// disjoncteur, erreurs) : chaque événement est persisté dans la table events puis
+// transmis aux abonnés (canaux de notification du package notify, webhook signé). La
+// WebUI les relit depuis la base pour son flux SSE (/api/events/stream).
+package events
+
+import (
+	"sync"
+	"time"
+
+	"bot/internal/logger"
+)
+
+// Type identifie un événement.
+type Type string
+
+const (
+	OrderPlaced     Type = "order.placed"     // ordre posé sur l'exchange
+	OrderFilled     Type = "order.filled"     // ordre exécuté (totalement, ou achat annulé après remplissage partiel)
+	OrderCancelled  Type = "order.cancelled"  // ordre annulé sans exécution
+	CycleOpened     Type = "cycle.opened"     // cycle créé avec son ordre d'achat
+	CycleCompleted  Type = "cycle.completed"  // vente du cycle exécutée
+	BuySkipped      Type = "buy.skipped"      // achat refusé par une limite de risque
+	BotPaused       Type = "bot.paused"       // pause (achats et ventes suspendus)
+	BotResumed      Type = "bot.resumed"      // reprise après une pause
+	BreakerTripped  Type = "breaker.tripped"  // disjoncteur déclenché ou arrêt d'urgence
+	BreakerReset    Type = "breaker.reset"    // disjoncteur réarmé
+	ReversalSignal  Type = "signal.reversal"  // figure de retournement 1h
+	ReconcileReport Type = "reconcile.report" // écarts base / exchange au démarrage
+	ErrorRaised     Type = "error.raised"     // nouvelles erreurs journalisées (throttlé)
+)
+
+// Types liste tous les types d'événements, dans l'ordre de la documentation.
+var Types = []Type{
+	OrderPlaced, OrderFilled, OrderCancelled, CycleOpened, CycleCompleted, BuySkipped,
+	BotPaused, BotResumed, BreakerTripped, BreakerReset, ReversalSignal, ReconcileReport, ErrorRaised,
+}
+
+// Event est un événement du bot. Les identifiants sont à zéro (ou vides) quand ils ne
+// s'appliquent pas.
+type Event struct {
+	ID         int64  `json:"id"`
+	Type       Type   `json:"type"`
+	Exchange   string `json:"exchange"`
+	Pair       string `json:"pair,omitempty"`
+	StrategyID int    `json:"strategy_id,omitempty"`
+	CycleID    int    `json:"cycle_id,omitempty"`
+	OrderID    string `json:"order_id,omitempty"` // identifiant exchange de l'ordre
+	// Message : texte de la notification (Telegram, Discord...) ; vide = événement non notifié.
+	Message   string         `json:"message,omitempty"`
+	Data      map[string]any `json:"data,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Store persiste les événements ; RecordEvent renseigne e.ID.
+type Store interface {
+	RecordEvent(e *Event) error
+}
+
+// Sink reçoit les événements auxquels il est abonné.
+type Sink interface {
+	Name() string
+	Handle(e Event) error
+}
+
+// queueSize : événements en attente par abonné au-delà desquels les suivants sont
+// abandonnés (abonné lent ou injoignable), pour ne jamais bloquer la boucle du bot.
+const queueSize = 256
+
+type subscription struct {
+	sink  Sink
+	types map[Type]bool // vide = tous les types
+	queue chan Event
+}
+
+// Bus persiste et diffuse les événements. Un Bus nil est valide : Publish est alors
+// sans effet (tests, outils hors démon).
+type Bus struct {
+	exchange string
+	store    Store
+	mu       sync.RWMutex
+	subs     []*subscription
+	closed   bool
+	wg       sync.WaitGroup
+}
+
+// NewBus crée un bus pour l'instance exchange. store peut être nil (pas de persistance).
+func NewBus(exchange string, store Store) *Bus {
+	return &Bus{exchange: exchange, store: store}
+}
+
+// Subscribe abonne sink aux types donnés (aucun = tous). Chaque abonné a sa propre file,
+// traitée dans l'ordre par une goroutine dédiée.
+func (b *Bus) Subscribe(sink Sink, types ...Type) {
+	sub := &subscription{sink: sink, types: make(map[Type]bool), queue: make(chan Event, queueSize)}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for e := range sub.queue {
+			if err := sub.sink.Handle(e); err != nil {
+				logger.Warnf("[%s] Événement %s non transmis à %s : %v", b.exchange, e.Type, sub.sink.Name(), err)
+			}
+		}
+	}()
+}
+
+// Publish horodate et persiste e, puis le transmet aux abonnés concernés sans attendre
+// leur traitement.
+func (b *Bus) Publish(e Event) {
+	if b == nil {
+		return
+	}
+	e.Exchange = b.exchange
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().UTC()
+	}
+	if b.store != nil {
+		// Warn et non Error : une erreur journalisée déclencherait elle-même un événement
+		if err := b.store.RecordEvent(&e); err != nil {
+			logger.Warnf("[%s] Échec de l'enregistrement de l'événement %s : %v", b.exchange, e.Type, err)
+		}
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return
+	}
+	for _, sub := range b.subs {
+		if len(sub.types) > 0 && !sub.types[e.Type] {
+			continue
+		}
+		select {
+		case sub.queue <- e:
+		default:
+			logger.Warnf("[%s] File de %s pleine : événement %s abandonné", b.exchange, sub.sink.Name(), e.Type)
+		}
+	}
+}
+
+// Close ferme les files et attend que les abonnés aient traité les événements en attente.
+func (b *Bus) Close() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	for _, sub := range b.subs {
+		close(sub.queue)
+	}
+	b.mu.Unlock()
+	b.wg.Wait()
+}
+
+// ParseTypes convertit une liste de noms de types (vide = nil, tous les types). Les noms
+// inconnus sont retournés à part pour être signalés.
+func ParseTypes(list []string) (types []Type, unknown []string) {
+	known := make(map[Type]bool, len(Types))
+	for _, t := range Types {
+		known[t] = true
+	}
+	for _, s := range list {
+		if t := Type(s); known[t] {
+			types = append(types, t)
+		} else {
+			unknown = append(unknown, s)
+		}
+	}
+	return types, unknown
+}
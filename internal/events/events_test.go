@@ -0,0 +1,121 @@
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"bot/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error", "")
+	os.Exit(m.Run())
+}
+
+type memStore struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *memStore) RecordEvent(e *Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.ID = int64(len(s.events) + 1)
+	s.events = append(s.events, *e)
+	return nil
+}
+
+type memSink struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func (s *memSink) Name() string { return "mem" }
+
+func (s *memSink) Handle(e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+// Tous les événements sont persistés ; chaque abonné ne reçoit que les types demandés.
+func TestBusPublish(t *testing.T) {
+	store := &memStore{}
+	bus := NewBus("mexc", store)
+	all, fills := &memSink{}, &memSink{}
+	bus.Subscribe(all)
+	bus.Subscribe(fills, OrderFilled)
+
+	bus.Publish(Event{Type: OrderPlaced, Pair: "BTC/USDC", OrderID: "1"})
+	bus.Publish(Event{Type: OrderFilled, Pair: "BTC/USDC", OrderID: "1", Message: "achat exécuté"})
+	bus.Close()
+	bus.Publish(Event{Type: OrderCancelled}) // après Close : persisté, non diffusé
+
+	if len(store.events) != 3 {
+		t.Fatalf("%d événements persistés, attendu 3", len(store.events))
+	}
+	if e := store.events[0]; e.Exchange != "mexc" || e.CreatedAt.IsZero() {
+		t.Errorf("événement non complété : %+v", e)
+	}
+	if len(all.events) != 2 {
+		t.Errorf("abonné sans filtre : %d événements, attendu 2", len(all.events))
+	}
+	if len(fills.events) != 1 || fills.events[0].Type != OrderFilled || fills.events[0].ID != 2 {
+		t.Errorf("abonné order.filled : %+v", fills.events)
+	}
+
+	var nilBus *Bus
+	nilBus.Publish(Event{Type: OrderPlaced}) // sans effet
+	nilBus.Close()
+}
+
+// Le webhook reçoit l'événement en JSON, signé avec le secret partagé.
+func TestWebhookSink(t *testing.T) {
+	var got Event
+	var body []byte
+	var header http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header
+		_ = json.Unmarshal(body, &got)
+	}))
+	defer srv.Close()
+
+	sink := &WebhookSink{URL: srv.URL, Secret: "s3cret"}
+	if err := sink.Handle(Event{ID: 7, Type: CycleCompleted, Pair: "BTC/USDC", CycleID: 3}); err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 7 || got.Type != CycleCompleted || got.CycleID != 3 {
+		t.Errorf("événement reçu : %+v", got)
+	}
+	if header.Get("X-SimpleBot-Event") != string(CycleCompleted) {
+		t.Errorf("X-SimpleBot-Event = %q", header.Get("X-SimpleBot-Event"))
+	}
+	if sig := header.Get("X-SimpleBot-Signature"); sig != "sha256="+Sign("s3cret", body) {
+		t.Errorf("signature invalide : %q", sig)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	if err := (&WebhookSink{URL: failing.URL}).Handle(Event{Type: OrderPlaced}); err == nil {
+		t.Error("erreur attendue sur une réponse 500")
+	}
+}
+
+func TestParseTypes(t *testing.T) {
+	types, unknown := ParseTypes([]string{"order.filled", "cycle.completed", "order.lost"})
+	if len(types) != 2 || types[0] != OrderFilled || types[1] != CycleCompleted {
+		t.Errorf("types = %v", types)
+	}
+	if len(unknown) != 1 || unknown[0] != "order.lost" {
+		t.Errorf("inconnus = %v", unknown)
+	}
+}
@@ -0,0 +1,66 @@
+package events
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSink poste chaque événement en JSON sur une URL. Si Secret est renseigné, le
+// corps est signé en HMAC-SHA256 dans l'en-tête X-SimpleBot-Signature (« sha256=<hex> »),
+// que le destinataire vérifie avec le même secret.
+type WebhookSink struct {
+	URL    string
+	Secret string
+	Client *http.Client // nil = client par défaut (timeout 10s)
+}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+func (w *WebhookSink) Name() string { return "webhook" }
+
+func (w *WebhookSink) Handle(e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-SimpleBot-Event", string(e.Type))
+	if w.Secret != "" {
+		req.Header.Set("X-SimpleBot-Signature", "sha256="+Sign(w.Secret, body))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = webhookClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// Sign retourne la signature HMAC-SHA256 hexadécimale de body avec secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
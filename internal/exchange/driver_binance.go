@@ -0,0 +1,19 @@
+//go:build binance
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("binance", driver{
+		config:  func() map[string]interface{} { return credentials("BINANCE") },
+		rest:    func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewBinance(config) },
+		pro:     func(config map[string]interface{}) proExchange { return ccxtpro.NewBinance(config) },
+		sandbox: sandboxEnv("BINANCE"),
+		// -1021 : "Timestamp for this request is outside of the recvWindow"
+		timestampCodes: []int{-1021},
+	})
+}
@@ -0,0 +1,18 @@
+//go:build bitget
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("bitget", driver{
+		config: func() map[string]interface{} { return credentials("BITGET") },
+		rest:   func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewBitget(config) },
+		pro:    func(config map[string]interface{}) proExchange { return ccxtpro.NewBitget(config) },
+		// 40008 : "Request timestamp expired"
+		timestampCodes: []int{40008},
+	})
+}
@@ -0,0 +1,19 @@
+//go:build bybit
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("bybit", driver{
+		config:  func() map[string]interface{} { return credentials("BYBIT") },
+		rest:    func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewBybit(config) },
+		pro:     func(config map[string]interface{}) proExchange { return ccxtpro.NewBybit(config) },
+		sandbox: sandboxEnv("BYBIT"),
+		// 10002 : "invalid request, please check your server timestamp or recv_window param"
+		timestampCodes: []int{10002},
+	})
+}
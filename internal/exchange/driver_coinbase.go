@@ -0,0 +1,16 @@
+//go:build coinbase
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("coinbase", driver{
+		config: func() map[string]interface{} { return credentials("COINBASE") },
+		rest:   func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewCoinbase(config) },
+		pro:    func(config map[string]interface{}) proExchange { return ccxtpro.NewCoinbase(config) },
+	})
+}
@@ -0,0 +1,29 @@
+package exchange
+
+import (
+	"os"
+
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("hyperliquid", driver{
+		config: func() map[string]interface{} {
+			return map[string]interface{}{
+				"walletAddress": os.Getenv("HL_WALLET_ADDRESS"),
+				"privateKey":    os.Getenv("HL_PRIVATE_KEY"),
+				"options": map[string]interface{}{
+					"defaultType":   "spot",
+					"defaultMarket": "spot",
+					"fetchMarkets": map[string]interface{}{
+						"types": []string{"spot"}, // without "swap" and "hip3"
+					},
+				},
+			}
+		},
+		rest:    func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewHyperliquid(config) },
+		pro:     func(config map[string]interface{}) proExchange { return ccxtpro.NewHyperliquid(config) },
+		sandbox: func() bool { return os.Getenv("HL_NETWORK") == "testnet" },
+	})
+}
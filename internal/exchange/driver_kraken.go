@@ -0,0 +1,16 @@
+//go:build kraken
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("kraken", driver{
+		config: func() map[string]interface{} { return credentials("KRAKEN") },
+		rest:   func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewKraken(config) },
+		pro:    func(config map[string]interface{}) proExchange { return ccxtpro.NewKraken(config) },
+	})
+}
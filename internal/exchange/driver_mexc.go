@@ -0,0 +1,24 @@
+package exchange
+
+import (
+	"os"
+
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("mexc", driver{
+		config: func() map[string]interface{} {
+			return map[string]interface{}{
+				"apiKey":          os.Getenv("MEXC_API_KEY"),
+				"secret":          os.Getenv("MEXC_SECRET"),
+				"enableRateLimit": true,
+			}
+		},
+		rest: func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewMexc(config) },
+		pro:  func(config map[string]interface{}) proExchange { return ccxtpro.NewMexc(config) },
+		// 700003 : "Timestamp for this request is outside of the recvWindow"
+		timestampCodes: []int{700003},
+	})
+}
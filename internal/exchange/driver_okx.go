@@ -0,0 +1,19 @@
+//go:build okx
+
+package exchange
+
+import (
+	ccxt "github.com/ccxt/ccxt/go/v4"
+	ccxtpro "github.com/ccxt/ccxt/go/v4/pro"
+)
+
+func init() {
+	register("okx", driver{
+		config:  func() map[string]interface{} { return credentials("OKX") },
+		rest:    func(config map[string]interface{}) ccxt.IExchange { return ccxt.NewOkx(config) },
+		pro:     func(config map[string]interface{}) proExchange { return ccxtpro.NewOkx(config) },
+		sandbox: sandboxEnv("OKX"), // démo (x-simulated-trading)
+		// 50102 : "Timestamp request expired"
+		timestampCodes: []int{50102},
+	})
+}
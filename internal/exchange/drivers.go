@@ -0,0 +1,74 @@
+package exchange
+
+import (
+	"os"
+	"sort"
+
+	ccxt "github.com/ccxt/ccxt/go/v4"
+)
+
+// driver décrit un exchange ccxt supporté : sa configuration (identifiants lus dans
+// l'environnement), ses constructeurs REST et WebSocket (ccxt pro), son mode
+// sandbox/testnet et ses codes d'erreur d'horodatage.
+//
+// Chaque exchange s'enregistre depuis son propre fichier (driver_<nom>.go). MEXC et
+// Hyperliquid sont toujours compilés ; les autres sont derrière un build tag du même
+// nom (go build -tags binance,okx ...). Seuls les constructeurs enregistrés sont
+// atteignables pour le linker : un binaire par défaut n'embarque pas les autres
+// exchanges ccxt (cf. NewExchange).
+type driver struct {
+	config func() map[string]interface{}
+	rest   func(config map[string]interface{}) ccxt.IExchange
+	pro    func(config map[string]interface{}) proExchange
+	// sandbox : vrai si l'instance doit utiliser le testnet de l'exchange (nil = pas
+	// de testnet côté ccxt)
+	sandbox func() bool
+	// timestampCodes : codes d'erreur de l'exchange signalant un horodatage hors de la
+	// fenêtre acceptée (recvWindow), retentés par retryWithBackoff
+	timestampCodes []int
+}
+
+var drivers = map[string]driver{}
+
+// timestampErrorCodes regroupe les timestampCodes des exchanges compilés.
+var timestampErrorCodes = map[int]bool{}
+
+func register(name string, d driver) {
+	drivers[name] = d
+	for _, code := range d.timestampCodes {
+		timestampErrorCodes[code] = true
+	}
+}
+
+// Supported retourne les exchanges compilés dans ce binaire, triés par nom.
+func Supported() []string {
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// credentials construit la configuration ccxt commune aux exchanges à clé d'API :
+// <PREFIX>_API_KEY, <PREFIX>_SECRET et, si renseignée, la passphrase <PREFIX>_PASSWORD.
+// Les marchés sont restreints au spot.
+func credentials(prefix string) map[string]interface{} {
+	config := map[string]interface{}{
+		"apiKey":          os.Getenv(prefix + "_API_KEY"),
+		"secret":          os.Getenv(prefix + "_SECRET"),
+		"enableRateLimit": true,
+		"options": map[string]interface{}{
+			"defaultType": "spot",
+		},
+	}
+	if password := os.Getenv(prefix + "_PASSWORD"); password != "" {
+		config["password"] = password
+	}
+	return config
+}
+
+// sandboxEnv retourne un test de mode sandbox lisant <PREFIX>_SANDBOX=1.
+func sandboxEnv(prefix string) func() bool {
+	return func() bool { return os.Getenv(prefix+"_SANDBOX") == "1" }
+}
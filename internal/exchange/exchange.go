@@ -2,10 +2,11 @@ package exchange
 
 import (
 	"bot/internal/bot"
+	"bot/internal/metrics"
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
+	"strconv"
 	"strings"
 	"time"
 
@@ -17,40 +18,68 @@ const (
 	baseRetryDelay = 1000 * time.Millisecond
 )
 
+// Métriques des appels exchange, étiquetées par opération (get_price, fetch_order...).
+var (
+	exchangeCallDuration = metrics.Default.NewHistogram("simplebot_exchange_request_duration_seconds",
+		"Durée des appels exchange, retries compris.", metrics.DefaultBuckets, "operation")
+	exchangeCallErrors = metrics.Default.NewCounter("simplebot_exchange_errors_total",
+		"Appels exchange en échec après retries.", "operation")
+	exchangeCallRetries = metrics.Default.NewCounter("simplebot_exchange_retries_total",
+		"Nouvelles tentatives sur erreur de timestamp/nonce.", "operation")
+)
+
 // Timeframes supportés universellement
 var SupportedTimeframes = []string{"1m", "5m", "15m", "30m", "1h", "4h", "1d", "1w", "1M"}
 
-// MexcErrorResponse représente la structure d'erreur JSON de MEXC
-type MexcErrorResponse struct {
-	Code int    `json:"code"`
-	Msg  string `json:"msg"`
+// errorCode extrait le code d'erreur de la réponse JSON brute d'un exchange, que ccxt
+// place dans le message, éventuellement précédée de l'identifiant de l'exchange
+// (`binance {"code":-1021,...}`). Le code est lu dans "code" ou "retCode" (Bybit),
+// numérique ou chaîne (OKX, Bitget).
+func errorCode(message string) (int, bool) {
+	start := strings.IndexByte(message, '{')
+	if start == -1 {
+		return 0, false
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(message[start:]), &body); err != nil {
+		return 0, false
+	}
+	for _, key := range []string{"code", "retCode"} {
+		switch v := body[key].(type) {
+		case float64:
+			return int(v), true
+		case string:
+			if code, err := strconv.Atoi(v); err == nil {
+				return code, true
+			}
+		}
+	}
+	return 0, false
 }
 
-// isTimestampError vérifie si l'erreur est liée à un problème de timestamp/nonce
+// isTimestampError vérifie si l'erreur est liée à un problème de timestamp/nonce :
+// code d'horodatage déclaré par un driver (timestampCodes), ou erreur ccxt InvalidNonce
+// sans code exploitable.
 func isTimestampError(err error) bool {
 	if err == nil {
 		return false
 	}
 
 	// Méthode idiomatique Go : vérifier le type d'erreur CCXT
-	if ccxtError, ok := err.(*ccxt.Error); ok {
-		// Vérifier si c'est une erreur InvalidNonce
-		if ccxtError.Type == ccxt.InvalidNonceErrType {
-			// Pour les erreurs InvalidNonce, parser le JSON pour vérifier le code spécifique
-			if ccxtError.Message != "" {
-				var mexcErr MexcErrorResponse
-				if err := json.Unmarshal([]byte(ccxtError.Message), &mexcErr); err == nil {
-					// Code 700003 = "Timestamp for this request is outside of the recvWindow"
-					return mexcErr.Code == 700003
-				}
-			}
-			// Si on ne peut pas parser le JSON mais que c'est InvalidNonce,
-			// on considère que c'est probablement un problème de timestamp
-			return true
-		}
+	ccxtError, ok := err.(*ccxt.Error)
+	if !ok {
+		return false
 	}
 
-	return false
+	// Un code connu tranche : certains exchanges classent l'horodatage hors recvWindow
+	// ailleurs qu'en InvalidNonce, et un InvalidNonce portant un autre code n'est pas
+	// un décalage d'horloge.
+	if code, ok := errorCode(ccxtError.Message); ok {
+		return timestampErrorCodes[code]
+	}
+	// Si on ne peut pas lire de code mais que c'est InvalidNonce, on considère que
+	// c'est probablement un problème de timestamp
+	return ccxtError.Type == ccxt.InvalidNonceErrType
 }
 
 // callSafe exécute fn en capturant les panics CCXT et en les convertissant en erreurs Go
@@ -86,26 +115,35 @@ func cleanCCXTError(err error) error {
 	return err
 }
 
-// retryWithBackoff exécute une fonction avec retry et backoff exponentiel
-func retryWithBackoff(operation func() error) error {
+// retryWithBackoff exécute une fonction avec retry et backoff exponentiel. op nomme
+// l'opération dans les métriques (durée totale, erreurs, retries).
+func retryWithBackoff(op string, operation func() error) (err error) {
+	start := time.Now()
+	defer func() {
+		exchangeCallDuration.Observe(time.Since(start).Seconds(), op)
+		if err != nil {
+			exchangeCallErrors.Inc(op)
+		}
+	}()
+
 	var lastError error
 
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		err := callSafe(operation)
-		if err == nil {
+		callErr := callSafe(operation)
+		if callErr == nil {
 			return nil // Succès
 		}
 
-		lastError = err
+		lastError = callErr
 
 		// Si ce n'est pas une erreur de timestamp, ne pas réessayer
-		if !isTimestampError(err) {
-			return cleanCCXTError(err)
+		if !isTimestampError(callErr) {
+			return cleanCCXTError(callErr)
 		}
 
 		// Si c'est le dernier essai, retourner l'erreur
 		if attempt == maxRetries-1 {
-			return cleanCCXTError(err)
+			return cleanCCXTError(callErr)
 		}
 
 		// Calculer le délai avec backoff exponentiel + jitter
@@ -113,6 +151,7 @@ func retryWithBackoff(operation func() error) error {
 		jitter := time.Duration(float64(delay) * 0.1)       // 10% de jitter
 		totalDelay := delay + jitter
 
+		exchangeCallRetries.Inc(op)
 		time.Sleep(totalDelay)
 	}
 
@@ -122,36 +161,24 @@ func retryWithBackoff(operation func() error) error {
 type Exchange struct {
 	ccxt.IExchange
 	name string
+	// pro : client WebSocket ccxt pro, ouvert par EnableStreaming (nil = polling seul)
+	pro proExchange
 }
 
 func NewExchange(exchangeName string) *Exchange {
 	var exchange ccxt.IExchange
 
-	// On appelle les constructeurs concrets (NewMexc, NewHyperliquid) plutôt que la
-	// factory générique ccxt.CreateExchange(string, ...) : celle-ci est un switch de 106
-	// exchanges, tous rendus « atteignables » pour le linker (le string est runtime), ce
-	// qui empêche l'élimination de code mort et embarque les 106 dans le binaire. Les
-	// constructeurs typés ne joignent que l'exchange voulu (~130 Mo → ~35-45 Mo).
-	switch exchangeName {
-	case "mexc":
-		exchange = ccxt.NewMexc(map[string]interface{}{
-			"apiKey":          os.Getenv("MEXC_API_KEY"),
-			"secret":          os.Getenv("MEXC_SECRET"),
-			"enableRateLimit": true,
-		})
-	case "hyperliquid":
-		exchange = ccxt.NewHyperliquid(map[string]interface{}{
-			"walletAddress": os.Getenv("HL_WALLET_ADDRESS"),
-			"privateKey":    os.Getenv("HL_PRIVATE_KEY"),
-			"options": map[string]interface{}{
-				"defaultType":   "spot",
-				"defaultMarket": "spot",
-				"fetchMarkets": map[string]interface{}{
-					"types": []string{"spot"}, // without "swap" and "hip3"
-				},
-			},
-		})
-		exchange.SetSandboxMode(os.Getenv("HL_NETWORK") == "testnet")
+	// On appelle les constructeurs concrets (NewMexc, NewHyperliquid...) enregistrés par
+	// les drivers plutôt que la factory générique ccxt.CreateExchange(string, ...) :
+	// celle-ci est un switch de 106 exchanges, tous rendus « atteignables » pour le
+	// linker (le string est runtime), ce qui empêche l'élimination de code mort et
+	// embarque les 106 dans le binaire. Les constructeurs typés ne joignent que les
+	// exchanges compilés (~130 Mo → ~35-45 Mo, cf. drivers.go pour les build tags).
+	if d, ok := drivers[exchangeName]; ok {
+		exchange = d.rest(d.config())
+		if d.sandbox != nil && d.sandbox() {
+			exchange.SetSandboxMode(true)
+		}
 	}
 
 	if exchange != nil {
@@ -166,8 +193,8 @@ func NewExchange(exchangeName string) *Exchange {
 
 func (e *Exchange) GetPrice(pair string) (float64, error) {
 	var result ccxt.Ticker
-	err := retryWithBackoff(func() error {
-		ticker, tickerErr := e.FetchTicker(pair)
+	err := retryWithBackoff("get_price", func() error {
+		ticker, tickerErr := e.IExchange.FetchTicker(pair)
 		if tickerErr == nil {
 			result = ticker
 		}
@@ -179,10 +206,37 @@ func (e *Exchange) GetPrice(pair string) (float64, error) {
 	return *result.Last, nil
 }
 
-func (e *Exchange) PlaceLimitBuyOrder(pair string, amount float64, price float64) (bot.Order, error) {
+func (e *Exchange) FetchTicker(pair string) (bot.Ticker, error) {
+	var result ccxt.Ticker
+	err := retryWithBackoff("fetch_ticker", func() error {
+		ticker, tickerErr := e.IExchange.FetchTicker(pair)
+		if tickerErr == nil {
+			result = ticker
+		}
+		return tickerErr
+	})
+	if err != nil {
+		return bot.Ticker{}, err
+	}
+	if result.Last == nil {
+		return bot.Ticker{}, fmt.Errorf("ticker %s sans dernier prix", pair)
+	}
+	return toBotTicker(result), nil
+}
+
+// withClientOrderID transmet l'identifiant client de l'ordre (paramètre unifié ccxt
+// clientOrderId, traduit par ccxt dans le champ propre à chaque exchange).
+func withClientOrderID(clientOrderID string) []ccxt.CreateOrderOptions {
+	if clientOrderID == "" {
+		return nil
+	}
+	return []ccxt.CreateOrderOptions{ccxt.WithCreateOrderParams(map[string]interface{}{"clientOrderId": clientOrderID})}
+}
+
+func (e *Exchange) PlaceLimitBuyOrder(pair string, amount float64, price float64, clientOrderID string) (bot.Order, error) {
 	var result ccxt.Order
-	err := retryWithBackoff(func() error {
-		order, orderErr := e.CreateLimitBuyOrder(pair, amount, price)
+	err := retryWithBackoff("place_limit_buy_order", func() error {
+		order, orderErr := e.CreateLimitBuyOrder(pair, amount, price, withClientOrderID(clientOrderID)...)
 		if orderErr == nil {
 			result = order
 		}
@@ -195,10 +249,10 @@ func (e *Exchange) PlaceLimitBuyOrder(pair string, amount float64, price float64
 	return e.FetchOrder(*result.Id, pair)
 }
 
-func (e *Exchange) PlaceLimitSellOrder(pair string, amount float64, price float64) (bot.Order, error) {
+func (e *Exchange) PlaceLimitSellOrder(pair string, amount float64, price float64, clientOrderID string) (bot.Order, error) {
 	var result ccxt.Order
-	err := retryWithBackoff(func() error {
-		order, orderErr := e.CreateLimitSellOrder(pair, amount, price)
+	err := retryWithBackoff("place_limit_sell_order", func() error {
+		order, orderErr := e.CreateLimitSellOrder(pair, amount, price, withClientOrderID(clientOrderID)...)
 		if orderErr == nil {
 			result = order
 		}
@@ -227,7 +281,7 @@ func (e *Exchange) GetMarketsList() []bot.Market {
 
 func (e *Exchange) FetchBalance() (map[string]bot.Balance, error) {
 	var result ccxt.Balances
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("fetch_balance", func() error {
 		balances, balanceErr := e.IExchange.FetchBalance()
 		if balanceErr == nil {
 			result = balances
@@ -264,7 +318,7 @@ func withFetchOHLCVOptions(timeframe string, since *int64, limit int64) ccxt.Fet
 
 func (e *Exchange) FetchCandles(pair string, timeframe string, since *int64, limit int64) ([]bot.Candle, error) {
 	var result []ccxt.OHLCV
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("fetch_candles", func() error {
 		ohlcv, ohlcvErr := e.IExchange.FetchOHLCV(pair,
 			withFetchOHLCVOptions(timeframe, since, limit),
 		)
@@ -314,7 +368,7 @@ func withFetchMyTradeOptions(pair string, since *int64, until *int64, limit int6
 func (e *Exchange) FetchMyTrades(pair string, since *int64, until *int64, limit int64) ([]bot.Trade, error) {
 	var result []ccxt.Trade
 	// e.IExchange.SetVerbose(true)
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("fetch_my_trades", func() error {
 		trades, tradeErr := e.IExchange.FetchMyTrades(withFetchMyTradeOptions(pair, since, until, limit))
 		if tradeErr == nil {
 			result = trades
@@ -337,7 +391,7 @@ func (e *Exchange) FetchMyTrades(pair string, since *int64, until *int64, limit
 func (e *Exchange) FetchOrder(id string, symbol string) (bot.Order, error) {
 	var result ccxt.Order
 	// e.IExchange.SetVerbose(true)
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("fetch_order", func() error {
 		order, orderErr := e.IExchange.FetchOrder(id, ccxt.WithFetchOrderSymbol(symbol))
 		if orderErr == nil {
 			result = order
@@ -352,6 +406,54 @@ func (e *Exchange) FetchOrder(id string, symbol string) (bot.Order, error) {
 	return toBotOrder(result), nil
 }
 
+// FetchOrderByClientID retrouve un ordre par son identifiant client. Les exchanges qui
+// ne savent pas le chercher directement sont interrogés via leurs ordres ouverts ; un
+// ordre introuvable des deux façons est signalé par l'erreur d'origine.
+func (e *Exchange) FetchOrderByClientID(clientOrderID string, symbol string) (bot.Order, error) {
+	var result ccxt.Order
+	err := retryWithBackoff("fetch_order_by_client_id", func() error {
+		order, orderErr := e.IExchange.FetchOrder("", ccxt.WithFetchOrderSymbol(symbol),
+			ccxt.WithFetchOrderParams(map[string]interface{}{"clientOrderId": clientOrderID}))
+		if orderErr == nil {
+			result = order
+		}
+		return orderErr
+	})
+	if err == nil {
+		return toBotOrder(result), nil
+	}
+
+	open, openErr := e.FetchOpenOrders(symbol)
+	if openErr == nil {
+		for _, o := range open {
+			if o.ClientOrderId != nil && *o.ClientOrderId == clientOrderID {
+				return o, nil
+			}
+		}
+	}
+	return bot.Order{}, err
+}
+
+func (e *Exchange) FetchOpenOrders(pair string) ([]bot.Order, error) {
+	var result []ccxt.Order
+	err := retryWithBackoff("fetch_open_orders", func() error {
+		orders, ordersErr := e.IExchange.FetchOpenOrders(ccxt.WithFetchOpenOrdersSymbol(pair))
+		if ordersErr == nil {
+			result = orders
+		}
+		return ordersErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	botOrders := make([]bot.Order, len(result))
+	for i, order := range result {
+		botOrders[i] = toBotOrder(order)
+	}
+	return botOrders, nil
+}
+
 func withFetchMyTradeForOrderOptions(pair string, orderId string) ccxt.FetchMyTradesOptions {
 	return func(opts *ccxt.FetchMyTradesOptionsStruct) {
 		opts.Symbol = &pair
@@ -363,7 +465,7 @@ func withFetchMyTradeForOrderOptions(pair string, orderId string) ccxt.FetchMyTr
 func (e *Exchange) FetchTradesForOrder(id string, symbol string) ([]bot.Trade, error) {
 	var result []ccxt.Trade
 	// e.IExchange.SetVerbose(true)
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("fetch_trades_for_order", func() error {
 		trade, tradeErr := e.IExchange.FetchMyTrades(withFetchMyTradeForOrderOptions(symbol, id))
 		if tradeErr == nil {
 			result = trade
@@ -385,7 +487,7 @@ func (e *Exchange) FetchTradesForOrder(id string, symbol string) ([]bot.Trade, e
 
 func (e *Exchange) CancelOrder(id string, symbol string) (bot.Order, error) {
 	//var result ccxt.Order
-	err := retryWithBackoff(func() error {
+	err := retryWithBackoff("cancel_order", func() error {
 		_, orderErr := e.IExchange.CancelOrder(id, ccxt.WithCancelOrderSymbol(symbol))
 		//if orderErr == nil {
 		//	result = order
@@ -416,11 +518,22 @@ func toBotMarket(market ccxt.MarketInterface) bot.Market {
 				amountPrecision = ap
 				amountDecimals = -int(math.Log10(amountPrecision))
 			}
+		} else {
+			// Réponse brute sans précision (Binance, OKX...) : précision unifiée ccxt,
+			// retenue seulement sous forme de pas (< 1) ; un entier pourrait être un
+			// nombre de décimales (mode DECIMAL_PLACES).
+			if pp := market.Precision.Price; pp != nil && *pp > 0 && *pp < 1 {
+				pricePrecision = *pp
+				priceDecimals = -int(math.Log10(pricePrecision))
+			}
+			if ap := market.Precision.Amount; ap != nil && *ap > 0 && *ap < 1 {
+				amountPrecision = *ap
+				amountDecimals = -int(math.Log10(amountPrecision))
+			}
 		}
-
 	}
 
-	return bot.Market{
+	m := bot.Market{
 		Symbol:     *market.Symbol,
 		BaseId:     *market.BaseId,
 		BaseAsset:  *market.BaseCurrency,
@@ -438,15 +551,43 @@ func toBotMarket(market ccxt.MarketInterface) bot.Market {
 			AmountDecimals: amountDecimals,
 		},
 	}
+	m.Limits.MinAmount, m.Limits.MaxAmount = minMax(market.Limits.Amount)
+	m.Limits.MinCost, m.Limits.MaxCost = minMax(market.Limits.Cost)
+	m.Limits.MinPrice, m.Limits.MaxPrice = minMax(market.Limits.Price)
+	return m
+}
+
+// minMax lit une limite ccxt, une borne absente valant 0 (pas de limite).
+func minMax(limit ccxt.MinMax) (min, max float64) {
+	if limit.Min != nil {
+		min = *limit.Min
+	}
+	if limit.Max != nil {
+		max = *limit.Max
+	}
+	return min, max
 }
 
 func toBotOrder(order ccxt.Order) bot.Order {
 	return bot.Order{
-		Id:        order.Id,
-		Price:     order.Price,
-		Amount:    order.Amount,
-		Status:    order.Status,
-		Timestamp: order.Timestamp,
+		Id:            order.Id,
+		ClientOrderId: order.ClientOrderId,
+		Side:          order.Side,
+		Price:         order.Price,
+		Amount:        order.Amount,
+		Filled:        order.Filled,
+		Average:       order.Average,
+		Status:        order.Status,
+		Timestamp:     order.Timestamp,
+	}
+}
+
+func toBotTicker(ticker ccxt.Ticker) bot.Ticker {
+	return bot.Ticker{
+		Last:      ticker.Last,
+		Bid:       ticker.Bid,
+		Ask:       ticker.Ask,
+		Timestamp: ticker.Timestamp,
 	}
 }
 
@@ -470,6 +611,10 @@ func toBotTrade(trade ccxt.Trade) bot.Trade {
 				feeToken = &ft
 			}
 		}
+		// Réponse brute MEXC/Binance : devise des frais dans commissionAsset
+		if ft, ok := trade.Info["commissionAsset"].(string); ok && feeToken == nil && ft != "" {
+			feeToken = &ft
+		}
 	}
 
 	return bot.Trade{
@@ -0,0 +1,41 @@
+package exchange
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	ccxt "github.com/ccxt/ccxt/go/v4"
+)
+
+// Le code d'horodatage déclaré par un driver est reconnu quel que soit le type d'erreur
+// ccxt et la forme du message ; sans code lisible, seul InvalidNonce est retenté.
+func TestIsTimestampError(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"code MEXC", &ccxt.Error{Type: ccxt.InvalidNonceErrType, Message: `{"code":700003,"msg":"Timestamp for this request is outside of the recvWindow."}`}, true},
+		{"code préfixé, autre type", &ccxt.Error{Type: "ExchangeError", Message: `mexc {"code":700003,"msg":"outside of the recvWindow"}`}, true},
+		{"code chaîne inconnu", &ccxt.Error{Type: ccxt.InvalidNonceErrType, Message: `{"code":"12345","msg":"nonce"}`}, false},
+		{"InvalidNonce sans code", &ccxt.Error{Type: ccxt.InvalidNonceErrType, Message: `kraken {"error":["EAPI:Invalid nonce"]}`}, true},
+		{"autre erreur ccxt", &ccxt.Error{Type: "InsufficientFunds", Message: "solde insuffisant"}, false},
+		{"erreur Go", errors.New("timeout"), false},
+	}
+	for _, c := range cases {
+		if got := isTimestampError(c.err); got != c.want {
+			t.Errorf("%s : isTimestampError = %v, attendu %v", c.name, got, c.want)
+		}
+	}
+}
+
+// MEXC et Hyperliquid sont compilés sans build tag.
+func TestSupportedDefaultDrivers(t *testing.T) {
+	supported := Supported()
+	for _, name := range []string{"hyperliquid", "mexc"} {
+		if !slices.Contains(supported, name) {
+			t.Errorf("%s absent des exchanges compilés : %v", name, supported)
+		}
+	}
+}
@@ -0,0 +1,873 @@
+package exchange
+
+import (
+	"bot/internal/backtest"
+	"bot/internal/bot"
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===============================
+// EXCHANGE SIMULÉ (PAPER TRADING)
+// ===============================
+//
+// PaperExchange implémente bot.Exchange sans jamais toucher un exchange réel :
+// les soldes sont virtuels et les ordres limites sont exécutés contre une série
+// de bougies, lue soit dans la table `candles` de l'instance (PAPER_SOURCE=db),
+// soit dans un fichier de prix enregistré (PAPER_SOURCE=file).
+//
+// Horloge : en mode db sans PAPER_START, l'horloge simulée est l'heure réelle
+// (suivi « live » de la base, alimentée par ailleurs). Sinon, la série est rejouée
+// depuis PAPER_START (ou sa première bougie) à la vitesse PAPER_SPEED.
+//
+// Exécution : un ordre n'est confronté qu'aux bougies qui démarrent APRÈS sa pose,
+// une fois closes (achat rempli si low <= prix limite, vente si high >= prix
+// limite), au prix limite. Un ordre « marketable » (achat au-dessus / vente en dessous du prix
+// courant) est rempli immédiatement au prix courant, comme un taker. Les frais
+// sont toujours prélevés en devise de cotation.
+//
+// L'état (soldes, ordres, trades, horloge) est persisté en JSON dans
+// PAPER_STATE_FILE pour que FetchOrder/FetchBalance restent cohérents après un
+// redémarrage du bot.
+
+const (
+	paperStatusOpen     = "open"
+	paperStatusClosed   = "closed"
+	paperStatusCanceled = "canceled"
+
+	// paperReloadInterval : fréquence max de relecture des nouvelles bougies en base (mode live).
+	paperReloadInterval = 30 * time.Second
+)
+
+// PaperConfig paramètre l'exchange simulé.
+type PaperConfig struct {
+	Pair            string             // paire simulée (ex. BTC/USDC)
+	Source          string             // "db" (table candles) ou "file" (fichier de prix)
+	Timeframe       string             // timeframe de la série de prix (bougies en base ou du fichier)
+	PriceFile       string             // fichier CSV de prix (mode file)
+	Balances        map[string]float64 // soldes initiaux par actif
+	FeeRate         float64            // frais par côté en fraction (0.001 = 0,1 %)
+	PricePrecision  float64
+	AmountPrecision float64
+	StartMs         int64   // début du rejeu (0 = live en mode db, première bougie en mode file)
+	Speed           float64 // accélération de l'horloge simulée (1 = temps réel)
+	StateFile       string  // persistance JSON de l'état ("" = en mémoire uniquement)
+}
+
+// PaperConfigFromEnv lit la configuration PAPER_* depuis l'environnement.
+func PaperConfigFromEnv(pair string) PaperConfig {
+	cfg := PaperConfig{
+		Pair:            pair,
+		Source:          strings.ToLower(envOr("PAPER_SOURCE", "db")),
+		Timeframe:       envOr("PAPER_TIMEFRAME", "15m"),
+		PriceFile:       os.Getenv("PAPER_PRICE_FILE"),
+		FeeRate:         envFloat("PAPER_FEE_RATE", 0.001),
+		PricePrecision:  envFloat("PAPER_PRICE_PRECISION", 0.01),
+		AmountPrecision: envFloat("PAPER_AMOUNT_PRECISION", 0.000001),
+		Speed:           envFloat("PAPER_SPEED", 1),
+		StateFile:       envOr("PAPER_STATE_FILE", "db/paper.json"),
+	}
+
+	cfg.Balances = parsePaperBalances(os.Getenv("PAPER_BALANCES"))
+	if len(cfg.Balances) == 0 {
+		// Par défaut : 1000 unités de la devise de cotation.
+		if _, quote, ok := strings.Cut(pair, "/"); ok {
+			cfg.Balances = map[string]float64{quote: 1000}
+		}
+	}
+
+	if start := os.Getenv("PAPER_START"); start != "" {
+		if t, err := time.ParseInLocation("2006-01-02", start, time.Local); err == nil {
+			cfg.StartMs = t.UnixMilli()
+		} else {
+			logger.Warnf("[paper] PAPER_START invalide (%q), attendu YYYY-MM-DD : ignoré", start)
+		}
+	}
+	return cfg
+}
+
+// parsePaperBalances lit une liste "USDC:1000,BTC:0.01".
+func parsePaperBalances(s string) map[string]float64 {
+	balances := make(map[string]float64)
+	for _, part := range strings.Split(s, ",") {
+		asset, amount, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(amount), 64)
+		if err != nil {
+			logger.Warnf("[paper] Solde initial invalide %q : ignoré", part)
+			continue
+		}
+		balances[strings.ToUpper(strings.TrimSpace(asset))] = v
+	}
+	return balances
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+func envFloat(key string, fallback float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || v <= 0 {
+		return fallback
+	}
+	return v
+}
+
+type paperBalance struct {
+	Free float64 `json:"free"`
+	Used float64 `json:"used"`
+}
+
+type paperOrder struct {
+	Id            string  `json:"id"`
+	ClientOrderId string  `json:"client_order_id,omitempty"`
+	Side          string  `json:"side"` // buy, sell
+	Price         float64 `json:"price"`
+	Amount        float64 `json:"amount"`
+	Status        string  `json:"status"`
+	Average       float64 `json:"average,omitempty"` // prix d'exécution (0 tant que non rempli)
+	Locked        float64 `json:"locked"`            // montant bloqué (quote pour un achat, base pour une vente)
+	Timestamp     int64   `json:"timestamp"`
+}
+
+type paperTrade struct {
+	Id        string  `json:"id"`
+	OrderId   string  `json:"order_id"`
+	Side      string  `json:"side"`
+	Timestamp int64   `json:"timestamp"`
+	Price     float64 `json:"price"`
+	Amount    float64 `json:"amount"`
+	Fee       float64 `json:"fee"`
+	Taker     bool    `json:"taker"`
+}
+
+// paperState est la partie persistée de l'exchange simulé.
+type paperState struct {
+	SimTime  int64                    `json:"sim_time"`
+	NextId   int                      `json:"next_id"`
+	Balances map[string]*paperBalance `json:"balances"`
+	Orders   []*paperOrder            `json:"orders"`
+	Trades   []paperTrade             `json:"trades"`
+}
+
+// PaperExchange est un exchange simulé implémentant bot.Exchange.
+type PaperExchange struct {
+	mu     sync.Mutex
+	cfg    PaperConfig
+	db     *database.DB
+	market bot.Market
+	series []bot.Candle // bougies de la série de prix, ordre chronologique
+	cursor int          // index de la dernière bougie déjà confrontée aux ordres (-1 = aucune)
+	state  paperState
+
+	now        func() time.Time // horloge murale (injectable pour les tests)
+	launchedAt time.Time
+	replayFrom int64 // horodatage simulé au lancement (mode rejeu)
+	live       bool
+	lastReload time.Time
+}
+
+// NewPaperExchange construit l'exchange simulé. db n'est requis qu'en mode "db".
+func NewPaperExchange(cfg PaperConfig, db *database.DB) (*PaperExchange, error) {
+	return newPaperExchange(cfg, db, time.Now)
+}
+
+func newPaperExchange(cfg PaperConfig, db *database.DB, now func() time.Time) (*PaperExchange, error) {
+	base, quote, ok := strings.Cut(cfg.Pair, "/")
+	if !ok {
+		return nil, fmt.Errorf("paire invalide %q (attendu BASE/QUOTE)", cfg.Pair)
+	}
+	if cfg.Speed <= 0 {
+		cfg.Speed = 1
+	}
+
+	p := &PaperExchange{
+		cfg:    cfg,
+		db:     db,
+		market: paperMarket(cfg.Pair, base, quote, cfg.PricePrecision, cfg.AmountPrecision),
+		cursor: -1,
+		now:    now,
+		state:  paperState{NextId: 1, Balances: make(map[string]*paperBalance)},
+	}
+
+	switch cfg.Source {
+	case "db":
+		if db == nil {
+			return nil, fmt.Errorf("source db : base de données requise")
+		}
+		candles, err := db.GetAllCandles(cfg.Pair, cfg.Timeframe)
+		if err != nil {
+			return nil, fmt.Errorf("lecture des bougies %s/%s : %w", cfg.Pair, cfg.Timeframe, err)
+		}
+		p.series = fromDBCandles(candles)
+		p.live = cfg.StartMs == 0
+	case "file":
+		series, err := loadPriceFile(cfg.PriceFile)
+		if err != nil {
+			return nil, err
+		}
+		p.series = series
+	default:
+		return nil, fmt.Errorf("PAPER_SOURCE inconnue %q (db ou file)", cfg.Source)
+	}
+
+	if len(p.series) == 0 && !p.live {
+		return nil, fmt.Errorf("aucune bougie disponible pour rejouer %s", cfg.Pair)
+	}
+
+	for asset, amount := range cfg.Balances {
+		p.state.Balances[asset] = &paperBalance{Free: amount}
+	}
+
+	restored, err := p.loadState()
+	if err != nil {
+		return nil, err
+	}
+
+	p.launchedAt = now()
+	switch {
+	case p.live:
+	case restored && p.state.SimTime > 0:
+		p.replayFrom = p.state.SimTime // reprise du rejeu là où il s'était arrêté
+	case cfg.StartMs > 0:
+		p.replayFrom = cfg.StartMs
+	default:
+		p.replayFrom = p.series[0].Timestamp + backtest.TimeframeMillis(cfg.Timeframe)
+	}
+
+	// Les bougies déjà « vécues » ne sont pas rejouées. En mode live, celles apparues
+	// pendant un arrêt du bot le sont : un ordre a pu s'exécuter entre-temps.
+	seen := p.simNow()
+	if restored && p.state.SimTime > 0 {
+		seen = p.state.SimTime
+	}
+	p.cursor = p.indexAt(seen) - 1
+
+	mode := "live"
+	if !p.live {
+		mode = fmt.Sprintf("rejeu depuis %s (x%g)", time.UnixMilli(p.replayFrom).Format("2006-01-02 15:04"), cfg.Speed)
+	}
+	logger.Infof("[paper] Exchange simulé %s : source=%s, %d bougies, mode %s", cfg.Pair, cfg.Source, len(p.series), mode)
+	return p, nil
+}
+
+func paperMarket(symbol, base, quote string, pricePrecision, amountPrecision float64) bot.Market {
+	m := bot.Market{Symbol: symbol, BaseAsset: base, BaseId: base, QuoteAsset: quote, QuoteId: quote}
+	m.Precision.Price = pricePrecision
+	m.Precision.PriceDecimals = -int(math.Log10(pricePrecision))
+	m.Precision.Amount = amountPrecision
+	m.Precision.AmountDecimals = -int(math.Log10(amountPrecision))
+	return m
+}
+
+func fromDBCandles(candles []database.Candle) []bot.Candle {
+	series := make([]bot.Candle, len(candles))
+	for i, c := range candles {
+		series[i] = bot.Candle{
+			Timestamp: c.Timestamp,
+			Open:      c.OpenPrice,
+			High:      c.HighPrice,
+			Low:       c.LowPrice,
+			Close:     c.ClosePrice,
+			Volume:    c.Volume,
+		}
+	}
+	return series
+}
+
+// loadPriceFile lit un fichier CSV de prix enregistré. Deux formats sont acceptés
+// (une éventuelle ligne d'en-tête est ignorée) :
+//   - timestamp,price
+//   - timestamp,open,high,low,close[,volume]
+//
+// Le timestamp est en millisecondes epoch (ou en secondes s'il est < 1e12).
+func loadPriceFile(path string) ([]bot.Candle, error) {
+	if path == "" {
+		return nil, fmt.Errorf("source file : PAPER_PRICE_FILE non renseigné")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ouverture du fichier de prix : %w", err)
+	}
+	defer f.Close()
+	return parsePriceCSV(f)
+}
+
+func parsePriceCSV(r io.Reader) ([]bot.Candle, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var series []bot.Candle
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("fichier de prix, ligne %d : %w", line, err)
+		}
+
+		values := make([]float64, len(record))
+		valid := len(record) == 2 || len(record) >= 5
+		for i := 0; valid && i < len(record); i++ {
+			values[i], err = strconv.ParseFloat(strings.TrimSpace(record[i]), 64)
+			valid = err == nil
+		}
+		if !valid {
+			if line == 1 {
+				continue // en-tête
+			}
+			return nil, fmt.Errorf("fichier de prix, ligne %d : format invalide %v", line, record)
+		}
+
+		ts := int64(values[0])
+		if ts < 1e12 {
+			ts *= 1000
+		}
+		c := bot.Candle{Timestamp: ts}
+		if len(values) == 2 {
+			c.Open, c.High, c.Low, c.Close = values[1], values[1], values[1], values[1]
+		} else {
+			c.Open, c.High, c.Low, c.Close = values[1], values[2], values[3], values[4]
+			if len(values) > 5 {
+				c.Volume = values[5]
+			}
+		}
+		series = append(series, c)
+	}
+
+	sort.Slice(series, func(i, j int) bool { return series[i].Timestamp < series[j].Timestamp })
+	return series, nil
+}
+
+// ===============================
+// HORLOGE ET EXÉCUTION DES ORDRES
+// ===============================
+
+// simNow retourne l'horodatage simulé courant (ms).
+func (p *PaperExchange) simNow() int64 {
+	if p.live {
+		return p.now().UnixMilli()
+	}
+	elapsed := float64(p.now().Sub(p.launchedAt).Milliseconds()) * p.cfg.Speed
+	return p.replayFrom + int64(elapsed)
+}
+
+// indexAt retourne le nombre de bougies CLÔTURÉES à l'instant ts. Comme dans le
+// backtest, une bougie n'est visible qu'une fois close : pas de look-ahead intra-bougie.
+func (p *PaperExchange) indexAt(ts int64) int {
+	tfMs := backtest.TimeframeMillis(p.cfg.Timeframe)
+	return sort.Search(len(p.series), func(i int) bool { return p.series[i].Timestamp+tfMs > ts })
+}
+
+// sync avance l'horloge simulée et confronte les ordres ouverts aux nouvelles
+// bougies. Doit être appelé sous p.mu.
+func (p *PaperExchange) sync() {
+	if p.live && p.db != nil && p.now().Sub(p.lastReload) >= paperReloadInterval {
+		p.reloadSeries()
+	}
+
+	now := p.simNow()
+	last := p.indexAt(now) - 1
+	if last <= p.cursor {
+		return
+	}
+
+	for i := p.cursor + 1; i <= last; i++ {
+		p.matchCandle(p.series[i])
+	}
+	p.cursor = last
+
+	// On ne persiste qu'à chaque nouvelle bougie : l'horloge reprend donc au plus
+	// une bougie en arrière après un redémarrage, sans réexécuter d'ordre.
+	p.saveState()
+}
+
+// reloadSeries ajoute à la série les bougies apparues en base depuis le dernier chargement.
+func (p *PaperExchange) reloadSeries() {
+	p.lastReload = p.now()
+	candles, err := p.db.GetAllCandles(p.cfg.Pair, p.cfg.Timeframe)
+	if err != nil {
+		logger.Warnf("[paper] Relecture des bougies impossible : %v", err)
+		return
+	}
+	var lastTs int64 = -1
+	if len(p.series) > 0 {
+		lastTs = p.series[len(p.series)-1].Timestamp
+	}
+	for _, c := range fromDBCandles(candles) {
+		if c.Timestamp > lastTs {
+			p.series = append(p.series, c)
+		}
+	}
+}
+
+// matchCandle remplit les ordres ouverts touchés par la bougie c. Seuls les ordres
+// posés avant le début de la bougie sont éligibles (pas d'exécution rétroactive).
+func (p *PaperExchange) matchCandle(c bot.Candle) {
+	for _, o := range p.state.Orders {
+		if o.Status != paperStatusOpen || o.Timestamp > c.Timestamp {
+			continue
+		}
+		if (o.Side == "buy" && c.Low <= o.Price) || (o.Side == "sell" && c.High >= o.Price) {
+			p.fill(o, o.Price, c.Timestamp, false)
+		}
+	}
+}
+
+// fill exécute intégralement l'ordre o au prix price et met à jour les soldes.
+func (p *PaperExchange) fill(o *paperOrder, price float64, ts int64, taker bool) {
+	cost := o.Amount * price
+	fee := cost * p.cfg.FeeRate
+	base, quote := p.balance(p.market.BaseAsset), p.balance(p.market.QuoteAsset)
+
+	switch o.Side {
+	case "buy":
+		quote.Used -= o.Locked
+		quote.Free += o.Locked - cost - fee
+		base.Free += o.Amount
+	case "sell":
+		base.Used -= o.Locked
+		quote.Free += cost - fee
+	}
+	o.Locked = 0
+	o.Status = paperStatusClosed
+	o.Average = price
+
+	p.state.Trades = append(p.state.Trades, paperTrade{
+		Id:        fmt.Sprintf("paper-trade-%d", len(p.state.Trades)+1),
+		OrderId:   o.Id,
+		Side:      o.Side,
+		Timestamp: ts,
+		Price:     price,
+		Amount:    o.Amount,
+		Fee:       fee,
+		Taker:     taker,
+	})
+	logger.Infof("[paper] Ordre %s (%s) exécuté : %s %s @ %s (frais %.4f %s)",
+		o.Id, o.Side, p.market.FormatAmount(o.Amount), p.market.BaseAsset, p.market.FormatPrice(price), fee, p.market.QuoteAsset)
+}
+
+func (p *PaperExchange) balance(asset string) *paperBalance {
+	b, ok := p.state.Balances[asset]
+	if !ok {
+		b = &paperBalance{}
+		p.state.Balances[asset] = b
+	}
+	return b
+}
+
+// currentPrice retourne la clôture de la dernière bougie close. Doit être appelé sous p.mu.
+func (p *PaperExchange) currentPrice() (float64, error) {
+	idx := p.indexAt(p.simNow()) - 1
+	if idx < 0 {
+		return 0, fmt.Errorf("[paper] aucun prix disponible pour %s à cet instant", p.cfg.Pair)
+	}
+	return p.series[idx].Close, nil
+}
+
+func (p *PaperExchange) findOrder(id string) (*paperOrder, error) {
+	for _, o := range p.state.Orders {
+		if o.Id == id {
+			return o, nil
+		}
+	}
+	return nil, fmt.Errorf("[paper] [OrderNotFound] ordre %s introuvable", id)
+}
+
+func (p *PaperExchange) checkPair(pair string) error {
+	if pair != p.cfg.Pair {
+		return fmt.Errorf("[paper] paire %s non simulée (seule %s est disponible)", pair, p.cfg.Pair)
+	}
+	return nil
+}
+
+// placeOrder bloque les fonds nécessaires puis enregistre l'ordre ; il est rempli
+// immédiatement s'il croise le prix courant. Un identifiant client déjà utilisé est
+// refusé, comme sur un exchange réel.
+func (p *PaperExchange) placeOrder(side, pair string, amount, price float64, clientOrderID string) (bot.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.checkPair(pair); err != nil {
+		return bot.Order{}, err
+	}
+	if amount <= 0 || price <= 0 {
+		return bot.Order{}, fmt.Errorf("[paper] ordre invalide : quantité %f, prix %f", amount, price)
+	}
+	if clientOrderID != "" {
+		for _, o := range p.state.Orders {
+			if o.ClientOrderId == clientOrderID {
+				return bot.Order{}, fmt.Errorf("[paper] [InvalidOrder] identifiant client %s déjà utilisé", clientOrderID)
+			}
+		}
+	}
+
+	p.sync()
+	current, err := p.currentPrice()
+	if err != nil {
+		return bot.Order{}, err
+	}
+
+	o := &paperOrder{
+		Id:            fmt.Sprintf("paper-%d", p.state.NextId),
+		ClientOrderId: clientOrderID,
+		Side:          side,
+		Price:         price,
+		Amount:        amount,
+		Status:        paperStatusOpen,
+		Timestamp:     p.simNow(),
+	}
+
+	switch side {
+	case "buy":
+		quote := p.balance(p.market.QuoteAsset)
+		o.Locked = amount * price * (1 + p.cfg.FeeRate)
+		if quote.Free < o.Locked {
+			return bot.Order{}, fmt.Errorf("[paper] solde %s insuffisant : %.2f disponible, %.2f requis", p.market.QuoteAsset, quote.Free, o.Locked)
+		}
+		quote.Free -= o.Locked
+		quote.Used += o.Locked
+	case "sell":
+		base := p.balance(p.market.BaseAsset)
+		o.Locked = amount
+		if base.Free < amount {
+			return bot.Order{}, fmt.Errorf("[paper] solde %s insuffisant : %s disponible, %s requis", p.market.BaseAsset, p.market.FormatAmount(base.Free), p.market.FormatAmount(amount))
+		}
+		base.Free -= amount
+		base.Used += amount
+	}
+
+	p.state.NextId++
+	p.state.Orders = append(p.state.Orders, o)
+
+	if (side == "buy" && price >= current) || (side == "sell" && price <= current) {
+		p.fill(o, current, o.Timestamp, true)
+	}
+
+	p.saveState()
+	return o.toBotOrder(), nil
+}
+
+func (o *paperOrder) toBotOrder() bot.Order {
+	id, side, status := o.Id, o.Side, o.Status
+	price, amount, ts := o.Price, o.Amount, o.Timestamp
+	order := bot.Order{Id: &id, Side: &side, Price: &price, Amount: &amount, Status: &status, Timestamp: &ts}
+	if o.ClientOrderId != "" {
+		clientID := o.ClientOrderId
+		order.ClientOrderId = &clientID
+	}
+	// L'exchange simulé remplit toujours un ordre en totalité.
+	filled, average := 0.0, o.Average
+	if o.Status == paperStatusClosed {
+		filled = o.Amount
+	}
+	order.Filled = &filled
+	if average > 0 {
+		order.Average = &average
+	}
+	return order
+}
+
+func (t paperTrade) toBotTrade(pair, feeToken string) bot.Trade {
+	id, orderId, side := t.Id, t.OrderId, t.Side
+	ts, price, amount, fee := t.Timestamp, t.Price, t.Amount, t.Fee
+	cost := t.Price * t.Amount
+	kind, role := "limit", "maker"
+	if t.Taker {
+		role = "taker"
+	}
+	return bot.Trade{
+		Id:           &id,
+		Timestamp:    &ts,
+		Symbol:       &pair,
+		OrderId:      &orderId,
+		Type:         &kind,
+		Side:         &side,
+		TakerOrMaker: &role,
+		Price:        &price,
+		Amount:       &amount,
+		Cost:         &cost,
+		Fee:          &fee,
+		FeeToken:     &feeToken,
+	}
+}
+
+// ===============================
+// PERSISTANCE DE L'ÉTAT
+// ===============================
+
+// loadState restaure l'état depuis StateFile. Retourne true si un état a été lu.
+func (p *PaperExchange) loadState() (bool, error) {
+	if p.cfg.StateFile == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(p.cfg.StateFile)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("lecture de l'état paper : %w", err)
+	}
+
+	var st paperState
+	if err := json.Unmarshal(data, &st); err != nil {
+		return false, fmt.Errorf("état paper corrompu (%s) : %w", p.cfg.StateFile, err)
+	}
+	if st.Balances == nil {
+		st.Balances = make(map[string]*paperBalance)
+	}
+	if st.NextId < 1 {
+		st.NextId = 1
+	}
+	p.state = st
+	logger.Infof("[paper] État restauré depuis %s (%d ordres, %d trades)", p.cfg.StateFile, len(st.Orders), len(st.Trades))
+	return true, nil
+}
+
+// saveState écrit l'état de façon atomique (fichier temporaire + rename). Doit être appelé sous p.mu.
+func (p *PaperExchange) saveState() {
+	if p.cfg.StateFile == "" {
+		return
+	}
+	p.state.SimTime = p.simNow()
+	data, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		logger.Errorf("[paper] Sérialisation de l'état impossible : %v", err)
+		return
+	}
+	tmp := p.cfg.StateFile + ".tmp"
+	if err := os.MkdirAll(filepath.Dir(p.cfg.StateFile), 0755); err == nil {
+		err = os.WriteFile(tmp, data, 0644)
+		if err == nil {
+			err = os.Rename(tmp, p.cfg.StateFile)
+		}
+	}
+	if err != nil {
+		logger.Errorf("[paper] Sauvegarde de l'état impossible : %v", err)
+	}
+}
+
+// ===============================
+// IMPLÉMENTATION DE bot.Exchange
+// ===============================
+
+func (p *PaperExchange) GetMarket(pair string) bot.Market {
+	return p.market
+}
+
+func (p *PaperExchange) GetMarketsList() []bot.Market {
+	return []bot.Market{p.market}
+}
+
+func (p *PaperExchange) FetchBalance() (map[string]bot.Balance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	balances := make(map[string]bot.Balance, len(p.state.Balances))
+	for asset, b := range p.state.Balances {
+		balances[asset] = bot.Balance{Free: b.Free, Used: b.Used, Total: b.Free + b.Used}
+	}
+	return balances, nil
+}
+
+func (p *PaperExchange) PlaceLimitBuyOrder(pair string, amount float64, price float64, clientOrderID string) (bot.Order, error) {
+	return p.placeOrder("buy", pair, amount, price, clientOrderID)
+}
+
+func (p *PaperExchange) PlaceLimitSellOrder(pair string, amount float64, price float64, clientOrderID string) (bot.Order, error) {
+	return p.placeOrder("sell", pair, amount, price, clientOrderID)
+}
+
+func (p *PaperExchange) FetchOrder(id string, symbol string) (bot.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	o, err := p.findOrder(id)
+	if err != nil {
+		return bot.Order{}, err
+	}
+	return o.toBotOrder(), nil
+}
+
+func (p *PaperExchange) FetchOrderByClientID(clientOrderID string, symbol string) (bot.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	for _, o := range p.state.Orders {
+		if clientOrderID != "" && o.ClientOrderId == clientOrderID {
+			return o.toBotOrder(), nil
+		}
+	}
+	return bot.Order{}, fmt.Errorf("[paper] [OrderNotFound] ordre client %s introuvable", clientOrderID)
+}
+
+func (p *PaperExchange) FetchOpenOrders(pair string) ([]bot.Order, error) {
+	if err := p.checkPair(pair); err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	var orders []bot.Order
+	for _, o := range p.state.Orders {
+		if o.Status == paperStatusOpen {
+			orders = append(orders, o.toBotOrder())
+		}
+	}
+	return orders, nil
+}
+
+func (p *PaperExchange) CancelOrder(id string, symbol string) (bot.Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync() // un ordre touché avant l'annulation est rempli, comme sur un vrai carnet
+
+	o, err := p.findOrder(id)
+	if err != nil {
+		return bot.Order{}, err
+	}
+	switch o.Status {
+	case paperStatusClosed:
+		return bot.Order{}, fmt.Errorf("[paper] ordre %s déjà exécuté : annulation impossible", id)
+	case paperStatusCanceled:
+		return o.toBotOrder(), nil
+	}
+
+	switch o.Side {
+	case "buy":
+		quote := p.balance(p.market.QuoteAsset)
+		quote.Used -= o.Locked
+		quote.Free += o.Locked
+	case "sell":
+		base := p.balance(p.market.BaseAsset)
+		base.Used -= o.Locked
+		base.Free += o.Locked
+	}
+	o.Locked = 0
+	o.Status = paperStatusCanceled
+	p.saveState()
+
+	logger.Infof("[paper] Ordre %s annulé", id)
+	return o.toBotOrder(), nil
+}
+
+func (p *PaperExchange) GetPrice(pair string) (float64, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.checkPair(pair); err != nil {
+		return 0, err
+	}
+	p.sync()
+	return p.currentPrice()
+}
+
+// FetchTicker retourne le prix simulé courant. Le papier n'a ni carnet d'ordres ni
+// horodatage exchange : fourchette et âge du ticker ne sont pas fournis.
+func (p *PaperExchange) FetchTicker(pair string) (bot.Ticker, error) {
+	price, err := p.GetPrice(pair)
+	if err != nil {
+		return bot.Ticker{}, err
+	}
+	return bot.Ticker{Last: &price}, nil
+}
+
+// FetchCandles agrège la série de prix dans la timeframe demandée, sans jamais
+// exposer de bougie postérieure à l'horloge simulée.
+func (p *PaperExchange) FetchCandles(pair string, timeframe string, since *int64, limit int64) ([]bot.Candle, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.checkPair(pair); err != nil {
+		return nil, err
+	}
+	p.sync()
+
+	visible := p.series[:p.indexAt(p.simNow())]
+	tfMs := backtest.TimeframeMillis(timeframe)
+
+	var candles []bot.Candle
+	for _, c := range visible {
+		bucket := c.Timestamp - c.Timestamp%tfMs
+		n := len(candles)
+		if n > 0 && candles[n-1].Timestamp == bucket {
+			last := &candles[n-1]
+			last.High = math.Max(last.High, c.High)
+			last.Low = math.Min(last.Low, c.Low)
+			last.Close = c.Close
+			last.Volume += c.Volume
+			continue
+		}
+		c.Timestamp = bucket
+		candles = append(candles, c)
+	}
+
+	if since != nil {
+		i := sort.Search(len(candles), func(i int) bool { return candles[i].Timestamp >= *since })
+		candles = candles[i:]
+		if limit > 0 && int64(len(candles)) > limit {
+			candles = candles[:limit]
+		}
+	} else if limit > 0 && int64(len(candles)) > limit {
+		candles = candles[int64(len(candles))-limit:]
+	}
+	return candles, nil
+}
+
+func (p *PaperExchange) FetchMyTrades(pair string, since *int64, until *int64, limit int64) ([]bot.Trade, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	var trades []bot.Trade
+	for _, t := range p.state.Trades {
+		if (since != nil && t.Timestamp < *since) || (until != nil && t.Timestamp > *until) {
+			continue
+		}
+		trades = append(trades, t.toBotTrade(p.cfg.Pair, p.market.QuoteAsset))
+		if limit > 0 && int64(len(trades)) >= limit {
+			break
+		}
+	}
+	return trades, nil
+}
+
+func (p *PaperExchange) FetchTradesForOrder(id string, pair string) ([]bot.Trade, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sync()
+
+	var trades []bot.Trade
+	for _, t := range p.state.Trades {
+		if t.OrderId == id {
+			trades = append(trades, t.toBotTrade(p.cfg.Pair, p.market.QuoteAsset))
+		}
+	}
+	return trades, nil
+}
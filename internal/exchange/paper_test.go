@@ -0,0 +1,224 @@
+package exchange
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bot/internal/logger"
+)
+
+// TestMain initialise le logger (utilisé par l'exchange simulé) avant les tests.
+func TestMain(m *testing.M) {
+	if err := logger.InitLogger("error", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeClock est une horloge murale pilotée par le test.
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestPaper crée un exchange simulé rejouant un fichier de bougies 1m
+// (timestamp,open,high,low,close) depuis sa première bougie.
+func newTestPaper(t *testing.T, csv string, stateFile string) (*PaperExchange, *fakeClock) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "prices.csv")
+	if err := os.WriteFile(path, []byte(csv), 0644); err != nil {
+		t.Fatal(err)
+	}
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+	p, err := newPaperExchange(PaperConfig{
+		Pair:            "BTC/USDC",
+		Source:          "file",
+		Timeframe:       "1m",
+		PriceFile:       path,
+		Balances:        map[string]float64{"USDC": 1000},
+		FeeRate:         0.001,
+		PricePrecision:  0.01,
+		AmountPrecision: 0.000001,
+		Speed:           1,
+		StateFile:       stateFile,
+	}, nil, clock.now)
+	if err != nil {
+		t.Fatalf("newPaperExchange : %v", err)
+	}
+	return p, clock
+}
+
+// Bougies 1m alignées sur une frontière de 5 minutes (timestamps en ms).
+const testPrices = `timestamp,open,high,low,close
+1700000100000,100,101,99,100
+1700000160000,100,100,98.5,99
+1700000220000,99,99,97,98
+1700000280000,98,103,98,102
+`
+
+func almostEqual(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+// Un achat limite sous le marché reste ouvert jusqu'à ce qu'une bougie CLOSE
+// postérieure à sa pose touche le prix, puis les soldes reflètent l'exécution.
+func TestPaperExchange_BuyFillsOnLaterCandle(t *testing.T) {
+	p, clock := newTestPaper(t, testPrices, "")
+
+	price, err := p.GetPrice("BTC/USDC")
+	if err != nil || price != 100 {
+		t.Fatalf("prix initial = %v (%v), attendu 100", price, err)
+	}
+
+	order, err := p.PlaceLimitBuyOrder("BTC/USDC", 1, 97.5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *order.Status != "open" {
+		t.Fatalf("statut = %s, attendu open", *order.Status)
+	}
+
+	bal, _ := p.FetchBalance()
+	locked := 97.5 * 1.001
+	if !almostEqual(bal["USDC"].Used, locked) || !almostEqual(bal["USDC"].Free, 1000-locked) {
+		t.Errorf("USDC après pose = %+v, attendu %.4f bloqués", bal["USDC"], locked)
+	}
+
+	// 2e bougie close (low 98.5) : pas touché.
+	clock.advance(time.Minute)
+	if o, _ := p.FetchOrder(*order.Id, "BTC/USDC"); *o.Status != "open" {
+		t.Fatalf("statut après bougie 2 = %s, attendu open", *o.Status)
+	}
+
+	// 3e bougie close (low 97) : exécuté au prix limite.
+	clock.advance(time.Minute)
+	o, _ := p.FetchOrder(*order.Id, "BTC/USDC")
+	if *o.Status != "closed" {
+		t.Fatalf("statut après bougie 3 = %s, attendu closed", *o.Status)
+	}
+
+	bal, _ = p.FetchBalance()
+	fee := 97.5 * 0.001
+	if !almostEqual(bal["BTC"].Free, 1) || !almostEqual(bal["USDC"].Total, 1000-97.5-fee) || bal["USDC"].Used != 0 {
+		t.Errorf("soldes après exécution = %+v", bal)
+	}
+
+	trades, _ := p.FetchTradesForOrder(*order.Id, "BTC/USDC")
+	if len(trades) != 1 || *trades[0].Price != 97.5 || !almostEqual(*trades[0].Fee, fee) || *trades[0].FeeToken != "USDC" {
+		t.Errorf("trades = %+v, attendu 1 trade @ 97.5", trades)
+	}
+
+	// Un ordre exécuté ne peut plus être annulé.
+	if _, err := p.CancelOrder(*order.Id, "BTC/USDC"); err == nil {
+		t.Error("attendu une erreur en annulant un ordre exécuté")
+	}
+}
+
+// L'annulation libère les fonds bloqués ; un ordre marketable est rempli
+// immédiatement au prix courant.
+func TestPaperExchange_CancelAndMarketable(t *testing.T) {
+	p, _ := newTestPaper(t, testPrices, "")
+
+	order, err := p.PlaceLimitBuyOrder("BTC/USDC", 2, 90, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	canceled, err := p.CancelOrder(*order.Id, "BTC/USDC")
+	if err != nil || *canceled.Status != "canceled" {
+		t.Fatalf("annulation : %v / %+v", err, canceled)
+	}
+	bal, _ := p.FetchBalance()
+	if bal["USDC"].Free != 1000 || bal["USDC"].Used != 0 {
+		t.Errorf("USDC après annulation = %+v, attendu 1000 libres", bal["USDC"])
+	}
+
+	// Achat au-dessus du marché (100) : exécuté tout de suite à 100.
+	taker, err := p.PlaceLimitBuyOrder("BTC/USDC", 1, 105, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *taker.Status != "closed" {
+		t.Fatalf("statut = %s, attendu closed", *taker.Status)
+	}
+	trades, _ := p.FetchTradesForOrder(*taker.Id, "BTC/USDC")
+	if len(trades) != 1 || *trades[0].Price != 100 || *trades[0].TakerOrMaker != "taker" {
+		t.Errorf("trade taker = %+v, attendu exécution à 100", trades)
+	}
+
+	// Vente au-delà du solde détenu : refusée.
+	if _, err := p.PlaceLimitSellOrder("BTC/USDC", 5, 110, ""); err == nil || !strings.Contains(err.Error(), "insuffisant") {
+		t.Errorf("attendu un refus pour solde insuffisant, obtenu %v", err)
+	}
+}
+
+// L'état persisté permet à un nouvel exchange de répondre de façon cohérente
+// sur les ordres posés avant le redémarrage.
+func TestPaperExchange_StatePersistence(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "paper.json")
+	p, _ := newTestPaper(t, testPrices, state)
+
+	order, err := p.PlaceLimitBuyOrder("BTC/USDC", 1, 97.5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restarted, clock := newTestPaper(t, testPrices, state)
+	o, err := restarted.FetchOrder(*order.Id, "BTC/USDC")
+	if err != nil || *o.Status != "open" {
+		t.Fatalf("ordre restauré : %v / %+v", err, o)
+	}
+
+	clock.advance(2 * time.Minute)
+	if o, _ := restarted.FetchOrder(*order.Id, "BTC/USDC"); *o.Status != "closed" {
+		t.Errorf("statut après reprise = %s, attendu closed", *o.Status)
+	}
+	next, err := restarted.PlaceLimitBuyOrder("BTC/USDC", 1, 50, "")
+	if err != nil || *next.Id == *order.Id {
+		t.Errorf("identifiant réutilisé après redémarrage : %v / %s", err, *next.Id)
+	}
+}
+
+// Un ordre se retrouve par son identifiant client, y compris après redémarrage, et un
+// identifiant déjà utilisé est refusé : une pose rejouée ne double pas l'ordre.
+func TestPaperExchange_ClientOrderID(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "paper.json")
+	p, _ := newTestPaper(t, testPrices, state)
+
+	order, err := p.PlaceLimitBuyOrder("BTC/USDC", 1, 97.5, "sb1b1t0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.PlaceLimitBuyOrder("BTC/USDC", 1, 97.5, "sb1b1t0"); err == nil {
+		t.Error("attendu un refus pour identifiant client déjà utilisé")
+	}
+
+	restarted, _ := newTestPaper(t, testPrices, state)
+	o, err := restarted.FetchOrderByClientID("sb1b1t0", "BTC/USDC")
+	if err != nil || *o.Id != *order.Id || o.ClientOrderId == nil || *o.ClientOrderId != "sb1b1t0" {
+		t.Fatalf("ordre retrouvé : %v / %+v", err, o)
+	}
+	if _, err := restarted.FetchOrderByClientID("sb1b2t0", "BTC/USDC"); err == nil || !strings.Contains(err.Error(), "OrderNotFound") {
+		t.Errorf("attendu OrderNotFound, obtenu %v", err)
+	}
+}
+
+// FetchCandles agrège la série dans la timeframe demandée sans exposer de
+// bougie non close.
+func TestPaperExchange_FetchCandlesAggregates(t *testing.T) {
+	p, clock := newTestPaper(t, testPrices, "")
+	clock.advance(3 * time.Minute) // les 4 bougies 1m sont closes
+
+	candles, err := p.FetchCandles("BTC/USDC", "5m", nil, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(candles) != 1 {
+		t.Fatalf("%d bougies 5m, attendu 1", len(candles))
+	}
+	c := candles[0]
+	if c.Open != 100 || c.High != 103 || c.Low != 97 || c.Close != 102 {
+		t.Errorf("bougie 5m = %+v, attendu O=100 H=103 L=97 C=102", c)
+	}
+}
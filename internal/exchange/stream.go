@@ -0,0 +1,98 @@
+package exchange
+
+import (
+	"bot/internal/bot"
+	"fmt"
+
+	ccxt "github.com/ccxt/ccxt/go/v4"
+)
+
+// proExchange regroupe les méthodes de ccxt pro utilisées par le streaming (watch*,
+// testnet).
+// Chaque appel bloque jusqu'au prochain message ; ccxt pro gère la connexion
+// WebSocket, les abonnements et leur reprise à l'appel suivant une coupure.
+type proExchange interface {
+	WatchTicker(symbol string, options ...ccxt.WatchTickerOptions) (ccxt.Ticker, error)
+	WatchOrders(options ...ccxt.WatchOrdersOptions) ([]ccxt.Order, error)
+	WatchMyTrades(options ...ccxt.WatchMyTradesOptions) ([]ccxt.Trade, error)
+	SetSandboxMode(enable bool)
+}
+
+// EnableStreaming ouvre le client WebSocket (ccxt pro) qui alimente WatchTicker,
+// WatchOrders et WatchMyTrades. Sans appel, ces méthodes échouent et le bot reste
+// en polling.
+func (e *Exchange) EnableStreaming() error {
+	d, ok := drivers[e.name]
+	if !ok {
+		return fmt.Errorf("streaming non supporté pour %s", e.name)
+	}
+	e.pro = d.pro(d.config())
+	if d.sandbox != nil && d.sandbox() {
+		e.pro.SetSandboxMode(true)
+	}
+	return nil
+}
+
+// watch exécute un appel watch* : panics ccxt convertis en erreurs, sans retry (la
+// reconnexion est pilotée par le bot).
+func (e *Exchange) watch(fn func() error) error {
+	if e.pro == nil {
+		return fmt.Errorf("streaming non activé pour %s", e.name)
+	}
+	return cleanCCXTError(callSafe(fn))
+}
+
+func (e *Exchange) WatchTicker(pair string) (bot.Ticker, error) {
+	var result ccxt.Ticker
+	err := e.watch(func() error {
+		ticker, tickerErr := e.pro.WatchTicker(pair)
+		if tickerErr == nil {
+			result = ticker
+		}
+		return tickerErr
+	})
+	if err != nil {
+		return bot.Ticker{}, err
+	}
+	return toBotTicker(result), nil
+}
+
+func (e *Exchange) WatchOrders(pair string) ([]bot.Order, error) {
+	var result []ccxt.Order
+	err := e.watch(func() error {
+		orders, ordersErr := e.pro.WatchOrders(ccxt.WithWatchOrdersSymbol(pair))
+		if ordersErr == nil {
+			result = orders
+		}
+		return ordersErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	botOrders := make([]bot.Order, len(result))
+	for i, order := range result {
+		botOrders[i] = toBotOrder(order)
+	}
+	return botOrders, nil
+}
+
+func (e *Exchange) WatchMyTrades(pair string) ([]bot.Trade, error) {
+	var result []ccxt.Trade
+	err := e.watch(func() error {
+		trades, tradesErr := e.pro.WatchMyTrades(ccxt.WithWatchMyTradesSymbol(pair))
+		if tradesErr == nil {
+			result = trades
+		}
+		return tradesErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	botTrades := make([]bot.Trade, len(result))
+	for i, trade := range result {
+		botTrades[i] = toBotTrade(trade)
+	}
+	return botTrades, nil
+}
@@ -0,0 +1,40 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+// WriteJournalCSV écrit les exécutions (journal des opérations) au format CSV.
+func WriteJournalCSV(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"date", "pair", "side", "order_id", "amount", "price", "value", "fee", "quote",
+		"cost_basis", "realized_pnl", "unmatched_amount", "position", "avg_cost"})
+	for _, e := range entries {
+		_ = cw.Write([]string{
+			e.ExecutedAt.UTC().Format("2006-01-02 15:04:05"), e.Pair, string(e.Side), e.OrderID,
+			formatFloat(e.Amount), formatFloat(e.Price), formatFloat(e.Amount * e.Price), formatFloat(e.Fee), e.Quote,
+			formatFloat(e.CostBasis), formatFloat(e.Realized), formatFloat(e.Unmatched),
+			formatFloat(e.Position), formatFloat(e.AvgCost),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WritePnLCSV écrit le PnL réalisé par période au format CSV.
+func WritePnLCSV(w io.Writer, pnl []PeriodPnL) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"period", "quote", "sells", "proceeds", "cost_basis", "fees", "realized_pnl"})
+	for _, p := range pnl {
+		_ = cw.Write([]string{
+			p.Period, p.Quote, strconv.Itoa(p.Sells),
+			formatFloat(p.Proceeds), formatFloat(p.CostBasis), formatFloat(p.Fees), formatFloat(p.Realized),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,273 @@
+// Package ledger tient la comptabilité des exécutions : position et coût moyen par
+// paire, appariement FIFO des ventes avec les lots d'achat, et PnL réalisé par jour ou
+// par mois en devise de cotation.
+//
+// Le coût d'un lot inclut ses frais d'achat ; le produit d'une vente est net de ses
+// frais. Le PnL réalisé d'une vente est donc produit net - coût FIFO des lots vendus.
+// Une vente qui dépasse les lots connus (achats antérieurs au grand livre) n'est
+// appariée que pour la partie couverte ; le reste est signalé dans Entry.Unmatched.
+package ledger
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/decimal"
+)
+
+// Entry est une exécution du grand livre enrichie du calcul FIFO.
+type Entry struct {
+	database.Trade
+	Quote string `json:"quote"`
+	// Vente : coût FIFO des lots vendus, PnL réalisé et quantité non appariée.
+	CostBasis float64 `json:"cost_basis,omitempty"`
+	Realized  float64 `json:"realized,omitempty"`
+	Unmatched float64 `json:"unmatched,omitempty"`
+	// Position et coût moyen de la paire après cette exécution.
+	Position float64 `json:"position"`
+	AvgCost  float64 `json:"avg_cost"`
+}
+
+// Position est la quantité détenue sur une paire et son coût de revient (lots FIFO
+// restants, frais d'achat inclus).
+type Position struct {
+	Pair    string  `json:"pair"`
+	Base    string  `json:"base"`
+	Quote   string  `json:"quote"`
+	Amount  float64 `json:"amount"`
+	Cost    float64 `json:"cost"`
+	AvgCost float64 `json:"avg_cost"`
+	Lots    int     `json:"lots"`
+}
+
+// PeriodPnL est le PnL réalisé d'une période (jour, mois ou année UTC) dans une devise
+// de cotation.
+type PeriodPnL struct {
+	Period    string  `json:"period"` // 2006-01-02, 2006-01 ou 2006
+	Quote     string  `json:"quote"`
+	Sells     int     `json:"sells"`
+	Proceeds  float64 `json:"proceeds"` // produit des ventes, net de frais
+	CostBasis float64 `json:"cost_basis"`
+	Fees      float64 `json:"fees"` // frais des ventes de la période (déjà déduits du produit)
+	Realized  float64 `json:"realized"`
+	Unmatched int     `json:"unmatched"` // ventes dépassant les achats connus
+}
+
+// Period est la granularité du PnL réalisé.
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+	Yearly  Period = "yearly"
+)
+
+func (p Period) key(t time.Time) string {
+	switch p {
+	case Daily:
+		return t.UTC().Format("2006-01-02")
+	case Yearly:
+		return t.UTC().Format("2006")
+	}
+	return t.UTC().Format("2006-01")
+}
+
+// Ledger est le grand livre calculé.
+type Ledger struct {
+	Entries   []Entry    `json:"entries"`
+	Positions []Position `json:"positions"`
+}
+
+// Les lots sont tenus en décimal exact : une vente qui solde un lot le solde exactement,
+// sans reliquat d'arrondi flottant.
+type lot struct {
+	amount, cost decimal.Decimal // cost : coût restant du lot, frais d'achat inclus
+}
+
+type book struct {
+	lots []lot
+}
+
+func (b *book) amount() (amount, cost decimal.Decimal) {
+	for _, l := range b.lots {
+		amount = amount.Add(l.amount)
+		cost = cost.Add(l.cost)
+	}
+	return amount, cost
+}
+
+// sell consomme les lots les plus anciens et retourne le coût des quantités vendues et
+// la quantité restée sans lot.
+func (b *book) sell(amount decimal.Decimal) (cost, unmatched decimal.Decimal) {
+	for amount.Sign() > 0 && len(b.lots) > 0 {
+		l := &b.lots[0]
+		take := amount
+		part := l.cost
+		if take.Cmp(l.amount) >= 0 {
+			take = l.amount
+		} else {
+			part = l.cost.Mul(take).Quo(l.amount)
+		}
+		cost = cost.Add(part)
+		l.cost = l.cost.Sub(part)
+		l.amount = l.amount.Sub(take)
+		amount = amount.Sub(take)
+		if l.amount.Sign() <= 0 {
+			b.lots = b.lots[1:]
+		}
+	}
+	if amount.Sign() > 0 {
+		unmatched = amount
+	}
+	return cost, unmatched
+}
+
+// SplitPair retourne les actifs de base et de cotation d'une paire (BTC/USDC, ou
+// BTC/USDC:USDC pour un marché à règlement).
+func SplitPair(pair string) (base, quote string) {
+	base, quote, _ = strings.Cut(pair, "/")
+	quote, _, _ = strings.Cut(quote, ":")
+	return base, quote
+}
+
+// Build calcule le grand livre à partir des exécutions, par ordre chronologique.
+func Build(trades []database.Trade) *Ledger {
+	books := make(map[string]*book)
+	l := &Ledger{Entries: make([]Entry, 0, len(trades))}
+
+	for _, t := range trades {
+		bk := books[t.Pair]
+		if bk == nil {
+			bk = &book{}
+			books[t.Pair] = bk
+		}
+
+		_, quote := SplitPair(t.Pair)
+		e := Entry{Trade: t, Quote: quote}
+		amount := decimal.FromFloat(t.Amount)
+		value := amount.Mul(decimal.FromFloat(t.Price))
+		fee := decimal.FromFloat(t.Fee)
+		switch t.Side {
+		case database.Buy:
+			bk.lots = append(bk.lots, lot{amount: amount, cost: value.Add(fee)})
+		case database.Sell:
+			cost, unmatched := bk.sell(amount)
+			proceeds := decimal.Zero
+			if amount.Sign() > 0 {
+				proceeds = value.Sub(fee)
+				if unmatched.Sign() > 0 {
+					proceeds = proceeds.Mul(amount.Sub(unmatched)).Quo(amount)
+				}
+			}
+			e.CostBasis = cost.Float64()
+			e.Realized = proceeds.Sub(cost).Float64()
+			e.Unmatched = unmatched.Float64()
+		}
+		e.Position, e.AvgCost = position(bk)
+		l.Entries = append(l.Entries, e)
+	}
+
+	for pair, bk := range books {
+		amount, cost := bk.amount()
+		if amount.Sign() <= 0 {
+			continue
+		}
+		base, quote := SplitPair(pair)
+		l.Positions = append(l.Positions, Position{
+			Pair: pair, Base: base, Quote: quote,
+			Amount: amount.Float64(), Cost: cost.Float64(), AvgCost: cost.Quo(amount).Float64(),
+			Lots: len(bk.lots),
+		})
+	}
+	sort.Slice(l.Positions, func(i, j int) bool { return l.Positions[i].Pair < l.Positions[j].Pair })
+	return l
+}
+
+func position(bk *book) (amount, avgCost float64) {
+	held, cost := bk.amount()
+	if held.Sign() <= 0 {
+		return 0, 0
+	}
+	return held.Float64(), cost.Quo(held).Float64()
+}
+
+// EntriesForYear retourne les exécutions de l'année UTC donnée (0 = toutes).
+func (l *Ledger) EntriesForYear(year int) []Entry {
+	if year == 0 {
+		return l.Entries
+	}
+	var entries []Entry
+	for _, e := range l.Entries {
+		if e.ExecutedAt.UTC().Year() == year {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
+
+// PnL agrège le PnL réalisé des ventes par période et devise de cotation, pour l'année
+// UTC donnée (0 = toutes), de la plus ancienne période à la plus récente.
+func (l *Ledger) PnL(period Period, year int) []PeriodPnL {
+	// Les sommes sont cumulées en décimal pour ne pas accumuler d'erreur sur une année
+	// de ventes.
+	type sums struct {
+		PeriodPnL
+		proceeds, costBasis, fees, realized decimal.Decimal
+	}
+	index := make(map[[2]string]*sums)
+	var list []*sums
+	for _, e := range l.EntriesForYear(year) {
+		if e.Side != database.Sell {
+			continue
+		}
+		k := [2]string{period.key(e.ExecutedAt), e.Quote}
+		p := index[k]
+		if p == nil {
+			p = &sums{PeriodPnL: PeriodPnL{Period: k[0], Quote: k[1]}}
+			index[k] = p
+			list = append(list, p)
+		}
+		costBasis, realized := decimal.FromFloat(e.CostBasis), decimal.FromFloat(e.Realized)
+		p.Sells++
+		p.proceeds = p.proceeds.Add(realized).Add(costBasis)
+		p.costBasis = p.costBasis.Add(costBasis)
+		p.fees = p.fees.Add(decimal.FromFloat(e.Fee))
+		p.realized = p.realized.Add(realized)
+		if e.Unmatched > 0 {
+			p.Unmatched++
+		}
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Period != list[j].Period {
+			return list[i].Period < list[j].Period
+		}
+		return list[i].Quote < list[j].Quote
+	})
+	pnl := make([]PeriodPnL, len(list))
+	for i, p := range list {
+		pnl[i] = p.PeriodPnL
+		pnl[i].Proceeds = p.proceeds.Float64()
+		pnl[i].CostBasis = p.costBasis.Float64()
+		pnl[i].Fees = p.fees.Float64()
+		pnl[i].Realized = p.realized.Float64()
+	}
+	return pnl
+}
+
+// Years retourne les années UTC couvertes par le grand livre, de la plus récente à la
+// plus ancienne.
+func (l *Ledger) Years() []int {
+	var years []int
+	seen := make(map[int]bool)
+	for _, e := range l.Entries {
+		if y := e.ExecutedAt.UTC().Year(); !seen[y] {
+			seen[y] = true
+			years = append(years, y)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(years)))
+	return years
+}
@@ -0,0 +1,84 @@
+package ledger
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"bot/internal/core/database"
+)
+
+func trade(day string, pair string, side database.OrderSide, amount, price, fee float64) database.Trade {
+	at, _ := time.Parse("2006-01-02", day)
+	return database.Trade{OrderID: day + string(side), Pair: pair, Side: side, Amount: amount, Price: price, Fee: fee, ExecutedAt: at}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+// Les ventes consomment les lots les plus anciens ; frais d'achat dans le coût, frais de
+// vente déduits du produit.
+func TestBuildFIFO(t *testing.T) {
+	l := Build([]database.Trade{
+		trade("2025-12-20", "BTC/USDC", database.Buy, 1, 100, 1),      // lot 1 : coût 101
+		trade("2026-01-05", "BTC/USDC", database.Buy, 1, 200, 2),      // lot 2 : coût 202
+		trade("2026-01-10", "BTC/USDC", database.Sell, 1.5, 300, 4.5), // produit 445.5, coût 101 + 101
+		trade("2026-02-01", "ETH/USDT", database.Sell, 2, 10, 0),      // aucun lot connu
+	})
+
+	sell := l.Entries[2]
+	if !near(sell.CostBasis, 202) || !near(sell.Realized, 243.5) || sell.Unmatched != 0 {
+		t.Errorf("vente BTC : coût %.4f, PnL %.4f, non apparié %.4f", sell.CostBasis, sell.Realized, sell.Unmatched)
+	}
+	if !near(sell.Position, 0.5) || !near(sell.AvgCost, 202) {
+		t.Errorf("position après vente : %.4f @ %.4f", sell.Position, sell.AvgCost)
+	}
+	if orphan := l.Entries[3]; orphan.Unmatched != 2 || orphan.Realized != 0 || orphan.Quote != "USDT" {
+		t.Errorf("vente sans lot : %+v", orphan)
+	}
+
+	if len(l.Positions) != 1 || l.Positions[0].Pair != "BTC/USDC" || !near(l.Positions[0].Amount, 0.5) ||
+		!near(l.Positions[0].Cost, 101) || l.Positions[0].Lots != 1 {
+		t.Errorf("positions : %+v", l.Positions)
+	}
+
+	monthly := l.PnL(Monthly, 2026)
+	if len(monthly) != 2 || monthly[0].Period != "2026-01" || !near(monthly[0].Realized, 243.5) ||
+		!near(monthly[0].Proceeds, 445.5) || !near(monthly[0].Fees, 4.5) || monthly[1].Quote != "USDT" {
+		t.Errorf("PnL mensuel : %+v", monthly)
+	}
+	if daily := l.PnL(Daily, 2025); len(daily) != 0 {
+		t.Errorf("PnL 2025 : %+v", daily)
+	}
+	if years := l.Years(); len(years) != 2 || years[0] != 2026 || years[1] != 2025 {
+		t.Errorf("années : %v", years)
+	}
+	if n := len(l.EntriesForYear(2025)); n != 1 {
+		t.Errorf("exécutions 2025 : %d", n)
+	}
+}
+
+func TestCSV(t *testing.T) {
+	l := Build([]database.Trade{
+		trade("2026-01-05", "BTC/USDC", database.Buy, 1, 100, 0),
+		trade("2026-01-10", "BTC/USDC", database.Sell, 1, 110, 0.5),
+	})
+
+	var buf bytes.Buffer
+	if err := WriteJournalCSV(&buf, l.Entries); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || lines[2] != "2026-01-10 00:00:00,BTC/USDC,SELL,2026-01-10SELL,1,110,110,0.5,USDC,100,9.5,0,0,0" {
+		t.Errorf("journal CSV :\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := WritePnLCSV(&buf, l.PnL(Monthly, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "2026-01,USDC,1,109.5,100,0.5,9.5") {
+		t.Errorf("PnL CSV :\n%s", buf.String())
+	}
+}
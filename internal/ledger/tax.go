@@ -0,0 +1,67 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+
+	"bot/internal/core/database"
+)
+
+// TaxFormats liste les formats CSV de déclaration supportés par WriteTaxCSV.
+var TaxFormats = []string{"koinly", "cointracking", "generic"}
+
+// WriteTaxCSV écrit les exécutions au format d'import d'un outil de déclaration :
+//   - koinly       : « Koinly Universal Format » (montants envoyés / reçus) ;
+//   - cointracking : import CSV CoinTracking (Buy / Sell Amount, type Trade) ;
+//   - generic      : une ligne par exécution, prix et valeur en devise de cotation.
+//
+// Les montants échangés sont bruts ; les frais, en devise de cotation, sont à part.
+func WriteTaxCSV(w io.Writer, format, exchange string, entries []Entry) error {
+	var header []string
+	var row func(e Entry, base, quote string) []string
+
+	switch format {
+	case "koinly":
+		header = []string{"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+			"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash"}
+		row = func(e Entry, base, quote string) []string {
+			sent, sentCur, recv, recvCur := formatFloat(e.Amount*e.Price), quote, formatFloat(e.Amount), base
+			if e.Side == database.Sell {
+				sent, sentCur, recv, recvCur = formatFloat(e.Amount), base, formatFloat(e.Amount*e.Price), quote
+			}
+			return []string{e.ExecutedAt.UTC().Format("2006-01-02 15:04:05") + " UTC", sent, sentCur, recv, recvCur,
+				formatFloat(e.Fee), quote, "", "", "", exchange + " " + e.Pair, e.OrderID}
+		}
+	case "cointracking":
+		header = []string{"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency",
+			"Fee", "Fee Currency", "Exchange", "Trade-Group", "Comment", "Date", "Tx-ID"}
+		row = func(e Entry, base, quote string) []string {
+			buy, buyCur, sell, sellCur := formatFloat(e.Amount), base, formatFloat(e.Amount*e.Price), quote
+			if e.Side == database.Sell {
+				buy, buyCur, sell, sellCur = formatFloat(e.Amount*e.Price), quote, formatFloat(e.Amount), base
+			}
+			return []string{"Trade", buy, buyCur, sell, sellCur, formatFloat(e.Fee), quote,
+				exchange, "simple-bot", e.Pair, e.ExecutedAt.UTC().Format("2006-01-02 15:04:05"), e.OrderID}
+		}
+	case "generic":
+		header = []string{"date", "side", "pair", "base", "quote", "amount", "price", "value", "fee", "fee_currency",
+			"exchange", "order_id"}
+		row = func(e Entry, base, quote string) []string {
+			return []string{e.ExecutedAt.UTC().Format("2006-01-02T15:04:05Z"), string(e.Side), e.Pair, base, quote,
+				formatFloat(e.Amount), formatFloat(e.Price), formatFloat(e.Amount * e.Price), formatFloat(e.Fee), quote,
+				exchange, e.OrderID}
+		}
+	default:
+		return fmt.Errorf("unknown tax format: %s", format)
+	}
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write(header)
+	for _, e := range entries {
+		base, quote := SplitPair(e.Pair)
+		_ = cw.Write(row(e, base, quote))
+	}
+	cw.Flush()
+	return cw.Error()
+}
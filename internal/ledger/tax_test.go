@@ -0,0 +1,48 @@
+package ledger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"bot/internal/core/database"
+)
+
+func TestWriteTaxCSV(t *testing.T) {
+	l := Build([]database.Trade{
+		trade("2026-01-05", "BTC/USDC", database.Buy, 0.5, 100, 0.05),
+		trade("2026-01-10", "BTC/USDC", database.Sell, 0.5, 120, 0.06),
+	})
+
+	for _, tc := range []struct {
+		format    string
+		header    string
+		buy, sell string
+	}{
+		{"koinly", "Date,Sent Amount,Sent Currency,Received Amount,Received Currency,Fee Amount,Fee Currency",
+			"2026-01-05 00:00:00 UTC,50,USDC,0.5,BTC,0.05,USDC,,,,mexc BTC/USDC,2026-01-05BUY",
+			"2026-01-10 00:00:00 UTC,0.5,BTC,60,USDC,0.06,USDC,,,,mexc BTC/USDC,2026-01-10SELL"},
+		{"cointracking", "Type,Buy Amount,Buy Currency,Sell Amount,Sell Currency,Fee,Fee Currency",
+			"Trade,0.5,BTC,50,USDC,0.05,USDC,mexc,simple-bot,BTC/USDC,2026-01-05 00:00:00,2026-01-05BUY",
+			"Trade,60,USDC,0.5,BTC,0.06,USDC,mexc,simple-bot,BTC/USDC,2026-01-10 00:00:00,2026-01-10SELL"},
+		{"generic", "date,side,pair,base,quote,amount,price,value,fee,fee_currency",
+			"2026-01-05T00:00:00Z,BUY,BTC/USDC,BTC,USDC,0.5,100,50,0.05,USDC,mexc,2026-01-05BUY",
+			"2026-01-10T00:00:00Z,SELL,BTC/USDC,BTC,USDC,0.5,120,60,0.06,USDC,mexc,2026-01-10SELL"},
+	} {
+		var buf bytes.Buffer
+		if err := WriteTaxCSV(&buf, tc.format, "mexc", l.Entries); err != nil {
+			t.Fatal(err)
+		}
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 || !strings.HasPrefix(lines[0], tc.header) || lines[1] != tc.buy || lines[2] != tc.sell {
+			t.Errorf("%s :\n%s", tc.format, buf.String())
+		}
+	}
+
+	if err := WriteTaxCSV(&bytes.Buffer{}, "turbotax", "mexc", l.Entries); err == nil {
+		t.Error("format inconnu : erreur attendue")
+	}
+	if y := l.PnL(Yearly, 2026); len(y) != 1 || y[0].Period != "2026" || !near(y[0].Realized, 9.89) {
+		t.Errorf("PnL annuel : %+v", y)
+	}
+}
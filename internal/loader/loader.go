@@ -8,6 +8,7 @@ import (
 	"bot/internal/logger"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -30,10 +31,19 @@ func LoadConfig() (config.AppConfig, *database.DB, error) {
 		return config.AppConfig{}, nil, fmt.Errorf("base de données : %w", err)
 	}
 
+	// Stratégies et ordres antérieurs au multi-paires : rattachés à TRADING_PAIR.
+	if err := db.SetDefaultPair(cfg.TradingPair); err != nil {
+		db.Close()
+		return config.AppConfig{}, nil, fmt.Errorf("paire par défaut : %w", err)
+	}
+
 	logger.Info("✓ Configuration chargée")
 	logger.Infof("  Exchange        %s", cfg.ExchangeName)
-	logger.Infof("  Paire           %s", cfg.TradingPair)
+	logger.Infof("  Paire défaut    %s", cfg.TradingPair)
 	logger.Infof("  Check interval  %v", cfg.CheckInterval)
+	if cfg.Streaming {
+		logger.Infof("  Streaming       actif (ventes évaluées toutes les %v au plus)", cfg.StreamSellInterval)
+	}
 	logger.Infof("  Port web        %s", cfg.WebPort)
 
 	return cfg, db, nil
@@ -59,9 +69,35 @@ func LoadOffline() (config.AppConfig, *database.DB, error) {
 		return config.AppConfig{}, nil, fmt.Errorf("base de données : %w", err)
 	}
 
+	// Stratégies et ordres antérieurs au multi-paires : rattachés à TRADING_PAIR.
+	if err := db.SetDefaultPair(cfg.TradingPair); err != nil {
+		db.Close()
+		return config.AppConfig{}, nil, fmt.Errorf("paire par défaut : %w", err)
+	}
+
 	return cfg, db, nil
 }
 
+// NewExchange construit l'exchange de l'instance : l'exchange simulé si
+// EXCHANGE=paper (soldes virtuels, configuration PAPER_*), sinon l'exchange réel via ccxt.
+func NewExchange(cfg config.AppConfig, db *database.DB) (bot.Exchange, error) {
+	if cfg.ExchangeName == "paper" {
+		return exchange.NewPaperExchange(exchange.PaperConfigFromEnv(cfg.TradingPair), db)
+	}
+
+	exchg := exchange.NewExchange(cfg.ExchangeName)
+	if exchg == nil || exchg.IExchange == nil {
+		return nil, fmt.Errorf("exchange %q non supporté par ce binaire (compilés : %s, paper ; les autres s'activent par build tag)",
+			cfg.ExchangeName, strings.Join(exchange.Supported(), ", "))
+	}
+	if cfg.Streaming {
+		if err := exchg.EnableStreaming(); err != nil {
+			return nil, fmt.Errorf("streaming : %w", err)
+		}
+	}
+	return exchg, nil
+}
+
 // LoadBot charge la configuration et initialise le bot complet.
 func LoadBot() (*bot.Bot, error) {
 	cfg, db, err := LoadConfig()
@@ -69,7 +105,10 @@ func LoadBot() (*bot.Bot, error) {
 		return nil, err
 	}
 
-	exchg := exchange.NewExchange(cfg.ExchangeName)
+	exchg, err := NewExchange(cfg, db)
+	if err != nil {
+		return nil, fmt.Errorf("exchange : %w", err)
+	}
 	logger.Infof("[%s] ✓ Exchange initialisé", cfg.ExchangeName)
 
 	tradingBot, err := bot.NewBot(cfg.ToBotConfig(), db, exchg)
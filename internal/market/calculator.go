@@ -6,7 +6,6 @@ import (
 	"fmt"
 
 	"github.com/cinar/indicator/v2/trend"
-	"github.com/cinar/indicator/v2/volatility"
 )
 
 // Calculator handles technical indicator calculations using cached candle data
@@ -114,12 +113,12 @@ func (c *Calculator) CalculateMACD(pair, timeframe string, fastPeriod, slowPerio
 	return latestMACD, latestSignal, histogramVal, nil
 }
 
-// CalculateBollingerBands computes Bollinger Bands using indicator v2 library with channels
+// CalculateBollingerBands computes Bollinger Bands (upper, middle, lower)
 func (c *Calculator) CalculateBollingerBands(pair, timeframe string, period int, k float64) (upper, middle, lower float64, err error) {
 	logger.Debugf("Calculating Bollinger Bands for %s/%s (period: %d, k: %.1f)", pair, timeframe, period, k)
 
 	// Ensure we have enough candles
-	requiredCandles := period * 2
+	requiredCandles := BollingerWindow(period)
 	err = c.collector.EnsureCandlesAvailable(pair, timeframe, requiredCandles)
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to ensure candles availability: %w", err)
@@ -131,58 +130,13 @@ func (c *Calculator) CalculateBollingerBands(pair, timeframe string, period int,
 		return 0, 0, 0, fmt.Errorf("failed to get candles for Bollinger Bands: %w", err)
 	}
 
-	if len(candles) < period {
-		return 0, 0, 0, fmt.Errorf("insufficient candles for Bollinger Bands calculation: need %d, got %d",
-			period, len(candles))
-	}
-
-	// Convert to closes array
-	closes := make([]float64, len(candles))
-	for i, candle := range candles {
-		closes[i] = candle.ClosePrice
-	}
-
-	// Create Bollinger Bands instance using indicator v2
-	bb := volatility.NewBollingerBandsWithPeriod[float64](period)
-
-	// Create input channel and send closing prices
-	inputChan := make(chan float64, len(closes))
-	for _, price := range closes {
-		inputChan <- price
-	}
-	close(inputChan)
-
-	// Compute Bollinger Bands values using channels (returns 3 channels: upper, middle, lower)
-	upperChan, middleChan, lowerChan := bb.Compute(inputChan)
-
-	// Collect results from all three channels
-	var upperResults, middleResults, lowerResults []float64
-
-	// Read from upper channel
-	for upperVal := range upperChan {
-		upperResults = append(upperResults, upperVal)
-	}
-	// Read from middle channel
-	for middleVal := range middleChan {
-		middleResults = append(middleResults, middleVal)
-	}
-	// Read from lower channel
-	for lowerVal := range lowerChan {
-		lowerResults = append(lowerResults, lowerVal)
-	}
-
-	if len(upperResults) == 0 || len(middleResults) == 0 || len(lowerResults) == 0 {
-		return 0, 0, 0, fmt.Errorf("Bollinger Bands calculation returned no values")
+	// Calcul délégué aux helpers partagés (cf. indicators_math.go).
+	upper, middle, lower, err = BollingerValue(closesOf(candles), period, k)
+	if err != nil {
+		return 0, 0, 0, err
 	}
-
-	// Get latest values from each band
-	latestUpper := upperResults[len(upperResults)-1]
-	latestMiddle := middleResults[len(middleResults)-1]
-	latestLower := lowerResults[len(lowerResults)-1]
-
-	logger.Debugf("Calculated Bollinger Bands: Upper=%.4f, Middle=%.4f, Lower=%.4f",
-		latestUpper, latestMiddle, latestLower)
-	return latestUpper, latestMiddle, latestLower, nil
+	logger.Debugf("Calculated Bollinger Bands: Upper=%.4f, Middle=%.4f, Lower=%.4f", upper, middle, lower)
+	return upper, middle, lower, nil
 }
 
 // CalculateSMA calculates Simple Moving Average manually
@@ -27,16 +27,14 @@ type Exchange interface {
 // MarketDataCollector manages candle data collection and storage
 type MarketDataCollector struct {
 	exchangeName string
-	pair         string
 	db           *database.DB
 	exchange     Exchange
 }
 
 // NewMarketDataCollector creates a new market data collector
-func NewMarketDataCollector(exchangeName, pair string, db *database.DB, exchange Exchange) *MarketDataCollector {
+func NewMarketDataCollector(exchangeName string, db *database.DB, exchange Exchange) *MarketDataCollector {
 	return &MarketDataCollector{
 		exchangeName: exchangeName,
-		pair:         pair,
 		db:           db,
 		exchange:     exchange,
 	}
@@ -85,10 +83,18 @@ func (mdc *MarketDataCollector) CollectCandles(pair, timeframe string, limit int
 func (mdc *MarketDataCollector) CollectAllActiveTimeframes() error {
 	logger.Debug("Collecting candles for all active timeframes...")
 
-	// Get all active timeframes from enabled strategies
-	activeTimeframes, err := mdc.db.GetActiveTimeframes(mdc.pair)
+	// Get all active timeframes from enabled strategies, for every traded pair
+	pairs, err := mdc.db.GetStrategyPairs()
 	if err != nil {
-		return fmt.Errorf("failed to get active timeframes: %w", err)
+		return fmt.Errorf("failed to get strategy pairs: %w", err)
+	}
+	var activeTimeframes []database.ActiveTimeframe
+	for _, pair := range pairs {
+		timeframes, err := mdc.db.GetActiveTimeframes(pair)
+		if err != nil {
+			return fmt.Errorf("failed to get active timeframes: %w", err)
+		}
+		activeTimeframes = append(activeTimeframes, timeframes...)
 	}
 
 	if len(activeTimeframes) == 0 {
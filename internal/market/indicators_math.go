@@ -2,6 +2,7 @@ package market
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/cinar/indicator/v2/momentum"
 	"github.com/cinar/indicator/v2/trend"
@@ -32,6 +33,9 @@ func EMAWindow(period int) int { return period * 3 }
 // période lente).
 func MACDWindow(slowPeriod int) int { return slowPeriod * 3 }
 
+// BollingerWindow retourne le nombre de bougies à charger pour les bandes de Bollinger.
+func BollingerWindow(period int) int { return period * 2 }
+
 // RSIValue calcule le RSI (Wilder) sur la série de clôtures fournie et renvoie la
 // dernière valeur. Identique à l'implémentation cinar utilisée en production.
 func RSIValue(closes []float64, period int) (float64, error) {
@@ -185,3 +189,29 @@ func MACDValue(closes []float64, fastPeriod, slowPeriod, signalPeriod int) (macd
 	latestSignal := signalResults[len(signalResults)-1]
 	return latestMACD, latestSignal, latestMACD - latestSignal, nil
 }
+
+// BollingerValue calcule les bandes de Bollinger sur les `period` dernières clôtures :
+// moyenne mobile simple (bande médiane) ± k écarts-types (écart-type de population,
+// comme cinar). Calcul direct plutôt que cinar, dont les bandes sont figées à k = 2.
+func BollingerValue(closes []float64, period int, k float64) (upper, middle, lower float64, err error) {
+	if period <= 0 {
+		return 0, 0, 0, fmt.Errorf("Bollinger Bands period must be positive, got %d", period)
+	}
+	if len(closes) < period {
+		return 0, 0, 0, fmt.Errorf("insufficient candles for Bollinger Bands calculation: need %d, got %d", period, len(closes))
+	}
+
+	window := closes[len(closes)-period:]
+	for _, price := range window {
+		middle += price
+	}
+	middle /= float64(period)
+
+	variance := 0.0
+	for _, price := range window {
+		variance += (price - middle) * (price - middle)
+	}
+	std := math.Sqrt(variance / float64(period))
+
+	return middle + k*std, middle, middle - k*std, nil
+}
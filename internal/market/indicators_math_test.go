@@ -57,6 +57,23 @@ func TestMACDValueGolden(t *testing.T) {
 	approx(t, "Histogram", hist, -0.5181621990)
 }
 
+func TestBollingerValueGolden(t *testing.T) {
+	upper, middle, lower, err := BollingerValue(goldenCloses(), 20, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "BB upper", upper, 123.8946363061)
+	approx(t, "BB middle", middle, 112.7663479281)
+	approx(t, "BB lower", lower, 101.6380595500)
+
+	// Le multiplicateur est bien pris en compte (et pas figé à 2).
+	_, _, lower, err = BollingerValue(goldenCloses(), 20, 1.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	approx(t, "BB lower k=1.5", lower, 104.4201316445)
+}
+
 func TestIndicatorErrorsOnInsufficientData(t *testing.T) {
 	if _, err := RSIValue([]float64{1, 2, 3}, 14); err == nil {
 		t.Error("RSIValue aurait dû échouer sur données insuffisantes")
@@ -64,4 +81,7 @@ func TestIndicatorErrorsOnInsufficientData(t *testing.T) {
 	if _, err := EMAValue([]float64{1, 2, 3}, 14); err == nil {
 		t.Error("EMAValue aurait dû échouer sur données insuffisantes")
 	}
+	if _, _, _, err := BollingerValue([]float64{1, 2, 3}, 20, 2); err == nil {
+		t.Error("BollingerValue aurait dû échouer sur données insuffisantes")
+	}
 }
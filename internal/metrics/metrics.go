@@ -0,0 +1,284 @@
+// Package metrics expose des métriques au format texte Prometheus (version 0.0.4),
+// sans dépendance externe : compteurs, jauges et histogrammes étiquetés, regroupés
+// dans un registre servi par Handler. Le registre global Default est alimenté par
+// les packages instrumentés (exchange, bot) et publié par l'API du bot sur /metrics.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets couvre des durées de 5 ms à 30 s (appels exchange, ticks du bot).
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Registry regroupe des familles de métriques et les fonctions de collecte appelées
+// juste avant chaque export (métriques calculées à la demande, ex. depuis la DB).
+type Registry struct {
+	mu         sync.Mutex
+	families   []*family
+	collectors []func()
+	// scrapeMu sérialise les exports : deux scrapes simultanés ne voient pas les
+	// jauges d'un collecteur à moitié recalculées.
+	scrapeMu sync.Mutex
+}
+
+// Default est le registre global publié par l'API du bot.
+var Default = NewRegistry()
+
+// NewRegistry crée un registre vide.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+type family struct {
+	name, help, kind string
+	labels           []string
+	buckets          []float64      // histogrammes uniquement
+	fn               func() float64 // métriques calculées (CounterFunc, GaugeFunc)
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	counts      []uint64 // histogrammes : effectif par borne (non cumulé)
+	sum         float64
+	count       uint64
+}
+
+func (r *Registry) register(f *family) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.families {
+		if existing.name == f.name {
+			panic(fmt.Sprintf("metrics: %s déjà enregistrée", f.name))
+		}
+	}
+	f.series = make(map[string]*series)
+	r.families = append(r.families, f)
+	return f
+}
+
+// OnCollect enregistre une fonction appelée avant chaque export, typiquement pour
+// recalculer des jauges (Reset puis Set) à partir d'une source externe.
+func (r *Registry) OnCollect(fn func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, fn)
+}
+
+// get retourne la série des valeurs d'étiquettes données, créée à la demande.
+func (f *family) get(labelValues []string) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s attend %d étiquettes, %d reçues", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.kind == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// Counter est un compteur monotone, éventuellement étiqueté.
+type Counter struct{ f *family }
+
+// NewCounter enregistre un compteur.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	return &Counter{r.register(&family{name: name, help: help, kind: "counter", labels: labels})}
+}
+
+// Inc incrémente le compteur de 1.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add incrémente le compteur de v (v >= 0).
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		return
+	}
+	c.f.mu.Lock()
+	defer c.f.mu.Unlock()
+	c.f.get(labelValues).value += v
+}
+
+// Gauge est une valeur instantanée, éventuellement étiquetée.
+type Gauge struct{ f *family }
+
+// NewGauge enregistre une jauge.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.register(&family{name: name, help: help, kind: "gauge", labels: labels})}
+}
+
+// Set fixe la valeur de la jauge.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.get(labelValues).value = v
+}
+
+// Reset supprime toutes les séries (une stratégie supprimée disparaît de l'export).
+func (g *Gauge) Reset() {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	g.f.series = make(map[string]*series)
+}
+
+// Histogram répartit des observations (durées en secondes) par bornes cumulées.
+type Histogram struct{ f *family }
+
+// NewHistogram enregistre un histogramme ; buckets = bornes supérieures croissantes
+// (+Inf est implicite).
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return &Histogram{r.register(&family{name: name, help: help, kind: "histogram", labels: labels, buckets: buckets})}
+}
+
+// Observe enregistre une observation.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(labelValues)
+	for i, upper := range h.f.buckets {
+		if v <= upper {
+			s.counts[i]++
+			break
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+// NewCounterFunc enregistre un compteur non étiqueté dont la valeur est lue à
+// chaque export (ex. compteur tenu par un autre package).
+func (r *Registry) NewCounterFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, kind: "counter", fn: fn})
+}
+
+// NewGaugeFunc enregistre une jauge non étiquetée dont la valeur est lue à chaque export.
+func (r *Registry) NewGaugeFunc(name, help string, fn func() float64) {
+	r.register(&family{name: name, help: help, kind: "gauge", fn: fn})
+}
+
+// WriteText écrit toutes les métriques au format texte Prometheus, familles dans
+// l'ordre d'enregistrement et séries triées par étiquettes.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.scrapeMu.Lock()
+	defer r.scrapeMu.Unlock()
+
+	r.mu.Lock()
+	collectors := append([]func(){}, r.collectors...)
+	families := append([]*family{}, r.families...)
+	r.mu.Unlock()
+
+	for _, collect := range collectors {
+		collect()
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.name, f.kind)
+		if f.fn != nil {
+			fmt.Fprintf(bw, "%s %s\n", f.name, formatValue(f.fn()))
+			continue
+		}
+		f.writeSeries(bw)
+	}
+	return bw.Flush()
+}
+
+func (f *family) writeSeries(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		s := f.series[k]
+		if f.kind != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), formatValue(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range f.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "le", formatValue(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, formatLabels(f.labels, s.labelValues, "", ""), s.count)
+	}
+}
+
+// formatLabels rend {a="x",b="y"} ; extraName/extraValue ajoutent l'étiquette le
+// des histogrammes.
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, escapeLabel(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// Handler sert le registre au format texte Prometheus.
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := r.WriteText(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	calls := r.NewCounter("test_calls_total", "Appels.", "operation")
+	price := r.NewGauge("test_price", "Prix.", "pair")
+	latency := r.NewHistogram("test_latency_seconds", "Latence.", []float64{0.1, 1})
+	r.NewGaugeFunc("test_answer", "Réponse.", func() float64 { return 42 })
+
+	calls.Inc("get_price")
+	calls.Add(2, "get_price")
+	calls.Inc(`fetch "order"`)
+	price.Set(65000.5, "BTC/USDC")
+	latency.Observe(0.05)
+	latency.Observe(0.5)
+	latency.Observe(3)
+
+	collected := 0
+	r.OnCollect(func() { collected++ })
+
+	var b strings.Builder
+	if err := r.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_calls_total Appels.
+# TYPE test_calls_total counter
+test_calls_total{operation="fetch \"order\""} 1
+test_calls_total{operation="get_price"} 3
+# HELP test_price Prix.
+# TYPE test_price gauge
+test_price{pair="BTC/USDC"} 65000.5
+# HELP test_latency_seconds Latence.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{le="0.1"} 1
+test_latency_seconds_bucket{le="1"} 2
+test_latency_seconds_bucket{le="+Inf"} 3
+test_latency_seconds_sum 3.55
+test_latency_seconds_count 3
+# HELP test_answer Réponse.
+# TYPE test_answer gauge
+test_answer 42
+`
+	if b.String() != want {
+		t.Errorf("export :\n%s\nattendu :\n%s", b.String(), want)
+	}
+	if collected != 1 {
+		t.Errorf("collecteur appelé %d fois, attendu 1", collected)
+	}
+
+	// Reset : les séries disparaissent de l'export.
+	price.Reset()
+	b.Reset()
+	_ = r.WriteText(&b)
+	if strings.Contains(b.String(), "BTC/USDC") {
+		t.Error("série conservée après Reset")
+	}
+}
@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"bot/internal/events"
+	"bot/internal/telegram"
+)
+
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// post envoie req et retourne une *statusError si la réponse n'est pas 2xx.
+func post(req *http.Request) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	return nil
+}
+
+func postJSON(url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return post(req)
+}
+
+// truncate limite s à max runes (limites de taille des messages Discord et Slack).
+func truncate(s string, max int) string {
+	if r := []rune(s); len(r) > max {
+		return string(r[:max-1]) + "…"
+	}
+	return s
+}
+
+// Telegram envoie le message via telegram.SendMessage (sans effet si TELEGRAM n'est
+// pas activé).
+type Telegram struct{}
+
+func (Telegram) Name() string { return KindTelegram }
+
+func (Telegram) Send(e events.Event) error { return telegram.SendMessage(e.Message) }
+
+// Webhook poste un objet JSON générique : type, exchange, pair, text et created_at.
+// Pour recevoir tous les événements avec leur détail, voir plutôt events.WebhookSink.
+type Webhook struct {
+	URL string
+}
+
+func (w *Webhook) Name() string { return KindWebhook }
+
+func (w *Webhook) Send(e events.Event) error {
+	return postJSON(w.URL, map[string]any{
+		"type":       e.Type,
+		"exchange":   e.Exchange,
+		"pair":       e.Pair,
+		"text":       e.Message,
+		"created_at": e.CreatedAt,
+	})
+}
+
+// Discord poste sur un webhook entrant Discord (Paramètres du salon > Intégrations).
+type Discord struct {
+	URL string
+}
+
+func (d *Discord) Name() string { return KindDiscord }
+
+func (d *Discord) Send(e events.Event) error {
+	return postJSON(d.URL, map[string]string{"content": truncate(e.Message, 2000)})
+}
+
+// Slack poste sur un webhook entrant Slack (application « Incoming Webhooks »).
+type Slack struct {
+	URL string
+}
+
+func (s *Slack) Name() string { return KindSlack }
+
+func (s *Slack) Send(e events.Event) error {
+	return postJSON(s.URL, map[string]string{"text": truncate(e.Message, 4000)})
+}
+
+// Ntfy publie sur un topic ntfy (https://ntfy.sh/<topic> ou serveur auto-hébergé).
+// Les alertes (disjoncteur, erreurs) sont publiées en priorité haute.
+type Ntfy struct {
+	URL   string
+	Token string // jeton d'accès, pour un topic protégé
+}
+
+func (n *Ntfy) Name() string { return KindNtfy }
+
+func (n *Ntfy) Send(e events.Event) error {
+	req, err := http.NewRequest(http.MethodPost, n.URL, strings.NewReader(e.Message))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Title", "simple-bot "+e.Exchange)
+	req.Header.Set("Tags", strings.ReplaceAll(string(e.Type), ".", "_"))
+	switch e.Type {
+	case events.BreakerTripped, events.ErrorRaised:
+		req.Header.Set("Priority", "high")
+	}
+	if n.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.Token)
+	}
+	return post(req)
+}
@@ -0,0 +1,128 @@
+// Package notify envoie les notifications du bot (exécutions, cycles terminés, signaux
+// de retournement, alertes d'erreur, disjoncteur...) sur un ou plusieurs canaux :
+// Telegram, webhook JSON générique, webhooks entrants Discord et Slack, ntfy.
+//
+// Chaque canal est abonné au bus d'événements (cf. events.Bus) pour les types qui lui
+// sont routés ; seuls les événements porteurs d'un Message sont notifiés. Un envoi en
+// échec est retenté avec un délai doublé à chaque tentative.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"bot/internal/events"
+	"bot/internal/logger"
+)
+
+// Canaux disponibles (valeur de Config.Kind).
+const (
+	KindTelegram = "telegram"
+	KindWebhook  = "webhook"
+	KindDiscord  = "discord"
+	KindSlack    = "slack"
+	KindNtfy     = "ntfy"
+)
+
+// Notifier est un canal de notification.
+type Notifier interface {
+	Name() string
+	Send(e events.Event) error
+}
+
+// Config décrit un canal configuré pour l'instance.
+type Config struct {
+	Kind  string
+	URL   string // webhook Discord/Slack/JSON, ou URL du topic ntfy
+	Token string // jeton d'accès ntfy (optionnel)
+}
+
+// New crée le canal décrit par cfg (sans le routage, cf. Subscribe).
+func New(cfg Config) (Notifier, error) {
+	if cfg.Kind != KindTelegram && cfg.URL == "" {
+		return nil, fmt.Errorf("notifier %s: missing URL", cfg.Kind)
+	}
+	switch cfg.Kind {
+	case KindTelegram:
+		return Telegram{}, nil
+	case KindWebhook:
+		return &Webhook{URL: cfg.URL}, nil
+	case KindDiscord:
+		return &Discord{URL: cfg.URL}, nil
+	case KindSlack:
+		return &Slack{URL: cfg.URL}, nil
+	case KindNtfy:
+		return &Ntfy{URL: cfg.URL, Token: cfg.Token}, nil
+	}
+	return nil, fmt.Errorf("unknown notifier: %s", cfg.Kind)
+}
+
+// Retry règle les nouvelles tentatives d'un envoi en échec : Attempts envois au total,
+// espacés de Backoff, puis 2×Backoff, 4×Backoff...
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// DefaultRetry : 3 envois espacés de 2s puis 4s.
+var DefaultRetry = Retry{Attempts: 3, Backoff: 2 * time.Second}
+
+// Subscribe abonne n au bus pour les types donnés (aucun = tous les événements
+// porteurs d'un message).
+func Subscribe(bus *events.Bus, n Notifier, retry Retry, types ...events.Type) {
+	bus.Subscribe(&sink{notifier: n, retry: retry}, types...)
+}
+
+// sink adapte un Notifier au bus : filtre les événements sans message et retente les
+// envois en échec. Il tourne dans la goroutine d'abonné du bus, l'attente entre deux
+// tentatives ne bloque donc que ce canal.
+type sink struct {
+	notifier Notifier
+	retry    Retry
+	sleep    func(time.Duration) // time.Sleep, remplacé dans les tests
+}
+
+func (s *sink) Name() string { return s.notifier.Name() }
+
+func (s *sink) Handle(e events.Event) error {
+	if e.Message == "" {
+		return nil
+	}
+	sleep := s.sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	delay := s.retry.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = s.notifier.Send(e); err == nil || !retryable(err) || attempt >= s.retry.Attempts {
+			return err
+		}
+		logger.Debugf("[%s] Envoi %s en échec (tentative %d/%d), nouvel essai dans %s : %v",
+			e.Exchange, s.notifier.Name(), attempt, s.retry.Attempts, delay, err)
+		sleep(delay)
+		delay *= 2
+	}
+}
+
+// statusError est une réponse HTTP en erreur d'un canal.
+type statusError struct {
+	Code   int
+	Status string
+}
+
+func (e *statusError) Error() string { return "unexpected response: " + e.Status }
+
+// retryable indique si un envoi en échec mérite un nouvel essai : erreur réseau,
+// limitation de débit (429) ou erreur serveur (5xx). Une autre erreur HTTP (URL ou
+// jeton invalide, message refusé) échouerait de la même façon.
+func retryable(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusTooManyRequests || se.Code >= 500
+	}
+	return true
+}
@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"bot/internal/events"
+	"bot/internal/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.InitLogger("error", "")
+	os.Exit(m.Run())
+}
+
+type request struct {
+	body   []byte
+	header http.Header
+}
+
+// recorder répond successivement les codes donnés (puis 200) et garde chaque requête.
+func recorder(t *testing.T, codes ...int) (*httptest.Server, *[]request) {
+	var got []request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got = append(got, request{body, r.Header})
+		if len(got) <= len(codes) {
+			w.WriteHeader(codes[len(got)-1])
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &got
+}
+
+var fill = events.Event{Type: events.OrderFilled, Exchange: "mexc", Pair: "BTC/USDC", Message: "✅ Buy Order Filled"}
+
+func TestChannels(t *testing.T) {
+	for _, tc := range []struct {
+		kind  string
+		check func(t *testing.T, r request)
+	}{
+		{KindDiscord, func(t *testing.T, r request) {
+			var p map[string]string
+			_ = json.Unmarshal(r.body, &p)
+			if p["content"] != fill.Message {
+				t.Errorf("payload Discord : %s", r.body)
+			}
+		}},
+		{KindSlack, func(t *testing.T, r request) {
+			var p map[string]string
+			_ = json.Unmarshal(r.body, &p)
+			if p["text"] != fill.Message {
+				t.Errorf("payload Slack : %s", r.body)
+			}
+		}},
+		{KindWebhook, func(t *testing.T, r request) {
+			var p map[string]any
+			_ = json.Unmarshal(r.body, &p)
+			if p["type"] != "order.filled" || p["exchange"] != "mexc" || p["text"] != fill.Message {
+				t.Errorf("payload webhook : %s", r.body)
+			}
+		}},
+		{KindNtfy, func(t *testing.T, r request) {
+			if string(r.body) != fill.Message || r.header.Get("Title") != "simple-bot mexc" ||
+				r.header.Get("Tags") != "order_filled" || r.header.Get("Authorization") != "Bearer tk" {
+				t.Errorf("requête ntfy : %s %v", r.body, r.header)
+			}
+		}},
+	} {
+		t.Run(tc.kind, func(t *testing.T) {
+			srv, got := recorder(t)
+			n, err := New(Config{Kind: tc.kind, URL: srv.URL, Token: "tk"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := n.Send(fill); err != nil {
+				t.Fatal(err)
+			}
+			if len(*got) != 1 {
+				t.Fatalf("%d requêtes, attendu 1", len(*got))
+			}
+			tc.check(t, (*got)[0])
+		})
+	}
+
+	if _, err := New(Config{Kind: KindDiscord}); err == nil {
+		t.Error("URL manquante : erreur attendue")
+	}
+	if _, err := New(Config{Kind: "pigeon", URL: "http://x"}); err == nil {
+		t.Error("canal inconnu : erreur attendue")
+	}
+}
+
+// Les erreurs serveur et la limitation de débit sont retentées avec un délai doublé ;
+// une erreur client ne l'est pas.
+func TestSinkRetry(t *testing.T) {
+	var delays []time.Duration
+	sleep := func(d time.Duration) { delays = append(delays, d) }
+
+	srv, got := recorder(t, http.StatusBadGateway, http.StatusTooManyRequests)
+	s := &sink{notifier: &Slack{URL: srv.URL}, retry: Retry{Attempts: 3, Backoff: time.Second}, sleep: sleep}
+	if err := s.Handle(fill); err != nil {
+		t.Fatalf("3e tentative réussie attendue : %v", err)
+	}
+	if len(*got) != 3 || len(delays) != 2 || delays[0] != time.Second || delays[1] != 2*time.Second {
+		t.Errorf("%d envois, délais %v", len(*got), delays)
+	}
+
+	srv, got = recorder(t, http.StatusNotFound)
+	s = &sink{notifier: &Slack{URL: srv.URL}, retry: Retry{Attempts: 3, Backoff: time.Second}, sleep: sleep}
+	var se *statusError
+	if err := s.Handle(fill); !errors.As(err, &se) || se.Code != http.StatusNotFound || len(*got) != 1 {
+		t.Errorf("404 : %d envois, erreur %v", len(*got), err)
+	}
+
+	// Événement sans message : rien n'est envoyé
+	if err := s.Handle(events.Event{Type: events.OrderPlaced}); err != nil || len(*got) != 1 {
+		t.Errorf("événement sans message envoyé (%d envois, %v)", len(*got), err)
+	}
+}
+
+// Chaque canal ne reçoit que les types qui lui sont routés.
+func TestSubscribeRouting(t *testing.T) {
+	discord, gotDiscord := recorder(t)
+	slack, gotSlack := recorder(t)
+
+	bus := events.NewBus("mexc", nil)
+	Subscribe(bus, &Discord{URL: discord.URL}, DefaultRetry, events.CycleCompleted)
+	Subscribe(bus, &Slack{URL: slack.URL}, DefaultRetry)
+
+	bus.Publish(fill)
+	bus.Publish(events.Event{Type: events.CycleCompleted, Message: "🌀 Cycle COMPLETE"})
+	bus.Close()
+
+	if len(*gotDiscord) != 1 || len(*gotSlack) != 2 {
+		t.Errorf("Discord : %d envois (attendu 1), Slack : %d (attendu 2)", len(*gotDiscord), len(*gotSlack))
+	}
+}
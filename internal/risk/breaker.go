@@ -0,0 +1,149 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TripKind identifie la condition qui a déclenché le disjoncteur.
+type TripKind string
+
+const (
+	TripPriceDrop      TripKind = "price_drop"      // BreakerConfig.PriceDropPct
+	TripExchangeErrors TripKind = "exchange_errors" // BreakerConfig.MaxExchangeErrors
+	TripStaleTicker    TripKind = "stale_ticker"    // BreakerConfig.MaxTickerAge
+	TripSpread         TripKind = "spread"          // BreakerConfig.MaxSpreadPct
+	TripDailyLoss      TripKind = "daily_loss"      // BreakerConfig.MaxDailyLoss
+	TripManual         TripKind = "manual"          // arrêt d'urgence de l'opérateur
+)
+
+// BreakerConfig regroupe les seuils du disjoncteur. 0 = condition désactivée.
+type BreakerConfig struct {
+	// PriceDropPct : baisse (%) du prix depuis le plus haut relevé sur PriceDropWindow.
+	PriceDropPct    float64
+	PriceDropWindow time.Duration
+	// MaxExchangeErrors : appels exchange en échec consécutifs (prix, suivi des ordres).
+	MaxExchangeErrors int
+	// MaxTickerAge : âge maximal du ticker (horodatage fourni par l'exchange).
+	MaxTickerAge time.Duration
+	// MaxSpreadPct : écart acheteur/vendeur maximal, en % du milieu de fourchette.
+	MaxSpreadPct float64
+	// MaxDailyLoss : perte réalisée (quote) maximale depuis minuit UTC.
+	MaxDailyLoss float64
+}
+
+// Trip décrit un déclenchement du disjoncteur.
+type Trip struct {
+	Kind   TripKind
+	Pair   string // paire concernée (vide = toute l'instance)
+	Reason string
+}
+
+// Ticker est un relevé de prix. Bid/Ask à 0 et Time nul = inconnus (non vérifiés).
+type Ticker struct {
+	Last float64
+	Bid  float64
+	Ask  float64
+	Time time.Time
+}
+
+type pricePoint struct {
+	at    time.Time
+	price float64
+}
+
+// Breaker surveille les prix et les erreurs exchange relevés par la boucle du bot et
+// signale la première condition anormale. Il ne suspend rien lui-même : c'est au bot
+// de stopper les achats, de persister et de notifier le déclenchement.
+type Breaker struct {
+	mu     sync.Mutex
+	cfg    BreakerConfig
+	prices map[string][]pricePoint
+	errors int
+}
+
+// NewBreaker crée un disjoncteur avec les seuils cfg.
+func NewBreaker(cfg BreakerConfig) *Breaker {
+	return &Breaker{cfg: cfg, prices: make(map[string][]pricePoint)}
+}
+
+// ObserveTicker enregistre un relevé de prix de pair et vérifie l'âge du ticker, la
+// fourchette et la baisse de prix sur la fenêtre. Retourne nil si tout est normal.
+func (b *Breaker) ObserveTicker(pair string, t Ticker, now time.Time) *Trip {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.cfg.MaxTickerAge > 0 && !t.Time.IsZero() {
+		if age := now.Sub(t.Time); age > b.cfg.MaxTickerAge {
+			return &Trip{TripStaleTicker, pair, fmt.Sprintf("ticker %s figé depuis %s (> %s)",
+				pair, age.Round(time.Second), b.cfg.MaxTickerAge)}
+		}
+	}
+
+	if b.cfg.MaxSpreadPct > 0 && t.Bid > 0 && t.Ask > 0 {
+		mid := (t.Bid + t.Ask) / 2
+		if spread := (t.Ask - t.Bid) / mid * 100; spread > b.cfg.MaxSpreadPct {
+			return &Trip{TripSpread, pair, fmt.Sprintf("fourchette %s anormale : %.2f%% (> %.2f%%, bid %g / ask %g)",
+				pair, spread, b.cfg.MaxSpreadPct, t.Bid, t.Ask)}
+		}
+	}
+
+	if b.cfg.PriceDropPct <= 0 || b.cfg.PriceDropWindow <= 0 || t.Last <= 0 {
+		return nil
+	}
+	points := b.prices[pair]
+	cutoff := now.Add(-b.cfg.PriceDropWindow)
+	for len(points) > 0 && points[0].at.Before(cutoff) {
+		points = points[1:]
+	}
+	points = append(points, pricePoint{now, t.Last})
+	b.prices[pair] = points
+
+	high := points[0].price
+	for _, p := range points {
+		high = max(high, p.price)
+	}
+	if drop := (high - t.Last) / high * 100; drop >= b.cfg.PriceDropPct {
+		return &Trip{TripPriceDrop, pair, fmt.Sprintf("chute de %s : -%.2f%% en moins de %s (%g → %g)",
+			pair, drop, b.cfg.PriceDropWindow, high, t.Last)}
+	}
+	return nil
+}
+
+// ObserveError compte un appel exchange en échec. Retourne un déclenchement quand le
+// nombre d'échecs consécutifs atteint le seuil.
+func (b *Breaker) ObserveError(err error) *Trip {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.errors++
+	if b.cfg.MaxExchangeErrors <= 0 || b.errors < b.cfg.MaxExchangeErrors {
+		return nil
+	}
+	return &Trip{TripExchangeErrors, "", fmt.Sprintf("%d erreurs exchange consécutives (dernière : %v)", b.errors, err)}
+}
+
+// ObserveSuccess remet à zéro le compteur d'échecs consécutifs.
+func (b *Breaker) ObserveSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errors = 0
+}
+
+// CheckDailyLoss vérifie le profit réalisé depuis minuit UTC (négatif = perte).
+func (b *Breaker) CheckDailyLoss(realized float64) *Trip {
+	if b.cfg.MaxDailyLoss <= 0 || -realized <= b.cfg.MaxDailyLoss {
+		return nil
+	}
+	return &Trip{TripDailyLoss, "", fmt.Sprintf("perte réalisée du jour : %.2f (> %.2f)", -realized, b.cfg.MaxDailyLoss)}
+}
+
+// Reset oublie l'historique des prix et les erreurs, au réarmement : la baisse qui a
+// déclenché le disjoncteur ne le redéclenche pas tant qu'elle ne se prolonge pas.
+func (b *Breaker) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.prices = make(map[string][]pricePoint)
+	b.errors = 0
+}
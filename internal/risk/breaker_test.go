@@ -0,0 +1,78 @@
+package risk
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerTicker(t *testing.T) {
+	b := NewBreaker(BreakerConfig{PriceDropPct: 10, PriceDropWindow: time.Hour, MaxTickerAge: 5 * time.Minute, MaxSpreadPct: 1})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 100, Bid: 99.9, Ask: 100.1, Time: now}, now); trip != nil {
+		t.Fatalf("relevé normal : déclenchement inattendu (%s)", trip.Reason)
+	}
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 95}, now.Add(10*time.Minute)); trip != nil {
+		t.Fatalf("baisse de 5%% : déclenchement inattendu (%s)", trip.Reason)
+	}
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 89}, now.Add(20*time.Minute)); trip == nil || trip.Kind != TripPriceDrop {
+		t.Fatalf("baisse de 11%% en 20 min : attendu %s, obtenu %v", TripPriceDrop, trip)
+	}
+	// Le plus haut sort de la fenêtre : la baisse n'est plus comptée depuis 100
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 89}, now.Add(90*time.Minute)); trip != nil {
+		t.Fatalf("hors fenêtre : déclenchement inattendu (%s)", trip.Reason)
+	}
+	// Chaque paire a sa propre fenêtre
+	if trip := b.ObserveTicker("ETH/USDC", Ticker{Last: 80}, now.Add(90*time.Minute)); trip != nil {
+		t.Fatalf("autre paire : déclenchement inattendu (%s)", trip.Reason)
+	}
+
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 89, Time: now}, now.Add(2*time.Hour)); trip == nil || trip.Kind != TripStaleTicker {
+		t.Errorf("ticker vieux de 2h : attendu %s, obtenu %v", TripStaleTicker, trip)
+	}
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 89, Bid: 88, Ask: 90}, now.Add(2*time.Hour)); trip == nil || trip.Kind != TripSpread {
+		t.Errorf("fourchette de 2.2%% : attendu %s, obtenu %v", TripSpread, trip)
+	}
+}
+
+func TestBreakerErrorsAndReset(t *testing.T) {
+	b := NewBreaker(BreakerConfig{MaxExchangeErrors: 3, PriceDropPct: 10, PriceDropWindow: time.Hour})
+	errTimeout := errors.New("timeout")
+
+	b.ObserveError(errTimeout)
+	b.ObserveError(errTimeout)
+	b.ObserveSuccess()
+	b.ObserveError(errTimeout)
+	if trip := b.ObserveError(errTimeout); trip != nil {
+		t.Fatalf("2 erreurs consécutives : déclenchement inattendu (%s)", trip.Reason)
+	}
+	if trip := b.ObserveError(errTimeout); trip == nil || trip.Kind != TripExchangeErrors {
+		t.Fatalf("3 erreurs consécutives : attendu %s, obtenu %v", TripExchangeErrors, trip)
+	}
+
+	now := time.Now()
+	b.ObserveTicker("BTC/USDC", Ticker{Last: 100}, now)
+	b.Reset()
+	if trip := b.ObserveTicker("BTC/USDC", Ticker{Last: 85}, now.Add(time.Minute)); trip != nil {
+		t.Errorf("après réarmement : déclenchement inattendu (%s)", trip.Reason)
+	}
+	if trip := b.ObserveError(errTimeout); trip != nil {
+		t.Errorf("après réarmement : compteur d'erreurs non remis à zéro (%s)", trip.Reason)
+	}
+}
+
+func TestBreakerDailyLoss(t *testing.T) {
+	b := NewBreaker(BreakerConfig{MaxDailyLoss: 50})
+	for _, tc := range []struct {
+		realized float64
+		trip     bool
+	}{{20, false}, {-50, false}, {-50.01, true}} {
+		if trip := b.CheckDailyLoss(tc.realized); (trip != nil) != tc.trip {
+			t.Errorf("profit du jour %.2f : déclenchement %v, attendu %v", tc.realized, trip != nil, tc.trip)
+		}
+	}
+	if NewBreaker(BreakerConfig{}).CheckDailyLoss(-1000) != nil {
+		t.Error("limite désactivée : déclenchement inattendu")
+	}
+}
@@ -0,0 +1,81 @@
+// Package risk applique les limites de risque aux achats : capital engagé par
+// stratégie et par instance, dépense quotidienne et réserve de quote intouchable.
+// Le bot (scheduler) et le backtest partagent le même contrôle, pour refuser les
+// mêmes achats en réel et en simulation. Le disjoncteur (cf. Breaker) suspend, lui,
+// tous les achats de l'instance sur un marché ou un exchange anormal.
+package risk
+
+import "fmt"
+
+// Limit identifie la limite qui refuse un achat.
+type Limit string
+
+const (
+	LimitStrategyCapital Limit = "strategy_capital" // Strategy.MaxCapital
+	LimitTotalCapital    Limit = "total_capital"    // Limits.MaxTotalCapital
+	LimitDailySpend      Limit = "daily_spend"      // Limits.MaxDailySpend
+	LimitQuoteReserve    Limit = "quote_reserve"    // Limits.MinQuoteReserve
+	LimitMarket          Limit = "market"           // limites de l'exchange (algorithms.MarketPrecision)
+)
+
+// Limits regroupe les limites communes à toute l'instance. 0 = pas de limite.
+type Limits struct {
+	// MaxTotalCapital : capital quote engagé simultanément, toutes stratégies confondues
+	// (notional des cycles ouverts, achats en attente compris).
+	MaxTotalCapital float64
+	// MaxDailySpend : montant quote dépensé en achats depuis minuit UTC.
+	MaxDailySpend float64
+	// MinQuoteReserve : solde quote libre que les achats ne doivent jamais entamer.
+	MinQuoteReserve float64
+}
+
+// Exposure est l'état du compte au moment d'un achat.
+type Exposure struct {
+	StrategyCapital float64 // notional des cycles ouverts de la stratégie
+	TotalCapital    float64 // notional des cycles ouverts de toutes les stratégies
+	DailySpend      float64 // achats du jour (UTC)
+	// FreeQuote : solde quote libre ; négatif = inconnu (réserve non vérifiée).
+	FreeQuote float64
+}
+
+// Commit ajoute un achat de cost à l'exposition, pour contrôler l'ordre suivant
+// d'un même tick (grille).
+func (e *Exposure) Commit(cost float64) {
+	e.StrategyCapital += cost
+	e.TotalCapital += cost
+	e.DailySpend += cost
+	if e.FreeQuote >= 0 {
+		e.FreeQuote -= cost
+	}
+}
+
+// Breach décrit un achat refusé par une limite.
+type Breach struct {
+	Limit  Limit
+	Reason string
+}
+
+func (b *Breach) Error() string { return b.Reason }
+
+// Check vérifie qu'un achat de cost (quote) reste dans les limites, maxCapital étant
+// le plafond propre à la stratégie (0 = illimité). Retourne nil si l'achat est permis,
+// sinon la première limite dépassée.
+func (l Limits) Check(maxCapital float64, e Exposure, cost float64) *Breach {
+	if maxCapital > 0 && e.StrategyCapital+cost > maxCapital {
+		return &Breach{LimitStrategyCapital, fmt.Sprintf("capital max de la stratégie atteint (%.2f engagés + %.2f > %.2f)",
+			e.StrategyCapital, cost, maxCapital)}
+	}
+	if l.MaxTotalCapital > 0 && e.TotalCapital+cost > l.MaxTotalCapital {
+		return &Breach{LimitTotalCapital, fmt.Sprintf("capital max de l'instance atteint (%.2f engagés + %.2f > %.2f)",
+			e.TotalCapital, cost, l.MaxTotalCapital)}
+	}
+	if l.MaxDailySpend > 0 && e.DailySpend+cost > l.MaxDailySpend {
+		return &Breach{LimitDailySpend, fmt.Sprintf("dépense max du jour atteinte (%.2f dépensés + %.2f > %.2f)",
+			e.DailySpend, cost, l.MaxDailySpend)}
+	}
+	if l.MinQuoteReserve > 0 && e.FreeQuote >= 0 && e.FreeQuote-cost < l.MinQuoteReserve {
+		return &Breach{LimitQuoteReserve, fmt.Sprintf("réserve de quote entamée (%.2f libres - %.2f < %.2f)",
+			e.FreeQuote, cost, l.MinQuoteReserve)}
+	}
+	return nil
+}
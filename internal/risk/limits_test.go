@@ -0,0 +1,50 @@
+package risk
+
+import "testing"
+
+func TestLimitsCheck(t *testing.T) {
+	limits := Limits{MaxTotalCapital: 500, MaxDailySpend: 100, MinQuoteReserve: 50}
+	cases := []struct {
+		name       string
+		maxCapital float64
+		exposure   Exposure
+		cost       float64
+		want       Limit
+	}{
+		{"dans les limites", 200, Exposure{StrategyCapital: 100, TotalCapital: 300, DailySpend: 50, FreeQuote: 200}, 25, ""},
+		{"capital de la stratégie", 120, Exposure{StrategyCapital: 100, TotalCapital: 300, FreeQuote: 200}, 25, LimitStrategyCapital},
+		{"capital illimité pour la stratégie", 0, Exposure{StrategyCapital: 1000, TotalCapital: 300, FreeQuote: 200}, 25, ""},
+		{"capital de l'instance", 0, Exposure{TotalCapital: 490, FreeQuote: 200}, 25, LimitTotalCapital},
+		{"dépense du jour", 0, Exposure{DailySpend: 80, FreeQuote: 200}, 25, LimitDailySpend},
+		{"réserve de quote", 0, Exposure{FreeQuote: 70}, 25, LimitQuoteReserve},
+		{"solde inconnu", 0, Exposure{FreeQuote: -1}, 25, ""},
+	}
+	for _, tc := range cases {
+		breach := limits.Check(tc.maxCapital, tc.exposure, tc.cost)
+		switch {
+		case tc.want == "" && breach != nil:
+			t.Errorf("%s : refus inattendu (%s)", tc.name, breach.Reason)
+		case tc.want != "" && (breach == nil || breach.Limit != tc.want):
+			t.Errorf("%s : attendu un refus %s, obtenu %v", tc.name, tc.want, breach)
+		}
+	}
+
+	// Sans limite, tout achat passe
+	if breach := (Limits{}).Check(0, Exposure{TotalCapital: 1e9, DailySpend: 1e9, FreeQuote: 0}, 25); breach != nil {
+		t.Errorf("limites vides : refus inattendu (%s)", breach.Reason)
+	}
+}
+
+// Commit cumule les achats d'un même tick : le deuxième ordre voit le premier.
+func TestExposureCommit(t *testing.T) {
+	e := Exposure{FreeQuote: 100}
+	e.Commit(30)
+	if e.StrategyCapital != 30 || e.TotalCapital != 30 || e.DailySpend != 30 || e.FreeQuote != 70 {
+		t.Errorf("exposition après achat : %+v", e)
+	}
+	unknown := Exposure{FreeQuote: -1}
+	unknown.Commit(30)
+	if unknown.FreeQuote != -1 {
+		t.Errorf("solde inconnu modifié : %.2f", unknown.FreeQuote)
+	}
+}
@@ -9,8 +9,10 @@ import (
 
 	"bot/internal/algorithms"
 	"bot/internal/core/database"
+	"bot/internal/events"
 	"bot/internal/logger"
 	"bot/internal/market"
+	"bot/internal/risk"
 
 	"github.com/go-co-op/gocron/v2"
 	"github.com/robfig/cron/v3"
@@ -19,7 +21,6 @@ import (
 // StrategyScheduler manages cron-based execution of trading strategies
 type StrategyScheduler struct {
 	exchangeName    string
-	pair            string
 	scheduler       gocron.Scheduler
 	started         bool
 	db              *database.DB
@@ -29,7 +30,7 @@ type StrategyScheduler struct {
 }
 
 // NewStrategyScheduler creates a new strategy scheduler
-func NewStrategyScheduler(exchangeName, pair string, db *database.DB, market StrategyMarket, marketCollector *market.MarketDataCollector, calculator *market.Calculator, algorithmRegistry *algorithms.AlgorithmRegistry, exchange StrategyExchange) (*StrategyScheduler, error) {
+func NewStrategyScheduler(exchangeName string, db *database.DB, markets StrategyMarkets, marketCollector *market.MarketDataCollector, calculator *market.Calculator, algorithmRegistry *algorithms.AlgorithmRegistry, exchange StrategyExchange, limits risk.Limits, bus *events.Bus) (*StrategyScheduler, error) {
 	// Create the scheduler with options
 	s, err := gocron.NewScheduler(
 		gocron.WithLocation(time.Local),
@@ -41,11 +42,10 @@ func NewStrategyScheduler(exchangeName, pair string, db *database.DB, market Str
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Create strategy manager for orchestrating execution
-	strategyManager := NewStrategyManager(exchangeName, pair, db, market, marketCollector, calculator, algorithmRegistry, exchange)
+	strategyManager := NewStrategyManager(exchangeName, db, markets, marketCollector, calculator, algorithmRegistry, exchange, limits, bus)
 
 	return &StrategyScheduler{
 		exchangeName:    exchangeName,
-		pair:            pair,
 		scheduler:       s,
 		started:         false,
 		db:              db,
@@ -4,17 +4,22 @@ import (
 	"bot/internal/algorithms"
 
 	"bot/internal/core/database"
+	"bot/internal/decimal"
+	"bot/internal/events"
 	"bot/internal/logger"
 	"bot/internal/market"
+	"bot/internal/risk"
 	"fmt"
+	"strings"
+	"time"
 )
 
 // StrategyExchange defines the interface needed for strategy execution
 type StrategyExchange interface {
 	FetchBalance() (map[string]ExchangeBalance, error)
 	GetPrice(pair string) (float64, error)
-	PlaceLimitBuyOrder(pair string, amount float64, price float64) (ExchangeOrder, error)
-	PlaceLimitSellOrder(pair string, amount float64, price float64) (ExchangeOrder, error)
+	PlaceLimitBuyOrder(pair string, amount float64, price float64, clientOrderID string) (ExchangeOrder, error)
+	PlaceLimitSellOrder(pair string, amount float64, price float64, clientOrderID string) (ExchangeOrder, error)
 }
 
 // ExchangeBalance represents balance from exchange
@@ -38,29 +43,39 @@ type StrategyMarket interface {
 	GetPrecision() algorithms.MarketPrecision
 }
 
+// StrategyMarkets fournit le marché (actifs, précision) de chaque paire tradée :
+// chaque stratégie porte sa propre paire.
+type StrategyMarkets interface {
+	MarketFor(pair string) StrategyMarket
+}
+
 // StrategyManager orchestrates the execution of trading strategies
 type StrategyManager struct {
 	exchangeName      string
-	pair              string
 	db                *database.DB
-	market            StrategyMarket
+	markets           StrategyMarkets
 	marketCollector   *market.MarketDataCollector
 	calculator        *market.Calculator
 	algorithmRegistry *algorithms.AlgorithmRegistry
 	exchange          StrategyExchange
+	// limits : limites de risque de l'instance, complétées par Strategy.MaxCapital
+	limits risk.Limits
+	// bus : ordres posés, cycles ouverts et achats refusés y sont publiés
+	bus *events.Bus
 }
 
 // NewStrategyManager creates a new strategy manager
-func NewStrategyManager(exchangeName, pair string, db *database.DB, market StrategyMarket, marketCollector *market.MarketDataCollector, calculator *market.Calculator, algorithmRegistry *algorithms.AlgorithmRegistry, exchange StrategyExchange) *StrategyManager {
+func NewStrategyManager(exchangeName string, db *database.DB, markets StrategyMarkets, marketCollector *market.MarketDataCollector, calculator *market.Calculator, algorithmRegistry *algorithms.AlgorithmRegistry, exchange StrategyExchange, limits risk.Limits, bus *events.Bus) *StrategyManager {
 	return &StrategyManager{
 		exchangeName:      exchangeName,
-		pair:              pair,
 		db:                db,
-		market:            market,
+		markets:           markets,
 		marketCollector:   marketCollector,
 		calculator:        calculator,
 		algorithmRegistry: algorithmRegistry,
 		exchange:          exchange,
+		limits:            limits,
+		bus:               bus,
 	}
 }
 
@@ -79,13 +94,17 @@ func (sm *StrategyManager) validateAndPrepareStrategy(strategy database.Strategy
 		return nil, algorithms.TradingContext{}, fmt.Errorf("invalid strategy configuration: %w", err)
 	}
 
+	if strategy.Pair == "" {
+		return nil, algorithms.TradingContext{}, fmt.Errorf("strategy %s has no trading pair", strategy.Name)
+	}
+
 	// Create trading context
 	tradingContext := algorithms.TradingContext{
 		ExchangeName: sm.exchangeName,
-		Pair:         sm.pair,
+		Pair:         strategy.Pair,
 		CurrentPrice: 0,
 		Calculator:   sm.calculator,
-		Precision:    sm.market.GetPrecision(),
+		Precision:    sm.markets.MarketFor(strategy.Pair).GetPrecision(),
 	}
 
 	return algorithm, tradingContext, nil
@@ -100,6 +119,11 @@ func (sm *StrategyManager) ExecuteBuyStrategy(strategy database.Strategy) error
 		return err
 	}
 
+	// Algorithme à ordres multiples (grille) : toute l'échelle est posée en un tick
+	if ladder, ok := algorithm.(algorithms.LadderBuyer); ok {
+		return sm.executeLadderBuy(ladder, tradingContext, strategy)
+	}
+
 	// Check if strategy has reached max concurrent cycles (0 = illimité : pas de plafond)
 	if strategy.MaxConcurrentCycles > 0 {
 		activeCycles, err := sm.countActiveCyclesForStrategy(strategy.ID)
@@ -115,7 +139,7 @@ func (sm *StrategyManager) ExecuteBuyStrategy(strategy database.Strategy) error
 	}
 
 	// Get current market data
-	currentPrice, err := sm.exchange.GetPrice(sm.pair)
+	currentPrice, err := sm.exchange.GetPrice(strategy.Pair)
 	if err != nil {
 		return fmt.Errorf("failed to get current price: %w", err)
 	}
@@ -134,8 +158,13 @@ func (sm *StrategyManager) ExecuteBuyStrategy(strategy database.Strategy) error
 	}
 
 	if buySignal.ShouldBuy {
+		buySignal, ok := sm.fitBuy(buySignal, tradingContext.Precision, strategy)
+		if !ok {
+			return nil
+		}
+
 		freeQuoteBalance := 0.0
-		if quoteBalance, exists := balance[sm.market.GetQuoteAsset()]; exists {
+		if quoteBalance, exists := balance[sm.markets.MarketFor(strategy.Pair).GetQuoteAsset()]; exists {
 			freeQuoteBalance = quoteBalance.Free
 		}
 		if freeQuoteBalance < strategy.QuoteAmount {
@@ -143,6 +172,17 @@ func (sm *StrategyManager) ExecuteBuyStrategy(strategy database.Strategy) error
 				sm.exchangeName, strategy.Name, freeQuoteBalance, strategy.QuoteAmount)
 		}
 
+		// Limites de risque : un achat qui en dépasserait une n'est pas posé
+		exposure, err := sm.exposure(strategy.ID, freeQuoteBalance)
+		if err != nil {
+			return err
+		}
+		cost := algorithms.Cost(buySignal.Amount, buySignal.LimitPrice)
+		if breach := sm.limits.Check(strategy.MaxCapital, exposure, cost); breach != nil {
+			sm.skipBuy(strategy, breach, cost)
+			return nil
+		}
+
 		// Execute buy order
 		err = sm.executeBuyOrder(buySignal, strategy)
 		if err != nil {
@@ -157,6 +197,94 @@ func (sm *StrategyManager) ExecuteBuyStrategy(strategy database.Strategy) error
 	return nil
 }
 
+// executeLadderBuy pose les achats d'un algorithme à ordres multiples (cf.
+// algorithms.LadderBuyer) : un ordre par signal, dans la limite des cycles
+// concurrents, du solde quote libre et des limites de risque. Les signaux étant
+// triés par priorité, on s'arrête au premier niveau que le solde ou une limite
+// ne couvre plus.
+func (sm *StrategyManager) executeLadderBuy(ladder algorithms.LadderBuyer, ctx algorithms.TradingContext, strategy database.Strategy) error {
+	activeCycles, err := sm.db.GetActiveCyclesForStrategy(strategy.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get active cycles: %w", err)
+	}
+
+	currentPrice, err := sm.exchange.GetPrice(strategy.Pair)
+	if err != nil {
+		return fmt.Errorf("failed to get current price: %w", err)
+	}
+	ctx.CurrentPrice = currentPrice
+
+	active := make([]database.Cycle, len(activeCycles))
+	for i, cycle := range activeCycles {
+		active[i] = cycle.Cycle
+	}
+
+	signals, err := ladder.LadderBuySignals(ctx, strategy, active)
+	if err != nil {
+		return fmt.Errorf("algorithm LadderBuySignals failed: %w", err)
+	}
+	if len(signals) == 0 {
+		logger.Debugf("[%s] Strategy %s: no buy order to place", sm.exchangeName, strategy.Name)
+		return nil
+	}
+
+	// Plafond de cycles concurrents (0 = illimité)
+	if strategy.MaxConcurrentCycles > 0 {
+		room := strategy.MaxConcurrentCycles - len(activeCycles)
+		if room <= 0 {
+			logger.Infof("[%s] Strategy %s has reached max concurrent cycles (%d/%d), skipping buy execution",
+				sm.exchangeName, strategy.Name, len(activeCycles), strategy.MaxConcurrentCycles)
+			return nil
+		}
+		if len(signals) > room {
+			signals = signals[:room]
+		}
+	}
+
+	balance, err := sm.exchange.FetchBalance()
+	if err != nil {
+		return fmt.Errorf("failed to fetch balance: %w", err)
+	}
+	freeQuoteBalance := 0.0
+	if quoteBalance, exists := balance[sm.markets.MarketFor(strategy.Pair).GetQuoteAsset()]; exists {
+		freeQuoteBalance = quoteBalance.Free
+	}
+
+	exposure, err := sm.exposure(strategy.ID, freeQuoteBalance)
+	if err != nil {
+		return err
+	}
+
+	placed := 0
+	for _, buySignal := range signals {
+		buySignal, ok := sm.fitBuy(buySignal, ctx.Precision, strategy)
+		if !ok {
+			continue
+		}
+		cost := algorithms.Cost(buySignal.Amount, buySignal.LimitPrice)
+		if freeQuoteBalance < cost {
+			logger.Warnf("[%s] Strategy %s: insufficient balance (%.2f < %.2f), %d buy order(s) not placed",
+				sm.exchangeName, strategy.Name, freeQuoteBalance, cost, len(signals)-placed)
+			break
+		}
+		if breach := sm.limits.Check(strategy.MaxCapital, exposure, cost); breach != nil {
+			sm.skipBuy(strategy, breach, cost)
+			break
+		}
+
+		if err := sm.executeBuyOrder(buySignal, strategy); err != nil {
+			logger.Errorf("Failed to execute buy order for strategy %s at %.4f: %v", strategy.Name, buySignal.LimitPrice, err)
+			continue
+		}
+		freeQuoteBalance -= cost
+		exposure.Commit(cost)
+		placed++
+	}
+
+	logger.Infof("[%s] Strategy %s: %d/%d buy order(s) placed", sm.exchangeName, strategy.Name, placed, len(signals))
+	return nil
+}
+
 // ForcedBuyResult décrit l'ordre d'achat manuel posé, pour le retour à l'appelant
 // (ex. message de confirmation Telegram).
 type ForcedBuyResult struct {
@@ -171,6 +299,8 @@ type ForcedBuyResult struct {
 // la condition d'entrée (RSI, filtre de tendance) ET le cooldown périodique — ce dernier
 // est géré côté bot et n'est donc jamais consulté ici. Le plafond de cycles concurrents
 // n'est volontairement PAS appliqué : un achat manuel est un ordre explicite de l'opérateur.
+// Les limites de risque (capital, dépense du jour, réserve), elles, restent des garde-fous
+// stricts : un achat manuel qui en dépasserait une est refusé avec la raison.
 // L'algorithme doit implémenter algorithms.ForceBuyer, sinon l'achat manuel est refusé.
 func (sm *StrategyManager) ExecuteForcedBuyStrategy(strategy database.Strategy) (ForcedBuyResult, error) {
 	logger.Infof("[%s] Executing FORCED BUY strategy '%s' (%s)", sm.exchangeName, strategy.Name, strategy.AlgorithmName)
@@ -185,7 +315,7 @@ func (sm *StrategyManager) ExecuteForcedBuyStrategy(strategy database.Strategy)
 		return ForcedBuyResult{}, fmt.Errorf("l'algorithme %s ne supporte pas l'achat manuel", strategy.AlgorithmName)
 	}
 
-	currentPrice, err := sm.exchange.GetPrice(sm.pair)
+	currentPrice, err := sm.exchange.GetPrice(strategy.Pair)
 	if err != nil {
 		return ForcedBuyResult{}, fmt.Errorf("failed to get current price: %w", err)
 	}
@@ -198,6 +328,26 @@ func (sm *StrategyManager) ExecuteForcedBuyStrategy(strategy database.Strategy)
 	if !buySignal.ShouldBuy {
 		return ForcedBuyResult{}, fmt.Errorf("achat manuel impossible : %s", buySignal.Reason)
 	}
+	buySignal, err = algorithms.FitBuySignal(buySignal, tradingContext.Precision)
+	if err != nil {
+		return ForcedBuyResult{}, fmt.Errorf("achat manuel refusé : %w", err)
+	}
+
+	balance, err := sm.exchange.FetchBalance()
+	if err != nil {
+		return ForcedBuyResult{}, fmt.Errorf("failed to fetch balance: %w", err)
+	}
+	freeQuoteBalance := 0.0
+	if quoteBalance, exists := balance[sm.markets.MarketFor(strategy.Pair).GetQuoteAsset()]; exists {
+		freeQuoteBalance = quoteBalance.Free
+	}
+	exposure, err := sm.exposure(strategy.ID, freeQuoteBalance)
+	if err != nil {
+		return ForcedBuyResult{}, err
+	}
+	if breach := sm.limits.Check(strategy.MaxCapital, exposure, algorithms.Cost(buySignal.Amount, buySignal.LimitPrice)); breach != nil {
+		return ForcedBuyResult{}, fmt.Errorf("achat manuel refusé : %s", breach.Reason)
+	}
 
 	if err := sm.executeBuyOrder(buySignal, strategy); err != nil {
 		return ForcedBuyResult{}, fmt.Errorf("failed to execute forced buy order: %w", err)
@@ -232,59 +382,195 @@ func (sm *StrategyManager) ExecuteSellStrategy(strategy database.Strategy, curre
 	return nil
 }
 
-// executeBuyOrder executes a buy order from algorithm signal
-func (sm *StrategyManager) executeBuyOrder(buySignal algorithms.BuySignal, strategy database.Strategy) error {
-	logger.Infof("[%s] Executing buy order for strategy %s: amount=%.4f, price=%.4f",
-		sm.exchangeName, strategy.Name, buySignal.Amount, buySignal.LimitPrice)
+// exposure calcule l'exposition courante pour les limites de risque : capital engagé
+// par la stratégie et par l'instance, achats du jour (depuis minuit UTC) et solde
+// quote libre.
+func (sm *StrategyManager) exposure(strategyID int, freeQuote float64) (risk.Exposure, error) {
+	capital, err := sm.db.GetOpenCapital()
+	if err != nil {
+		return risk.Exposure{}, err
+	}
+	e := risk.Exposure{StrategyCapital: capital[strategyID], FreeQuote: freeQuote}
+	for _, c := range capital {
+		e.TotalCapital += c
+	}
+
+	now := time.Now().UTC()
+	e.DailySpend, err = sm.db.GetBuySpendSince(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return risk.Exposure{}, err
+	}
+	return e, nil
+}
+
+// skipBuy trace un achat refusé par une limite de risque : log, épisode en base (affiché
+// dans la WebUI) et événement buy.skipped (notifié sur Telegram) au premier refus de
+// l'épisode seulement, pour ne pas répéter l'alerte à chaque déclenchement de la stratégie.
+func (sm *StrategyManager) skipBuy(strategy database.Strategy, breach *risk.Breach, cost float64) {
+	logger.Warnf("[%s] Strategy %s: buy of %.2f skipped, %s", sm.exchangeName, strategy.Name, cost, breach.Reason)
 
-	// Place order on exchange
-	order, err := sm.exchange.PlaceLimitBuyOrder(sm.pair, buySignal.Amount, buySignal.LimitPrice)
+	repeated, err := sm.db.RecordBuySkip(strategy.ID, string(breach.Limit), breach.Reason, cost)
 	if err != nil {
-		return fmt.Errorf("failed to place buy order on exchange: %w", err)
+		logger.Errorf("Failed to record buy skip for strategy %s: %v", strategy.Name, err)
 	}
+	if repeated {
+		return
+	}
+	sm.bus.Publish(events.Event{
+		Type:       events.BuySkipped,
+		Pair:       strategy.Pair,
+		StrategyID: strategy.ID,
+		Message:    fmt.Sprintf("⛔ [%s] Achat refusé (%s, %s)\n%s", sm.exchangeName, strategy.Name, strategy.Pair, breach.Reason),
+		Data:       map[string]any{"limit": string(breach.Limit), "reason": breach.Reason, "cost": cost},
+	})
+}
 
-	// Save order to database with strategy ID
-	dbOrder, err := sm.db.CreateOrder(*order.Id, database.Buy, *order.Amount, *order.Price, 0.0, strategy.ID)
+// fitBuy ajuste un signal d'achat aux limites de marché de l'exchange (cf.
+// algorithms.FitBuySignal). Un achat que l'exchange refuserait n'est pas posé : il est
+// tracé comme un refus de limite (buy.skipped) et ok vaut false.
+func (sm *StrategyManager) fitBuy(buySignal algorithms.BuySignal, precision algorithms.MarketPrecision, strategy database.Strategy) (algorithms.BuySignal, bool) {
+	fitted, err := algorithms.FitBuySignal(buySignal, precision)
 	if err != nil {
-		return fmt.Errorf("failed to save buy order to database: %w", err)
+		sm.skipBuy(strategy, &risk.Breach{Limit: risk.LimitMarket, Reason: err.Error()}, algorithms.Cost(buySignal.Amount, buySignal.LimitPrice))
+		return buySignal, false
 	}
+	if fitted.Amount != buySignal.Amount {
+		logger.Infof("[%s] Strategy %s: buy amount adjusted to market limits (%g -> %g)",
+			sm.exchangeName, strategy.Name, buySignal.Amount, fitted.Amount)
+	}
+	return fitted, true
+}
+
+// executeBuyOrder executes a buy order from algorithm signal
+func (sm *StrategyManager) executeBuyOrder(buySignal algorithms.BuySignal, strategy database.Strategy) error {
+	logger.Infof("[%s] Executing buy order for strategy %s: amount=%.4f, price=%.4f",
+		sm.exchangeName, strategy.Name, buySignal.Amount, buySignal.LimitPrice)
 
-	// Create cycle with strategy ID
-	cycle, err := sm.db.CreateCycle(dbOrder.ID, buySignal.TargetPrice)
+	// Ordre et cycle enregistrés ensemble (frais enregistrés au remplissage, cf. Bot.recordOrderFees)
+	dbOrder, cycle, err := sm.placeOrder(database.OrderIntent{
+		StrategyID:  strategy.ID,
+		Pair:        strategy.Pair,
+		Side:        database.Buy,
+		Amount:      buySignal.Amount,
+		Price:       buySignal.LimitPrice,
+		TargetPrice: buySignal.TargetPrice,
+	})
 	if err != nil {
-		logger.Errorf("Failed to create cycle: %v", err)
+		return err
 	}
 
+	sm.publishOrderPlaced(dbOrder, strategy, 0)
+
 	logger.Infof("[%s] Buy order created: Order ID=%d, Cycle ID=%d, Strategy=%s",
 		sm.exchangeName, dbOrder.ID, cycle.ID, strategy.Name)
+	sm.bus.Publish(events.Event{
+		Type:       events.CycleOpened,
+		Pair:       strategy.Pair,
+		StrategyID: strategy.ID,
+		CycleID:    cycle.ID,
+		OrderID:    dbOrder.ExternalID,
+		Data:       map[string]any{"target_price": buySignal.TargetPrice},
+	})
 
 	return nil
 }
 
-func (sm *StrategyManager) executeSellOrder(sellSignal algorithms.SellSignal, cycle database.CycleEnhanced, strategy database.Strategy) error {
-	logger.Infof("[%s] Executing sell order for strategy %s: Cycle=%d, Amount=%.4f, Price=%.4f",
-		sm.exchangeName, strategy.Name, cycle.ID, cycle.BuyOrder.Amount, sellSignal.LimitPrice)
+// placeOrder enregistre l'intention d'un ordre, le pose sur l'exchange avec son
+// identifiant client puis l'enregistre en base (ordre et cycle) en une transaction.
+// Si le sort de l'ordre est incertain (erreur réseau, écriture en base en échec),
+// l'intention reste en attente : le bot la résout en interrogeant l'exchange, et la
+// stratégie ne pose plus d'ordre d'ici là, pour ne jamais doubler un achat.
+func (sm *StrategyManager) placeOrder(intent database.OrderIntent) (*database.Order, *database.CycleEnhanced, error) {
+	side := strings.ToLower(string(intent.Side))
+
+	pending, err := sm.db.CountPendingOrderIntents(intent.StrategyID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending > 0 {
+		return nil, nil, fmt.Errorf("%s order deferred: %d unconfirmed order(s) awaiting resolution", side, pending)
+	}
+
+	created, err := sm.db.CreateOrderIntent(intent)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to record %s order intent: %w", side, err)
+	}
+
+	place := sm.exchange.PlaceLimitBuyOrder
+	if intent.Side == database.Sell {
+		place = sm.exchange.PlaceLimitSellOrder
+	}
+	order, err := place(intent.Pair, intent.Amount, intent.Price, created.ClientOrderID)
+	if err != nil {
+		if !placementUncertain(err) {
+			if failErr := sm.db.FailOrderIntent(created.ClientOrderID, err.Error()); failErr != nil {
+				logger.Errorf("Failed to mark order intent %s as failed: %v", created.ClientOrderID, failErr)
+			}
+		}
+		return nil, nil, fmt.Errorf("failed to place %s order on exchange: %w", side, err)
+	}
 
-	// Place order on exchange
-	order, err := sm.exchange.PlaceLimitSellOrder(sm.pair, cycle.BuyOrder.Amount, sellSignal.LimitPrice)
+	dbOrder, cycle, err := sm.db.RecordOrderIntent(created.ClientOrderID, *order.Id, *order.Amount, *order.Price)
 	if err != nil {
-		return fmt.Errorf("failed to place sell order on exchange: %w", err)
+		return nil, nil, fmt.Errorf("failed to save %s order %s to database (intent %s left for resolution): %w",
+			side, *order.Id, created.ClientOrderID, err)
+	}
+	return dbOrder, cycle, nil
+}
+
+// placementUncertain indique si une erreur de pose laisse le sort de l'ordre inconnu
+// (requête peut-être reçue par l'exchange) : délai dépassé ou erreur réseau.
+func placementUncertain(err error) bool {
+	msg := err.Error()
+	for _, kind := range []string{"NetworkError", "RequestTimeout", "ExchangeNotAvailable", "timeout"} {
+		if strings.Contains(msg, kind) {
+			return true
+		}
 	}
+	return false
+}
+
+// publishOrderPlaced publie l'événement order.placed d'un ordre enregistré en base.
+func (sm *StrategyManager) publishOrderPlaced(order *database.Order, strategy database.Strategy, cycleID int) {
+	sm.bus.Publish(events.Event{
+		Type:       events.OrderPlaced,
+		Pair:       strategy.Pair,
+		StrategyID: strategy.ID,
+		CycleID:    cycleID,
+		OrderID:    order.ExternalID,
+		Data:       map[string]any{"side": string(order.Side), "amount": order.Amount, "price": order.Price},
+	})
+}
 
-	// Save sell order to database with strategy ID
-	dbSellOrder, err := sm.db.CreateOrder(*order.Id, database.Sell, *order.Amount, *order.Price, 0.0, strategy.ID)
+func (sm *StrategyManager) executeSellOrder(sellSignal algorithms.SellSignal, cycle database.CycleEnhanced, strategy database.Strategy) error {
+	amount, err := sm.heldAmount(cycle, strategy.Pair)
 	if err != nil {
-		return fmt.Errorf("failed to save sell order to database: %w", err)
+		return err
+	}
+	if err := algorithms.CheckSell(amount, sellSignal.LimitPrice, sm.markets.MarketFor(strategy.Pair).GetPrecision()); err != nil {
+		return fmt.Errorf("sell order outside market limits: %w", err)
 	}
 
-	// Associate sell order with cycle
-	err = sm.db.UpdateCycleSellOrder(cycle.ID, dbSellOrder.ID)
+	logger.Infof("[%s] Executing sell order for strategy %s: Cycle=%d, Amount=%.4f, Price=%.4f",
+		sm.exchangeName, strategy.Name, cycle.ID, amount, sellSignal.LimitPrice)
+
+	// Vente enregistrée et rattachée au cycle ensemble (frais enregistrés au remplissage)
+	dbSellOrder, _, err := sm.placeOrder(database.OrderIntent{
+		StrategyID: strategy.ID,
+		CycleID:    cycle.ID,
+		Pair:       strategy.Pair,
+		Side:       database.Sell,
+		Amount:     amount,
+		Price:      sellSignal.LimitPrice,
+	})
 	if err != nil {
-		logger.Errorf("Failed to associate sell order with cycle: %v", err)
-		return fmt.Errorf("failed to associate sell order with cycle: %w", err)
+		return err
 	}
 
-	expectedProfit := (sellSignal.LimitPrice-cycle.BuyOrder.Price)*cycle.BuyOrder.Amount - cycle.BuyOrder.Fees
+	sm.publishOrderPlaced(dbSellOrder, strategy, cycle.ID)
+
+	expectedProfit := decimal.FromFloat(sellSignal.LimitPrice).Sub(decimal.FromFloat(cycle.BuyOrder.ExecutedPrice())).
+		Mul(decimal.FromFloat(amount)).Sub(decimal.FromFloat(cycle.BuyOrder.Fees)).Float64()
 
 	logger.Infof("[%s] Sell order created: Order ID=%d, Cycle ID=%d, Strategy=%s, Expected profit=%.2f",
 		sm.exchangeName,
@@ -294,6 +580,32 @@ func (sm *StrategyManager) executeSellOrder(sellSignal algorithms.SellSignal, cy
 	return nil
 }
 
+// heldAmount retourne la quantité réellement détenue pour un cycle (cf. Cycle.HeldAmount),
+// plafonnée au solde libre de l'actif de base (certains exchanges prélèvent les frais
+// d'achat sur l'actif reçu).
+func (sm *StrategyManager) heldAmount(cycle database.CycleEnhanced, pair string) (float64, error) {
+	amount := cycle.HeldAmount()
+	if amount <= 0 {
+		return 0, fmt.Errorf("cycle %d: no held amount left to sell", cycle.ID)
+	}
+
+	balances, err := sm.exchange.FetchBalance()
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch balance: %w", err)
+	}
+	baseMarket := sm.markets.MarketFor(pair)
+	if free := balances[baseMarket.GetBaseAsset()].Free; free < amount {
+		logger.Warnf("[%s] Cycle %d : solde %s libre (%s) inférieur à la quantité du cycle (%s), vente plafonnée",
+			sm.exchangeName, cycle.ID, baseMarket.GetBaseAsset(), baseMarket.FormatAmount(free), baseMarket.FormatAmount(amount))
+		amount = free
+	}
+	if amount <= 0 {
+		return 0, fmt.Errorf("cycle %d: no free %s balance to sell", cycle.ID, baseMarket.GetBaseAsset())
+	}
+
+	return amount, nil
+}
+
 // checkSellSignals checks if any positions should be sold
 func (sm *StrategyManager) checkSellSignals(algorithm algorithms.Algorithm, ctx algorithms.TradingContext, strategy database.Strategy) error {
 	openCycles, err := sm.getOpenCyclesForStrategy(strategy.ID)
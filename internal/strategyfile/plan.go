@@ -0,0 +1,159 @@
+package strategyfile
+
+import (
+	"bot/internal/algorithms"
+	"bot/internal/core/database"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Action est l'opération prévue pour une stratégie du fichier.
+type Action string
+
+const (
+	ActionCreate    Action = "create"
+	ActionUpdate    Action = "update"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Change est un paramètre dont la valeur diffère entre la base et le fichier.
+type Change struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// PlanItem décrit ce que l'import fera d'une stratégie du fichier.
+type PlanItem struct {
+	Name       string   `json:"name"`
+	Action     Action   `json:"action"`
+	StrategyID int      `json:"strategy_id,omitempty"` // stratégie existante (update, unchanged)
+	Changes    []Change `json:"changes,omitempty"`
+	Spec       Spec     `json:"-"`
+}
+
+// String rend l'élément en une ligne, suivie d'une ligne par paramètre modifié.
+func (p PlanItem) String() string {
+	var b strings.Builder
+	switch p.Action {
+	case ActionCreate:
+		fmt.Fprintf(&b, "+ %s (nouvelle stratégie %s sur %s)", p.Name, p.Spec.AlgorithmName, p.Spec.Pair)
+	case ActionUpdate:
+		fmt.Fprintf(&b, "~ %s (#%d, %d paramètre(s) modifié(s))", p.Name, p.StrategyID, len(p.Changes))
+		for _, c := range p.Changes {
+			fmt.Fprintf(&b, "\n    %s: %s -> %s", c.Field, c.Old, c.New)
+		}
+	default:
+		fmt.Fprintf(&b, "= %s (#%d, inchangée)", p.Name, p.StrategyID)
+	}
+	return b.String()
+}
+
+// Plan valide toutes les stratégies du fichier avec les règles du runtime puis les
+// compare, par nom, à celles de la base. Aucune écriture : c'est le rapport du
+// --dry-run, et l'entrée d'Apply. Une seule stratégie invalide fait échouer le plan
+// entier, pour ne jamais importer un fichier à moitié.
+func Plan(db *database.DB, registry *algorithms.AlgorithmRegistry, file *File) ([]PlanItem, error) {
+	plan := make([]PlanItem, 0, len(file.Strategies))
+	for _, sp := range file.Strategies {
+		if err := registry.ValidateStrategy(sp.Strategy()); err != nil {
+			return nil, fmt.Errorf("stratégie %q invalide : %w", sp.Name, err)
+		}
+		if err := algorithms.CheckStrategyMarket(db, sp.Strategy()); err != nil {
+			return nil, fmt.Errorf("stratégie %q invalide : %w", sp.Name, err)
+		}
+
+		existing, err := db.GetStrategyByName(sp.Name)
+		if err != nil {
+			return nil, err
+		}
+		item := PlanItem{Name: sp.Name, Spec: sp}
+		if existing == nil {
+			item.Action = ActionCreate
+		} else {
+			item.StrategyID = existing.ID
+			item.Changes = Diff(FromStrategy(*existing), sp)
+			item.Action = ActionUnchanged
+			if len(item.Changes) > 0 {
+				item.Action = ActionUpdate
+			}
+		}
+		plan = append(plan, item)
+	}
+	return plan, nil
+}
+
+// Apply exécute un plan : crée les nouvelles stratégies et met à jour celles qui ont
+// changé. Les stratégies de la base absentes du fichier ne sont pas touchées. src est
+// enregistré dans l'historique de chaque stratégie modifiée.
+func Apply(db *database.DB, plan []PlanItem, src database.ChangeSource) error {
+	for _, item := range plan {
+		s := item.Spec
+		var err error
+		switch item.Action {
+		case ActionCreate:
+			err = db.CreateStrategyFromWeb(src, s.Name, s.Description, s.Pair, s.AlgorithmName, s.CronExpression, s.BuyIntervalSeconds, s.Enabled,
+				s.QuoteAmount, s.ProfitTarget, s.TrailingStopDelta, s.SellOffset,
+				s.RSIThreshold, s.RSIPeriod, s.RSITimeframe,
+				s.MACDFastPeriod, s.MACDSlowPeriod, s.MACDSignalPeriod, s.MACDTimeframe,
+				s.BBPeriod, s.BBMultiplier, s.BBTimeframe,
+				s.VolatilityPeriod, s.VolatilityAdjustment, s.VolatilityTimeframe,
+				s.TrendFilterEnabled, s.TrendFilterFastPeriod, s.TrendFilterSlowPeriod, s.TrendFilterTimeframe,
+				s.DynamicSizingEnabled, s.DynamicSizingMin, s.DynamicSizingMax, s.DynamicSizingWindowDays, s.DynamicSizingFullDrawdown,
+				s.StopLossPercent, s.MaxCycleAgeDays, s.MaxCycleAgeExit, s.BreakEvenAfterDays,
+				s.GridLowerPrice, s.GridUpperPrice, s.GridLevels,
+				s.MaxConcurrentCycles, s.MaxBuyOrderAgeHours, s.MaxCapital)
+		case ActionUpdate:
+			err = db.UpdateStrategy(src, item.StrategyID, s.Name, s.Description, s.Pair, s.AlgorithmName, s.CronExpression, s.BuyIntervalSeconds, s.Enabled,
+				s.QuoteAmount, s.ProfitTarget, s.TrailingStopDelta, s.SellOffset,
+				s.RSIThreshold, s.RSIPeriod, s.RSITimeframe,
+				s.MACDFastPeriod, s.MACDSlowPeriod, s.MACDSignalPeriod, s.MACDTimeframe,
+				s.BBPeriod, s.BBMultiplier, s.BBTimeframe,
+				s.VolatilityPeriod, s.VolatilityAdjustment, s.VolatilityTimeframe,
+				s.TrendFilterEnabled, s.TrendFilterFastPeriod, s.TrendFilterSlowPeriod, s.TrendFilterTimeframe,
+				s.DynamicSizingEnabled, s.DynamicSizingMin, s.DynamicSizingMax, s.DynamicSizingWindowDays, s.DynamicSizingFullDrawdown,
+				s.StopLossPercent, s.MaxCycleAgeDays, s.MaxCycleAgeExit, s.BreakEvenAfterDays,
+				s.GridLowerPrice, s.GridUpperPrice, s.GridLevels,
+				s.MaxConcurrentCycles, s.MaxBuyOrderAgeHours, s.MaxCapital)
+		}
+		if err != nil {
+			return fmt.Errorf("stratégie %q : %w", item.Name, err)
+		}
+	}
+	return nil
+}
+
+// Diff liste les paramètres qui diffèrent entre deux configurations, dans l'ordre du
+// fichier, sous leur nom de clé.
+func Diff(old, new Spec) []Change {
+	var changes []Change
+	ov, nv := reflect.ValueOf(old), reflect.ValueOf(new)
+	t := ov.Type()
+	for i := 0; i < t.NumField(); i++ {
+		o, n := formatField(ov.Field(i)), formatField(nv.Field(i))
+		if o != n {
+			key, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+			changes = append(changes, Change{Field: key, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+// formatField rend une valeur de Spec pour le diff ; un pointeur nil s'affiche « - ».
+func formatField(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "-"
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(v.Interface())
+}
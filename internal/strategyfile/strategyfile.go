@@ -0,0 +1,234 @@
+// Package strategyfile sérialise les stratégies (tous les paramètres d'algorithme) dans
+// un fichier versionné YAML ou JSON, et les réimporte par nom. Permet de garder les
+// stratégies sous git et de les promouvoir à l'identique d'une instance à l'autre
+// (paper → MEXC, Hyperliquid), la base de chaque instance restant la source du runtime.
+package strategyfile
+
+import (
+	"bot/internal/core/database"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion est la version courante du format de fichier. À incrémenter (avec une
+// conversion dans Decode) si un champ change de sens ; un ajout de champ optionnel n'en
+// a pas besoin.
+const FormatVersion = 1
+
+// Formats de fichier supportés.
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// File est le contenu d'un fichier de stratégies.
+type File struct {
+	Version    int    `yaml:"version" json:"version"`
+	Strategies []Spec `yaml:"strategies" json:"strategies"`
+}
+
+// Spec est la configuration d'une stratégie, sans son état d'exécution (ID, dates).
+// Les clés reprennent les colonnes de la table strategies ; les champs pointeurs
+// absents valent NULL (paramètre désactivé).
+type Spec struct {
+	Name                string  `yaml:"name" json:"name"`
+	Description         string  `yaml:"description" json:"description"`
+	Enabled             bool    `yaml:"enabled" json:"enabled"`
+	AlgorithmName       string  `yaml:"algorithm_name" json:"algorithm_name"`
+	Pair                string  `yaml:"pair" json:"pair"`
+	CronExpression      string  `yaml:"cron_expression" json:"cron_expression"`
+	BuyIntervalSeconds  int     `yaml:"buy_interval_seconds" json:"buy_interval_seconds"`
+	QuoteAmount         float64 `yaml:"quote_amount" json:"quote_amount"`
+	MaxConcurrentCycles int     `yaml:"max_concurrent_cycles" json:"max_concurrent_cycles"`
+	MaxBuyOrderAgeHours int     `yaml:"max_buy_order_age_hours" json:"max_buy_order_age_hours"`
+	MaxCapital          float64 `yaml:"max_capital" json:"max_capital"`
+
+	ProfitTarget      float64 `yaml:"profit_target" json:"profit_target"`
+	TrailingStopDelta float64 `yaml:"trailing_stop_delta" json:"trailing_stop_delta"`
+	SellOffset        float64 `yaml:"sell_offset" json:"sell_offset"`
+
+	RSIThreshold     *float64 `yaml:"rsi_threshold,omitempty" json:"rsi_threshold,omitempty"`
+	RSIPeriod        *int     `yaml:"rsi_period,omitempty" json:"rsi_period,omitempty"`
+	RSITimeframe     string   `yaml:"rsi_timeframe" json:"rsi_timeframe"`
+	MACDFastPeriod   int      `yaml:"macd_fast_period" json:"macd_fast_period"`
+	MACDSlowPeriod   int      `yaml:"macd_slow_period" json:"macd_slow_period"`
+	MACDSignalPeriod int      `yaml:"macd_signal_period" json:"macd_signal_period"`
+	MACDTimeframe    string   `yaml:"macd_timeframe" json:"macd_timeframe"`
+	BBPeriod         int      `yaml:"bb_period" json:"bb_period"`
+	BBMultiplier     float64  `yaml:"bb_multiplier" json:"bb_multiplier"`
+	BBTimeframe      string   `yaml:"bb_timeframe" json:"bb_timeframe"`
+
+	VolatilityPeriod     *int     `yaml:"volatility_period,omitempty" json:"volatility_period,omitempty"`
+	VolatilityAdjustment *float64 `yaml:"volatility_adjustment,omitempty" json:"volatility_adjustment,omitempty"`
+	VolatilityTimeframe  string   `yaml:"volatility_timeframe" json:"volatility_timeframe"`
+
+	TrendFilterEnabled    bool   `yaml:"trend_filter_enabled" json:"trend_filter_enabled"`
+	TrendFilterFastPeriod *int   `yaml:"trend_filter_fast_period,omitempty" json:"trend_filter_fast_period,omitempty"`
+	TrendFilterSlowPeriod *int   `yaml:"trend_filter_slow_period,omitempty" json:"trend_filter_slow_period,omitempty"`
+	TrendFilterTimeframe  string `yaml:"trend_filter_timeframe" json:"trend_filter_timeframe"`
+
+	DynamicSizingEnabled      bool     `yaml:"dynamic_sizing_enabled" json:"dynamic_sizing_enabled"`
+	DynamicSizingMin          *float64 `yaml:"dynamic_sizing_min,omitempty" json:"dynamic_sizing_min,omitempty"`
+	DynamicSizingMax          *float64 `yaml:"dynamic_sizing_max,omitempty" json:"dynamic_sizing_max,omitempty"`
+	DynamicSizingWindowDays   *int     `yaml:"dynamic_sizing_window_days,omitempty" json:"dynamic_sizing_window_days,omitempty"`
+	DynamicSizingFullDrawdown *float64 `yaml:"dynamic_sizing_full_drawdown,omitempty" json:"dynamic_sizing_full_drawdown,omitempty"`
+
+	StopLossPercent    float64 `yaml:"stop_loss_percent" json:"stop_loss_percent"`
+	MaxCycleAgeDays    int     `yaml:"max_cycle_age_days" json:"max_cycle_age_days"`
+	MaxCycleAgeExit    string  `yaml:"max_cycle_age_exit" json:"max_cycle_age_exit"`
+	BreakEvenAfterDays int     `yaml:"break_even_after_days" json:"break_even_after_days"`
+
+	GridLowerPrice float64 `yaml:"grid_lower_price" json:"grid_lower_price"`
+	GridUpperPrice float64 `yaml:"grid_upper_price" json:"grid_upper_price"`
+	GridLevels     int     `yaml:"grid_levels" json:"grid_levels"`
+}
+
+// FromStrategy extrait la configuration d'une stratégie de la base.
+func FromStrategy(s database.Strategy) Spec {
+	return Spec{
+		Name: s.Name, Description: s.Description, Enabled: s.Enabled,
+		AlgorithmName: s.AlgorithmName, Pair: s.Pair, CronExpression: s.CronExpression,
+		BuyIntervalSeconds: s.BuyIntervalSeconds, QuoteAmount: s.QuoteAmount,
+		MaxConcurrentCycles: s.MaxConcurrentCycles, MaxBuyOrderAgeHours: s.MaxBuyOrderAgeHours, MaxCapital: s.MaxCapital,
+		ProfitTarget: s.ProfitTarget, TrailingStopDelta: s.TrailingStopDelta, SellOffset: s.SellOffset,
+		RSIThreshold: s.RSIThreshold, RSIPeriod: s.RSIPeriod, RSITimeframe: s.RSITimeframe,
+		MACDFastPeriod: s.MACDFastPeriod, MACDSlowPeriod: s.MACDSlowPeriod, MACDSignalPeriod: s.MACDSignalPeriod, MACDTimeframe: s.MACDTimeframe,
+		BBPeriod: s.BBPeriod, BBMultiplier: s.BBMultiplier, BBTimeframe: s.BBTimeframe,
+		VolatilityPeriod: s.VolatilityPeriod, VolatilityAdjustment: s.VolatilityAdjustment, VolatilityTimeframe: s.VolatilityTimeframe,
+		TrendFilterEnabled: s.TrendFilterEnabled, TrendFilterFastPeriod: s.TrendFilterFastPeriod, TrendFilterSlowPeriod: s.TrendFilterSlowPeriod, TrendFilterTimeframe: s.TrendFilterTimeframe,
+		DynamicSizingEnabled: s.DynamicSizingEnabled, DynamicSizingMin: s.DynamicSizingMin, DynamicSizingMax: s.DynamicSizingMax,
+		DynamicSizingWindowDays: s.DynamicSizingWindowDays, DynamicSizingFullDrawdown: s.DynamicSizingFullDrawdown,
+		StopLossPercent: s.StopLossPercent, MaxCycleAgeDays: s.MaxCycleAgeDays, MaxCycleAgeExit: s.MaxCycleAgeExit, BreakEvenAfterDays: s.BreakEvenAfterDays,
+		GridLowerPrice: s.GridLowerPrice, GridUpperPrice: s.GridUpperPrice, GridLevels: s.GridLevels,
+	}
+}
+
+// Strategy convertit la configuration en stratégie (sans ID), prête à être validée.
+func (sp Spec) Strategy() database.Strategy {
+	return database.Strategy{
+		Name: sp.Name, Description: sp.Description, Enabled: sp.Enabled,
+		AlgorithmName: sp.AlgorithmName, Pair: sp.Pair, CronExpression: sp.CronExpression,
+		BuyIntervalSeconds: sp.BuyIntervalSeconds, QuoteAmount: sp.QuoteAmount,
+		MaxConcurrentCycles: sp.MaxConcurrentCycles, MaxBuyOrderAgeHours: sp.MaxBuyOrderAgeHours, MaxCapital: sp.MaxCapital,
+		ProfitTarget: sp.ProfitTarget, TrailingStopDelta: sp.TrailingStopDelta, SellOffset: sp.SellOffset,
+		RSIThreshold: sp.RSIThreshold, RSIPeriod: sp.RSIPeriod, RSITimeframe: sp.RSITimeframe,
+		MACDFastPeriod: sp.MACDFastPeriod, MACDSlowPeriod: sp.MACDSlowPeriod, MACDSignalPeriod: sp.MACDSignalPeriod, MACDTimeframe: sp.MACDTimeframe,
+		BBPeriod: sp.BBPeriod, BBMultiplier: sp.BBMultiplier, BBTimeframe: sp.BBTimeframe,
+		VolatilityPeriod: sp.VolatilityPeriod, VolatilityAdjustment: sp.VolatilityAdjustment, VolatilityTimeframe: sp.VolatilityTimeframe,
+		TrendFilterEnabled: sp.TrendFilterEnabled, TrendFilterFastPeriod: sp.TrendFilterFastPeriod, TrendFilterSlowPeriod: sp.TrendFilterSlowPeriod, TrendFilterTimeframe: sp.TrendFilterTimeframe,
+		DynamicSizingEnabled: sp.DynamicSizingEnabled, DynamicSizingMin: sp.DynamicSizingMin, DynamicSizingMax: sp.DynamicSizingMax,
+		DynamicSizingWindowDays: sp.DynamicSizingWindowDays, DynamicSizingFullDrawdown: sp.DynamicSizingFullDrawdown,
+		StopLossPercent: sp.StopLossPercent, MaxCycleAgeDays: sp.MaxCycleAgeDays, MaxCycleAgeExit: sp.MaxCycleAgeExit, BreakEvenAfterDays: sp.BreakEvenAfterDays,
+		GridLowerPrice: sp.GridLowerPrice, GridUpperPrice: sp.GridUpperPrice, GridLevels: sp.GridLevels,
+	}
+}
+
+// applyDefaults complète les timeframes et le mode de sortie laissés vides avec les
+// mêmes valeurs par défaut que CreateStrategyFromWeb / UpdateStrategy, pour qu'un
+// fichier qui les omet ne produise pas de faux écart au diff.
+func (sp *Spec) applyDefaults() {
+	sp.Pair = strings.ToUpper(strings.TrimSpace(sp.Pair))
+	for _, d := range []struct {
+		field *string
+		def   string
+	}{
+		{&sp.RSITimeframe, "4h"},
+		{&sp.MACDTimeframe, "4h"},
+		{&sp.BBTimeframe, "1h"},
+		{&sp.VolatilityTimeframe, "4h"},
+		{&sp.TrendFilterTimeframe, "1d"},
+		{&sp.MaxCycleAgeExit, database.CycleAgeExitMarket},
+	} {
+		if *d.field == "" {
+			*d.field = d.def
+		}
+	}
+}
+
+// FormatFromPath déduit le format de l'extension du fichier (.yaml, .yml, .json).
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".json":
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("extension de fichier non reconnue : %q (attendu .yaml, .yml ou .json)", path)
+}
+
+// Encode écrit les stratégies au format demandé, estampillées FormatVersion.
+func Encode(w io.Writer, strategies []database.Strategy, format string) error {
+	file := File{Version: FormatVersion, Strategies: make([]Spec, 0, len(strategies))}
+	for _, s := range strategies {
+		file.Strategies = append(file.Strategies, FromStrategy(s))
+	}
+
+	switch format {
+	case FormatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(file); err != nil {
+			return fmt.Errorf("failed to encode strategies: %w", err)
+		}
+		return enc.Close()
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file); err != nil {
+			return fmt.Errorf("failed to encode strategies: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("format inconnu : %q (yaml ou json)", format)
+}
+
+// Decode lit un fichier de stratégies. Les clés inconnues sont refusées (une faute de
+// frappe ne doit pas laisser un paramètre silencieusement à sa valeur par défaut), de
+// même qu'une version absente ou plus récente que FormatVersion.
+func Decode(data []byte, format string) (*File, error) {
+	var file File
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(&file); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("fichier YAML invalide : %w", err)
+		}
+	case FormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("fichier JSON invalide : %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("format inconnu : %q (yaml ou json)", format)
+	}
+
+	if file.Version == 0 {
+		return nil, fmt.Errorf("version du format absente (attendu version: %d)", FormatVersion)
+	}
+	if file.Version > FormatVersion {
+		return nil, fmt.Errorf("version du format %d non supportée (max %d) : mettez simple-bot à jour", file.Version, FormatVersion)
+	}
+
+	seen := make(map[string]bool, len(file.Strategies))
+	for i := range file.Strategies {
+		sp := &file.Strategies[i]
+		sp.Name = strings.TrimSpace(sp.Name)
+		if sp.Name == "" {
+			return nil, fmt.Errorf("stratégie n°%d : nom requis", i+1)
+		}
+		if seen[sp.Name] {
+			return nil, fmt.Errorf("stratégie %q présente deux fois dans le fichier", sp.Name)
+		}
+		seen[sp.Name] = true
+		sp.applyDefaults()
+	}
+	return &file, nil
+}
@@ -0,0 +1,128 @@
+package strategyfile
+
+import (
+	"bot/internal/algorithms"
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	if err := logger.InitLogger("error", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func newTestDB(t *testing.T) *database.DB {
+	t.Helper()
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+const rsiFile = `version: 1
+strategies:
+  - name: dca-btc
+    description: DCA RSI quotidien
+    enabled: true
+    algorithm_name: rsi_dca
+    pair: btc/usdc
+    cron_expression: "0 9 * * *"
+    quote_amount: 25
+    profit_target: 2.5
+    trailing_stop_delta: 0.5
+    rsi_threshold: 70
+    rsi_period: 14
+`
+
+func plan(t *testing.T, db *database.DB, content, format string) []PlanItem {
+	t.Helper()
+	file, err := Decode([]byte(content), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	items, err := Plan(db, algorithms.NewAlgorithmRegistry(), file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+// Import, export puis réimport : le fichier exporté décrit exactement la base, et
+// une modification n'apparaît qu'au diff du paramètre concerné.
+func TestImportExportRoundTrip(t *testing.T) {
+	db := newTestDB(t)
+
+	items := plan(t, db, rsiFile, FormatYAML)
+	if len(items) != 1 || items[0].Action != ActionCreate {
+		t.Fatalf("plan initial : %+v", items)
+	}
+	if err := Apply(db, items, database.ChangeSource{Origin: database.OriginImport}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := db.GetStrategyByName("dca-btc")
+	if err != nil || s == nil {
+		t.Fatalf("stratégie non créée : %v", err)
+	}
+	if s.Pair != "BTC/USDC" || s.RSITimeframe != "4h" || *s.RSIThreshold != 70 {
+		t.Errorf("stratégie importée : %+v", s)
+	}
+
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		var buf bytes.Buffer
+		if err := Encode(&buf, []database.Strategy{*s}, format); err != nil {
+			t.Fatal(err)
+		}
+		if items := plan(t, db, buf.String(), format); items[0].Action != ActionUnchanged {
+			t.Errorf("%s : réimport de l'export non neutre : %s", format, items[0])
+		}
+	}
+
+	edited := strings.Replace(rsiFile, "profit_target: 2.5", "profit_target: 3", 1)
+	items = plan(t, db, edited, FormatYAML)
+	if items[0].Action != ActionUpdate || len(items[0].Changes) != 1 ||
+		items[0].Changes[0] != (Change{Field: "profit_target", Old: "2.5", New: "3"}) {
+		t.Fatalf("diff : %+v", items[0].Changes)
+	}
+	if err := Apply(db, items, database.ChangeSource{Origin: database.OriginImport}); err != nil {
+		t.Fatal(err)
+	}
+	if s, _ := db.GetStrategyByName("dca-btc"); s.ID != items[0].StrategyID || s.ProfitTarget != 3 {
+		t.Errorf("mise à jour : %+v", s)
+	}
+}
+
+func TestDecode_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"version absente": strings.Replace(rsiFile, "version: 1\n", "", 1),
+		"version future":  strings.Replace(rsiFile, "version: 1", "version: 2", 1),
+		"clé inconnue":    strings.Replace(rsiFile, "rsi_period: 14", "rsi_periode: 14", 1),
+		"nom en double":   rsiFile + strings.SplitN(rsiFile, "strategies:\n", 2)[1],
+	}
+	for name, content := range cases {
+		if _, err := Decode([]byte(content), FormatYAML); err == nil {
+			t.Errorf("%s : fichier accepté", name)
+		}
+	}
+}
+
+// Une stratégie invalide fait échouer tout le plan, avant toute écriture.
+func TestPlan_ValidatesWithAlgorithm(t *testing.T) {
+	db := newTestDB(t)
+	content := strings.Replace(rsiFile, "    rsi_period: 14\n", "", 1)
+	file, err := Decode([]byte(content), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Plan(db, algorithms.NewAlgorithmRegistry(), file); err == nil || !strings.Contains(err.Error(), "rsi_period") {
+		t.Errorf("stratégie sans rsi_period : err = %v", err)
+	}
+}
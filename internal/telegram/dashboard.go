@@ -32,6 +32,18 @@ import (
 type StatusSnapshot struct {
 	Version      string // version du binaire (injectée par make release)
 	Exchange     string
+	Pairs        []PairStatus // une entrée par paire tradée
+	Paused       bool
+	Halted       string // raison du déclenchement du disjoncteur, ou "" si achats autorisés
+	UpdatedAt    time.Time
+	Uptime       string // durée depuis le démarrage, ou "" si inconnu
+	LastCheckAgo string // temps écoulé depuis le dernier price-check, ou "" si aucun
+	ErrorMsg     string // dernière erreur récente, ou "" si aucune
+	ErrorAgo     string // temps écoulé depuis cette erreur
+}
+
+// PairStatus est l'état d'une paire tradée dans /status.
+type PairStatus struct {
 	Pair         string
 	Price        string // déjà formaté selon la précision du marché
 	RSI          string // formaté, ou "n/a"
@@ -41,17 +53,12 @@ type StatusSnapshot struct {
 	TotalProfit  float64
 	AvgProfit    float64
 	Quote        string
-	Paused       bool
-	UpdatedAt    time.Time
-	Uptime       string // durée depuis le démarrage, ou "" si inconnu
-	LastCheckAgo string // temps écoulé depuis le dernier price-check, ou "" si aucun
-	ErrorMsg     string // dernière erreur récente, ou "" si aucune
-	ErrorAgo     string // temps écoulé depuis cette erreur
 }
 
 // CycleView est une ligne de la vue /cycles.
 type CycleView struct {
 	ID       int
+	Pair     string
 	Status   string
 	Amount   string
 	BuyPrice string
@@ -59,8 +66,9 @@ type CycleView struct {
 	Age      string
 }
 
-// PnLSnapshot est l'instantané de la vue /pnl.
+// PnLSnapshot est l'instantané de la vue /pnl, pour une paire.
 type PnLSnapshot struct {
+	Pair        string
 	Completed   int
 	TotalProfit float64
 	AvgProfit   float64
@@ -88,7 +96,7 @@ type BalanceSnapshot struct {
 type Dashboard interface {
 	Status() (StatusSnapshot, error)
 	Cycles() ([]CycleView, error)
-	PnL() (PnLSnapshot, error)
+	PnL() ([]PnLSnapshot, error)
 	Balance() (BalanceSnapshot, error)
 	Pause() error
 	Resume() error
@@ -405,23 +413,30 @@ func renderStatus(s StatusSnapshot) string {
 	switch {
 	case s.Paused:
 		state = "⏸ En pause"
+	case s.Halted != "":
+		state = "🚨 Achats suspendus (disjoncteur)"
 	case s.ErrorMsg != "":
 		state = "⚠️ Erreurs récentes"
 	}
 
 	var b strings.Builder
-	fmt.Fprintf(&b, "📊 simple-bot %s — %s %s\n", s.Version, s.Exchange, s.Pair)
+	fmt.Fprintf(&b, "📊 simple-bot %s — %s\n", s.Version, s.Exchange)
 	fmt.Fprintf(&b, "%s\n", state)
+	if s.Halted != "" {
+		fmt.Fprintf(&b, "↳ %s\n", s.Halted)
+	}
 	if s.ErrorMsg != "" {
 		fmt.Fprintf(&b, "↳ il y a %s : %s\n", s.ErrorAgo, s.ErrorMsg)
 	}
-	b.WriteString("\n")
-	fmt.Fprintf(&b, "Prix : %s %s\n", s.Price, s.Quote)
-	if s.RSI != "n/a" {
-		fmt.Fprintf(&b, "RSI(%s) : %s\n", s.RSITimeframe, s.RSI)
+	for _, p := range s.Pairs {
+		fmt.Fprintf(&b, "\n— %s —\n", p.Pair)
+		fmt.Fprintf(&b, "Prix : %s %s\n", p.Price, p.Quote)
+		if p.RSI != "n/a" {
+			fmt.Fprintf(&b, "RSI(%s) : %s\n", p.RSITimeframe, p.RSI)
+		}
+		fmt.Fprintf(&b, "Cycles actifs : %d   (achat rempli : %d)\n", p.ActiveCycles, p.OpenCycles)
+		fmt.Fprintf(&b, "PnL réalisé : %+.2f %s   (moy. %+.2f)\n", p.TotalProfit, p.Quote, p.AvgProfit)
 	}
-	fmt.Fprintf(&b, "Cycles actifs : %d   (achat rempli : %d)\n", s.ActiveCycles, s.OpenCycles)
-	fmt.Fprintf(&b, "PnL réalisé : %+.2f %s   (moy. %+.2f)\n", s.TotalProfit, s.Quote, s.AvgProfit)
 
 	b.WriteString("\n")
 	if s.LastCheckAgo != "" {
@@ -442,8 +457,8 @@ func renderCycles(cs []CycleView) string {
 	var b strings.Builder
 	b.WriteString("📈 Cycles actifs")
 	for _, c := range cs {
-		fmt.Fprintf(&b, "\n\n#%d · %s\n  %s @ %s → 🎯 %s  (%s)",
-			c.ID, c.Status, c.Amount, c.BuyPrice, c.Target, c.Age)
+		fmt.Fprintf(&b, "\n\n#%d · %s · %s\n  %s @ %s → 🎯 %s  (%s)",
+			c.ID, c.Pair, c.Status, c.Amount, c.BuyPrice, c.Target, c.Age)
 	}
 	return b.String()
 }
@@ -470,12 +485,15 @@ func renderBalance(s BalanceSnapshot) string {
 	return b.String()
 }
 
-func renderPnL(p PnLSnapshot) string {
+func renderPnL(ps []PnLSnapshot) string {
 	var b strings.Builder
-	b.WriteString("💰 PnL réalisé\n\n")
-	fmt.Fprintf(&b, "Cycles terminés : %d\n", p.Completed)
-	fmt.Fprintf(&b, "Profit total : %+.2f %s\n", p.TotalProfit, p.Quote)
-	fmt.Fprintf(&b, "Profit moyen : %+.2f %s", p.AvgProfit, p.Quote)
+	b.WriteString("💰 PnL réalisé")
+	for _, p := range ps {
+		fmt.Fprintf(&b, "\n\n— %s —\n", p.Pair)
+		fmt.Fprintf(&b, "Cycles terminés : %d\n", p.Completed)
+		fmt.Fprintf(&b, "Profit total : %+.2f %s\n", p.TotalProfit, p.Quote)
+		fmt.Fprintf(&b, "Profit moyen : %+.2f %s", p.AvgProfit, p.Quote)
+	}
 	return b.String()
 }
 
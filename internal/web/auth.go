@@ -0,0 +1,248 @@
+package web
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sessionCookie = "simplebot_session"
+	sessionTTL    = 7 * 24 * time.Hour
+
+	// Le jeton CSRF est attendu dans le champ de formulaire csrf_token (formulaires
+	// HTML) ou dans l'en-tête X-CSRF-Token (appels fetch).
+	csrfField  = "csrf_token"
+	csrfHeader = "X-CSRF-Token"
+
+	// Clés du contexte gin posées par le middleware de session.
+	ctxSession = "session"
+)
+
+// newToken génère un jeton aléatoire de 32 octets, encodé en hexadécimal.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// currentSession retourne la session posée par requireLogin (nil sur les pages publiques).
+func currentSession(c *gin.Context) *database.Session {
+	if s, ok := c.Get(ctxSession); ok {
+		return s.(*database.Session)
+	}
+	return nil
+}
+
+// changeSource identifie l'utilisateur connecté comme auteur d'une modification de
+// stratégie, pour son historique.
+func changeSource(c *gin.Context) database.ChangeSource {
+	src := database.ChangeSource{Origin: database.OriginWeb}
+	if isAPI(c) {
+		src.Origin = database.OriginAPI
+	}
+	if session := currentSession(c); session != nil {
+		src.User = session.User.Username
+	}
+	return src
+}
+
+// isAPI : les routes /api répondent en JSON, les pages par une redirection ou une page HTML.
+func isAPI(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, "/api/")
+}
+
+// secureCookies : cookie de session marqué Secure quand la WebUI est servie en HTTPS
+// (directement, ou derrière un reverse proxy TLS avec WEB_SECURE_COOKIES=1).
+func secureCookies(c *gin.Context) bool {
+	return c.Request.TLS != nil || os.Getenv("WEB_SECURE_COOKIES") == "1"
+}
+
+func setSessionCookie(c *gin.Context, token string, maxAge int) {
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(sessionCookie, token, maxAge, "/", "", secureCookies(c), true)
+}
+
+// requireLogin exige une session valide. Sans session, les pages redirigent vers
+// /login (en conservant la destination) et l'API répond 401.
+func requireLogin(db *database.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var session *database.Session
+		if token, err := c.Cookie(sessionCookie); err == nil && token != "" {
+			session, err = db.GetSession(token)
+			if err != nil {
+				logger.Warnf("Failed to load session: %v", err)
+			}
+		}
+		if session == nil {
+			if isAPI(c) {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentification requise"})
+				return
+			}
+			c.Redirect(http.StatusFound, "/login?next="+url.QueryEscape(c.Request.URL.RequestURI()))
+			c.Abort()
+			return
+		}
+		c.Set(ctxSession, session)
+		c.Next()
+	}
+}
+
+// requireRole restreint une route aux utilisateurs disposant au moins du rôle donné.
+// À placer après requireLogin.
+func requireRole(role string, exchangeName string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session := currentSession(c)
+		if session != nil && session.User.HasRole(role) {
+			c.Next()
+			return
+		}
+		msg := "accès réservé au rôle " + role
+		if isAPI(c) || c.Request.Method != http.MethodGet {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": msg})
+			return
+		}
+		c.HTML(http.StatusForbidden, "error_index", pageData(c, gin.H{
+			"title":    makeTitle(exchangeName, "Accès refusé"),
+			"exchange": exchangeName,
+			"active":   "",
+			"error":    msg,
+		}))
+		c.Abort()
+	}
+}
+
+// csrfProtect vérifie le jeton CSRF de la session sur toute requête modifiante
+// (POST, PUT, PATCH, DELETE). À placer après requireLogin.
+func csrfProtect() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		session := currentSession(c)
+		token := c.GetHeader(csrfHeader)
+		if token == "" {
+			token = c.PostForm(csrfField)
+		}
+		if session == nil || token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(session.CSRFToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "jeton CSRF invalide"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// pageData complète les données d'une page avec l'utilisateur connecté et le jeton
+// CSRF, utilisés par le layout (menu, déconnexion) et les formulaires.
+func pageData(c *gin.Context, h gin.H) gin.H {
+	if session := currentSession(c); session != nil {
+		h["user"] = session.User
+		h["csrfToken"] = session.CSRFToken
+		h["canOperate"] = session.User.HasRole(database.RoleOperator)
+	}
+	return h
+}
+
+// safeRedirect n'accepte que des chemins locaux comme destination après connexion
+// (pas de redirection ouverte vers un autre site).
+func safeRedirect(next string) string {
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return "/"
+	}
+	return next
+}
+
+// registerAuthHandlers enregistre les pages publiques de connexion.
+func registerAuthHandlers(router gin.IRouter, exchangeName string, db *database.DB) {
+	renderLogin := func(c *gin.Context, status int, next, username, errMsg string) {
+		c.HTML(status, "auth_login", gin.H{
+			"title":    makeTitle(exchangeName, "Connexion"),
+			"exchange": exchangeName,
+			"active":   "login",
+			"next":     next,
+			"username": username,
+			"error":    errMsg,
+		})
+	}
+
+	router.GET("/login", func(c *gin.Context) {
+		renderLogin(c, http.StatusOK, safeRedirect(c.Query("next")), "", "")
+	})
+
+	router.POST("/login", func(c *gin.Context) {
+		username := strings.TrimSpace(c.PostForm("username"))
+		next := safeRedirect(c.PostForm("next"))
+
+		user, err := db.AuthenticateUser(username, c.PostForm("password"))
+		if err != nil {
+			if err != database.ErrInvalidCredentials {
+				logger.Errorf("Failed to authenticate %s: %v", username, err)
+			} else {
+				logger.Warnf("Échec de connexion WebUI pour %q depuis %s", username, c.ClientIP())
+			}
+			renderLogin(c, http.StatusUnauthorized, next, username, "Identifiant ou mot de passe incorrect")
+			return
+		}
+
+		token, err := newToken()
+		if err == nil {
+			var csrf string
+			if csrf, err = newToken(); err == nil {
+				err = db.CreateSession(user.ID, token, csrf, time.Now().Add(sessionTTL))
+			}
+		}
+		if err != nil {
+			logger.Errorf("Failed to create session for %s: %v", username, err)
+			renderLogin(c, http.StatusInternalServerError, next, username, "Impossible d'ouvrir la session")
+			return
+		}
+		if err := db.DeleteExpiredSessions(); err != nil {
+			logger.Warnf("Failed to purge expired sessions: %v", err)
+		}
+
+		logger.Infof("Connexion WebUI de %s (%s)", user.Username, user.Role)
+		setSessionCookie(c, token, int(sessionTTL.Seconds()))
+		c.Redirect(http.StatusFound, next)
+	})
+}
+
+// bootstrapAdmin crée un premier operator à partir de WEB_ADMIN_USER (défaut « admin »)
+// et WEB_ADMIN_PASSWORD quand aucun utilisateur n'existe encore. Sans ces variables,
+// les comptes se gèrent avec `simple-bot admin --cmd user-add`.
+func bootstrapAdmin(db *database.DB) {
+	count, err := db.CountUsers()
+	if err != nil {
+		logger.Errorf("Failed to count WebUI users: %v", err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+	password := os.Getenv("WEB_ADMIN_PASSWORD")
+	if password == "" {
+		logger.Warnf("Aucun utilisateur WebUI : créez-en un avec `simple-bot admin --cmd user-add --user <nom> --role operator` ou définissez WEB_ADMIN_PASSWORD")
+		return
+	}
+	username := os.Getenv("WEB_ADMIN_USER")
+	if username == "" {
+		username = "admin"
+	}
+	if _, err := db.CreateUser(username, password, database.RoleOperator); err != nil {
+		logger.Errorf("Failed to create initial WebUI user %s: %v", username, err)
+		return
+	}
+	logger.Infof("Utilisateur WebUI initial %s créé (operator)", username)
+}
@@ -0,0 +1,109 @@
+package web
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/logger"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMain initialise le logger (utilisé par la base et les middlewares).
+func TestMain(m *testing.M) {
+	if err := logger.InitLogger("error", ""); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+// authRouter monte requireLogin + csrfProtect sur quelques routes API, avec une
+// session ouverte par rôle.
+func authRouter(t *testing.T) (*gin.Engine, map[string]*database.Session, map[string]string) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	db, err := database.NewDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	sessions := map[string]*database.Session{}
+	tokens := map[string]string{}
+	for _, role := range []string{database.RoleViewer, database.RoleOperator} {
+		u, err := db.CreateUser(role, "motdepasse", role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens[role] = "jeton-" + role
+		if err := db.CreateSession(u.ID, tokens[role], "csrf-"+role, time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		sessions[role], _ = db.GetSession(tokens[role])
+	}
+
+	router := gin.New()
+	private := router.Group("/", requireLogin(db), csrfProtect())
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) }
+	private.GET("/api/stats", ok)
+	private.POST("/api/buy", requireRole(database.RoleOperator, "test"), ok)
+	private.GET("/cycles", ok)
+	return router, sessions, tokens
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	router, sessions, tokens := authRouter(t)
+
+	do := func(method, path, role, csrf string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if role != "" {
+			req.AddCookie(&http.Cookie{Name: sessionCookie, Value: tokens[role]})
+		}
+		if csrf != "" {
+			req.Header.Set(csrfHeader, csrf)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	cases := []struct {
+		name, method, path, role, csrf string
+		want                           int
+	}{
+		{"API sans session", "GET", "/api/stats", "", "", http.StatusUnauthorized},
+		{"page sans session", "GET", "/cycles", "", "", http.StatusFound},
+		{"viewer en lecture", "GET", "/api/stats", database.RoleViewer, "", http.StatusOK},
+		{"viewer achat", "POST", "/api/buy", database.RoleViewer, sessions[database.RoleViewer].CSRFToken, http.StatusForbidden},
+		{"operator sans CSRF", "POST", "/api/buy", database.RoleOperator, "", http.StatusForbidden},
+		{"operator CSRF d'une autre session", "POST", "/api/buy", database.RoleOperator, sessions[database.RoleViewer].CSRFToken, http.StatusForbidden},
+		{"operator achat", "POST", "/api/buy", database.RoleOperator, sessions[database.RoleOperator].CSRFToken, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if w := do(tc.method, tc.path, tc.role, tc.csrf); w.Code != tc.want {
+			t.Errorf("%s : HTTP %d, attendu %d", tc.name, w.Code, tc.want)
+		}
+	}
+
+	if w := do("GET", "/cycles?pair=BTC/USDC", "", ""); w.Header().Get("Location") != "/login?next=%2Fcycles%3Fpair%3DBTC%2FUSDC" {
+		t.Errorf("redirection vers %q", w.Header().Get("Location"))
+	}
+}
+
+func TestSafeRedirect(t *testing.T) {
+	for next, want := range map[string]string{
+		"/strategies":          "/strategies",
+		"":                     "/",
+		"//evil.example":       "/",
+		"https://evil.example": "/",
+		"/\\evil.example":      "/",
+	} {
+		if got := safeRedirect(next); got != want {
+			t.Errorf("safeRedirect(%q) = %q, attendu %q", next, got, want)
+		}
+	}
+}
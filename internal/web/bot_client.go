@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"time"
@@ -154,6 +155,65 @@ func (bc *BotClient) TriggerBuy() (string, error) {
 	return botResponse.Message, nil
 }
 
+// KillSwitch demande au bot l'arrêt d'urgence : achats suspendus jusqu'au réarmement et
+// annulation des achats en attente. Retourne le nombre d'ordres annulés.
+func (bc *BotClient) KillSwitch(user string) (int, error) {
+	var response struct {
+		BotResponse
+		Cancelled int `json:"cancelled"`
+	}
+	if err := bc.postBreaker("/killswitch", user, &response); err != nil {
+		return 0, err
+	}
+	return response.Cancelled, nil
+}
+
+// ResetBreaker demande au bot de réarmer le disjoncteur (reprise des achats).
+func (bc *BotClient) ResetBreaker(user string) error {
+	var response BotResponse
+	return bc.postBreaker("/breaker/reset", user, &response)
+}
+
+// postBreaker envoie une commande du disjoncteur au bot, avec l'opérateur qui la demande.
+func (bc *BotClient) postBreaker(path, user string, response interface{}) error {
+	if bc.authToken == "" {
+		return fmt.Errorf("bot reload token not configured")
+	}
+
+	body, err := json.Marshal(map[string]string{"user": user})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	req, err := http.NewRequest("POST", bc.baseURL+path, bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+bc.authToken)
+
+	resp, err := bc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send %s request: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(raw, response)
+	if resp.StatusCode != http.StatusOK {
+		var botResponse BotResponse
+		_ = json.Unmarshal(raw, &botResponse)
+		errorMsg := botResponse.Message
+		if errorMsg == "" {
+			errorMsg = botResponse.Error
+		}
+		if errorMsg == "" {
+			errorMsg = fmt.Sprintf("HTTP %d", resp.StatusCode)
+		}
+		return fmt.Errorf("%s", errorMsg)
+	}
+	return nil
+}
+
 // RequestCandleCollection demande au bot de collecter des bougies pour une paire/timeframe
 func (bc *BotClient) RequestCandleCollection(pair, timeframe string, since *int64, limit int) (*CollectCandlesResponse, error) {
 	if bc.authToken == "" {
@@ -0,0 +1,76 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"bot/internal/core/database"
+	"bot/internal/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventTypesQuery lit le filtre ?types=order.filled,cycle.completed (vide = tous).
+func eventTypesQuery(c *gin.Context) []events.Type {
+	var list []string
+	for _, t := range strings.Split(c.Query("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			list = append(list, t)
+		}
+	}
+	types, _ := events.ParseTypes(list)
+	return types
+}
+
+// streamEvents ouvre un flux SSE des événements du bot, relus en base chaque seconde.
+// Seuls les événements postérieurs à la connexion sont poussés, sauf si le client
+// transmet l'en-tête Last-Event-ID (reconnexion automatique d'EventSource) : le flux
+// reprend alors juste après le dernier événement reçu. L'historique récent est
+// disponible via GET /api/events.
+func streamEvents(c *gin.Context, db *database.DB) {
+	types := eventTypesQuery(c)
+
+	lastID, err := strconv.ParseInt(c.GetHeader("Last-Event-ID"), 10, 64)
+	if err != nil {
+		lastID = 0
+		if last, err := db.GetEvents(0, 1); err == nil && len(last) > 0 {
+			lastID = last[0].ID
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	fmt.Fprint(c.Writer, ": ok\n\n")
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	heartbeat := 0
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-ticker.C:
+		}
+		list, err := db.GetEvents(lastID, 100, types...)
+		if err == nil {
+			for _, e := range list {
+				data, _ := json.Marshal(e)
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+				lastID = e.ID
+			}
+		}
+		if heartbeat++; heartbeat >= 15 {
+			heartbeat = 0
+			fmt.Fprint(w, ": ping\n\n")
+		}
+		return true
+	})
+}
@@ -4,6 +4,8 @@ import (
 	"bot/internal/algorithms"
 	"bot/internal/chat"
 	"bot/internal/core/database"
+	"bot/internal/events"
+	"bot/internal/ledger"
 	"bot/internal/logger"
 	"bot/internal/market"
 	"bot/internal/version"
@@ -20,7 +22,6 @@ import (
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/gin-contrib/multitemplate"
 	"github.com/gin-gonic/gin"
-	"github.com/robfig/cron/v3"
 )
 
 // Variable globale pour le client bot
@@ -30,79 +31,6 @@ var botClient *BotClient
 // avec exactement les mêmes règles que celles appliquées au runtime par le scheduler.
 var strategyRegistry = algorithms.NewAlgorithmRegistry()
 
-// validateStrategyForm valide une stratégie soumise via le formulaire web. Couvre
-// d'abord les champs communs, le filtre de tendance EMA (non couvert par les algos),
-// puis délègue la validation spécifique à l'algorithme (RSI/MACD + taille dynamique)
-// à son ValidateConfig pour garantir la parité avec le runtime.
-func validateStrategyForm(s database.Strategy) error {
-	// Champs de base communs à toutes les stratégies
-	if strings.TrimSpace(s.Name) == "" {
-		return fmt.Errorf("le nom de la stratégie est requis")
-	}
-	// Déclenchement des achats : cron (heure fixe) XOR intervalle périodique.
-	// Exactement un des deux doit être renseigné.
-	hasCron := strings.TrimSpace(s.CronExpression) != ""
-	hasInterval := s.BuyIntervalSeconds > 0
-	if hasCron && hasInterval {
-		return fmt.Errorf("choisissez soit une expression cron, soit un intervalle périodique, pas les deux")
-	}
-	if !hasCron && !hasInterval {
-		return fmt.Errorf("une expression cron ou un intervalle d'achat périodique est requis")
-	}
-	if hasCron {
-		// Parité avec le runtime : le scheduler utilise robfig/cron pour planifier.
-		// Un cron invalide passait la validation puis échouait silencieusement à se planifier.
-		if _, err := cron.ParseStandard(strings.TrimSpace(s.CronExpression)); err != nil {
-			return fmt.Errorf("expression cron invalide : %w", err)
-		}
-	}
-	if hasInterval && s.BuyIntervalSeconds < 3600 {
-		return fmt.Errorf("l'intervalle d'achat périodique doit être d'au moins 1 heure (reçu %d s)", s.BuyIntervalSeconds)
-	}
-	if s.QuoteAmount <= 0 {
-		return fmt.Errorf("le montant par ordre doit être positif (reçu %.2f)", s.QuoteAmount)
-	}
-	if s.MaxConcurrentCycles < 0 {
-		return fmt.Errorf("le nombre de cycles simultanés ne peut pas être négatif (0 = illimité, reçu %d)", s.MaxConcurrentCycles)
-	}
-	if s.MaxBuyOrderAgeHours < 0 {
-		return fmt.Errorf("l'âge maximal d'un ordre d'achat ne peut pas être négatif (0 = désactivé, reçu %d)", s.MaxBuyOrderAgeHours)
-	}
-	if s.ProfitTarget <= 0 {
-		return fmt.Errorf("l'objectif de profit doit être positif (reçu %.2f)", s.ProfitTarget)
-	}
-	if s.TrailingStopDelta < 0 {
-		return fmt.Errorf("le trailing stop ne peut pas être négatif (reçu %.2f)", s.TrailingStopDelta)
-	}
-	if s.SellOffset < 0 {
-		return fmt.Errorf("l'offset de vente ne peut pas être négatif (reçu %.2f)", s.SellOffset)
-	}
-	if s.VolatilityPeriod != nil && *s.VolatilityPeriod <= 0 {
-		return fmt.Errorf("la période de volatilité doit être positive (reçu %d)", *s.VolatilityPeriod)
-	}
-
-	// Filtre de tendance EMA (si activé) — non couvert par les ValidateConfig des algos
-	if s.TrendFilterEnabled {
-		if s.TrendFilterFastPeriod == nil || s.TrendFilterSlowPeriod == nil {
-			return fmt.Errorf("les périodes EMA rapide et lente sont requises quand le filtre de tendance est activé")
-		}
-		if *s.TrendFilterFastPeriod <= 0 || *s.TrendFilterSlowPeriod <= 0 {
-			return fmt.Errorf("les périodes EMA du filtre de tendance doivent être positives")
-		}
-		if *s.TrendFilterFastPeriod >= *s.TrendFilterSlowPeriod {
-			return fmt.Errorf("l'EMA rapide (%d) doit être inférieure à l'EMA lente (%d)",
-				*s.TrendFilterFastPeriod, *s.TrendFilterSlowPeriod)
-		}
-	}
-
-	// Validation spécifique à l'algorithme (réutilise les règles du runtime)
-	algo, ok := strategyRegistry.Get(s.AlgorithmName)
-	if !ok {
-		return fmt.Errorf("algorithme inconnu : %q", s.AlgorithmName)
-	}
-	return algo.ValidateConfig(s)
-}
-
 // parseTrigger lit le mode de déclenchement des achats depuis le formulaire :
 // soit une expression cron (heure fixe), soit un intervalle périodique
 // (valeur + unité). Retourne (cron, intervalSeconds) avec au plus un renseigné.
@@ -141,6 +69,39 @@ func parseBuyOrderAge(c *gin.Context) int {
 	return value * unitHours
 }
 
+// parseExitRules lit les sorties de protection depuis le formulaire : stop-loss (%),
+// âge maximal d'un cycle (jours + mode de sortie) et prix de revient après N jours.
+// Champ vide = 0 = désactivé.
+func parseExitRules(c *gin.Context) (stopLossPercent float64, maxCycleAgeDays int, maxCycleAgeExit string, breakEvenAfterDays int) {
+	stopLossPercent, _ = strconv.ParseFloat(c.PostForm("stop_loss_percent"), 64)
+	maxCycleAgeDays, _ = strconv.Atoi(c.PostForm("max_cycle_age_days"))
+	maxCycleAgeExit = c.PostForm("max_cycle_age_exit")
+	if maxCycleAgeExit == "" {
+		maxCycleAgeExit = database.CycleAgeExitMarket
+	}
+	breakEvenAfterDays, _ = strconv.Atoi(c.PostForm("break_even_after_days"))
+	return
+}
+
+// parseGrid lit les paramètres de la grille (algorithme grid) : bornes de prix et
+// nombre de niveaux. Champs vides = 0 (non utilisés par les autres algorithmes).
+func parseGrid(c *gin.Context) (gridLowerPrice, gridUpperPrice float64, gridLevels int) {
+	gridLowerPrice, _ = strconv.ParseFloat(c.PostForm("grid_lower_price"), 64)
+	gridUpperPrice, _ = strconv.ParseFloat(c.PostForm("grid_upper_price"), 64)
+	gridLevels, _ = strconv.Atoi(c.PostForm("grid_levels"))
+	return
+}
+
+// parsePair lit la paire de la stratégie depuis le formulaire (ex: « eth/usdc » ->
+// « ETH/USDC »). Champ vide = paire par défaut de l'instance (TRADING_PAIR).
+func parsePair(c *gin.Context, defaultPair string) string {
+	pair := strings.ToUpper(strings.TrimSpace(c.PostForm("pair")))
+	if pair == "" {
+		return defaultPair
+	}
+	return pair
+}
+
 // Fonctions helper pour les templates
 var templateFuncs = template.FuncMap{
 	// version : exposée à tous les templates (via le layout partagé) pour afficher
@@ -315,20 +276,39 @@ func createRenderer(rootDir string) multitemplate.Renderer {
 	return r
 }
 
+// renderHTML rend une page en y ajoutant l'utilisateur connecté et le jeton CSRF.
+func renderHTML(c *gin.Context, code int, name string, h gin.H) {
+	c.HTML(code, name, pageData(c, h))
+}
+
 func makeTitle(exchangeName string, title string) string {
 	return fmt.Sprintf("%s - %s - Simple Bot by PrY", exchangeName, title)
 }
 
-func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *database.DB, client *BotClient, logFilePath string) {
+func registerHandlers(router gin.IRouter, exchangeName, tradingPair string, db *database.DB, client *BotClient, logFilePath string) {
 	// Assigner le client bot global
 	botClient = client
+
+	// Routes modifiantes (stratégies, achat manuel, assistant IA) : rôle operator.
+	operator := requireRole(database.RoleOperator, exchangeName)
 	// Configuration des templates
 
 	// router.LoadHTMLGlob("templates/*")
 
+	// Déconnexion : ferme la session côté serveur et efface le cookie.
+	router.POST("/logout", func(c *gin.Context) {
+		if token, err := c.Cookie(sessionCookie); err == nil {
+			if err := db.DeleteSession(token); err != nil {
+				logger.Warnf("Failed to delete session: %v", err)
+			}
+		}
+		setSessionCookie(c, "", -1)
+		c.Redirect(http.StatusFound, "/login")
+	})
+
 	// Page d'erreur générique
 	handleError := func(c *gin.Context, title, active, errMsg string) {
-		c.HTML(http.StatusInternalServerError, "error_index", gin.H{
+		renderHTML(c, http.StatusInternalServerError, "error_index", gin.H{
 			"title":    makeTitle(exchangeName, title),
 			"exchange": exchangeName,
 			"active":   active,
@@ -336,15 +316,34 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		})
 	}
 
+	// Paires tradées par les stratégies, pour le sélecteur des pages filtrables
+	// (?pair=BTC/USDC ; vide = toutes les paires).
+	strategyPairs := func() []string {
+		pairs, err := db.GetStrategyPairs()
+		if err != nil {
+			logger.Warnf("Failed to get strategy pairs: %v", err)
+		}
+		return pairs
+	}
+
 	// Dashboard
 	router.GET("/", func(c *gin.Context) {
-		metrics, err := db.GetDashboardMetrics()
+		pair := c.Query("pair")
+		metrics, err := db.GetDashboardMetrics(pair)
 		if err != nil {
 			handleError(c, "Erreur - Dashboard", "dashboard", "Failed to get dashboard metrics: "+err.Error())
 			return
 		}
+		trips, err := db.GetCircuitTrips(5)
+		if err != nil {
+			logger.Warnf("Failed to get circuit trips: %v", err)
+		}
+		var activeTrip *database.CircuitTrip
+		if len(trips) > 0 && trips[0].ResetAt == nil {
+			activeTrip = &trips[0]
+		}
 
-		c.HTML(http.StatusOK, "dashboard_index", gin.H{
+		renderHTML(c, http.StatusOK, "dashboard_index", gin.H{
 			"title":       makeTitle(exchangeName, "Dashboard"),
 			"exchange":    exchangeName,
 			"active":      "dashboard",
@@ -353,18 +352,42 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			"totalProfit": metrics["total_profit"],
 			"successRate": metrics["success_rate"],
 			"autoRefresh": false,
+			"pair":        pair,
+			"pairs":       strategyPairs(),
+			"currentURL":  "/",
+			"activeTrip":  activeTrip,
+			"trips":       trips,
 		})
 	})
 
+	// Arrêt d'urgence : le bot suspend les achats et annule les achats en attente.
+	router.POST("/breaker/kill", operator, func(c *gin.Context) {
+		if _, err := botClient.KillSwitch(changeSource(c).User); err != nil {
+			handleError(c, "Erreur - Dashboard", "dashboard", "Arrêt d'urgence impossible : "+err.Error())
+			return
+		}
+		c.Redirect(http.StatusFound, "/")
+	})
+
+	// Réarmement du disjoncteur : reprise des achats.
+	router.POST("/breaker/reset", operator, func(c *gin.Context) {
+		if err := botClient.ResetBreaker(changeSource(c).User); err != nil {
+			handleError(c, "Erreur - Dashboard", "dashboard", "Réarmement impossible : "+err.Error())
+			return
+		}
+		c.Redirect(http.StatusFound, "/")
+	})
+
 	// Ordres en attente
 	// Ordres - helper local pour éviter la répétition
 	serveOrders := func(c *gin.Context, filter, pageTitle, currentURL string) {
-		orders, err := db.GetOrders(filter)
+		pair := c.Query("pair")
+		orders, err := db.GetOrders(filter, pair)
 		if err != nil {
 			handleError(c, "Erreur - Ordres", "orders", "Failed to get orders: "+err.Error())
 			return
 		}
-		c.HTML(http.StatusOK, "orders_index", gin.H{
+		renderHTML(c, http.StatusOK, "orders_index", gin.H{
 			"title":      makeTitle(exchangeName, pageTitle),
 			"exchange":   exchangeName,
 			"active":     "orders",
@@ -372,6 +395,8 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			"orders":     orders,
 			"orderType":  filter,
 			"currentURL": currentURL,
+			"pair":       pair,
+			"pairs":      strategyPairs(),
 		})
 	}
 
@@ -388,20 +413,87 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		serveOrders(c, "all", "Tous les Ordres", "/orders/all")
 	})
 
+	// Comptabilité : grand livre FIFO, PnL réalisé par période et export CSV
+	router.GET("/ledger", func(c *gin.Context) {
+		pair, year, period := ledgerQuery(c)
+		trades, err := db.GetTrades(pair)
+		if err != nil {
+			handleError(c, "Erreur - Comptabilité", "ledger", "Failed to get trades: "+err.Error())
+			return
+		}
+		l := ledger.Build(trades)
+		years := l.Years()
+		if year != 0 && !containsYear(years, year) {
+			years = append([]int{year}, years...)
+		}
+		entries := l.EntriesForYear(year)
+		// Journal : exécutions les plus récentes en premier
+		journal := make([]ledger.Entry, len(entries))
+		for i, e := range entries {
+			journal[len(entries)-1-i] = e
+		}
+		renderHTML(c, http.StatusOK, "ledger_index", gin.H{
+			"title":      makeTitle(exchangeName, "Comptabilité"),
+			"exchange":   exchangeName,
+			"active":     "ledger",
+			"positions":  l.Positions,
+			"pnl":        l.PnL(period, year),
+			"journal":    journal,
+			"years":      years,
+			"year":       year,
+			"period":     string(period),
+			"currentURL": "/ledger",
+			"pair":       pair,
+			"pairs":      strategyPairs(),
+		})
+	})
+
+	router.GET("/ledger/export.csv", func(c *gin.Context) {
+		pair, year, period := ledgerQuery(c)
+		trades, err := db.GetTrades(pair)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Failed to get trades: %v", err)
+			return
+		}
+		l := ledger.Build(trades)
+
+		report := "journal"
+		if c.Query("report") == "pnl" {
+			report = "pnl-" + string(period)
+		}
+		name := exchangeName + "-" + report
+		if year != 0 {
+			name += "-" + strconv.Itoa(year)
+		}
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+		if report == "journal" {
+			err = ledger.WriteJournalCSV(c.Writer, l.EntriesForYear(year))
+		} else {
+			err = ledger.WritePnLCSV(c.Writer, l.PnL(period, year))
+		}
+		if err != nil {
+			logger.Errorf("Failed to write ledger CSV: %v", err)
+		}
+	})
+
 	// Cycles - helper local pour éviter la répétition
 	serveCycles := func(c *gin.Context, filter, titleSuffix, currentURL string) {
-		cycles, err := db.GetCycles(filter)
+		pair := c.Query("pair")
+		cycles, err := db.GetCycles(filter, pair)
 		if err != nil {
 			handleError(c, "Erreur - Cycles", "cycles", "Failed to get cycles: "+err.Error())
 			return
 		}
-		c.HTML(http.StatusOK, "cycles_index", gin.H{
+		renderHTML(c, http.StatusOK, "cycles_index", gin.H{
 			"title":      makeTitle(exchangeName, titleSuffix),
 			"exchange":   exchangeName,
 			"active":     "cycles",
 			"cycles":     cycles,
 			"cycleType":  filter,
 			"currentURL": currentURL,
+			"pair":       pair,
+			"pairs":      strategyPairs(),
 		})
 	}
 
@@ -434,35 +526,50 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			handleError(c, "Erreur - Stratégies", "strategies", "Failed to get strategies: "+err.Error())
 			return
 		}
+		// Limites de risque : capital engagé et refus d'achat en cours, par stratégie
+		openCapital, err := db.GetOpenCapital()
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Failed to get open capital: "+err.Error())
+			return
+		}
+		buySkips, err := db.GetActiveBuySkips()
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Failed to get buy skips: "+err.Error())
+			return
+		}
 
-		c.HTML(http.StatusOK, "strategies_index", gin.H{
-			"title":      makeTitle(exchangeName, "Stratégies"),
-			"exchange":   exchangeName,
-			"active":     "strategies",
-			"strategies": strategies,
+		renderHTML(c, http.StatusOK, "strategies_index", gin.H{
+			"title":       makeTitle(exchangeName, "Stratégies"),
+			"exchange":    exchangeName,
+			"active":      "strategies",
+			"strategies":  strategies,
+			"openCapital": openCapital,
+			"buySkips":    buySkips,
 		})
 	})
 
 	// Create new strategy form
-	router.GET("/strategies/new", func(c *gin.Context) {
-		c.HTML(http.StatusOK, "strategies_new", gin.H{
-			"title":       makeTitle(exchangeName, "Nouvelle Stratégie"),
-			"exchange":    exchangeName,
-			"active":      "strategies",
-			"algorithms":  []string{"rsi_dca", "macd_cross"},          // Available algorithms
-			"strategy":    &database.Strategy{MaxConcurrentCycles: 1}, // Défauts explicites (1 cycle) ; 0 est réservé à « illimité »
-			"pageTitle":   "Nouvelle Stratégie",
-			"cardHeader":  "Configuration de la stratégie",
-			"formAction":  "/strategies",
-			"submitLabel": "Créer la stratégie",
-			"submitClass": "btn-success",
+	router.GET("/strategies/new", operator, func(c *gin.Context) {
+		renderHTML(c, http.StatusOK, "strategies_new", gin.H{
+			"title":        makeTitle(exchangeName, "Nouvelle Stratégie"),
+			"exchange":     exchangeName,
+			"active":       "strategies",
+			"algorithms":   []string{"rsi_dca", "macd_cross", "bollinger_dca", "grid"},    // Available algorithms
+			"strategy":     &database.Strategy{MaxConcurrentCycles: 1, Pair: tradingPair}, // Défauts explicites (1 cycle) ; 0 est réservé à « illimité »
+			"pageTitle":    "Nouvelle Stratégie",
+			"cardHeader":   "Configuration de la stratégie",
+			"formAction":   "/strategies",
+			"submitLabel":  "Créer la stratégie",
+			"submitClass":  "btn-success",
+			"marketLimits": formMarketLimits(db),
 		})
 	})
 
 	// Create strategy (POST)
-	router.POST("/strategies", func(c *gin.Context) {
+	router.POST("/strategies", operator, func(c *gin.Context) {
 		name := c.PostForm("name")
 		description := c.PostForm("description")
+		pair := parsePair(c, tradingPair)
 		algorithm := c.PostForm("algorithm")
 		cron, buyIntervalSeconds := parseTrigger(c)
 		enabled := c.PostForm("enabled") == "on"
@@ -472,7 +579,10 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		trailingStopDelta, _ := strconv.ParseFloat(c.PostForm("trailing_stop_delta"), 64)
 		sellOffset, _ := strconv.ParseFloat(c.PostForm("sell_offset"), 64)
 		concurrentCycles, _ := strconv.ParseInt(c.PostForm("concurrent_cycles"), 10, 64)
+		maxCapital, _ := strconv.ParseFloat(c.PostForm("max_capital"), 64)
 		maxBuyOrderAgeHours := parseBuyOrderAge(c)
+		stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays := parseExitRules(c)
+		gridLowerPrice, gridUpperPrice, gridLevels := parseGrid(c)
 
 		// Set defaults if not provided
 		if trailingStopDelta == 0 {
@@ -567,10 +677,10 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		}
 
 		// Valider la configuration avant insertion (parité avec le runtime)
-		if err := validateStrategyForm(database.Strategy{
-			Name: name, Description: description, Enabled: enabled,
+		candidate := database.Strategy{
+			Name: name, Description: description, Pair: pair, Enabled: enabled,
 			AlgorithmName: algorithm, CronExpression: cron, BuyIntervalSeconds: buyIntervalSeconds, QuoteAmount: quoteAmount,
-			MaxConcurrentCycles: int(concurrentCycles), MaxBuyOrderAgeHours: maxBuyOrderAgeHours,
+			MaxConcurrentCycles: int(concurrentCycles), MaxBuyOrderAgeHours: maxBuyOrderAgeHours, MaxCapital: maxCapital,
 			ProfitTarget: profitTarget, TrailingStopDelta: trailingStopDelta, SellOffset: sellOffset,
 			RSIThreshold: rsiThreshold, RSIPeriod: rsiPeriod, RSITimeframe: rsiTimeframe,
 			MACDFastPeriod: macdFastPeriod, MACDSlowPeriod: macdSlowPeriod, MACDSignalPeriod: macdSignalPeriod, MACDTimeframe: macdTimeframe,
@@ -579,13 +689,21 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			TrendFilterEnabled: trendFilterEnabled, TrendFilterFastPeriod: trendFilterFastPeriod, TrendFilterSlowPeriod: trendFilterSlowPeriod, TrendFilterTimeframe: trendFilterTimeframe,
 			DynamicSizingEnabled: dynamicSizingEnabled, DynamicSizingMin: dynamicSizingMin, DynamicSizingMax: dynamicSizingMax,
 			DynamicSizingWindowDays: dynamicSizingWindowDays, DynamicSizingFullDrawdown: dynamicSizingFullDrawdown,
-		}); err != nil {
+			StopLossPercent: stopLossPercent, MaxCycleAgeDays: maxCycleAgeDays, MaxCycleAgeExit: maxCycleAgeExit, BreakEvenAfterDays: breakEvenAfterDays,
+			GridLowerPrice: gridLowerPrice, GridUpperPrice: gridUpperPrice, GridLevels: gridLevels,
+		}
+		if err := strategyRegistry.ValidateStrategy(candidate); err != nil {
+			handleError(c, "Erreur - Création Stratégie", "strategies", "Configuration invalide : "+err.Error())
+			return
+		}
+		// Limites de marché de l'exchange : un montant intradable est refusé dès l'enregistrement
+		if err := algorithms.CheckStrategyMarket(db, candidate); err != nil {
 			handleError(c, "Erreur - Création Stratégie", "strategies", "Configuration invalide : "+err.Error())
 			return
 		}
 
 		// Use the new comprehensive method
-		err := db.CreateStrategyFromWeb(name, description, algorithm, cron, buyIntervalSeconds, enabled,
+		err := db.CreateStrategyFromWeb(changeSource(c), name, description, pair, algorithm, cron, buyIntervalSeconds, enabled,
 			quoteAmount, profitTarget, trailingStopDelta, sellOffset,
 			rsiThreshold, rsiPeriod, rsiTimeframe,
 			macdFastPeriod, macdSlowPeriod, macdSignalPeriod, macdTimeframe,
@@ -593,7 +711,9 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			volatilityPeriod, volatilityAdjustment, volatilityTimeframe,
 			trendFilterEnabled, trendFilterFastPeriod, trendFilterSlowPeriod, trendFilterTimeframe,
 			dynamicSizingEnabled, dynamicSizingMin, dynamicSizingMax, dynamicSizingWindowDays, dynamicSizingFullDrawdown,
-			int(concurrentCycles), maxBuyOrderAgeHours)
+			stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays,
+			gridLowerPrice, gridUpperPrice, gridLevels,
+			int(concurrentCycles), maxBuyOrderAgeHours, maxCapital)
 		if err != nil {
 			handleError(c, "Erreur - Création Stratégie", "strategies", "Failed to create strategy: "+err.Error())
 			return
@@ -608,7 +728,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 	})
 
 	// Edit strategy form
-	router.GET("/strategies/:id/edit", func(c *gin.Context) {
+	router.GET("/strategies/:id/edit", operator, func(c *gin.Context) {
 		idStr := c.Param("id")
 		strategyID, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -622,22 +742,23 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			return
 		}
 
-		c.HTML(http.StatusOK, "strategies_edit", gin.H{
-			"title":       makeTitle(exchangeName, "Modifier Stratégie"),
-			"exchange":    exchangeName,
-			"active":      "strategies",
-			"strategy":    strategy,
-			"algorithms":  []string{"rsi_dca", "macd_cross"},
-			"pageTitle":   "Modification de la Stratégie",
-			"cardHeader":  "Édition de la stratégie",
-			"formAction":  fmt.Sprintf("/strategies/%d/update", strategy.ID),
-			"submitLabel": "Modifier la stratégie",
-			"submitClass": "btn-primary",
+		renderHTML(c, http.StatusOK, "strategies_edit", gin.H{
+			"title":        makeTitle(exchangeName, "Modifier Stratégie"),
+			"exchange":     exchangeName,
+			"active":       "strategies",
+			"strategy":     strategy,
+			"algorithms":   []string{"rsi_dca", "macd_cross", "bollinger_dca", "grid"},
+			"pageTitle":    "Modification de la Stratégie",
+			"cardHeader":   "Édition de la stratégie",
+			"formAction":   fmt.Sprintf("/strategies/%d/update", strategy.ID),
+			"submitLabel":  "Modifier la stratégie",
+			"submitClass":  "btn-primary",
+			"marketLimits": formMarketLimits(db),
 		})
 	})
 
 	// Update strategy
-	router.POST("/strategies/:id/update", func(c *gin.Context) {
+	router.POST("/strategies/:id/update", operator, func(c *gin.Context) {
 		idStr := c.Param("id")
 		strategyID, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -648,6 +769,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		// Extraire les données du formulaire (similaire à la création)
 		name := c.PostForm("name")
 		description := c.PostForm("description")
+		pair := parsePair(c, tradingPair)
 		algorithm := c.PostForm("algorithm")
 		cron, buyIntervalSeconds := parseTrigger(c)
 		enabled := c.PostForm("enabled") == "on"
@@ -657,7 +779,10 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		trailingStopDelta, _ := strconv.ParseFloat(c.PostForm("trailing_stop_delta"), 64)
 		sellOffset, _ := strconv.ParseFloat(c.PostForm("sell_offset"), 64)
 		concurrentCycles, _ := strconv.ParseInt(c.PostForm("concurrent_cycles"), 10, 64)
+		maxCapital, _ := strconv.ParseFloat(c.PostForm("max_capital"), 64)
 		maxBuyOrderAgeHours := parseBuyOrderAge(c)
+		stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays := parseExitRules(c)
+		gridLowerPrice, gridUpperPrice, gridLevels := parseGrid(c)
 
 		// Set defaults if not provided
 		if trailingStopDelta == 0 {
@@ -752,10 +877,10 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		}
 
 		// Valider la configuration avant mise à jour (parité avec le runtime)
-		if err := validateStrategyForm(database.Strategy{
-			Name: name, Description: description, Enabled: enabled,
+		candidate := database.Strategy{
+			Name: name, Description: description, Pair: pair, Enabled: enabled,
 			AlgorithmName: algorithm, CronExpression: cron, BuyIntervalSeconds: buyIntervalSeconds, QuoteAmount: quoteAmount,
-			MaxConcurrentCycles: int(concurrentCycles), MaxBuyOrderAgeHours: maxBuyOrderAgeHours,
+			MaxConcurrentCycles: int(concurrentCycles), MaxBuyOrderAgeHours: maxBuyOrderAgeHours, MaxCapital: maxCapital,
 			ProfitTarget: profitTarget, TrailingStopDelta: trailingStopDelta, SellOffset: sellOffset,
 			RSIThreshold: rsiThreshold, RSIPeriod: rsiPeriod, RSITimeframe: rsiTimeframe,
 			MACDFastPeriod: macdFastPeriod, MACDSlowPeriod: macdSlowPeriod, MACDSignalPeriod: macdSignalPeriod, MACDTimeframe: macdTimeframe,
@@ -764,12 +889,20 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			TrendFilterEnabled: trendFilterEnabled, TrendFilterFastPeriod: trendFilterFastPeriod, TrendFilterSlowPeriod: trendFilterSlowPeriod, TrendFilterTimeframe: trendFilterTimeframe,
 			DynamicSizingEnabled: dynamicSizingEnabled, DynamicSizingMin: dynamicSizingMin, DynamicSizingMax: dynamicSizingMax,
 			DynamicSizingWindowDays: dynamicSizingWindowDays, DynamicSizingFullDrawdown: dynamicSizingFullDrawdown,
-		}); err != nil {
+			StopLossPercent: stopLossPercent, MaxCycleAgeDays: maxCycleAgeDays, MaxCycleAgeExit: maxCycleAgeExit, BreakEvenAfterDays: breakEvenAfterDays,
+			GridLowerPrice: gridLowerPrice, GridUpperPrice: gridUpperPrice, GridLevels: gridLevels,
+		}
+		if err := strategyRegistry.ValidateStrategy(candidate); err != nil {
+			handleError(c, "Erreur - Modification Stratégie", "strategies", "Configuration invalide : "+err.Error())
+			return
+		}
+		// Limites de marché de l'exchange : un montant intradable est refusé dès l'enregistrement
+		if err := algorithms.CheckStrategyMarket(db, candidate); err != nil {
 			handleError(c, "Erreur - Modification Stratégie", "strategies", "Configuration invalide : "+err.Error())
 			return
 		}
 
-		err = db.UpdateStrategy(strategyID, name, description, algorithm, cron, buyIntervalSeconds, enabled,
+		err = db.UpdateStrategy(changeSource(c), strategyID, name, description, pair, algorithm, cron, buyIntervalSeconds, enabled,
 			quoteAmount, profitTarget, trailingStopDelta, sellOffset,
 			rsiThreshold, rsiPeriod, rsiTimeframe,
 			macdFastPeriod, macdSlowPeriod, macdSignalPeriod, macdTimeframe,
@@ -777,7 +910,9 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			volatilityPeriod, volatilityAdjustment, volatilityTimeframe,
 			trendFilterEnabled, trendFilterFastPeriod, trendFilterSlowPeriod, trendFilterTimeframe,
 			dynamicSizingEnabled, dynamicSizingMin, dynamicSizingMax, dynamicSizingWindowDays, dynamicSizingFullDrawdown,
-			int(concurrentCycles), maxBuyOrderAgeHours)
+			stopLossPercent, maxCycleAgeDays, maxCycleAgeExit, breakEvenAfterDays,
+			gridLowerPrice, gridUpperPrice, gridLevels,
+			int(concurrentCycles), maxBuyOrderAgeHours, maxCapital)
 		if err != nil {
 			handleError(c, "Erreur - Modification Stratégie", "strategies", "Failed to update strategy: "+err.Error())
 			return
@@ -792,7 +927,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 	})
 
 	// Toggle strategy enabled/disabled
-	router.POST("/strategies/:id/toggle", func(c *gin.Context) {
+	router.POST("/strategies/:id/toggle", operator, func(c *gin.Context) {
 		idStr := c.Param("id")
 		strategyID, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -801,7 +936,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		}
 
 		// Toggle strategy enabled status
-		err = db.ToggleStrategyEnabled(strategyID)
+		err = db.ToggleStrategyEnabled(changeSource(c), strategyID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
@@ -816,7 +951,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 	})
 
 	// Delete strategy
-	router.DELETE("/strategies/:id", func(c *gin.Context) {
+	router.DELETE("/strategies/:id", operator, func(c *gin.Context) {
 		idStr := c.Param("id")
 		strategyID, err := strconv.Atoi(idStr)
 		if err != nil {
@@ -825,7 +960,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		}
 
 		// Delete strategy
-		err = db.DeleteStrategy(strategyID)
+		err = db.DeleteStrategy(changeSource(c), strategyID)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
@@ -839,9 +974,94 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		c.JSON(http.StatusOK, gin.H{"message": "Strategy deleted successfully"})
 	})
 
+	// Historique des modifications d'une stratégie (diff par révision, bilan des cycles)
+	router.GET("/strategies/:id/history", func(c *gin.Context) {
+		strategyID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Invalid strategy ID")
+			return
+		}
+
+		revisions, err := db.GetStrategyRevisions(strategyID)
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Failed to get strategy history: "+err.Error())
+			return
+		}
+		// La stratégie peut avoir été supprimée : son historique reste consultable
+		strategy, _ := db.GetStrategy(strategyID)
+		if strategy == nil && len(revisions) == 0 {
+			handleError(c, "Erreur - Stratégies", "strategies", "Strategy not found")
+			return
+		}
+
+		renderHTML(c, http.StatusOK, "strategies_history", gin.H{
+			"title":     makeTitle(exchangeName, "Historique Stratégie"),
+			"exchange":  exchangeName,
+			"active":    "strategies",
+			"strategy":  strategy,
+			"name":      strategyName(strategy, revisions),
+			"revisions": revisionViews(strategy, revisions),
+		})
+	})
+
+	// Revenir à une révision : sa configuration remplace la configuration actuelle
+	// (validée comme une modification depuis le formulaire, et historisée à son tour).
+	router.POST("/strategies/:id/revisions/:rev/revert", operator, func(c *gin.Context) {
+		strategyID, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Invalid strategy ID")
+			return
+		}
+		revisionID, err := strconv.Atoi(c.Param("rev"))
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Invalid revision ID")
+			return
+		}
+
+		revision, err := db.GetStrategyRevision(revisionID)
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Failed to get revision: "+err.Error())
+			return
+		}
+		if revision == nil || revision.StrategyID != strategyID || revision.After == nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Revision not found")
+			return
+		}
+
+		s := *revision.After
+		if err := strategyRegistry.ValidateStrategy(s); err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Configuration de la révision invalide : "+err.Error())
+			return
+		}
+		src := changeSource(c)
+		src.Comment = fmt.Sprintf("retour à la révision #%d", revision.ID)
+		err = db.UpdateStrategy(src, strategyID, s.Name, s.Description, s.Pair, s.AlgorithmName, s.CronExpression, s.BuyIntervalSeconds, s.Enabled,
+			s.QuoteAmount, s.ProfitTarget, s.TrailingStopDelta, s.SellOffset,
+			s.RSIThreshold, s.RSIPeriod, s.RSITimeframe,
+			s.MACDFastPeriod, s.MACDSlowPeriod, s.MACDSignalPeriod, s.MACDTimeframe,
+			s.BBPeriod, s.BBMultiplier, s.BBTimeframe,
+			s.VolatilityPeriod, s.VolatilityAdjustment, s.VolatilityTimeframe,
+			s.TrendFilterEnabled, s.TrendFilterFastPeriod, s.TrendFilterSlowPeriod, s.TrendFilterTimeframe,
+			s.DynamicSizingEnabled, s.DynamicSizingMin, s.DynamicSizingMax, s.DynamicSizingWindowDays, s.DynamicSizingFullDrawdown,
+			s.StopLossPercent, s.MaxCycleAgeDays, s.MaxCycleAgeExit, s.BreakEvenAfterDays,
+			s.GridLowerPrice, s.GridUpperPrice, s.GridLevels,
+			s.MaxConcurrentCycles, s.MaxBuyOrderAgeHours, s.MaxCapital)
+		if err != nil {
+			handleError(c, "Erreur - Stratégies", "strategies", "Failed to revert strategy: "+err.Error())
+			return
+		}
+
+		// Notifier le bot après retour à une révision
+		if err := botClient.NotifyReload(); err != nil {
+			logger.Warnf("Failed to notify bot of strategy revert: %v", err)
+		}
+
+		c.Redirect(http.StatusFound, fmt.Sprintf("/strategies/%d/history", strategyID))
+	})
+
 	// Vue des logs (le webui lit le fichier LOG_FILE écrit par le bot, partagé via le volume db/)
 	router.GET("/logs", func(c *gin.Context) {
-		c.HTML(http.StatusOK, "logs_index", gin.H{
+		renderHTML(c, http.StatusOK, "logs_index", gin.H{
 			"title":      makeTitle(exchangeName, "Logs"),
 			"exchange":   exchangeName,
 			"active":     "logs",
@@ -850,8 +1070,8 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 	})
 
 	// Assistant IA : page de chat conseiller (analyse + backtest via Claude)
-	router.GET("/chat", func(c *gin.Context) {
-		c.HTML(http.StatusOK, "chat_index", gin.H{
+	router.GET("/chat", operator, func(c *gin.Context) {
+		renderHTML(c, http.StatusOK, "chat_index", gin.H{
 			"title":     makeTitle(exchangeName, "Assistant"),
 			"exchange":  exchangeName,
 			"active":    "chat",
@@ -872,7 +1092,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		// Assistant IA : flux SSE de la boucle agentique. Le corps POST porte
 		// l'historique de conversation ; la réponse streame les événements
 		// (text / tool / tool_result / done / error).
-		api.POST("/chat", func(c *gin.Context) {
+		api.POST("/chat", operator, func(c *gin.Context) {
 			if chatAgent == nil {
 				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "ANTHROPIC_API_KEY non configurée"})
 				return
@@ -947,8 +1167,32 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 			streamLogs(c, logFilePath)
 		})
 
+		// Événements du bot (ordres, cycles, disjoncteur...) : derniers événements, ou
+		// ceux postérieurs à ?after_id, filtrables par ?types=
+		api.GET("/events", func(c *gin.Context) {
+			afterID, _ := strconv.ParseInt(c.Query("after_id"), 10, 64)
+			limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+			if limit <= 0 || limit > 1000 {
+				limit = 100
+			}
+			list, err := db.GetEvents(afterID, limit, eventTypesQuery(c)...)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if list == nil {
+				list = []events.Event{}
+			}
+			c.JSON(http.StatusOK, gin.H{"events": list})
+		})
+
+		// Événements : flux SSE temps réel
+		api.GET("/events/stream", func(c *gin.Context) {
+			streamEvents(c, db)
+		})
+
 		api.GET("/stats", func(c *gin.Context) {
-			metrics, err := db.GetDashboardMetrics()
+			metrics, err := db.GetDashboardMetrics(c.Query("pair"))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -957,7 +1201,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		})
 
 		// Achat manuel : relaie la demande au bot (override hors RSI/cooldown).
-		api.POST("/buy", func(c *gin.Context) {
+		api.POST("/buy", operator, func(c *gin.Context) {
 			if botClient == nil {
 				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "bot client non configuré"})
 				return
@@ -971,7 +1215,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		})
 
 		serveAPIOrders := func(c *gin.Context, filter string) {
-			orders, err := db.GetOrders(filter)
+			orders, err := db.GetOrders(filter, c.Query("pair"))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -985,7 +1229,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 		api.GET("/orders/all", func(c *gin.Context) { serveAPIOrders(c, "all") })
 
 		serveAPICycles := func(c *gin.Context, filter string) {
-			cycles, err := db.GetCycles(filter)
+			cycles, err := db.GetCycles(filter, c.Query("pair"))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -1003,7 +1247,7 @@ func registerHandlers(router *gin.Engine, exchangeName, tradingPair string, db *
 
 		// Endpoint pour les profits détaillés
 		api.GET("/profits", func(c *gin.Context) {
-			avgProfit, totalProfit, err := db.GetProfitStats()
+			avgProfit, totalProfit, err := db.GetProfitStats(c.Query("pair"))
 			if err != nil {
 				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 				return
@@ -1227,8 +1471,14 @@ func SetupServer(exchangeName, tradingPair string, db *database.DB, rootDir, log
 	// Initialiser le client bot
 	client := NewBotClient()
 
+	// Authentification : seules les pages de connexion (et /static) sont publiques,
+	// tout le reste exige une session et un jeton CSRF sur les requêtes modifiantes.
+	bootstrapAdmin(db)
+	registerAuthHandlers(router, exchangeName, db)
+	private := router.Group("/", requireLogin(db), csrfProtect())
+
 	// Enregistrer les handlers
-	registerHandlers(router, exchangeName, tradingPair, db, client, logFilePath)
+	registerHandlers(private, exchangeName, tradingPair, db, client, logFilePath)
 
 	return router
 }
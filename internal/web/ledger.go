@@ -0,0 +1,35 @@
+package web
+
+import (
+	"strconv"
+	"time"
+
+	"bot/internal/ledger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ledgerQuery lit les filtres de la page Comptabilité : ?pair=, ?year= (défaut : année
+// en cours, 0 = toutes) et ?period=daily|monthly (défaut : monthly).
+func ledgerQuery(c *gin.Context) (pair string, year int, period ledger.Period) {
+	pair = c.Query("pair")
+	year = time.Now().UTC().Year()
+	if y, err := strconv.Atoi(c.Query("year")); err == nil && y >= 0 {
+		year = y
+	}
+	period = ledger.Monthly
+	if c.Query("period") == string(ledger.Daily) {
+		period = ledger.Daily
+	}
+	return pair, year, period
+}
+
+// containsYear indique si year figure dans years (sélecteur d'année de la page).
+func containsYear(years []int, year int) bool {
+	for _, y := range years {
+		if y == year {
+			return true
+		}
+	}
+	return false
+}
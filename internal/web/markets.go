@@ -0,0 +1,31 @@
+package web
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/logger"
+)
+
+// marketLimitsView : limites de marché d'une paire relevées par le bot et dernier prix
+// connu, affichées sous le montant par ordre du formulaire des stratégies.
+type marketLimitsView struct {
+	database.MarketLimits
+	Price float64 `json:"price"`
+}
+
+// formMarketLimits retourne les limites de marché de toutes les paires connues, pour le
+// contrôle côté client du formulaire des stratégies.
+func formMarketLimits(db *database.DB) []marketLimitsView {
+	limits, err := db.GetAllMarketLimits()
+	if err != nil {
+		logger.Warnf("Failed to get market limits: %v", err)
+		return nil
+	}
+	views := make([]marketLimitsView, len(limits))
+	for i, l := range limits {
+		views[i].MarketLimits = l
+		if views[i].Price, err = db.GetLastPrice(l.Pair); err != nil {
+			logger.Warnf("Failed to get last price of %s: %v", l.Pair, err)
+		}
+	}
+	return views
+}
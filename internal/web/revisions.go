@@ -0,0 +1,59 @@
+package web
+
+import (
+	"bot/internal/core/database"
+	"bot/internal/strategyfile"
+)
+
+// revisionView est une révision telle qu'affichée dans l'historique d'une stratégie.
+type revisionView struct {
+	database.StrategyRevision
+	// Paramètres modifiés (création : paramètres renseignés, suppression : aucun)
+	Changes []strategyfile.Change
+	// Current : la configuration de la révision est celle de la stratégie aujourd'hui
+	Current bool
+	// CanRevert : la révision a une configuration différente de l'actuelle, applicable
+	CanRevert bool
+}
+
+// revisionViews calcule le diff de chaque révision et repère celle qui correspond à
+// la configuration actuelle (strategy nil si la stratégie a été supprimée).
+func revisionViews(strategy *database.Strategy, revisions []database.StrategyRevision) []revisionView {
+	var current strategyfile.Spec
+	if strategy != nil {
+		current = strategyfile.FromStrategy(*strategy)
+	}
+
+	views := make([]revisionView, 0, len(revisions))
+	for _, r := range revisions {
+		v := revisionView{StrategyRevision: r}
+		if r.After != nil {
+			after := strategyfile.FromStrategy(*r.After)
+			var before strategyfile.Spec
+			if r.Before != nil {
+				before = strategyfile.FromStrategy(*r.Before)
+			}
+			v.Changes = strategyfile.Diff(before, after)
+			if strategy != nil {
+				v.Current = len(strategyfile.Diff(current, after)) == 0
+				v.CanRevert = !v.Current
+			}
+		}
+		views = append(views, v)
+	}
+	return views
+}
+
+// strategyName retourne le nom de la stratégie, ou celui de sa dernière configuration
+// connue si elle a été supprimée.
+func strategyName(strategy *database.Strategy, revisions []database.StrategyRevision) string {
+	if strategy != nil {
+		return strategy.Name
+	}
+	for _, r := range revisions {
+		if r.Before != nil {
+			return r.Before.Name
+		}
+	}
+	return ""
+}